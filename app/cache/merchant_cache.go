@@ -10,14 +10,22 @@ package cache
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"github.com/garyburd/redigo/redis"
 	"github.com/jsix/gof/storage"
+	"go2o/core"
 	"go2o/core/domain/interface/express"
 	"go2o/core/domain/interface/merchant"
+	"go2o/core/infrastructure/apisign"
+	"go2o/core/module"
 	"go2o/core/service/rsi"
+	"go2o/core/variable"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // 获取商户信息缓存
@@ -86,16 +94,83 @@ func GetMerchantIdByApiId(apiId string) int32 {
 func GetMerchantApiInfo(mchId int32) *merchant.ApiInfo {
 	var d *merchant.ApiInfo = new(merchant.ApiInfo)
 	kvs := GetKVS()
-	key := fmt.Sprintf("cache:partner:api:info-%d", mchId)
+	key := fmt.Sprintf("%s%d", variable.KvMerchantApiInfo, mchId)
 	err := kvs.Get(key, &d)
 	if err != nil {
 		if d = rsi.MerchantService.GetApiInfo(mchId); d != nil {
-			kvs.Set(key, d)
+			kvs.SetExpire(key, d, DefaultMaxSeconds)
 		}
 	}
 	return d
 }
 
+// 获取接口分组的限流配置
+func GetMerchantApiLimit(mchId int32, group string) *merchant.ApiLimit {
+	var d *merchant.ApiLimit = new(merchant.ApiLimit)
+	kvs := GetKVS()
+	key := fmt.Sprintf("%s%d-%s", variable.KvMerchantApiLimit, mchId, group)
+	if err := kvs.Get(key, &d); err != nil {
+		if d = rsi.MerchantService.GetApiLimit(mchId, group); d != nil {
+			kvs.SetExpire(key, d, DefaultMaxSeconds)
+		}
+	}
+	return d
+}
+
+// 获取接口令牌,返回nil表示未配置限流
+func TakeMerchantApiToken(mchId int32, group string) (*module.ApiLimitResult, error) {
+	l := GetMerchantApiLimit(mchId, group)
+	if l == nil {
+		return nil, nil
+	}
+	return rsi.MerchantService.TakeApiToken(l)
+}
+
+var _ apisign.NonceStore = new(apiNonceStore)
+
+// 接口签名随机串存储
+type apiNonceStore struct {
+}
+
+// 使用随机串,已使用过返回false
+func (a *apiNonceStore) Use(key string, expires int64) bool {
+	conn := core.GetRedisConn()
+	defer conn.Close()
+	_, err := redis.String(conn.Do("SET", "go2o:api:nonce:"+key, 1,
+		"EX", expires, "NX"))
+	return err == nil
+}
+
+var apiVerifier = apisign.NewVerifier(&apiNonceStore{})
+
+// 获取商户接口签名校验器
+func GetApiVerifier() *apisign.Verifier {
+	return apiVerifier
+}
+
+// 校验商户接口请求的签名,时间戳及随机串,返回商户编号;
+// 轮换密钥期间,新旧密钥签名均可通过校验
+func CheckMerchantApiSign(apiId string, method string, path string,
+	params url.Values) (int32, error) {
+	if len(apiId) == 0 {
+		return 0, errors.New("missing merchant_id")
+	}
+	req, sign, err := apisign.NewRequest(method, path, params)
+	if err != nil {
+		return 0, err
+	}
+	mchId := GetMerchantIdByApiId(apiId)
+	apiInfo := GetMerchantApiInfo(mchId)
+	if mchId <= 0 || apiInfo == nil || apiInfo.ApiSecret == "" {
+		return 0, errors.New("no such merchant")
+	}
+	if apiInfo.Enabled == 0 {
+		return mchId, errors.New("api has exipres")
+	}
+	secrets := apiInfo.ValidSecrets(time.Now().Unix())
+	return mchId, apiVerifier.Verify(req, sign, apiId, secrets...)
+}
+
 var (
 	expressCacheKey = "go2o:rep:express:ship-tab"
 )
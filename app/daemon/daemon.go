@@ -11,12 +11,10 @@ package daemon
 
 import (
 	"flag"
-	"fmt"
 	"github.com/garyburd/redigo/redis"
 	"github.com/jsix/gof"
 	"github.com/jsix/gof/db"
 	"github.com/jsix/gof/db/orm"
-	"github.com/jsix/gof/util"
 	"github.com/robfig/cron"
 	"go2o/app"
 	"go2o/core"
@@ -83,7 +81,7 @@ func RegisterService(s Service) {
 	services = append(services, s)
 }
 
-// 添加定时执行任务(默认5秒)
+// 添加定时执行任务(默认5秒),仅在主节点执行
 func AddTickerFunc(f Func) {
 	tickerInvokeFunc = append(tickerInvokeFunc, f)
 }
@@ -94,6 +92,7 @@ func Start() {
 		cronTab.Stop()
 		ticker.Stop()
 	}()
+	electLeader() // 竞选主节点
 	//运行自定义服务
 	for i, s := range services {
 		log.Println("** [ Go2o][ Daemon] - (", i, ")", s.Name(), "daemon running")
@@ -108,6 +107,9 @@ func startTicker() {
 	for {
 		select {
 		case <-ticker.C:
+			if !isLeader() {
+				continue
+			}
 			for _, f := range tickerInvokeFunc {
 				go f(appCtx)
 			}
@@ -126,11 +128,18 @@ func isHandled(key string, unix int64) bool {
 	return unix == int64(unix2)
 }
 
-// 标记最后处理时间
-func signHandled(key string, unix int64) {
-	conn := core.GetRedisConn()
-	defer conn.Close()
-	conn.Do("SET", key, unix)
+// 标记最后处理时间,仅主节点持有有效令牌时写入
+func signHandled(key string, unix int64) bool {
+	return signFenced(leaderModule().Token(leaderName), key, unix)
+}
+
+// 使用任务开始时的令牌标记最后处理时间,令牌失效时不写入
+func signFenced(token int64, key string, unix int64) bool {
+	ok, err := leaderModule().FencedSet(leaderName, token, key, unix)
+	if err != nil {
+		log.Println("[ Daemon][ Sign][ Error]:", key, err.Error())
+	}
+	return ok
 }
 
 // 比较最后运行的时间戳
@@ -138,29 +147,27 @@ func CompareLastUnix(key string, unix int64) bool {
 	return isHandled(key, unix)
 }
 
-// 设置最后运行的时间戳
-func SetLastUnix(key string, unix int64) {
-	signHandled(key, unix)
+// 设置最后运行的时间戳,非主节点时不设置并返回false
+func SetLastUnix(key string, unix int64) bool {
+	return signHandled(key, unix)
 }
 
 // 运行定时任务
 func startCronTab() {
 	//商户每日报表
-	cronTab.AddFunc("0 0 0 * * *", mchDayChart)
+	cronTab.AddFunc("0 0 0 * * *", singleton(mchDayChart))
 	//个人金融结算,每天00:20更新数据
-	cronTab.AddFunc("0 20 0 * * *", personFinanceSettle)
-	//检查订单过期,1分钟检测一次
-	cronTab.AddFunc("0 * * * * *", detectOrderExpires)
-	//订单自动收货,2分钟检测一次
-	cronTab.AddFunc("0 */2 * * * *", orderAutoReceive)
+	cronTab.AddFunc("0 20 0 * * *", singleton(personFinanceSettle))
+	//清理过期的站内信,每天00:40执行
+	cronTab.AddFunc("0 40 0 * * *", singleton(clearExpiredInbox))
 	cronTab.Start()
 }
 
-// 添加定时任务
+// 添加定时任务,仅在主节点执行
 func AddCron(spec string, cmd func()) {
 	mux.Lock()
 	defer mux.Unlock()
-	cronTab.AddFunc(spec, cmd)
+	cronTab.AddFunc(spec, singleton(cmd))
 }
 
 type defaultService struct {
@@ -187,12 +194,11 @@ func (d *defaultService) Name() string {
 // 启动服务
 func (d *defaultService) Start(a gof.App) {
 	d.app = a
-	go superviseMemberUpdate(services)
-	go superviseOrder(services)
-	go supervisePaymentOrderFinish(services)
+	go startEventRelay(services)
 	go startMailQueue(services)
-	go personFinanceSettle() //启动时结算
-	go mchDayChart()         //商户每日报表
+	go startDelayQueue()
+	go singleton(personFinanceSettle)() //启动时结算
+	go singleton(mchDayChart)()         //商户每日报表
 
 	//go func() {
 	//    time.Sleep(time.Second * 6)
@@ -210,23 +216,21 @@ func (d *defaultService) OrderObs(o *define.ComplexOrder) bool {
 		d.app.Log().Println("-- 订单", o.OrderNo, "状态:", o.State)
 	}
 	if d.sOrder {
-		conn := core.GetRedisConn()
-		defer conn.Close()
 		defer Recover()
 
 		switch o.State {
 		//订单未支付，则超时自动取消
 		case order.StatAwaitingPayment:
-			d.updateOrderExpires(conn, o)
+			d.updateOrderExpires(o)
 		//自动确认订单
 		case order.StatAwaitingConfirm:
-			d.orderAutoConfirm(conn, o)
+			d.orderAutoConfirm(o)
 		//订单自动收货
 		case order.StatShipped:
-			d.orderAutoReceive(conn, o)
+			d.orderAutoReceive(o)
 		//订单已经收货
 		case order.StatCompleted:
-			d.orderReceived(conn, o)
+			d.orderReceived(o)
 		}
 	}
 	return true
@@ -261,68 +265,44 @@ func (d *defaultService) testSubId(o *define.ComplexOrder) (string, bool) {
 	return o.OrderNo, false
 }
 
-// 批量删除REDIS KEY
-func (d *defaultService) batchDelKeys(conn redis.Conn, key string) {
-	list, err := redis.Strings(conn.Do("KEYS", key))
-	if err == nil {
-		for _, oKey := range list {
-			conn.Do("DEL", oKey)
-		}
-	}
-}
-
 //设置订单过期时间
-func (d *defaultService) updateOrderExpires(conn redis.Conn, o *define.ComplexOrder) {
+func (d *defaultService) updateOrderExpires(o *define.ComplexOrder) {
 	//订单刚创建时,设置过期时间
 	if o.State == order.StatAwaitingPayment {
 		ss := rsi.FoundationService.GetGlobMchSaleConf()
 		unix := o.UpdateTime + int64(ss.OrderTimeOutMinute)*60
-		t := time.Unix(unix, 0)
-		tk := getTick(t)
-		orderNo, sub := d.testSubId(o)
-		prefix := util.BoolExt.TString(sub, "sub!", "")
-		key := fmt.Sprintf("%s:%s%s:%s", variable.KvOrderExpiresTime, prefix, orderNo, tk)
-		//log.Println(" [Daemon][Exprire][ Key]:", key)
-		conn.Do("SET", key, unix)
+		scheduleOrderJob(jobOrderTimeout, o, unix)
 	}
 }
 
 //取消订单过期时间
-func (d *defaultService) cancelOrderExpires(conn redis.Conn, o *define.ComplexOrder) {
-	orderNo, sub := d.testSubId(o)
-	prefix := util.BoolExt.TString(sub, "sub!", "")
-	key := fmt.Sprintf("%s:%s%s:*", variable.KvOrderExpiresTime, prefix, orderNo)
-	d.batchDelKeys(conn, key)
+func (d *defaultService) cancelOrderExpires(o *define.ComplexOrder) {
+	cancelOrderJob(jobOrderTimeout, o)
 }
 
-// 确认订单
-func (d *defaultService) orderAutoConfirm(conn redis.Conn, o *define.ComplexOrder) {
-	rsi.ShoppingService.ConfirmOrder(d.testSubId(o))
-	d.cancelOrderExpires(conn, o) //付款后取消自动取消
+// 安排自动确认订单,在订单更新时间加上确认分钟数后执行
+func (d *defaultService) orderAutoConfirm(o *define.ComplexOrder) {
+	if o.State == order.StatAwaitingConfirm {
+		ss := rsi.FoundationService.GetGlobMchSaleConf()
+		unix := o.UpdateTime + int64(ss.OrderConfirmAfterMinute)*60
+		scheduleOrderJob(jobOrderConfirm, o, unix)
+	}
+	d.cancelOrderExpires(o) //付款后取消自动取消
 }
 
 // 订单自动收货
-func (d *defaultService) orderAutoReceive(conn redis.Conn, o *define.ComplexOrder) {
+func (d *defaultService) orderAutoReceive(o *define.ComplexOrder) {
 	if o.State == order.StatShipped {
 		ss := rsi.FoundationService.GetGlobMchSaleConf()
 		unix := o.UpdateTime + int64(ss.OrderTimeOutReceiveHour)*60*60
-		t := time.Unix(unix, 0)
-		tk := getTick(t)
-		orderNo, sub := d.testSubId(o)
-		prefix := util.BoolExt.TString(sub, "sub!", "")
-		key := fmt.Sprintf("%s:%s%s:%s", variable.KvOrderAutoReceive, prefix, orderNo, tk)
-		//log.Println(" [Daemon][AutoReceive][ Key]:", key)
-		conn.Do("SET", key, unix)
+		scheduleOrderJob(jobOrderReceive, o, unix)
 	}
 }
 
 // 完成订单自动收货
-func (d *defaultService) orderReceived(conn redis.Conn, o *define.ComplexOrder) {
+func (d *defaultService) orderReceived(o *define.ComplexOrder) {
 	if o.State == order.StatCompleted {
-		orderNo, sub := d.testSubId(o)
-		prefix := util.BoolExt.TString(sub, "sub!", "")
-		key := fmt.Sprintf("%s:%s%s:*", variable.KvOrderAutoReceive, prefix, orderNo)
-		d.batchDelKeys(conn, key)
+		cancelOrderJob(jobOrderReceive, o)
 	}
 }
 
@@ -330,7 +310,7 @@ func (d *defaultService) orderReceived(conn redis.Conn, o *define.ComplexOrder)
 // 返回布尔值,如果返回false,则不继续执行
 func (d *defaultService) HandleMailQueue(list []*mss.MailTask) bool {
 	defer Recover()
-	if !d.sMail {
+	if d.sMail {
 		handleMailQueue(list)
 	}
 	return true
@@ -8,9 +8,38 @@ import (
 
 // 测试生成商户的报表
 func TestGenerateMchDayChart(t *testing.T) {
+	token, _ := leaderModule().Campaign(leaderName)
+	if !acquireFence(token) {
+		t.Error("acquire leader fence failed")
+		t.FailNow()
+	}
 	dt := time.Now().Add(time.Hour * -24 * 15)
 	for i := 0; i < 15; i++ {
 		st, et := tool.GetStartEndUnix(dt.Add(time.Hour * 24 * time.Duration(i)))
-		generateMchDayChart(st, et)
+		if !generateMchDayChart(token, st, et) {
+			t.Error("generate chart failed")
+			t.FailNow()
+		}
+	}
+}
+
+// 测试新主节点登记令牌后,旧令牌不能写入报表
+func TestMchDayChartFence(t *testing.T) {
+	token, _ := leaderModule().Campaign(leaderName)
+	if !acquireFence(token) {
+		t.Error("acquire leader fence failed")
+		t.FailNow()
+	}
+	// 模拟新主节点登记了更大的令牌
+	_db.ExecNonQuery("UPDATE sys_leader_fence SET token=? WHERE name=?",
+		token+1, leaderName)
+	defer _db.ExecNonQuery("UPDATE sys_leader_fence SET token=? WHERE name=?",
+		token, leaderName)
+	if checkFence(token) {
+		t.Error("stale token should be fenced")
+	}
+	st, et := tool.GetStartEndUnix(time.Now().Add(time.Hour * -24))
+	if generateMchDayChart(token, st, et) {
+		t.Error("stale token should not write chart")
 	}
 }
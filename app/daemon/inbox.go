@@ -0,0 +1,23 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : inbox
+ * author : jarryliu
+ * date : 2026-10-23 14:40
+ * description : 清理已过期的站内信
+ * history :
+ */
+package daemon
+
+import (
+	"go2o/core/service/rsi"
+	"log"
+)
+
+func clearExpiredInbox() {
+	n, err := rsi.MssService.ClearExpiredInbox()
+	if err != nil {
+		log.Println("[ Inbox][ Clear][ Error]:", err.Error())
+		return
+	}
+	log.Println("[ Inbox][ Clear]: clear expired messages:", n)
+}
@@ -0,0 +1,78 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : leader.go
+ * author : jarryliu
+ * date : 2026-10-20 19:20
+ * description : 守护进程主节点,多个实例运行时仅主节点执行计划任务及定时任务
+ * history :
+ */
+package daemon
+
+import (
+	"go2o/core/module"
+	"log"
+)
+
+const (
+	// 守护进程选举名称
+	leaderName = "daemon"
+)
+
+func leaderModule() *module.LeaderModule {
+	return module.Get(module.M_LEADER).(*module.LeaderModule)
+}
+
+// 竞选主节点并保持租约,竞选前以数据库中登记的令牌作为令牌的起始值
+func electLeader() {
+	l := leaderModule()
+	var fence int64
+	_db.ExecScalar("SELECT MAX(token) FROM sys_leader_fence WHERE name=?",
+		&fence, leaderName)
+	if err := l.SeedToken(leaderName, fence); err != nil {
+		log.Println("[ Daemon][ Leader][ Error]:", err.Error())
+	}
+	if _, err := l.Campaign(leaderName); err != nil {
+		log.Println("[ Daemon][ Leader][ Error]:", err.Error())
+	}
+	go l.Keep(leaderName, nil)
+}
+
+// 当前节点是否为主节点
+func isLeader() bool {
+	return leaderModule().IsLeader(leaderName)
+}
+
+// 包装仅在主节点执行的任务
+func singleton(f func()) func() {
+	return func() {
+		if isLeader() {
+			f()
+		}
+	}
+}
+
+// 在数据库中登记主节点令牌,返回令牌是否有效。令牌只增不减,
+// 新主节点登记后,旧主节点的令牌在数据库中失效
+func acquireFence(token int64) bool {
+	if token <= 0 {
+		return false
+	}
+	_, err := _db.ExecNonQuery(`INSERT INTO sys_leader_fence(name,token)
+		VALUES(?,?) ON DUPLICATE KEY UPDATE token=GREATEST(token,VALUES(token))`,
+		leaderName, token)
+	if err != nil {
+		log.Println("[ Daemon][ Leader][ Fence][ Error]:", err.Error())
+		return false
+	}
+	return checkFence(token)
+}
+
+// 令牌是否仍然有效,写入数据前校验,租约过期或已有新主节点时返回false
+func checkFence(token int64) bool {
+	if leaderModule().Token(leaderName) != token {
+		return false
+	}
+	var v int64
+	_db.ExecScalar("SELECT token FROM sys_leader_fence WHERE name=?", &v, leaderName)
+	return v == token
+}
@@ -3,7 +3,7 @@
  * name : mail_queue
  * author : jarryliu
  * date : 2015-07-27 17:06
- * description :
+ * description : 邮件队列,由固定数量的协程发送,发送失败按指数退避重试
  * history :
  */
 package daemon
@@ -13,64 +13,197 @@ import (
 	"go2o/core"
 	"go2o/core/domain/interface/mss"
 	mssIns "go2o/core/infrastructure/mss"
+	"go2o/core/module"
 	"go2o/core/variable"
 	"strconv"
 	"time"
 )
 
-var (
-	mailChan chan int
+const (
+	// 邮件重试的延迟任务
+	jobMailRetry = "mail.retry"
+	// 默认的发送协程数
+	defaultMailWorkers = 4
 )
 
-// 邮件队列
+// 邮件队列,从队列中获取邮件编号并交由发送协程处理
 func startMailQueue(ss []Service) {
+	workers := appCtx.Config().GetInt(variable.MailQueueWorkers)
+	if workers <= 0 {
+		workers = defaultMailWorkers
+	}
+	ch := make(chan int32, workers)
+	for i := 0; i < workers; i++ {
+		go mailWorker(ss, ch)
+	}
+	go singleton(recoverMailTasks)()
+	for {
+		if err := popMailTasks(ch); err != nil {
+			appCtx.Log().Println("[ Daemon][ MailQueue][ Error] - ",
+				err.Error(), "; retry after 10 seconds.")
+			time.Sleep(time.Second * 10)
+		}
+	}
+}
+
+func popMailTasks(ch chan<- int32) error {
 	conn := core.GetRedisConn()
 	defer conn.Close()
-	//var id int
 	for {
 		arr, err := redis.Values(conn.Do("BLPOP", variable.KvNewMailTask, 0))
+		if err != nil {
+			return err
+		}
+		id, err := strconv.Atoi(string(arr[1].([]byte)))
 		if err == nil {
-			_, err = strconv.Atoi(string(arr[1].([]byte)))
-			if err == nil {
-				//todo: 此处获取所有需发送的邮件,应去掉从数据库批量查询操作
-				sendForWaitingQueue(ss)
-			}
-		} else {
+			ch <- int32(id)
+		}
+	}
+}
+
+// 加入到邮件队列
+func pushMailTask(id int32) error {
+	conn := core.GetRedisConn()
+	defer conn.Close()
+	_, err := conn.Do("RPUSH", variable.KvNewMailTask, id)
+	return err
+}
+
+// 重试邮件的延迟任务,将邮件重新加入到队列
+func retryMailTask(j *module.DelayJob) error {
+	id, err := strconv.Atoi(j.Key)
+	if err != nil {
+		return nil
+	}
+	return pushMailTask(int32(id))
+}
+
+// 安排邮件在指定时间重新发送,相同的邮件只保留一个任务
+func scheduleMailTask(id int32, unix int64) error {
+	return delayQueue().Schedule(jobMailRetry, strconv.Itoa(int(id)),
+		"", time.Unix(unix, 0))
+}
+
+// 恢复未发送的邮件,如取出后未发送完成的邮件,及旧版本发送失败的邮件
+func recoverMailTasks() {
+	var list = []*mss.MailTask{}
+	orm := appCtx.Db().GetOrm()
+	orm.Select(&list, "is_send = 0 OR (is_failed = 1 AND attempts = 0)")
+	unix := time.Now().Unix()
+	for _, t := range list {
+		if t.IsSend == 1 {
+			t.IsSend = 0
+			orm.Save(t.Id, t)
+		}
+		next := t.NextTime
+		if next < unix {
+			next = unix
+		}
+		if err := scheduleMailTask(t.Id, next); err != nil {
 			appCtx.Log().Println("[ Daemon][ MailQueue][ Error] - ", err.Error())
 			break
 		}
 	}
 }
 
-func sendForWaitingQueue(ss []Service) {
-	var list = []*mss.MailTask{}
-	err := appCtx.Db().GetOrm().Select(&list, "is_send = 0 OR is_failed = 1")
-	if err == nil && len(list) > 0 {
+// 锁定邮件,避免多个协程同时发送
+func lockMailTask(id int32) bool {
+	conn := core.GetRedisConn()
+	defer conn.Close()
+	key := variable.KvNewMailTask + ":lock:" + strconv.Itoa(int(id))
+	s, _ := redis.String(conn.Do("SET", key, 1, "EX", 600, "NX"))
+	return s == "OK"
+}
+
+func unlockMailTask(id int32) {
+	conn := core.GetRedisConn()
+	defer conn.Close()
+	conn.Do("DEL", variable.KvNewMailTask+":lock:"+strconv.Itoa(int(id)))
+}
+
+// 发送协程,锁定邮件后再读取状态,
+// 避免读取后其他协程发送完成并解锁,导致邮件重复发送
+func mailWorker(ss []Service, ch <-chan int32) {
+	for id := range ch {
+		if !lockMailTask(id) {
+			continue
+		}
+		t := mss.MailTask{}
+		if appCtx.Db().GetOrm().Get(id, &t) != nil || t.IsSend == 1 {
+			unlockMailTask(id)
+			continue
+		}
+		list := []*mss.MailTask{&t}
 		for _, s := range ss {
 			if !s.HandleMailQueue(list) {
 				break
 			}
 		}
+		unlockMailTask(id)
 	}
 }
 
+// 重试间隔,按发送次数指数增长
+func mailBackoff(attempts int) int64 {
+	sec := int64(mss.MailRetryBase)
+	for i := 1; i < attempts && sec < mss.MailRetryMax; i++ {
+		sec *= 2
+	}
+	if sec > mss.MailRetryMax {
+		sec = mss.MailRetryMax
+	}
+	return sec
+}
+
+func mailFromTask(t *mss.MailTask) *mssIns.Mail {
+	m := &mssIns.Mail{
+		To:      []string{t.SendTo},
+		Subject: t.Subject,
+		Html:    t.Body,
+		Text:    t.TextBody,
+	}
+	for _, a := range t.GetAttachments() {
+		m.Attachments = append(m.Attachments, &mssIns.Attachment{
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Path:        a.Path,
+			Data:        a.Data,
+		})
+	}
+	return m
+}
+
+// 发送邮件,失败时安排重试,达到最大发送次数后不再发送
 func handleMailQueue(list []*mss.MailTask) {
-	mailChan = make(chan int, len(list))
-	for _, v := range list {
-		go func(ch chan int, t *mss.MailTask) {
-			err := mssIns.SendMailWithDefaultConfig(t.Subject, []string{t.SendTo}, []byte(t.Body))
-			if err != nil {
-				appCtx.Log().Error(err)
-				t.IsFailed = 1
-				t.IsSend = 1
+	for _, t := range list {
+		err := mssIns.SendWithDefaultConfig(mailFromTask(t))
+		unix := time.Now().Unix()
+		t.Attempts++
+		t.SendTime = unix
+		t.NextTime = 0
+		if err == nil {
+			t.IsSend = 1
+			t.IsFailed = 0
+			t.Error = ""
+		} else {
+			appCtx.Log().Error(err)
+			t.IsFailed = 1
+			if r := []rune(err.Error()); len(r) > 255 {
+				t.Error = string(r[:255])
 			} else {
+				t.Error = string(r)
+			}
+			if t.Attempts >= mss.MailMaxAttempts {
 				t.IsSend = 1
-				t.IsFailed = 0
+			} else {
+				t.NextTime = unix + mailBackoff(t.Attempts)
+			}
+		}
+		appCtx.Db().GetOrm().Save(t.Id, t)
+		if t.NextTime > 0 {
+			if err = scheduleMailTask(t.Id, t.NextTime); err != nil {
+				appCtx.Log().Error(err)
 			}
-			t.SendTime = time.Now().Unix()
-			appCtx.Db().GetOrm().Save(t.Id, t)
-			mailChan <- 0
-		}(mailChan, v)
-		<-mailChan
+		}
 	}
 }
@@ -11,12 +11,10 @@ package daemon
 
 import (
 	"database/sql"
-	"github.com/jsix/gof/db/orm"
 	"go2o/core/domain/interface/merchant"
 	"go2o/core/domain/interface/order"
 	"go2o/core/infrastructure/tool"
 	"log"
-	"sync"
 	"time"
 )
 
@@ -35,21 +33,33 @@ func mchDayChart() {
 		log.Println("[ Mch][ Day][ Chart]: today chart is generated!")
 		return
 	}
+	// 使用开始时的令牌写入报表,令牌失效后不再写入
+	token := leaderModule().Token(leaderName)
+	if !acquireFence(token) {
+		return
+	}
 	now := time.Now().Add(time.Hour * -24)
 	st, et := tool.GetStartEndUnix(now)
-	generateMchDayChart(st, et)
-	signHandled(mchDayChartKey, unix)
+	if generateMchDayChart(token, st, et) {
+		signFenced(token, mchDayChartKey, unix)
+	}
 }
 
-// 生成每日报表
-func generateMchDayChart(start, end int64) {
+// 生成每日报表,令牌失效时停止并返回false
+func generateMchDayChart(token int64, start, end int64) bool {
 	begin := 0
 	size := 20
 	var mchList []int32
 	var tmp int32
 	dateStr := time.Unix(start, 0).Format("2006-01-02")
 	// 清理数据
-	appCtx.Db().ExecNonQuery(`DELETE FROM mch_day_chart WHERE date_str=?`, dateStr)
+	_, err := appCtx.Db().ExecNonQuery(`DELETE FROM mch_day_chart WHERE date_str=?
+		AND EXISTS(SELECT 1 FROM sys_leader_fence WHERE name=? AND token=?)`,
+		dateStr, leaderName, token)
+	if err != nil || !checkFence(token) {
+		log.Println("[ Mch][ Day][ Chart]: leader token expired, stop generating")
+		return false
+	}
 	// 开始统计数据
 	for {
 		mchList = []int32{}
@@ -59,22 +69,23 @@ func generateMchDayChart(start, end int64) {
 				mchList = append(mchList, tmp)
 			}
 		}, begin, size)
-		wg := &sync.WaitGroup{}
 		for _, v := range mchList {
-			wg.Add(1)
-			genDayChartForMch(wg, v, dateStr, start, end)
+			if !genDayChartForMch(token, v, dateStr, start, end) {
+				log.Println("[ Mch][ Day][ Chart]: leader token expired, stop generating")
+				return false
+			}
 		}
-		wg.Wait()
 		if l := len(mchList); l == size {
 			begin += l
 		} else {
 			break
 		}
 	}
+	return true
 }
 
-func genDayChartForMch(wg *sync.WaitGroup, mchId int32, dateStr string, start int64, end int64) {
-	defer wg.Done()
+// 统计商户的每日数据,仅令牌有效时保存,返回是否保存
+func genDayChartForMch(token int64, mchId int32, dateStr string, start int64, end int64) bool {
 	c := &merchant.MchDayChart{
 		MchId:   mchId,
 		DateStr: dateStr,
@@ -100,7 +111,17 @@ where sale_sub_order.vendor_id=? AND pay_order.state = 1 AND pay_order.paid_time
  AND update_time BETWEEN ? AND ?`, func(r *sql.Row) error {
 		return r.Scan(&c.CompleteOrders, &c.InAmount)
 	}, mchId, order.StatCompleted, start, end)
-	// 保存
-	orm.Save(db.GetOrm(), c, 0)
-	//log.Println("---", c, start, end)
+	// 校验令牌后保存,令牌与写入在同一语句中校验
+	c.UpdateTime = time.Now().Unix()
+	n, err := db.ExecNonQuery(`INSERT INTO mch_day_chart(mch_id,order_number,
+ order_amount,buyer_number,paid_number,paid_amount,complete_orders,in_amount,
+ offline_orders,offline_amount,date,date_str,update_time)
+ SELECT ?,?,?,?,?,?,?,?,?,?,?,?,? FROM sys_leader_fence WHERE name=? AND token=?`,
+		c.MchId, c.OrderNumber, c.OrderAmount, c.BuyerNumber, c.PaidNumber,
+		c.PaidAmount, c.CompleteOrders, c.InAmount, c.OfflineOrders,
+		c.OfflineAmount, c.Date, c.DateStr, c.UpdateTime, leaderName, token)
+	if err != nil {
+		log.Println("[ Mch][ Day][ Chart][ Error]:", mchId, err.Error())
+	}
+	return n > 0
 }
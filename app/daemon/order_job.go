@@ -0,0 +1,146 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : order_job.go
+ * author : jarryliu
+ * date : 2026-10-20 18:30
+ * description : 订单超时取消、自动确认及自动收货的延迟任务
+ * history :
+ */
+package daemon
+
+import (
+	"github.com/garyburd/redigo/redis"
+	"go2o/core"
+	"go2o/core/domain/interface/order"
+	"go2o/core/module"
+	"go2o/core/service/rsi"
+	"go2o/core/service/thrift/idl/gen-go/define"
+	"go2o/core/variable"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// 订单超时取消
+	jobOrderTimeout = "order.timeout"
+	// 订单自动确认
+	jobOrderConfirm = "order.confirm"
+	// 订单自动收货
+	jobOrderReceive = "order.receive"
+)
+
+func delayQueue() *module.DelayQueueModule {
+	return module.Get(module.M_DELAY).(*module.DelayQueueModule)
+}
+
+// 订单任务的业务键,子订单以"sub!"开头
+func orderJobKey(o *define.ComplexOrder) string {
+	if o.SubOrderId > 0 {
+		return "sub!" + o.OrderNo
+	}
+	return o.OrderNo
+}
+
+// 从业务键中获取订单号及是否为子订单
+func parseOrderJobKey(key string) (orderNo string, sub bool) {
+	if strings.HasPrefix(key, "sub!") {
+		return key[4:], true
+	}
+	return key, false
+}
+
+// 安排订单任务
+func scheduleOrderJob(topic string, o *define.ComplexOrder, unix int64) {
+	err := delayQueue().Schedule(topic, orderJobKey(o), "", time.Unix(unix, 0))
+	if err != nil {
+		log.Println("[ Daemon][ Order][ Schedule][ Error]:", topic,
+			o.OrderNo, err.Error())
+	}
+}
+
+// 取消订单任务
+func cancelOrderJob(topic string, o *define.ComplexOrder) {
+	delayQueue().Cancel(topic, orderJobKey(o))
+}
+
+// 执行订单任务,订单状态已改变时忽略任务
+func handleOrderJob(state int32, fn func(orderNo string, sub bool) error) module.DelayHandler {
+	return func(j *module.DelayJob) error {
+		orderNo, sub := parseOrderJobKey(j.Key)
+		o, err := rsi.ShoppingService.GetOrder(orderNo, sub)
+		if err != nil || o == nil || o.State != state {
+			return nil
+		}
+		return fn(orderNo, sub)
+	}
+}
+
+// 注册订单任务
+func registerOrderJobs(q *module.DelayQueueModule) {
+	ss := rsi.ShoppingService
+	q.Handle(jobOrderTimeout, handleOrderJob(order.StatAwaitingPayment,
+		func(orderNo string, sub bool) error {
+			return ss.CancelOrder(orderNo, sub, "订单超时,自动取消")
+		}))
+	q.Handle(jobOrderConfirm, handleOrderJob(order.StatAwaitingConfirm,
+		ss.ConfirmOrder))
+	q.Handle(jobOrderReceive, handleOrderJob(order.StatShipped,
+		ss.BuyerReceived))
+}
+
+// 将旧版本以时间片保存的订单键迁移到延迟队列,
+// 键如:go2o:order:timeout:sub!1000:11-0-2,值为到期时间
+func migrateLegacyOrderKeys(q *module.DelayQueueModule) {
+	conn := core.GetRedisConn()
+	defer conn.Close()
+	legacy := map[string]string{
+		variable.KvOrderExpiresTime: jobOrderTimeout,
+		variable.KvOrderAutoReceive: jobOrderReceive,
+	}
+	for prefix, topic := range legacy {
+		cursor := 0
+		for {
+			arr, err := redis.Values(conn.Do("SCAN", cursor,
+				"MATCH", prefix+":*", "COUNT", 100))
+			if err != nil {
+				log.Println("[ Daemon][ Delay][ Migrate][ Error]:", err.Error())
+				break
+			}
+			cursor, _ = redis.Int(arr[0], nil)
+			keys, _ := redis.Strings(arr[1], nil)
+			for _, k := range keys {
+				seg := strings.Split(k, ":")
+				unix, err := redis.Int64(conn.Do("GET", k))
+				if err == nil && len(seg) >= 2 {
+					err = q.Schedule(topic, seg[len(seg)-2], "", time.Unix(unix, 0))
+				}
+				if err == nil {
+					conn.Do("DEL", k)
+				}
+			}
+			if cursor == 0 {
+				break
+			}
+		}
+	}
+}
+
+// 启动延迟任务队列
+func startDelayQueue() {
+	q := delayQueue()
+	registerOrderJobs(q)
+	q.Handle(rsi.WebhookTopic, rsi.MerchantService.HandleWebhookJob)
+	q.Handle(jobMailRetry, retryMailTask)
+	migrateLegacyOrderKeys(q)
+	for {
+		n, err := q.Consume(100)
+		if err != nil {
+			log.Println("[ Daemon][ Delay][ Error]:",
+				err.Error(), "; retry after 10 seconds.")
+			time.Sleep(time.Second * 10)
+		} else if n == 0 {
+			time.Sleep(time.Second)
+		}
+	}
+}
@@ -32,24 +32,35 @@ func personFinanceSettle() {
 		log.Println("[ PersonFinance][ Settle][ Info]:Today is settled!")
 		return
 	}
-	invokeSettle(now)
-	// 保存最新结算日期
-	SetLastUnix(settleUnixKey, unix)
+	// 使用开始时的令牌结算,令牌失效后停止结算
+	token := leaderModule().Token(leaderName)
+	if !acquireFence(token) {
+		return
+	}
+	if invokeSettle(token, now) {
+		// 保存最新结算日期
+		signFenced(token, settleUnixKey, unix)
+	}
 }
 
 // 执行结算,结算时间为当天,
-// 收益计算当天前一天收益,转入转出按当天计算
-func invokeSettle(t time.Time) {
+// 收益计算当天前一天收益,转入转出按当天计算;令牌失效时停止并返回false
+func invokeSettle(token int64, t time.Time) bool {
 	b := time.Now()
-	confirmTransferIn(t)                   //今天确认T+?前的转入
-	settleRiseData(t.Add(time.Hour * -24)) //今天结算昨日的收益
+	//今天确认T+?前的转入,今天结算昨日的收益
+	if !confirmTransferIn(token, t) || !settleRiseData(token, t.Add(time.Hour*-24)) {
+		log.Println("[ PersonFinance][ Settle][ Stop]: leader token expired")
+		return false
+	}
 	log.Println("[ PersonFinance][ Settle][ Success]:Total used",
 		math.Floor(time.Now().Sub(b).Minutes()*100)/100, "minutes!")
+	return true
 }
 
 // 确认转入数据
 // 采用按ID分段,通过传入ID区间用多个gorouting进行处理.
-func confirmTransferIn(t time.Time) {
+// 每条数据写入前校验令牌,令牌失效时停止并返回false
+func confirmTransferIn(token int64, t time.Time) bool {
 	settleTime := t.AddDate(0, 0, -personfinance.RiseSettleTValue) // 倒推结算日
 	unixDate := tool.GetStartDate(settleTime).Unix()
 	begin := 0
@@ -76,6 +87,9 @@ func confirmTransferIn(t time.Time) {
 		//wg := sync.WaitGroup{}
 		for _, v := range idArr {
 			//wg.Add(1)
+			if !checkFence(token) {
+				return false
+			}
 			confirmTransferInByCursor(unixDate, v)
 		}
 		log.Println("[ PersonFinance][ RiseSettle][ Job]:begin:", begin,
@@ -87,6 +101,7 @@ func confirmTransferIn(t time.Time) {
 			break
 		}
 	}
+	return true
 }
 
 // 分组确认转入数据
@@ -112,7 +127,8 @@ func confirmTransferInByCursor(unixDate int64, logId int32) {
 
 // 结算增利数据,t为结算日
 // 采用按ID分段,通过传入ID区间用多个gorouting进行处理.
-func settleRiseData(settleDate time.Time) {
+// 每条数据写入前校验令牌,令牌失效时停止并返回false
+func settleRiseData(token int64, settleDate time.Time) bool {
 	settleUnix := tool.GetStartDate(settleDate).Unix() //结算日期
 	begin := 0
 	size := 20
@@ -133,10 +149,13 @@ func settleRiseData(settleDate time.Time) {
 			log.Println("[ Error][ Rise-Settle]:", err.Error())
 			break
 		}
+		if !checkFence(token) {
+			return false
+		}
 		wg := sync.WaitGroup{}
 		for _, personId := range idArr {
 			wg.Add(1)
-			go riseGroupSettle(&wg, settleUnix, personId)
+			go riseGroupSettle(&wg, token, settleUnix, personId)
 		}
 		wg.Wait()
 		log.Println("[ PersonFinance][ RiseSettle][ Job]:begin:", begin,
@@ -148,15 +167,19 @@ func settleRiseData(settleDate time.Time) {
 			break
 		}
 	}
+	return true
 }
 
 // 结算每日数据
-func riseGroupSettle(wg *sync.WaitGroup, settleUnix int64, personId int64) {
+func riseGroupSettle(wg *sync.WaitGroup, token int64, settleUnix int64, personId int64) {
+	defer wg.Done()
+	if !checkFence(token) {
+		return
+	}
 	err := rsi.PersonFinanceService.RiseSettleByDay(personId, settleUnix,
 		personfinance.RiseDayRatioProvider(personId))
 	if err != nil {
 		log.Println("[ PersonFinance][ Settle][ Fail]: person_id=",
 			personId, "error=", err.Error())
 	}
-	wg.Done()
 }
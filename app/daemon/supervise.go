@@ -10,134 +10,100 @@
 package daemon
 
 import (
-	"fmt"
-	"github.com/garyburd/redigo/redis"
 	"github.com/jsix/gof/util"
-	"go2o/core"
+	"go2o/core/domain/interface/event"
 	"go2o/core/service/rsi"
-	"go2o/core/variable"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 )
 
-// 监视新订单
-func superviseOrder(ss []Service) {
-	sv := rsi.ShoppingService
-	notify := func(orderNo string, sub bool, ss []Service) {
-		o, _ := sv.GetOrder(orderNo, sub)
-		if o != nil {
-			for _, v := range ss {
-				if !v.OrderObs(o) {
-					break
-				}
-			}
-		}
+// 订阅领域事件并通知服务
+func subscribeEvents(ss []Service) {
+	err := rsi.EventService.Subscribe("daemon", nil, func(e *event.Event) error {
+		return notifyServices(ss, e)
+	})
+	if err == nil {
+		// 商户事件推送
+		err = rsi.MerchantService.SubscribeWebhookEvents()
 	}
-	// 监听队列
-	conn := core.GetRedisConn()
-	defer conn.Close()
-	for {
-		arr, err := redis.ByteSlices(conn.Do("BLPOP",
-			variable.KvOrderBusinessQueue, 0)) //取出队列的一个元素
-		if err == nil {
-			//通知订单更新
-			orderNo := string(arr[1])
-			sub := strings.HasPrefix(orderNo, "sub!")
-			if sub {
-				orderNo = orderNo[4:]
-			}
-			//log.Println("----- 订单号：",orderNo, "; 是否子订单：", isSub)
-			go notify(orderNo, sub, ss)
-
-		} else {
-			appCtx.Log().Println("[ Daemon][ OrderQueue][ Error]:",
-				err.Error(), "; retry after 10 seconds.")
-			time.Sleep(time.Second * 10)
-		}
+	if err == nil {
+		// 会员设备推送
+		err = rsi.MssService.SubscribePushEvents()
+	}
+	if err != nil {
+		log.Println("[ Daemon][ Event][ Error]:", err.Error())
 	}
 }
 
-// 监视新会员
-func superviseMemberUpdate(ss []Service) {
-	sv := rsi.MemberService
-	notify := func(id int64, action string, ss []Service) {
-		m, _ := sv.GetMember(id)
+// 根据事件通知服务,事件按顺序逐个处理
+func notifyServices(ss []Service, e *event.Event) error {
+	switch e.Type {
+	case event.MemberRegistered, event.MemberUpdated:
+		d := event.MemberData{}
+		if err := e.Unmarshal(&d); err != nil {
+			return err
+		}
+		m, _ := rsi.MemberService.GetMember(d.MemberId)
 		if m != nil {
 			for _, v := range ss {
-				if !v.MemberObs(m, action == "create") {
+				if !v.MemberObs(m, e.Type == event.MemberRegistered) {
 					break
 				}
 			}
 		}
-	}
-	id := 0
-	conn := core.GetRedisConn()
-	defer conn.Close()
-	for {
-		arr, err := redis.ByteSlices(conn.Do("BLPOP",
-			variable.KvMemberUpdateQueue, 0))
-		if err == nil {
-			//通知会员修改,格式如: 1-[create|update]
-			s := string(arr[1])
-			mArr := strings.Split(s, "-")
-			id, err = strconv.Atoi(mArr[0])
-			if err == nil {
-				go notify(int64(id), mArr[1], ss)
-			}
-		} else {
-			appCtx.Log().Println("[ Daemon][ MemberQueue][ Error]:",
-				err.Error(), "; retry after 10 seconds.")
-			time.Sleep(time.Second * 10)
+	case event.PaymentFinished:
+		d := event.PaymentData{}
+		if err := e.Unmarshal(&d); err != nil {
+			return err
 		}
-	}
-}
-
-// 监视支付单完成
-func supervisePaymentOrderFinish(ss []Service) {
-	sv := rsi.PaymentService
-	notify := func(id int, ss []Service) {
-		order, _ := sv.GetPaymentOrderById(int32(id))
-		if order != nil {
+		o, _ := rsi.PaymentService.GetPaymentOrderById(d.PaymentOrderId)
+		if o != nil {
 			for _, v := range ss {
-				if !v.PaymentOrderObs(order) {
+				if !v.PaymentOrderObs(o) {
 					break
 				}
 			}
 		}
-	}
-	id := 0
-	conn := core.GetRedisConn()
-	defer conn.Close()
-	for {
-		arr, err := redis.ByteSlices(conn.Do("BLPOP",
-			variable.KvPaymentOrderFinishQueue, 0))
-		if err == nil {
-			//通知服务
-			s := string(arr[1])
-			id, err = strconv.Atoi(s)
-			if err == nil {
-				go notify(id, ss)
+	default:
+		if e.AggregateType != event.AggregateOrder &&
+			e.AggregateType != event.AggregateSubOrder {
+			return nil
+		}
+		d := event.OrderData{}
+		if err := e.Unmarshal(&d); err != nil {
+			return err
+		}
+		o, _ := rsi.ShoppingService.GetOrder(d.OrderNo, d.Sub)
+		if o != nil {
+			for _, v := range ss {
+				if !v.OrderObs(o) {
+					break
+				}
 			}
-		} else {
-			appCtx.Log().Println("[ Daemon][ PaymentOrderQueue][ Error]:",
-				err.Error(), "; retry after 10 seconds.")
-			time.Sleep(time.Second * 10)
 		}
 	}
+	return nil
 }
 
-// 从RDS键中找到订单编号，如：go2o:queue:sub!1 , go2o:queue:2
-func testIdFromRdsKey2(key string) (orderNo string, sub bool, err error) {
-	arr := strings.Split(key, ":")
-	orderNo = arr[len(arr)-1]
-	//orderNo = arr[len(arr) - 2]
-	sub = strings.HasPrefix(orderNo, "sub!")
-	if sub {
-		orderNo = orderNo[4:]
+// 发布发件箱中的事件,仅在主节点发布以保证事件的顺序
+func startEventRelay(ss []Service) {
+	subscribeEvents(ss)
+	for {
+		n := 0
+		if isLeader() {
+			var err error
+			if n, err = rsi.EventService.Relay(100); err != nil {
+				log.Println("[ Daemon][ Event][ Error]:",
+					err.Error(), "; retry after 10 seconds.")
+				time.Sleep(time.Second * 10)
+			}
+		}
+		if n == 0 {
+			time.Sleep(time.Second)
+		}
 	}
-	return orderNo, sub, err
 }
 
 // 从RDS键中找到订单编号，如：go2o:queue:sub!1 , go2o:queue:2
@@ -151,61 +117,3 @@ func testIdFromRdsKey(key string) (orderId int64, sub bool, err error) {
 	orderId, err = util.I64Err(strconv.Atoi(oidKey))
 	return orderId, isSub, err
 }
-
-// 检测已过期的订单并标记
-func detectOrderExpires() {
-	if appCtx.Debug() {
-		log.Println("[ Order]: detect order time out ...")
-	}
-	conn := core.GetRedisConn()
-	defer conn.Close()
-	tick := getTick(time.Now())
-	key := fmt.Sprintf("%s:*:%s", variable.KvOrderExpiresTime, tick)
-	//key = "go2o:order:timeout:11-0-2:*"
-	//获取标记为等待过期的订单
-	ss := rsi.ShoppingService
-	list, err := redis.Strings(conn.Do("KEYS", key))
-	if err == nil {
-		for _, oKey := range list {
-			orderNo, isSub, err := testIdFromRdsKey2(oKey)
-			if err == nil && orderNo != "" {
-				err = ss.CancelOrder(orderNo, isSub, "订单超时,自动取消")
-				//清除待取消记录
-				conn.Do("DEL", oKey)
-				//log.Println("---",orderId,"---",unix, "--", time.Now().Unix(), v, err)
-			}
-		}
-	} else {
-		log.Println("[ Daemon][ Order][ Cancel][ Error]:",
-			err.Error(), "; retry after 10 seconds.")
-		time.Sleep(time.Second * 10)
-	}
-}
-
-// 订单自动收货
-func orderAutoReceive() {
-	if appCtx.Debug() {
-		log.Println("[ Order]: order auto receive ...")
-	}
-	conn := core.GetRedisConn()
-	defer conn.Close()
-	tick := getTick(time.Now())
-	key := fmt.Sprintf("%s:*:%s", variable.KvOrderAutoReceive, tick)
-	//key = "go2o:order:autoreceive:11-0-2:*"
-	//获取标记为自动收货的订单
-	ss := rsi.ShoppingService
-	list, err := redis.Strings(conn.Do("KEYS", key))
-	if err == nil {
-		for _, oKey := range list {
-			orderNo, isSub, err := testIdFromRdsKey2(oKey)
-			//log.Println("----",oKey,orderId,isSub,err)
-			if err == nil && orderNo != "" {
-				err = ss.BuyerReceived(orderNo, isSub)
-			}
-		}
-	} else {
-		log.Println("[ Daemon][ Order][ Receive][ Error]:",
-			err.Error(), "; retry after 10 seconds.")
-		time.Sleep(time.Second * 10)
-	}
-}
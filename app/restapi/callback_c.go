@@ -0,0 +1,31 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : callback_c.go
+ * author : jarryliu
+ * date : 2026-10-21 11:50
+ * description : 第三方服务的回调接口,不检查商户接口权限
+ * history :
+ */
+package restapi
+
+import (
+	"github.com/labstack/echo"
+	"go2o/core/service/rsi"
+	"net/http"
+	"strconv"
+)
+
+type callbackC struct {
+}
+
+// 短信状态报告,回执地址如:/callback/sms?provider=3&token=xxx
+func (cb *callbackC) SmsReceipt(c echo.Context) error {
+	r := c.Request()
+	r.ParseForm()
+	provider, _ := strconv.Atoi(r.FormValue("provider"))
+	n, err := rsi.MssService.HandleSmsReceipt(provider, r.Form)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	return c.String(http.StatusOK, "ok:"+strconv.Itoa(n))
+}
@@ -0,0 +1,35 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : captcha_c.go
+ * author : jarryliu
+ * date : 2026-10-20 17:30
+ * description : 图形验证码接口
+ * history :
+ */
+package restapi
+
+import (
+	"encoding/base64"
+	"github.com/labstack/echo"
+	"go2o/core/module"
+	"net/http"
+)
+
+type captchaC struct {
+}
+
+func (cc *captchaC) module() *module.CaptchaModule {
+	return module.Get(module.M_CAPTCHA).(*module.CaptchaModule)
+}
+
+// 获取图形验证码,返回验证码编号及图片
+func (cc *captchaC) Captcha(c echo.Context) error {
+	ch, err := cc.module().NewChallenge()
+	if err != nil {
+		return c.JSON(http.StatusOK, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"id":    ch.Id,
+		"image": "data:image/png;base64," + base64.StdEncoding.EncodeToString(ch.Image),
+	})
+}
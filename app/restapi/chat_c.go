@@ -0,0 +1,155 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : chat_c.go
+ * author : jarryliu
+ * date : 2026-10-22 14:30
+ * description : 客服会话接口,消息通过TCP/WebSocket推送,
+ *               离线或无法连接时通过接口获取历史消息及发送消息
+ * history :
+ */
+package restapi
+
+import (
+	"github.com/jsix/gof"
+	"github.com/labstack/echo"
+	"go2o/core/service/rsi"
+	"net/http"
+	"strconv"
+)
+
+type chatC struct {
+}
+
+func formInt32(c echo.Context, key string) int32 {
+	i, _ := strconv.Atoi(c.Request().FormValue(key))
+	return int32(i)
+}
+
+func formInt64(c echo.Context, key string) int64 {
+	i, _ := strconv.ParseInt(c.Request().FormValue(key), 10, 64)
+	return i
+}
+
+// 返回数据或错误
+func chatResult(c echo.Context, data interface{}, err error) error {
+	if err != nil {
+		return c.JSON(http.StatusOK, gof.Message{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, data)
+}
+
+// 会员发起会话,mch_id为商户编号
+func (h *chatC) Open(c echo.Context) error {
+	s, err := rsi.MssService.OpenChat(GetMemberId(c), formInt32(c, "mch_id"))
+	return chatResult(c, s, err)
+}
+
+// 会员的会话
+func (h *chatC) Sessions(c echo.Context) error {
+	return c.JSON(http.StatusOK, rsi.MssService.GetMemberChats(GetMemberId(c)))
+}
+
+// 会员获取历史消息,before为消息编号,为0时获取最新的消息
+func (h *chatC) Messages(c echo.Context) error {
+	size, _ := strconv.Atoi(c.Request().FormValue("size"))
+	list, err := rsi.MssService.GetMemberChatMessages(GetMemberId(c),
+		formInt32(c, "session_id"), formInt64(c, "before"), size)
+	return chatResult(c, list, err)
+}
+
+// 会员发送消息,msg_type为消息类型,商品及订单卡片的内容为JSON格式
+func (h *chatC) Send(c echo.Context) error {
+	msgType, _ := strconv.Atoi(c.Request().FormValue("msg_type"))
+	m, err := rsi.MssService.MemberSendChat(GetMemberId(c), formInt32(c, "session_id"),
+		msgType, c.Request().FormValue("content"))
+	return chatResult(c, m, err)
+}
+
+// 会员标记已读,msg_id为已读到的消息编号
+func (h *chatC) Read(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MssService.MemberReadChat(GetMemberId(c),
+		formInt32(c, "session_id"), formInt64(c, "msg_id"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 会员结束会话
+func (h *chatC) Close(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MssService.MemberCloseChat(GetMemberId(c), formInt32(c, "session_id"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 设置客服状态,state:0为离线,1为在线,2为忙碌;person_id为员工编号
+func (h *chatC) AgentState(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	state, _ := strconv.Atoi(r.FormValue("state"))
+	maxSessions, _ := strconv.Atoi(r.FormValue("max_sessions"))
+	err := rsi.MssService.SetChatAgentState(getMerchantId(c),
+		formInt32(c, "person_id"), state, maxSessions)
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 商户的客服
+func (h *chatC) Agents(c echo.Context) error {
+	return c.JSON(http.StatusOK, rsi.MssService.GetChatAgents(getMerchantId(c)))
+}
+
+// 获取客服连接推送服务的令牌
+func (h *chatC) AgentToken(c echo.Context) error {
+	token, err := rsi.MssService.CreateChatAgentToken(getMerchantId(c),
+		formInt32(c, "person_id"))
+	return chatResult(c, map[string]string{"token": token}, err)
+}
+
+// 客服接待中及等待接入的会话
+func (h *chatC) AgentSessions(c echo.Context) error {
+	serving, waiting := rsi.MssService.GetAgentChats(getMerchantId(c),
+		formInt32(c, "person_id"))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"serving": serving,
+		"waiting": waiting,
+	})
+}
+
+// 客服获取历史消息
+func (h *chatC) AgentMessages(c echo.Context) error {
+	size, _ := strconv.Atoi(c.Request().FormValue("size"))
+	list, err := rsi.MssService.GetAgentChatMessages(getMerchantId(c),
+		formInt32(c, "person_id"), formInt32(c, "session_id"),
+		formInt64(c, "before"), size)
+	return chatResult(c, list, err)
+}
+
+// 客服发送消息
+func (h *chatC) AgentSend(c echo.Context) error {
+	msgType, _ := strconv.Atoi(c.Request().FormValue("msg_type"))
+	m, err := rsi.MssService.AgentSendChat(getMerchantId(c), formInt32(c, "person_id"),
+		formInt32(c, "session_id"), msgType, c.Request().FormValue("content"))
+	return chatResult(c, m, err)
+}
+
+// 客服标记已读
+func (h *chatC) AgentRead(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MssService.AgentReadChat(getMerchantId(c), formInt32(c, "person_id"),
+		formInt32(c, "session_id"), formInt64(c, "msg_id"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 客服结束会话
+func (h *chatC) AgentClose(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MssService.AgentCloseChat(getMerchantId(c), formInt32(c, "person_id"),
+		formInt32(c, "session_id"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 转接会话,to_person_id为接收的客服
+func (h *chatC) Transfer(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MssService.TransferChat(getMerchantId(c), formInt32(c, "person_id"),
+		formInt32(c, "session_id"), formInt32(c, "to_person_id"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
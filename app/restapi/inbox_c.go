@@ -0,0 +1,84 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : inbox_c.go
+ * author : jarryliu
+ * date : 2026-10-23 14:10
+ * description : 会员站内信接口,广播消息由平台通过Thrift服务发送
+ * history :
+ */
+package restapi
+
+import (
+	"github.com/jsix/gof"
+	"github.com/labstack/echo"
+	"go2o/core/service/rsi"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type inboxC struct {
+}
+
+// 解析以逗号分隔的消息编号
+func formIds(c echo.Context, key string) []int64 {
+	ids := []int64{}
+	for _, v := range strings.Split(c.Request().FormValue(key), ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// 站内信列表,unread为1时只获取未读的消息
+func (h *inboxC) List(c echo.Context) error {
+	r := c.Request()
+	begin, _ := strconv.Atoi(r.FormValue("begin"))
+	size, _ := strconv.Atoi(r.FormValue("size"))
+	total, list := rsi.MssService.GetInboxMessages(GetMemberId(c),
+		r.FormValue("unread") == "1", begin, size)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"total": total,
+		"rows":  list,
+	})
+}
+
+// 站内信详情,查看时标记为已读
+func (h *inboxC) Get(c echo.Context) error {
+	memberId := GetMemberId(c)
+	id := formInt64(c, "id")
+	m := rsi.MssService.GetInboxMessage(memberId, id)
+	if m == nil {
+		return c.JSON(http.StatusOK, gof.Message{Message: "no such message"})
+	}
+	if m.HasRead == 0 {
+		rsi.MssService.MarkInboxRead(memberId, []int64{id})
+	}
+	return c.JSON(http.StatusOK, m)
+}
+
+// 未读站内信数量
+func (h *inboxC) Unread(c echo.Context) error {
+	n, _ := rsi.MssService.InboxUnread(GetMemberId(c))
+	return c.JSON(http.StatusOK, map[string]int32{"unread": n})
+}
+
+// 标记已读,ids为以逗号分隔的消息编号,all为1时标记全部
+func (h *inboxC) Read(c echo.Context) error {
+	result := gof.Message{}
+	ids := formIds(c, "ids")
+	if len(ids) == 0 && c.Request().FormValue("all") != "1" {
+		result.Message = "no message selected"
+		return c.JSON(http.StatusOK, result)
+	}
+	err := rsi.MssService.MarkInboxRead(GetMemberId(c), ids)
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 删除站内信,ids为以逗号分隔的消息编号
+func (h *inboxC) Delete(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MssService.DeleteInboxMessages(GetMemberId(c), formIds(c, "ids"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
@@ -13,8 +13,11 @@ import (
 	"fmt"
 	"github.com/jsix/gof"
 	"github.com/labstack/echo"
+	"go2o/app/util"
+	"go2o/core/domain/interface/mss/notify"
 	"go2o/core/dto"
 	"go2o/core/infrastructure/domain"
+	"go2o/core/module"
 	"go2o/core/service/rsi"
 	"go2o/core/service/thrift"
 	"go2o/core/service/thrift/idl/gen-go/define"
@@ -40,6 +43,17 @@ func (mc *MemberC) Login(c echo.Context) error {
 		result.Message = "会员不存在"
 		return c.JSON(http.StatusOK, result)
 	}
+	// 登录失败次数过多时需要图形验证码
+	ip := getClientIp(r)
+	cm := module.Get(module.M_CAPTCHA).(*module.CaptchaModule)
+	if cm.LoginCaptchaRequired(usr, ip) {
+		result.CaptchaRequired = true
+		if err := cm.Verify(r.FormValue("captcha_id"),
+			r.FormValue("captcha_code")); err != nil {
+			result.Message = err.Error()
+			return c.JSON(http.StatusOK, result)
+		}
+	}
 	cli, err := thrift.MemberServeClient()
 	if err != nil {
 		result.Message = "网络连接失败"
@@ -49,12 +63,29 @@ func (mc *MemberC) Login(c echo.Context) error {
 		r, _ := cli.CheckLogin(usr, encPwd, true)
 		result.Message = r.Message
 		result.Result = r.Result_
-		if r.Result_ {
-			token, _ := cli.GetToken(r.ID, false)
-			result.Member = &dto.LoginMember{
-				Id:         int(r.ID),
-				Token:      token,
-				UpdateTime: time.Now().Unix(),
+		if !r.Result_ {
+			cm.LoginFailed(usr, ip)
+			result.CaptchaRequired = cm.LoginCaptchaRequired(usr, ip)
+		} else {
+			cm.LoginSucceed(usr)
+			result.CaptchaRequired = false
+			// 每个设备创建独立的会话
+			req := c.Request()
+			t, err := rsi.MemberService.CreateSession(r.ID,
+				req.FormValue("device_id"), util.GetBrownerDevice(req),
+				req.UserAgent(), getClientIp(req))
+			if err != nil {
+				result.Result = false
+				result.Message = err.Error()
+			} else {
+				result.Member = &dto.LoginMember{
+					Id:           int(r.ID),
+					Token:        t.AccessToken,
+					UpdateTime:   time.Now().Unix(),
+					SessionId:    t.SessionId,
+					ExpiresIn:    t.ExpiresIn,
+					RefreshToken: t.RefreshToken,
+				}
 			}
 		}
 	}
@@ -74,10 +105,7 @@ func (mc *MemberC) Register(c echo.Context) error {
 	phone := r.FormValue("phone")
 	registerFrom := r.FormValue("reg_from")          // 注册来源
 	invitationCode := r.FormValue("invitation_code") // 邀请码
-	var regIp string
-	if i := strings.Index(r.RemoteAddr, ":"); i != -1 {
-		regIp = r.RemoteAddr[:i]
-	}
+	regIp := getClientIp(r)
 	m := &define.Member{}
 	pro := &define.Profile{}
 	m.Usr = usr
@@ -87,7 +115,7 @@ func (mc *MemberC) Register(c echo.Context) error {
 	pro.Phone = phone
 	pro.Name = m.Usr
 	_, err := rsi.MemberService.RegisterMember(mchId,
-		m, pro, "", invitationCode)
+		m, pro, "", invitationCode, riskClient(r))
 	return c.JSON(http.StatusOK, result.Error(err))
 }
 
@@ -170,3 +198,241 @@ func (mc *MemberC) Disconnect(c echo.Context) error {
 	//}
 	//return c.JSON(http.StatusOK, result.Error(err))
 }
+
+// 刷新令牌,返回新的访问令牌及刷新令牌,原刷新令牌失效
+func (mc *MemberC) RefreshToken(c echo.Context) error {
+	result := dto.MemberLoginResult{}
+	t, err := rsi.MemberService.RefreshToken(c.Request().FormValue("refresh_token"))
+	if err != nil {
+		result.Message = err.Error()
+	} else {
+		result.Result = true
+		result.Member = &dto.LoginMember{
+			Id:           int(t.MemberId),
+			Token:        t.AccessToken,
+			UpdateTime:   time.Now().Unix(),
+			SessionId:    t.SessionId,
+			ExpiresIn:    t.ExpiresIn,
+			RefreshToken: t.RefreshToken,
+		}
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// 获取会员已登录的设备会话
+func (mc *MemberC) Sessions(c echo.Context) error {
+	memberId := GetMemberId(c)
+	return c.JSON(http.StatusOK, rsi.MemberService.GetSessions(memberId))
+}
+
+// 注销设备会话
+func (mc *MemberC) RevokeSession(c echo.Context) error {
+	result := gof.Message{}
+	memberId := GetMemberId(c)
+	sid := c.Request().FormValue("session_id")
+	err := rsi.MemberService.RevokeSession(memberId, sid)
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 在所有设备上退出
+func (mc *MemberC) LogoutAll(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MemberService.RemoveToken(GetMemberId(c))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 申请提现,手续费按系统设置收取;启用两步验证时须传入tf_code
+func (mc *MemberC) TakeOut(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	kind, _ := strconv.Atoi(r.FormValue("kind"))
+	amount, _ := strconv.ParseFloat(r.FormValue("amount"), 32)
+	csn := rsi.FoundationService.GetGlobNumberConf().TakeOutCsn
+	_, tradeNo, err := rsi.MemberService.SubmitTakeOutRequest(GetMemberId(c),
+		int32(kind), float32(amount), csn, r.FormValue("tf_code"), riskClient(r))
+	if err == nil {
+		result.Data = tradeNo
+	}
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 转账到其他会员,手续费按系统设置收取;需人工审核时,审核通过后完成转账;
+// 启用两步验证时须传入tf_code
+func (mc *MemberC) Transfer(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	accountKind, _ := strconv.Atoi(r.FormValue("account_kind"))
+	toMember, _ := strconv.Atoi(r.FormValue("to_member"))
+	amount, _ := strconv.ParseFloat(r.FormValue("amount"), 32)
+	csn := rsi.FoundationService.GetGlobNumberConf().TransferCsn
+	err := rsi.MemberService.TransferAccount(accountKind, GetMemberId(c),
+		int64(toMember), float32(amount), csn, r.FormValue("remark"),
+		r.FormValue("tf_code"), riskClient(r))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 注册会话的推送设备令牌,platform为推送平台(apns/fcm)
+func (mc *MemberC) PushRegister(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	err := rsi.MemberService.RegisterPushToken(GetMemberId(c),
+		r.FormValue("session_id"), r.FormValue("platform"), r.FormValue("token"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 取消会话的推送
+func (mc *MemberC) PushUnregister(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MemberService.UnregisterPushToken(GetMemberId(c),
+		c.Request().FormValue("session_id"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 创建设备会话,并设置登录结果
+func (mc *MemberC) createSession(c echo.Context, memberId int64,
+	result *dto.MemberLoginResult) {
+	req := c.Request()
+	t, err := rsi.MemberService.CreateSession(memberId,
+		req.FormValue("device_id"), util.GetBrownerDevice(req),
+		req.UserAgent(), getClientIp(req))
+	if err != nil {
+		result.Result = false
+		result.Message = err.Error()
+		return
+	}
+	result.Result = true
+	result.Member = &dto.LoginMember{
+		Id:           int(memberId),
+		Token:        t.AccessToken,
+		UpdateTime:   time.Now().Unix(),
+		SessionId:    t.SessionId,
+		ExpiresIn:    t.ExpiresIn,
+		RefreshToken: t.RefreshToken,
+	}
+}
+
+// 第三方账号登录,provider为平台(wechat/alipay/apple),
+// code为客户端获取的授权码(Apple为identity_token);未绑定的账号将自动注册
+func (mc *MemberC) SocialLogin(c echo.Context) error {
+	result := dto.MemberLoginResult{}
+	r := c.Request()
+	memberId, err := rsi.MemberService.SocialLogin(r.FormValue("provider"),
+		r.FormValue("code"), getClientIp(r))
+	if err != nil {
+		result.Message = err.Error()
+	} else {
+		mc.createSession(c, memberId, &result)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// 获取绑定的第三方账号
+func (mc *MemberC) SocialList(c echo.Context) error {
+	memberId := GetMemberId(c)
+	return c.JSON(http.StatusOK, rsi.MemberService.GetSocialIdentities(memberId))
+}
+
+// 绑定第三方账号
+func (mc *MemberC) SocialBind(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	err := rsi.MemberService.BindSocial(GetMemberId(c),
+		r.FormValue("provider"), r.FormValue("code"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 解绑第三方账号
+func (mc *MemberC) SocialUnbind(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.MemberService.UnbindSocial(GetMemberId(c),
+		c.Request().FormValue("provider"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 合并到手机号对应的会员,未传入验证码时发送验证码到该手机;
+// 合并成功后返回目标会员的会话
+func (mc *MemberC) SocialMerge(c echo.Context) error {
+	r := c.Request()
+	memberId := GetMemberId(c)
+	phone := strings.TrimSpace(r.FormValue("phone"))
+	code := strings.TrimSpace(r.FormValue("check_code"))
+	if code == "" {
+		result := gof.Message{}
+		err := rsi.MemberService.SendSocialMergeCode(memberId, phone,
+			getClientIp(r), r.FormValue("captcha_id"), r.FormValue("captcha_code"))
+		return c.JSON(http.StatusOK, result.Error(err))
+	}
+	result := dto.MemberLoginResult{}
+	targetId, err := rsi.MemberService.MergeSocialMember(memberId, phone, code)
+	if err != nil {
+		result.Message = err.Error()
+	} else {
+		mc.createSession(c, targetId, &result)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// 获取通知偏好,包含免打扰时段及各通知项的渠道
+func (mc *MemberC) NotifyPref(c echo.Context) error {
+	memberId := GetMemberId(c)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"pref":   rsi.MssService.GetNotifyPreference(memberId),
+		"events": rsi.MssService.GetNotifyEventPrefs(memberId),
+		"items":  rsi.MssService.GetAllNotifyItem(),
+	})
+}
+
+// 保存通知偏好,免打扰时段格式如:22:00,开始与结束时间相同时不启用
+func (mc *MemberC) SaveNotifyPref(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	v := rsi.MssService.GetNotifyPreference(GetMemberId(c))
+	v.Locale = r.FormValue("locale")
+	var err error
+	if s := r.FormValue("quiet_start"); s != "" {
+		v.QuietStart, err = notify.ParseClock(s)
+	}
+	if s := r.FormValue("quiet_end"); err == nil && s != "" {
+		v.QuietEnd, err = notify.ParseClock(s)
+	}
+	if err == nil {
+		err = rsi.MssService.SaveNotifyPreference(v)
+	}
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 设置通知项的接收渠道,channels以逗号分隔,为空时不接收该通知
+func (mc *MemberC) SaveNotifyChannels(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	channels := notify.EventPref{Channels: r.FormValue("channels")}.ChannelList()
+	err := rsi.MssService.SaveNotifyEventPref(GetMemberId(c),
+		r.FormValue("key"), channels)
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 通知发送记录
+func (mc *MemberC) NotifyLogs(c echo.Context) error {
+	r := c.Request()
+	begin, _ := strconv.Atoi(r.FormValue("begin"))
+	size, _ := strconv.Atoi(r.FormValue("size"))
+	if size <= 0 || size > 50 {
+		size = 20
+	}
+	total, rows := rsi.MssService.QueryNotifyDeliveries(GetMemberId(c), begin, size)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"total": total,
+		"rows":  rows,
+	})
+}
+
+// 获取同城配送的签收码,送达时出示给配送员
+func (mc *MemberC) DeliverCode(c echo.Context) error {
+	result := gof.Message{}
+	id, _ := strconv.ParseInt(c.Request().FormValue("sub_order_id"), 10, 64)
+	code, err := rsi.ShipmentService.GetDeliverCode(GetMemberId(c), id)
+	if err != nil {
+		return c.JSON(http.StatusOK, result.Error(err))
+	}
+	return c.JSON(http.StatusOK, map[string]string{"code": code})
+}
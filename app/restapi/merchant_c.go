@@ -11,8 +11,13 @@ package restapi
 import (
 	"github.com/jsix/gof"
 	"github.com/labstack/echo"
+	"go2o/core/domain/interface/merchant"
+	"go2o/core/domain/interface/oauth"
+	"go2o/core/domain/interface/shipment"
 	"go2o/core/service/rsi"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type merchantC struct {
@@ -29,3 +34,173 @@ func (m *merchantC) Get_ad(c echo.Context) error {
 	return c.JSON(http.StatusOK,
 		gof.Message{Message: "没有广告数据"})
 }
+
+// 获取接口最近的用量,days为天数,默认为7天
+func (m *merchantC) ApiUsage(c echo.Context) error {
+	mchId := getMerchantId(c)
+	days, _ := strconv.Atoi(c.Request().FormValue("days"))
+	if days <= 0 || days > 31 {
+		days = 7
+	}
+	return c.JSON(http.StatusOK, rsi.MerchantService.GetApiUsage(mchId, days))
+}
+
+// 获取事件推送地址
+func (m *merchantC) Webhooks(c echo.Context) error {
+	return c.JSON(http.StatusOK, rsi.MerchantService.GetWebhooks(getMerchantId(c)))
+}
+
+// 保存事件推送地址,events为订阅的事件,以逗号分隔;
+// 创建时返回推送地址编号及签名密钥
+func (m *merchantC) SaveWebhook(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	id, _ := strconv.Atoi(r.FormValue("id"))
+	v := &merchant.Webhook{
+		Id:      int32(id),
+		Url:     r.FormValue("url"),
+		Secret:  r.FormValue("secret"),
+		Events:  r.FormValue("events"),
+		Enabled: 1,
+	}
+	if r.FormValue("enabled") == "0" {
+		v.Enabled = 0
+	}
+	created := v.Id <= 0
+	id, err := rsi.MerchantService.SaveWebhook(getMerchantId(c), v)
+	// 密钥不在列表中返回,仅在创建时返回一次
+	if err == nil && created {
+		result.Data = map[string]interface{}{
+			"id":     id,
+			"secret": v.Secret,
+		}
+	}
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 删除事件推送地址
+func (m *merchantC) DeleteWebhook(c echo.Context) error {
+	result := gof.Message{}
+	id, _ := strconv.Atoi(c.Request().FormValue("id"))
+	err := rsi.MerchantService.DeleteWebhook(getMerchantId(c), int32(id))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 查询事件推送记录
+func (m *merchantC) WebhookDeliveries(c echo.Context) error {
+	r := c.Request()
+	webhookId, _ := strconv.Atoi(r.FormValue("webhook_id"))
+	state, _ := strconv.Atoi(r.FormValue("state"))
+	begin, _ := strconv.Atoi(r.FormValue("begin"))
+	size, _ := strconv.Atoi(r.FormValue("size"))
+	if size <= 0 || size > 50 {
+		size = 20
+	}
+	total, rows := rsi.MerchantService.QueryWebhookDeliveries(getMerchantId(c),
+		int32(webhookId), state, begin, size)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"total": total,
+		"rows":  rows,
+	})
+}
+
+// 重新推送事件
+func (m *merchantC) RedeliverWebhook(c echo.Context) error {
+	result := gof.Message{}
+	id, _ := strconv.ParseInt(c.Request().FormValue("delivery_id"), 10, 64)
+	_, err := rsi.MerchantService.RedeliverWebhook(getMerchantId(c), id)
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 获取子订单的电子面单
+func (m *merchantC) Waybill(c echo.Context) error {
+	result := gof.Message{}
+	id, _ := strconv.ParseInt(c.Request().FormValue("sub_order_id"), 10, 64)
+	w, err := rsi.ShipmentService.GetWaybill(getMerchantId(c), id)
+	if err != nil {
+		return c.JSON(http.StatusOK, result.Error(err))
+	}
+	return c.JSON(http.StatusOK, w)
+}
+
+// 批量打印电子面单,sub_order_ids为子订单编号,以逗号分隔;
+// format为面单格式,如:pdf,zpl,默认为pdf
+func (m *merchantC) PrintWaybills(c echo.Context) error {
+	result := gof.Message{}
+	r := c.Request()
+	var ids []int64
+	for _, v := range strings.Split(r.FormValue("sub_order_ids"), ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	format := r.FormValue("format")
+	if format == "" {
+		format = shipment.WaybillFormatPdf
+	}
+	data, err := rsi.ShipmentService.PrintWaybills(getMerchantId(c), ids, format)
+	if err != nil {
+		return c.JSON(http.StatusOK, result.Error(err))
+	}
+	contentType := "application/pdf"
+	if format == shipment.WaybillFormatZpl {
+		contentType = "text/plain; charset=utf-8"
+	}
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+// 获取商户的OAuth2应用
+func (m *merchantC) OAuthClients(c echo.Context) error {
+	return c.JSON(http.StatusOK, rsi.OAuthService.GetClients(getMerchantId(c)))
+}
+
+// 获取应用参数,redirect_uris为回调地址,多个以换行分隔;scopes以空格分隔
+func (m *merchantC) oauthClient(c echo.Context) *oauth.Client {
+	r := c.Request()
+	v := &oauth.Client{
+		ClientId:     r.FormValue("client_id"),
+		Name:         r.FormValue("name"),
+		Logo:         r.FormValue("logo"),
+		RedirectUris: r.FormValue("redirect_uris"),
+		Scopes:       r.FormValue("scopes"),
+		State:        oauth.ClientEnabled,
+	}
+	if r.FormValue("public") == "1" {
+		v.Public = 1
+	}
+	if r.FormValue("state") == "0" {
+		v.State = oauth.ClientDisabled
+	}
+	return v
+}
+
+// 注册OAuth2应用,返回应用编号及密钥,密钥仅在此时返回
+func (m *merchantC) RegisterOAuthClient(c echo.Context) error {
+	result := gof.Message{}
+	id, secret, err := rsi.OAuthService.RegisterClient(getMerchantId(c), m.oauthClient(c))
+	if err != nil {
+		return c.JSON(http.StatusOK, result.Error(err))
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"clientId":     id,
+		"clientSecret": secret,
+	})
+}
+
+// 保存OAuth2应用
+func (m *merchantC) SaveOAuthClient(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.OAuthService.SaveClient(getMerchantId(c), m.oauthClient(c))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
+
+// 重置OAuth2应用密钥,返回新的密钥
+func (m *merchantC) ResetOAuthSecret(c echo.Context) error {
+	result := gof.Message{}
+	secret, err := rsi.OAuthService.ResetClientSecret(getMerchantId(c),
+		c.Request().FormValue("client_id"))
+	if err != nil {
+		return c.JSON(http.StatusOK, result.Error(err))
+	}
+	return c.JSON(http.StatusOK, map[string]string{"clientSecret": secret})
+}
@@ -0,0 +1,237 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : oauth_c.go
+ * author : jarryliu
+ * date : 2026-10-19 22:50
+ * description : OAuth2 / OpenID Connect接口,授权页面由通行证站点提供,
+ *               通行证站点在会员登录后以POST方式调用authorize及consent接口
+ * history :
+ */
+package restapi
+
+import (
+	"github.com/jsix/gof"
+	"github.com/labstack/echo"
+	"go2o/core/domain/interface/oauth"
+	"go2o/core/module"
+	"go2o/core/service/rsi"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type oauthC struct {
+}
+
+// 输出OAuth2错误(RFC 6749 5.2)
+func (o *oauthC) error(c echo.Context, status int, code string, err error) error {
+	mp := map[string]string{"error": code}
+	if err != nil {
+		mp["error_description"] = err.Error()
+	}
+	if status == http.StatusUnauthorized {
+		c.Response().Header().Set("WWW-Authenticate",
+			`Bearer error="`+code+`"`)
+	}
+	return c.JSON(status, mp)
+}
+
+// 获取错误对应的OAuth2错误码
+func (o *oauthC) errorCode(err error) (int, string) {
+	switch err {
+	case oauth.ErrNoSuchClient, oauth.ErrClientDisabled, oauth.ErrClientSecret:
+		return http.StatusUnauthorized, "invalid_client"
+	case oauth.ErrScope:
+		return http.StatusBadRequest, "invalid_scope"
+	case oauth.ErrInsufficientScope:
+		return http.StatusForbidden, "insufficient_scope"
+	case module.ErrAccessToken:
+		return http.StatusUnauthorized, "invalid_token"
+	case oauth.ErrPkceRequired:
+		return http.StatusBadRequest, "invalid_request"
+	}
+	return http.StatusBadRequest, "invalid_grant"
+}
+
+// 获取授权请求参数
+func (o *oauthC) authorizeRequest(c echo.Context) *rsi.AuthorizeRequest {
+	r := c.Request()
+	return &rsi.AuthorizeRequest{
+		ClientId:            r.FormValue("client_id"),
+		RedirectUri:         r.FormValue("redirect_uri"),
+		Scope:               r.FormValue("scope"),
+		State:               r.FormValue("state"),
+		Nonce:               r.FormValue("nonce"),
+		CodeChallenge:       r.FormValue("code_challenge"),
+		CodeChallengeMethod: r.FormValue("code_challenge_method"),
+	}
+}
+
+// 授权,会员已授权时返回包含授权码的回调地址,否则返回应用信息供会员确认;
+// 由通行证站点以POST方式调用,会员令牌不能通过地址传入
+func (o *oauthC) Authorize(c echo.Context) error {
+	if !checkMemberPostToken(c) {
+		return o.error(c, http.StatusUnauthorized, "login_required", nil)
+	}
+	if c.Request().FormValue("response_type") != "code" {
+		return o.error(c, http.StatusBadRequest, "unsupported_response_type", nil)
+	}
+	rlt, err := rsi.OAuthService.Authorize(GetMemberId(c), o.authorizeRequest(c))
+	if err != nil {
+		status, code := o.errorCode(err)
+		if err == oauth.ErrRedirectUri {
+			status, code = http.StatusBadRequest, "invalid_request"
+		}
+		return o.error(c, status, code, err)
+	}
+	return c.JSON(http.StatusOK, rlt)
+}
+
+// 会员确认或拒绝授权,allow为1时同意,返回回调地址
+func (o *oauthC) Consent(c echo.Context) error {
+	if !checkMemberPostToken(c) {
+		return o.error(c, http.StatusUnauthorized, "login_required", nil)
+	}
+	allow := c.Request().FormValue("allow") == "1"
+	uri, err := rsi.OAuthService.Consent(GetMemberId(c), o.authorizeRequest(c), allow)
+	if err != nil {
+		status, code := o.errorCode(err)
+		return o.error(c, status, code, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"redirectUrl": uri})
+}
+
+// 获取应用身份,支持HTTP Basic认证及表单参数
+func (o *oauthC) clientCredential(c echo.Context) (string, string) {
+	r := c.Request()
+	if id, secret, ok := r.BasicAuth(); ok {
+		return id, secret
+	}
+	return r.FormValue("client_id"), r.FormValue("client_secret")
+}
+
+// 获取令牌,支持authorization_code及refresh_token
+func (o *oauthC) Token(c echo.Context) error {
+	r := c.Request()
+	h := c.Response().Header()
+	h.Set("Cache-Control", "no-store")
+	h.Set("Pragma", "no-cache")
+	clientId, secret := o.clientCredential(c)
+	var t *module.OAuthToken
+	var err error
+	switch r.FormValue("grant_type") {
+	case "authorization_code":
+		t, err = rsi.OAuthService.ExchangeCode(clientId, secret,
+			r.FormValue("code"), r.FormValue("redirect_uri"),
+			r.FormValue("code_verifier"))
+	case "refresh_token":
+		t, err = rsi.OAuthService.RefreshToken(clientId, secret,
+			r.FormValue("refresh_token"))
+	default:
+		return o.error(c, http.StatusBadRequest, "unsupported_grant_type", nil)
+	}
+	if err != nil {
+		status, code := o.errorCode(err)
+		return o.error(c, status, code, err)
+	}
+	return c.JSON(http.StatusOK, t)
+}
+
+// 注销令牌(RFC 7009)
+func (o *oauthC) Revoke(c echo.Context) error {
+	clientId, secret := o.clientCredential(c)
+	err := rsi.OAuthService.RevokeToken(clientId, secret, c.Request().FormValue("token"))
+	if err != nil {
+		status, code := o.errorCode(err)
+		return o.error(c, status, code, err)
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// 检查访问令牌,令牌通过Authorization请求头传入;
+// 检查失败时返回nil,并已输出错误
+func (o *oauthC) checkBearer(c echo.Context, scope string) (*module.AccessInfo, error) {
+	r := c.Request()
+	token := r.Header.Get("Authorization")
+	if strings.HasPrefix(token, "Bearer ") {
+		token = token[7:]
+	} else {
+		token = r.FormValue("access_token")
+	}
+	info, err := rsi.OAuthService.CheckAccessToken(token, scope)
+	if err != nil {
+		status, code := o.errorCode(err)
+		return nil, o.error(c, status, code, nil)
+	}
+	return info, nil
+}
+
+// 获取会员信息(OpenID Connect UserInfo)
+func (o *oauthC) UserInfo(c echo.Context) error {
+	info, err := o.checkBearer(c, oauth.ScopeOpenId)
+	if info == nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, rsi.OAuthService.UserInfo(info))
+}
+
+// 获取会员订单,须包含orders授权范围
+func (o *oauthC) Orders(c echo.Context) error {
+	info, err := o.checkBearer(c, oauth.ScopeOrders)
+	if info == nil {
+		return err
+	}
+	r := c.Request()
+	begin, _ := strconv.Atoi(r.FormValue("begin"))
+	size, _ := strconv.Atoi(r.FormValue("size"))
+	if size <= 0 || size > 50 {
+		size = 20
+	}
+	total, rows, _ := rsi.OAuthService.QueryOrders(info, begin, size)
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"total": total,
+		"rows":  rows,
+	})
+}
+
+// 公钥集
+func (o *oauthC) Jwks(c echo.Context) error {
+	m := module.Get(module.M_OAUTH).(*module.OAuthModule)
+	c.Response().Header().Set("Cache-Control", "public, max-age=3600")
+	return c.JSON(http.StatusOK, m.Jwks())
+}
+
+// OpenID Connect服务发现,接口地址以签发者为前缀,
+// 由通行证站点将/oauth/路径转发到接口服务
+func (o *oauthC) Discovery(c echo.Context) error {
+	iss := module.Get(module.M_OAUTH).(*module.OAuthModule).Issuer()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"issuer":                                iss,
+		"authorization_endpoint":                iss + "/oauth/authorize",
+		"token_endpoint":                        iss + "/oauth/token",
+		"userinfo_endpoint":                     iss + "/oauth/userinfo",
+		"revocation_endpoint":                   iss + "/oauth/revoke",
+		"jwks_uri":                              iss + "/oauth/jwks",
+		"scopes_supported":                      oauth.Scopes,
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{oauth.PkceS256, oauth.PkcePlain},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic",
+			"client_secret_post", "none"},
+	})
+}
+
+// 获取会员已授权的应用
+func (o *oauthC) Consents(c echo.Context) error {
+	return c.JSON(http.StatusOK, rsi.OAuthService.GetConsents(GetMemberId(c)))
+}
+
+// 取消对应用的授权
+func (o *oauthC) RevokeConsent(c echo.Context) error {
+	result := gof.Message{}
+	err := rsi.OAuthService.RevokeConsent(GetMemberId(c),
+		c.Request().FormValue("client_id"))
+	return c.JSON(http.StatusOK, result.Error(err))
+}
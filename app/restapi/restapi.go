@@ -14,9 +14,16 @@ import (
 	"github.com/labstack/echo"
 	"go2o/app/cache"
 	"go2o/core/domain/interface/merchant"
+	"go2o/core/domain/interface/risk"
 	"go2o/core/service/thrift"
+	"log"
+	"math"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // 获取存储
@@ -24,44 +31,46 @@ func GetStorage() storage.Interface {
 	return sto
 }
 
-// 获取传入的商户接口编号和密钥
-func getUserInfo(c echo.Context) (string, string) {
+// 获取传入的商户接口编号,请求参数须已解析
+func getApiId(c echo.Context) string {
 	r := c.Request()
-	apiId := r.FormValue("merchant_id")
-	apiSecret := r.FormValue("secret")
-	if len(apiId) == 0 {
-		apiId = r.URL.Query().Get("merchant_id")
-	}
-
+	apiId := r.Form.Get("merchant_id")
 	//todo: 兼容partner_id  ,将删除
 	if len(apiId) == 0 {
-		apiId = r.FormValue("partner_id")
-		if len(apiId) == 0 {
-			apiId = r.URL.Query().Get("partner_id")
-		}
-	}
-
-	if len(apiSecret) == 0 {
-		apiSecret = r.URL.Query().Get("secret")
+		apiId = r.Form.Get("partner_id")
 	}
-	return apiId, apiSecret
+	return apiId
 }
 
-// 检查是否有权限调用接口(商户)
-func chkMerchantApiSecret(c echo.Context) bool {
-	i, s := getUserInfo(c)
-	ok, mchId := CheckApiPermission(i, s)
-	if ok {
+// 检查是否有权限调用接口(商户),请求须使用接口密钥签名
+func chkMerchantApiSign(c echo.Context) error {
+	r := c.Request()
+	mchId, err := CheckApiPermission(getApiId(c), r.Method, r.URL.Path, r.Form)
+	if err == nil {
 		c.Set("merchant_id", mchId)
 	}
-	return ok
+	return err
 }
 
 // 检查会员令牌信息
 func checkMemberToken(c echo.Context) bool {
 	r := c.Request()
-	memberId, _ := util.I64Err(strconv.Atoi(r.FormValue("member_id")))
-	token := r.FormValue("member_token")
+	return checkMemberCredential(c, r.FormValue("member_id"), r.FormValue("member_token"))
+}
+
+// 检查会员令牌,令牌只能通过POST请求体传入,避免出现在地址及访问日志中
+func checkMemberPostToken(c echo.Context) bool {
+	r := c.Request()
+	if r.Method != http.MethodPost {
+		return false
+	}
+	r.ParseForm()
+	return checkMemberCredential(c, r.PostFormValue("member_id"), r.PostFormValue("member_token"))
+}
+
+// 校验会员编号及令牌,成功后保存会员编号
+func checkMemberCredential(c echo.Context, id string, token string) bool {
+	memberId, _ := util.I64Err(strconv.Atoi(id))
 	cli, err := thrift.MemberServeClient()
 	if err == nil {
 		defer cli.Transport.Close()
@@ -87,14 +96,130 @@ func ApiTest(c echo.Context) error {
 	return c.String(http.StatusOK, "It's working!")
 }
 
-// 检查是否有权限
-func CheckApiPermission(apiId string, secret string) (bool, int32) {
-	if len(apiId) != 0 && len(secret) != 0 {
-		mchId := cache.GetMerchantIdByApiId(apiId)
-		var apiInfo *merchant.ApiInfo = cache.GetMerchantApiInfo(mchId)
-		if apiInfo != nil {
-			return apiInfo.ApiSecret == secret, mchId
+// 检查是否有权限,校验请求的签名,时间戳及随机串;
+// 签名方法参见: apisign.Request
+func CheckApiPermission(apiId string, method string, path string,
+	params url.Values) (int32, error) {
+	return cache.CheckMerchantApiSign(apiId, method, path, params)
+}
+
+// 获取请求路径对应的接口分组
+func getApiGroup(path string) string {
+	path = strings.TrimPrefix(path, PathPrefix)
+	switch {
+	case strings.HasPrefix(path, "/get/"):
+		return merchant.ApiGroupGet
+	case strings.HasPrefix(path, "/mm_"), strings.HasPrefix(path, "/member"):
+		return merchant.ApiGroupMember
+	case strings.HasPrefix(path, "/merchant/"), strings.HasPrefix(path, "/partner/"):
+		return merchant.ApiGroupMerchant
+	}
+	return merchant.ApiGroupDefault
+}
+
+// 接口限流,超出速率或每日配额时返回false,并输出429状态
+func chkApiRateLimit(c echo.Context) (bool, error) {
+	mchId := getMerchantId(c)
+	r, err := cache.TakeMerchantApiToken(mchId, getApiGroup(c.Request().URL.Path))
+	// 限流服务不可用时不影响接口调用
+	if err != nil || r == nil {
+		return true, nil
+	}
+	h := c.Response().Header()
+	reset := int64(math.Ceil(r.RetryAfter.Seconds()))
+	if r.Rate > 0 {
+		h.Set("X-RateLimit-Limit", strconv.Itoa(r.Rate))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(r.Remaining))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix()+reset, 10))
+	}
+	if r.DailyQuota > 0 {
+		remain := r.DailyQuota - r.Used
+		if remain < 0 {
+			remain = 0
+		}
+		h.Set("X-Quota-Limit", strconv.Itoa(r.DailyQuota))
+		h.Set("X-Quota-Remaining", strconv.Itoa(remain))
+	}
+	if r.Allowed {
+		return true, nil
+	}
+	h.Set("Retry-After", strconv.FormatInt(reset, 10))
+	msg := "rate limit exceeded"
+	if r.QuotaExceeded {
+		msg = "daily quota exceeded"
+	}
+	return false, c.JSON(http.StatusTooManyRequests, map[string]string{
+		"error": msg})
+}
+
+// 受信任的代理服务器,仅信任来自这些地址的X-Forwarded-For
+var trustedProxies []*net.IPNet
+
+// 设置受信任的代理,cidrs以逗号分隔,如:127.0.0.1/32,10.0.0.0/8
+func SetTrustedProxies(cidrs string) {
+	list := []*net.IPNet{}
+	for _, v := range strings.Split(cidrs, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if strings.Index(v, "/") == -1 {
+			if strings.Index(v, ":") == -1 {
+				v += "/32"
+			} else {
+				v += "/128"
+			}
+		}
+		if _, n, err := net.ParseCIDR(v); err == nil {
+			list = append(list, n)
+		} else {
+			log.Println("[ Go2o][ API]: bad trusted proxy:", v)
+		}
+	}
+	trustedProxies = list
+}
+
+func isTrustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, n := range trustedProxies {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// 获取风控评估的客户端信息
+func riskClient(r *http.Request) *risk.Client {
+	return &risk.Client{
+		Ip:       getClientIp(r),
+		DeviceId: r.FormValue("device_id"),
+	}
+}
+
+// 获取客户端IP.请求来自受信任的代理时,从右向左取X-Forwarded-For中
+// 首个不受信任的地址;否则使用连接的地址,以免客户端伪造IP
+func getClientIp(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	if !isTrustedProxy(ip) {
+		return ip
+	}
+	arr := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(arr) - 1; i >= 0; i-- {
+		v := strings.TrimSpace(arr[i])
+		if net.ParseIP(v) == nil {
+			break
+		}
+		ip = v
+		if !isTrustedProxy(v) {
+			break
 		}
 	}
-	return false, 0
+	return ip
 }
@@ -51,6 +51,7 @@ func GetServe() *echo.Echo {
 func Run(app gof.App, port int) {
 	sto = app.Storage()
 	API_DOMAIN = app.Config().GetString(variable.ApiDomain)
+	SetTrustedProxies(app.Config().GetString(variable.TrustedProxies))
 	log.Println("** [ Go2o][ API][ Booted] - Api server running on port " +
 		strconv.Itoa(port))
 	http.ListenAndServe(":"+strconv.Itoa(port), serve)
@@ -60,15 +61,104 @@ func registerRoutes(s *echo.Echo) {
 	pc := &merchantC{}
 	mc := &MemberC{}
 	gc := &getC{}
+	oc := &oauthC{}
+	cc := &captchaC{}
+	cb := &callbackC{}
+	hc := &chatC{}
+	ic := &inboxC{}
 
 	s.GET("/", ApiTest)
-	s.GET(PathPrefix+"/get/invite_qr", gc.Invite_qr) // 获取二维码
-	s.GET(PathPrefix+"/get/gen_qr", gc.GenQr)        //生成二维码
-	s.POST(PathPrefix+"/mm_login", mc.Login)         // 会员登录接口
-	s.POST(PathPrefix+"/mm_register", mc.Register)   // 会员注册接口
-	s.POST(PathPrefix+"/merchant/get_ad", pc.Get_ad) // 商户广告接口
-	s.POST(PathPrefix+"/partner/get_ad", pc.Get_ad)  // 商户广告接口
+	s.GET(PathPrefix+"/get/invite_qr", gc.Invite_qr)              // 获取二维码
+	s.GET(PathPrefix+"/get/gen_qr", gc.GenQr)                     //生成二维码
+	s.POST(PathPrefix+"/mm_captcha", cc.Captcha)                  // 图形验证码
+	s.POST(PathPrefix+"/mm_login", mc.Login)                      // 会员登录接口
+	s.POST(PathPrefix+"/mm_register", mc.Register)                // 会员注册接口
+	s.POST(PathPrefix+"/merchant/get_ad", pc.Get_ad)              // 商户广告接口
+	s.POST(PathPrefix+"/partner/get_ad", pc.Get_ad)               // 商户广告接口
+	s.POST(PathPrefix+"/merchant/api_usage", pc.ApiUsage)         // 接口用量
+	s.POST(PathPrefix+"/mm_refresh_token", mc.RefreshToken)       // 刷新会员令牌
+	s.POST(PathPrefix+"/member/sessions", mc.Sessions)            // 会员设备会话
+	s.POST(PathPrefix+"/member/revoke_session", mc.RevokeSession) // 注销设备会话
+	s.POST(PathPrefix+"/member/logout_all", mc.LogoutAll)         // 在所有设备上退出
+	s.POST(PathPrefix+"/member/oauth_consents", oc.Consents)      // 已授权的应用
+	s.POST(PathPrefix+"/member/oauth_revoke", oc.RevokeConsent)   // 取消应用授权
+	s.POST(PathPrefix+"/mm_social_login", mc.SocialLogin)         // 第三方账号登录
+	s.POST(PathPrefix+"/member/social_list", mc.SocialList)       // 绑定的第三方账号
+	s.POST(PathPrefix+"/member/social_bind", mc.SocialBind)       // 绑定第三方账号
+	s.POST(PathPrefix+"/member/social_unbind", mc.SocialUnbind)   // 解绑第三方账号
+	s.POST(PathPrefix+"/member/social_merge", mc.SocialMerge)     // 合并到手机会员
+
+	// 会员通知偏好
+	s.POST(PathPrefix+"/member/notify_pref", mc.NotifyPref)                  // 通知偏好
+	s.POST(PathPrefix+"/member/save_notify_pref", mc.SaveNotifyPref)         // 保存通知偏好
+	s.POST(PathPrefix+"/member/save_notify_channels", mc.SaveNotifyChannels) // 设置通知渠道
+	s.POST(PathPrefix+"/member/notify_logs", mc.NotifyLogs)                  // 通知记录
+
+	// 会员设备推送
+	s.POST(PathPrefix+"/member/push_register", mc.PushRegister)     // 注册推送设备
+	s.POST(PathPrefix+"/member/push_unregister", mc.PushUnregister) // 取消推送
+
+	// 商户事件推送
+	s.POST(PathPrefix+"/merchant/webhooks", pc.Webhooks)                    // 推送地址
+	s.POST(PathPrefix+"/merchant/save_webhook", pc.SaveWebhook)             // 保存推送地址
+	s.POST(PathPrefix+"/merchant/delete_webhook", pc.DeleteWebhook)         // 删除推送地址
+	s.POST(PathPrefix+"/merchant/webhook_deliveries", pc.WebhookDeliveries) // 推送记录
+	s.POST(PathPrefix+"/merchant/redeliver_webhook", pc.RedeliverWebhook)   // 重新推送
+
+	// 会员账户
+	s.POST(PathPrefix+"/member/take_out", mc.TakeOut)  // 申请提现
+	s.POST(PathPrefix+"/member/transfer", mc.Transfer) // 转账
+
+	// 同城配送
+	s.POST(PathPrefix+"/member/deliver_code", mc.DeliverCode) // 签收码
+
+	// 第三方应用
+	s.POST(PathPrefix+"/merchant/oauth_clients", pc.OAuthClients)                // 应用列表
+	s.POST(PathPrefix+"/merchant/oauth_register_client", pc.RegisterOAuthClient) // 注册应用
+	s.POST(PathPrefix+"/merchant/oauth_save_client", pc.SaveOAuthClient)         // 保存应用
+	s.POST(PathPrefix+"/merchant/oauth_reset_secret", pc.ResetOAuthSecret)       // 重置应用密钥
+
+	// 电子面单
+	s.POST(PathPrefix+"/merchant/waybill", pc.Waybill)              // 获取电子面单
+	s.POST(PathPrefix+"/merchant/print_waybills", pc.PrintWaybills) // 批量打印面单
+
+	// 客服会话
+	s.POST(PathPrefix+"/member/chat_open", hc.Open)                // 发起会话
+	s.POST(PathPrefix+"/member/chat_sessions", hc.Sessions)        // 会员的会话
+	s.POST(PathPrefix+"/member/chat_messages", hc.Messages)        // 历史消息
+	s.POST(PathPrefix+"/member/chat_send", hc.Send)                // 发送消息
+	s.POST(PathPrefix+"/member/chat_read", hc.Read)                // 标记已读
+	s.POST(PathPrefix+"/member/chat_close", hc.Close)              // 结束会话
+	s.POST(PathPrefix+"/merchant/chat_agent_state", hc.AgentState) // 设置客服状态
+	s.POST(PathPrefix+"/merchant/chat_agents", hc.Agents)          // 商户的客服
+	s.POST(PathPrefix+"/merchant/chat_agent_token", hc.AgentToken) // 客服连接令牌
+	s.POST(PathPrefix+"/merchant/chat_sessions", hc.AgentSessions) // 客服的会话
+	s.POST(PathPrefix+"/merchant/chat_messages", hc.AgentMessages) // 历史消息
+	s.POST(PathPrefix+"/merchant/chat_send", hc.AgentSend)         // 发送消息
+	s.POST(PathPrefix+"/merchant/chat_read", hc.AgentRead)         // 标记已读
+	s.POST(PathPrefix+"/merchant/chat_close", hc.AgentClose)       // 结束会话
+	s.POST(PathPrefix+"/merchant/chat_transfer", hc.Transfer)      // 转接会话
+
+	// 站内信
+	s.POST(PathPrefix+"/member/inbox", ic.List)          // 站内信列表
+	s.POST(PathPrefix+"/member/inbox_get", ic.Get)       // 站内信详情
+	s.POST(PathPrefix+"/member/inbox_unread", ic.Unread) // 未读数量
+	s.POST(PathPrefix+"/member/inbox_read", ic.Read)     // 标记已读
+	s.POST(PathPrefix+"/member/inbox_delete", ic.Delete) // 删除站内信
 	//s.Post("/member/*",mc)  // 会员接口
+
+	// OAuth2 / OpenID Connect
+	s.GET("/.well-known/openid-configuration", oc.Discovery)
+	s.GET("/oauth/jwks", oc.Jwks)
+	s.POST("/oauth/authorize", oc.Authorize)
+	s.POST("/oauth/consent", oc.Consent)
+	s.POST("/oauth/token", oc.Token)
+	s.POST("/oauth/revoke", oc.Revoke)
+	s.Any("/oauth/userinfo", oc.UserInfo)
+	s.GET("/oauth/orders", oc.Orders)
+
+	// 第三方回调
+	s.Any("/callback/sms", cb.SmsReceipt) // 短信状态报告
 }
 
 func beforeRequest() echo.MiddlewareFunc {
@@ -81,14 +171,21 @@ func beforeRequest() echo.MiddlewareFunc {
 				return c.String(http.StatusNotFound, "no such file")
 			}
 
-			if path != "/" {
+			// OAuth2接口使用应用密钥或访问令牌认证,回调接口由服务商调用
+			if path != "/" && !isOAuthPath(path) && !isCallbackPath(path) {
 				//检查商户接口权限
 				c.Request().ParseForm()
-				if !chkMerchantApiSecret(c) {
-					return c.String(http.StatusOK, "{error:\"incorrent secret\"}")
+				if err := chkMerchantApiSign(c); err != nil {
+					return c.JSON(http.StatusOK, map[string]string{
+						"error": err.Error()})
+				}
+				//接口限流及每日配额
+				if ok, err := chkApiRateLimit(c); !ok {
+					return err
 				}
 				//检查会员会话
-				if strings.HasPrefix(path, "/member") && !checkMemberToken(c) {
+				if strings.HasPrefix(strings.TrimPrefix(path, PathPrefix),
+					"/member") && !checkMemberToken(c) {
 					return c.String(http.StatusOK, "{error:\"incorrent session\"}")
 				}
 			}
@@ -97,3 +194,14 @@ func beforeRequest() echo.MiddlewareFunc {
 		}
 	}
 }
+
+// 是否为OAuth2 / OpenID Connect接口
+func isOAuthPath(path string) bool {
+	return strings.HasPrefix(path, "/oauth/") ||
+		strings.HasPrefix(path, "/.well-known/")
+}
+
+// 是否为第三方回调接口
+func isCallbackPath(path string) bool {
+	return strings.HasPrefix(path, "/callback/")
+}
@@ -0,0 +1,168 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : tcp_chat
+ * author : jarryliu
+ * date : 2026-10-22 13:40
+ * description : 客服会话,会员通过TCP或WebSocket收发消息,客服通过WebSocket;
+ *               消息保存后由通知队列推送给会话的会员及客服
+ * history :
+ */
+package tcpserve
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/jsix/gof/net/nc"
+	"go2o/core"
+	"go2o/core/domain/interface/mss/chat"
+	"go2o/core/service/rsi"
+	"go2o/core/variable"
+)
+
+func init() {
+	Handle("CHAT", cliChat)
+	Handle("CREAD", cliChatRead)
+}
+
+// 发送消息的参数,如:{"sessionId":1,"msgType":1,"content":"你好"}
+type chatRequest struct {
+	SessionId int32  `json:"sessionId"`
+	MsgType   int    `json:"msgType"`
+	Content   string `json:"content"`
+	// 已读到的消息编号
+	MsgId int64 `json:"msgId"`
+}
+
+// 会员发送消息,返回保存的消息,如:MCHT:{...}
+func cliChat(ci *nc.Client, plan string) ([]byte, error) {
+	return chatCommand(ci, 0, "CHAT", plan)
+}
+
+// 会员标记已读,如:CREAD:{"sessionId":1,"msgId":100}
+func cliChatRead(ci *nc.Client, plan string) ([]byte, error) {
+	return chatCommand(ci, 0, "CREAD", plan)
+}
+
+// 处理会话命令,agentId大于0时为客服
+func chatCommand(ci *nc.Client, agentId int32, cmd string, data string) ([]byte, error) {
+	if agentId <= 0 && ci.User <= 0 {
+		return nil, errors.New("member not auth")
+	}
+	r := chatRequest{}
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		return nil, errors.New("bad request")
+	}
+	mchId := int32(ci.Source)
+	if cmd == "CREAD" {
+		var err error
+		if agentId > 0 {
+			err = rsi.MssService.AgentReadChat(mchId, agentId, r.SessionId, r.MsgId)
+		} else {
+			err = rsi.MssService.MemberReadChat(ci.User, r.SessionId, r.MsgId)
+		}
+		return nil, err
+	}
+	var m *chat.Message
+	var err error
+	if agentId > 0 {
+		m, err = rsi.MssService.AgentSendChat(mchId, agentId, r.SessionId,
+			r.MsgType, r.Content)
+	} else {
+		m, err = rsi.MssService.MemberSendChat(ci.User, r.SessionId,
+			r.MsgType, r.Content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	d, err := json.Marshal(m)
+	return append([]byte("MCHT:"), d...), err
+}
+
+// 客服认证,data为客服令牌,令牌须属于已认证的商户
+func (w *WebSocketServer) agentAuth(c *wsClient, data string) error {
+	mchId, personId, err := rsi.MssService.CheckChatAgentToken(data)
+	if err != nil {
+		return err
+	}
+	if int64(mchId) != c.cli.Source {
+		return errors.New("auth fail")
+	}
+	w.unbindAgent(c)
+	c.agentId = personId
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	m, ok := w.agents[personId]
+	if !ok {
+		m = map[*wsClient]bool{}
+		w.agents[personId] = m
+	}
+	m[c] = true
+	return nil
+}
+
+func (w *WebSocketServer) unbindAgent(c *wsClient) {
+	if c.agentId <= 0 {
+		return
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if m, ok := w.agents[c.agentId]; ok {
+		delete(m, c)
+		if len(m) == 0 {
+			delete(w.agents, c.agentId)
+		}
+	}
+}
+
+// 获取客服的客户端,agentId为0时获取商户所有客服的客户端
+func (w *WebSocketServer) agentClients(mchId int32, agentId int32) []*wsClient {
+	w.mux.RLock()
+	defer w.mux.RUnlock()
+	list := []*wsClient{}
+	for id, m := range w.agents {
+		if agentId > 0 && id != agentId {
+			continue
+		}
+		for c := range m {
+			if c.cli.Source == int64(mchId) {
+				list = append(list, c)
+			}
+		}
+	}
+	return list
+}
+
+// 客服会话通知
+func ChatNotifyJob(s *nc.SocketServer) {
+	conn := core.GetRedisConn()
+	defer conn.Close()
+	for {
+		sessionId, msgId, err := popMemberNotify(conn,
+			variable.KvChatTcpNotifyQueue)
+		if err == nil {
+			go pushChat(s, int32(sessionId), msgId)
+		}
+	}
+}
+
+// 推送消息或会话状态给会员及客服,等待接入的会话推送给商户的所有客服
+func pushChat(s *nc.SocketServer, sessionId int32, msgId int64) {
+	cs := rsi.MssService.GetChatSession(sessionId)
+	if cs == nil {
+		return
+	}
+	var tp string = "MCSS"
+	var v interface{} = cs
+	if msgId > 0 {
+		tp, v = "MCHT", rsi.MssService.GetChatMessage(msgId)
+	}
+	connList, wsList := getPushTargets(s, cs.MemberId, TopicChat)
+	if wsServe != nil {
+		wsList = append(wsList, wsServe.agentClients(cs.MchId, cs.AgentId)...)
+	}
+	if len(connList) == 0 && len(wsList) == 0 {
+		return
+	}
+	s.Printf("[ TCP][ NOTIFY] - notify chat - %d:%d", sessionId, msgId)
+	pushNotify(connList, wsList, TopicChat, tp, v)
+}
@@ -10,12 +10,16 @@ package tcpserve
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/garyburd/redigo/redis"
 	"github.com/jsix/gof/net/nc"
 	"go2o/core"
+	"go2o/core/domain/interface/mss"
+	"go2o/core/service/rsi"
 	"go2o/core/variable"
 	"net"
 	"strconv"
+	"strings"
 )
 
 //note: 业务逻辑上可能会出现通知多次的情况
@@ -28,27 +32,50 @@ func AccountNotifyJob(s *nc.SocketServer) {
 		if err == nil {
 			id, err := strconv.Atoi(string(values[1].([]byte)))
 			if err == nil {
-				connList := s.GetConnections(int64(id))
-				if len(connList) > 0 {
-					go pushMemberAccount(s, connList, int64(id))
-				}
+				go pushMemberAccount(s, int64(id))
 			}
 		}
 	}
 }
 
-// push member summary to tcp client
-func pushMemberAccount(s *nc.SocketServer, connList []net.Conn, memberId int64) {
-	s.Printf("[ TCP][ NOTIFY] - notify account update - %d", memberId)
-	sm := getMemberAccount(memberId, 0)
-	if sm != nil {
-		if d, err := json.Marshal(sm); err == nil {
-			d = append([]byte("MACC:"), d...)
+// 获取需要推送的TCP连接及订阅了主题的WebSocket客户端
+func getPushTargets(s *nc.SocketServer, memberId int64,
+	topic string) ([]net.Conn, []*wsClient) {
+	connList := s.GetConnections(memberId)
+	var wsList []*wsClient
+	if wsServe != nil {
+		wsList = wsServe.subscribers(memberId, topic)
+	}
+	return connList, wsList
+}
+
+// 推送到TCP连接及WebSocket客户端,tp为数据类型,如:MACC
+func pushNotify(connList []net.Conn, wsList []*wsClient,
+	topic string, tp string, v interface{}) {
+	if len(connList) > 0 {
+		if d, err := json.Marshal(v); err == nil {
+			d = append([]byte(tp+":"), d...)
 			for _, conn := range connList {
 				conn.Write(append(d, '\n'))
 			}
 		}
 	}
+	if len(wsList) > 0 {
+		wsServe.push(wsList, &WsEvent{Event: topic, Type: tp, Data: v})
+	}
+}
+
+// push member summary to tcp client
+func pushMemberAccount(s *nc.SocketServer, memberId int64) {
+	connList, wsList := getPushTargets(s, memberId, TopicAccount)
+	if len(connList) == 0 && len(wsList) == 0 {
+		return
+	}
+	s.Printf("[ TCP][ NOTIFY] - notify account update - %d", memberId)
+	sm := getMemberAccount(memberId, 0)
+	if sm != nil {
+		pushNotify(connList, wsList, TopicAccount, "MACC", sm)
+	}
 }
 
 func MemberSummaryNotifyJob(s *nc.SocketServer) {
@@ -60,23 +87,91 @@ func MemberSummaryNotifyJob(s *nc.SocketServer) {
 		if err == nil {
 			id, err := strconv.Atoi(string(values[1].([]byte)))
 			if err == nil {
-				connList := s.GetConnections(int64(id))
-				if len(connList) > 0 {
-					go pushMemberSummary(s, connList, int64(id))
-				}
+				go pushMemberSummary(s, int64(id))
 			}
 		}
 	}
 }
 
 // push member summary to tcp client
-func pushMemberSummary(s *nc.SocketServer, connList []net.Conn, memberId int64) {
+func pushMemberSummary(s *nc.SocketServer, memberId int64) {
+	connList, wsList := getPushTargets(s, memberId, TopicAccount)
+	if len(connList) == 0 && len(wsList) == 0 {
+		return
+	}
 	s.Printf("[ TCP][ NOTIFY] - notify member update - %d", memberId)
 	sm := GetMemberSummary(memberId, 0)
-	if d, err := json.Marshal(sm); err == nil {
-		d = append([]byte("MSUM:"), d...)
-		for _, conn := range connList {
-			conn.Write(append(d, '\n'))
+	if sm != nil {
+		pushNotify(connList, wsList, TopicAccount, "MSUM", sm)
+	}
+}
+
+// 从队列中取出会员编号及对象编号,格式如:1!1001
+func popMemberNotify(conn redis.Conn, queue string) (int64, int64, error) {
+	values, err := redis.Values(conn.Do("BLPOP", queue, 0))
+	if err != nil {
+		return 0, 0, err
+	}
+	arr := strings.Split(string(values[1].([]byte)), "!")
+	if len(arr) != 2 {
+		return 0, 0, errors.New("bad notify:" + string(values[1].([]byte)))
+	}
+	memberId, err := strconv.ParseInt(arr[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := strconv.ParseInt(arr[1], 10, 64)
+	return memberId, id, err
+}
+
+// 订单状态变更通知
+func OrderStateNotifyJob(s *nc.SocketServer) {
+	conn := core.GetRedisConn()
+	defer conn.Close()
+	for {
+		memberId, orderId, err := popMemberNotify(conn,
+			variable.KvOrderStateTcpNotifyQueue)
+		if err == nil {
+			go pushOrderState(s, memberId, orderId)
 		}
 	}
 }
+
+// push order state to client
+func pushOrderState(s *nc.SocketServer, memberId int64, orderId int64) {
+	connList, wsList := getPushTargets(s, memberId, TopicOrder)
+	if len(connList) == 0 && len(wsList) == 0 {
+		return
+	}
+	s.Printf("[ TCP][ NOTIFY] - notify order state - %d", orderId)
+	o, _ := rsi.ShoppingService.GetSubOrder(orderId)
+	if o != nil && o.BuyerId == memberId {
+		pushNotify(connList, wsList, TopicOrder, "MORD", o)
+	}
+}
+
+// 站内信通知
+func MessageNotifyJob(s *nc.SocketServer) {
+	conn := core.GetRedisConn()
+	defer conn.Close()
+	for {
+		memberId, msgId, err := popMemberNotify(conn,
+			variable.KvMessageTcpNotifyQueue)
+		if err == nil {
+			go pushMessage(s, memberId, msgId)
+		}
+	}
+}
+
+// push site message to client
+func pushMessage(s *nc.SocketServer, memberId int64, msgId int64) {
+	connList, wsList := getPushTargets(s, memberId, TopicMessage)
+	if len(connList) == 0 && len(wsList) == 0 {
+		return
+	}
+	s.Printf("[ TCP][ NOTIFY] - notify site message - %d", msgId)
+	msg := rsi.MssService.GetSiteMessage(int32(msgId), int32(memberId), mss.RoleMember)
+	if msg != nil {
+		pushNotify(connList, wsList, TopicMessage, "MMSG", msg)
+	}
+}
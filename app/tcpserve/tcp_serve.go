@@ -10,11 +10,16 @@ package tcpserve
 
 import (
 	"errors"
+	"fmt"
 	"github.com/jsix/gof/net/nc"
 	"github.com/jsix/gof/util"
-	"go2o/core/service/rsi"
+	"go2o/app/cache"
+	"go2o/core/domain/interface/merchant"
+	"go2o/core/infrastructure/apisign"
 	"go2o/core/service/thrift"
+	"log"
 	"net"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -61,6 +66,25 @@ func NewServe(output bool) *nc.SocketServer {
 	return s
 }
 
+// 启动Socket服务及推送任务,wsAddr不为空时同时启动WebSocket网关,
+// 如:Serve(":14197", ":14198", true)
+func Serve(addr string, wsAddr string, output bool) {
+	s := NewServe(output)
+	go AccountNotifyJob(s)
+	go MemberSummaryNotifyJob(s)
+	go OrderStateNotifyJob(s)
+	go MessageNotifyJob(s)
+	go ChatNotifyJob(s)
+	if wsAddr != "" {
+		go func() {
+			if err := ListenWebSocket(s, wsAddr, "/ws"); err != nil {
+				log.Println("[ WS][ Error]:", err.Error())
+			}
+		}()
+	}
+	s.Listen(addr)
+}
+
 // Add socket command handler
 func Handle(cmd string, handler nc.CmdFunc) {
 	mux.Lock()
@@ -71,46 +95,44 @@ func Handle(cmd string, handler nc.CmdFunc) {
 // auth connection
 func connAuth(s *nc.SocketServer, conn net.Conn, line string) error {
 	if strings.HasPrefix(line, "AUTH:") {
-		arr := strings.Split(line[5:], "#") // AUTH:API_ID#SECRET#VERSION
-		if len(arr) == 3 {
+		// AUTH:API_ID#TIMESTAMP#NONCE#SIGN_VERSION#SIGN#VERSION
+		arr := strings.Split(line[5:], "#")
+		if len(arr) == 6 {
 			var af nc.AuthFunc = func() (int64, error) {
-				mchId := rsi.MerchantService.GetMerchantIdByApiId(arr[0])
-				apiInfo := rsi.MerchantService.GetApiInfo(mchId)
-				if apiInfo != nil && apiInfo.ApiSecret == arr[1] {
-					if apiInfo.Enabled == 0 {
-						return int64(mchId), errors.New("api has exipres")
-					}
-				}
-				return int64(mchId), nil
+				return checkApiAuth(arr[:5])
 			}
 			if err := s.Auth(conn, af); err != nil {
 				return err
 			}
-			s.Printf("[ CLIENT] - Version = %s", arr[2])
+			s.Printf("[ CLIENT] - Version = %s", arr[5])
 			return nil
 		}
 	}
 	return errors.New("conn reject")
 }
 
+// check signed auth of merchant, returns merchant id.
+// params: API_ID,TIMESTAMP,NONCE,SIGN_VERSION,SIGN,
+// the canonical request is 'AUTH\n/tcp\nmerchant_id=API_ID\nTIMESTAMP\nNONCE'.
+func checkApiAuth(arr []string) (int64, error) {
+	params := url.Values{}
+	params.Set("merchant_id", arr[0])
+	params.Set(apisign.ParamTimestamp, arr[1])
+	params.Set(apisign.ParamNonce, arr[2])
+	params.Set(apisign.ParamVersion, arr[3])
+	params.Set(apisign.ParamSign, arr[4])
+	mchId, err := cache.CheckMerchantApiSign(arr[0], "AUTH", "/tcp", params)
+	return int64(mchId), err
+}
+
 // member auth,command like 'MAUTH:1#3234234242342342'
 func memberAuth(s *nc.SocketServer, id *nc.Client, param string) ([]byte, error) {
 	var err error
 	arr := strings.Split(param, "#")
 	if len(arr) == 2 {
 		f := func() (int64, error) {
-			memberId, _ := util.I64Err(strconv.Atoi(arr[0]))
-			cli, err := thrift.MemberServeClient()
-			if err == nil {
-				defer cli.Transport.Close()
-				if b, _ := cli.CheckToken(memberId, arr[1]); b {
-					return memberId, nil
-				}
-				return memberId, errors.New("auth fail")
-			}
-			return memberId, errors.New("connect refused")
+			return checkMemberToken(arr[0], arr[1])
 		}
-
 		if err = s.UAuth(id.Conn, f); err == nil {
 			//验证成功
 			return []byte("ok"), nil
@@ -119,6 +141,20 @@ func memberAuth(s *nc.SocketServer, id *nc.Client, param string) ([]byte, error)
 	return nil, err
 }
 
+// check token of member, returns member id.
+func checkMemberToken(id string, token string) (int64, error) {
+	memberId, _ := util.I64Err(strconv.Atoi(id))
+	cli, err := thrift.MemberServeClient()
+	if err == nil {
+		defer cli.Transport.Close()
+		if b, _ := cli.CheckToken(memberId, token); b {
+			return memberId, nil
+		}
+		return memberId, errors.New("auth fail")
+	}
+	return memberId, errors.New("connect refused")
+}
+
 // Handle command of client sending.
 func handleCommand(s *nc.SocketServer, ci *nc.Client, cmd string) ([]byte, error) {
 	if time.Now().Sub(ci.LatestConnectTime) > disconnectDuration {
@@ -130,6 +166,9 @@ func handleCommand(s *nc.SocketServer, ci *nc.Client, cmd string) ([]byte, error
 	if !strings.HasPrefix(cmd, "PING") {
 		s.Printf("[ CLIENT][ MESSAGE] - send by %d ; %s", ci.Source, cmd)
 		ci.LatestConnectTime = time.Now()
+		if err := chkRateLimit(ci); err != nil {
+			return nil, err
+		}
 	}
 	i := strings.Index(cmd, ":")
 	if i != -1 {
@@ -141,6 +180,19 @@ func handleCommand(s *nc.SocketServer, ci *nc.Client, cmd string) ([]byte, error
 	return nil, errors.New("unknown command:" + cmd)
 }
 
+// 商户接口限流,超出速率或每日配额时返回错误
+func chkRateLimit(ci *nc.Client) error {
+	r, err := cache.TakeMerchantApiToken(int32(ci.Source), merchant.ApiGroupTcp)
+	if err != nil || r == nil || r.Allowed {
+		return nil
+	}
+	if r.QuotaExceeded {
+		return errors.New("daily quota exceeded")
+	}
+	return fmt.Errorf("rate limited, retry after %dms",
+		r.RetryAfter/time.Millisecond)
+}
+
 // print text by client sending.
 func cliPrint(id *nc.Client, params string) ([]byte, error) {
 	return []byte(params), nil
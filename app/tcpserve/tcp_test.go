@@ -11,10 +11,13 @@ package tcpserve
 import (
 	"bufio"
 	"fmt"
+	"go2o/core/infrastructure/apisign"
 	"io"
 	"log"
 	"net"
+	"net/url"
 	"testing"
+	"time"
 )
 
 func TestConn(t *testing.T) {
@@ -34,7 +37,7 @@ func TestConn(t *testing.T) {
 
 	var buffer []byte = make([]byte, 6048)
 
-	cli.Write([]byte("AUTH:6000037440#0befdb52f387cc93#1.0\n"))
+	cli.Write([]byte(signAuthLine("6000037440", "0befdb52f387cc93", "1.0")))
 	n, _ := cli.Read(buffer)
 	line := string(buffer[:n])
 	if line != "ok\n" {
@@ -54,6 +57,23 @@ func TestConn(t *testing.T) {
 	<-ch
 }
 
+// 生成签名的认证命令
+func signAuthLine(apiId string, secret string, version string) string {
+	params := url.Values{}
+	params.Set("merchant_id", apiId)
+	r := &apisign.Request{
+		Method:    "AUTH",
+		Path:      "/tcp",
+		Params:    params,
+		Timestamp: time.Now().Unix(),
+		Nonce:     apisign.NewNonce(),
+		Version:   apisign.DefaultVersion,
+	}
+	sign, _ := r.Sign(secret)
+	return fmt.Sprintf("AUTH:%s#%d#%s#%s#%s#%s\n", apiId, r.Timestamp,
+		r.Nonce, r.Version, sign, version)
+}
+
 func listenTcp(conn net.Conn) {
 	for {
 		buf := bufio.NewReader(conn)
@@ -64,3 +84,14 @@ func listenTcp(conn net.Conn) {
 		log.Println(line)
 	}
 }
+
+// 测试WebSocket返回内容的转换
+func TestWsData(t *testing.T) {
+	tp, d := wsData([]byte(`MACC:{"Balance":1}`))
+	if tp != "MACC" || string(d) != `{"Balance":1}` {
+		t.Errorf("typed data: %s %s", tp, string(d))
+	}
+	if tp, d = wsData([]byte("PONG")); tp != "" || string(d) != `"PONG"` {
+		t.Errorf("text data: %s %s", tp, string(d))
+	}
+}
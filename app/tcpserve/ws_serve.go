@@ -0,0 +1,340 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : ws_serve
+ * author : jarryliu
+ * date : 2026-10-19 15:10
+ * description : WebSocket网关,与TCP服务共用认证,命令及推送
+ * history :
+ */
+package tcpserve
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/jsix/gof/net/nc"
+	"go2o/core/service/rsi"
+	"golang.org/x/net/websocket"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 订单状态
+	TopicOrder = "order"
+	// 账户及会员资料
+	TopicAccount = "account"
+	// 站内信
+	TopicMessage = "message"
+	// 客服会话
+	TopicChat = "chat"
+)
+
+var (
+	// WebSocket服务,未启动时为nil
+	wsServe *WebSocketServer
+	// 带类型前缀的返回内容,如:MACC:{...}
+	wsTypedReg = regexp.MustCompile("^([A-Z]+):")
+	// 可订阅的主题
+	wsTopics = map[string]bool{
+		TopicOrder:   true,
+		TopicAccount: true,
+		TopicMessage: true,
+		TopicChat:    true,
+	}
+)
+
+type (
+	// WebSocket服务
+	WebSocketServer struct {
+		s       *nc.SocketServer
+		handler websocket.Handler
+		mux     sync.RWMutex
+		// 会员编号对应的客户端
+		clients map[int64]map[*wsClient]bool
+		// 客服(人员编号)对应的客户端
+		agents map[int32]map[*wsClient]bool
+	}
+
+	// WebSocket客户端
+	wsClient struct {
+		ws     *websocket.Conn
+		cli    *nc.Client
+		topics map[string]bool
+		// 客服的人员编号,客服认证后设置
+		agentId int32
+		mux     sync.Mutex
+		// 写入锁,同一连接不能并发写入
+		wmux sync.Mutex
+	}
+
+	// 客户端请求,如:{"id":1,"cmd":"MGET","data":"ACCOUNT:0"}
+	WsRequest struct {
+		// 请求编号,原样返回
+		Id int `json:"id"`
+		// 命令
+		Cmd string `json:"cmd"`
+		// 参数
+		Data string `json:"data"`
+	}
+
+	// 服务端响应,Code不为0时表示错误
+	WsResponse struct {
+		Id   int             `json:"id"`
+		Cmd  string          `json:"cmd"`
+		Code int             `json:"code"`
+		Msg  string          `json:"msg,omitempty"`
+		Type string          `json:"type,omitempty"`
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+
+	// 服务端推送
+	WsEvent struct {
+		// 主题,如:account
+		Event string `json:"event"`
+		// 数据类型,与TCP推送的前缀一致,如:MACC
+		Type string      `json:"type"`
+		Data interface{} `json:"data"`
+	}
+)
+
+// 创建WebSocket服务,s为TCP服务,用于共享命令处理及推送任务
+func NewWebSocketServe(s *nc.SocketServer) *WebSocketServer {
+	w := &WebSocketServer{
+		s:       s,
+		clients: map[int64]map[*wsClient]bool{},
+		agents:  map[int32]map[*wsClient]bool{},
+	}
+	w.handler = websocket.Handler(w.serve)
+	wsServe = w
+	return w
+}
+
+// 在指定地址监听WebSocket连接,如:ListenWebSocket(s,":14198","/ws")
+func ListenWebSocket(s *nc.SocketServer, addr string, path string) error {
+	mux := http.NewServeMux()
+	mux.Handle(path, NewWebSocketServe(s))
+	return http.ListenAndServe(addr, mux)
+}
+
+func (w *WebSocketServer) ServeHTTP(rsp http.ResponseWriter, req *http.Request) {
+	w.handler.ServeHTTP(rsp, req)
+}
+
+// 处理连接,首个请求须为AUTH命令
+func (w *WebSocketServer) serve(ws *websocket.Conn) {
+	c := &wsClient{ws: ws, topics: map[string]bool{}}
+	defer func() {
+		w.unbind(c)
+		w.unbindAgent(c)
+		ws.Close()
+	}()
+	for {
+		ws.SetReadDeadline(time.Now().Add(defaultReadDeadLine))
+		var frame string
+		if err := websocket.Message.Receive(ws, &frame); err != nil {
+			return
+		}
+		req := WsRequest{}
+		if err := json.Unmarshal([]byte(frame), &req); err != nil {
+			c.send(&WsResponse{Code: 1, Msg: "bad request"})
+			continue
+		}
+		rsp := w.handle(c, &req)
+		c.send(rsp)
+		// 认证失败,关闭连接
+		if c.cli == nil {
+			return
+		}
+	}
+}
+
+// 处理请求
+func (w *WebSocketServer) handle(c *wsClient, r *WsRequest) *WsResponse {
+	var d []byte
+	var err error
+	cmd := strings.ToUpper(r.Cmd)
+	switch {
+	case c.cli == nil:
+		err = w.auth(c, cmd, r.Data)
+	case cmd == "MAUTH":
+		err = w.memberAuth(c, r.Data)
+	case cmd == "SAUTH":
+		err = w.agentAuth(c, r.Data)
+	case c.agentId > 0 && (cmd == "CHAT" || cmd == "CREAD"):
+		d, err = chatCommand(c.cli, c.agentId, cmd, r.Data)
+	case cmd == "SUB", cmd == "UNSUB":
+		err = w.subscribe(c, r.Data, cmd == "SUB")
+	default:
+		d, err = handleCommand(w.s, c.cli, cmd+":"+r.Data)
+	}
+	rsp := &WsResponse{Id: r.Id, Cmd: cmd}
+	if err != nil {
+		rsp.Code = 1
+		rsp.Msg = err.Error()
+	} else if d == nil {
+		rsp.Data = json.RawMessage(`"ok"`)
+	} else {
+		rsp.Type, rsp.Data = wsData(d)
+	}
+	return rsp
+}
+
+// 连接认证,首个请求须为以下命令之一:
+// AUTH为商户签名认证,data格式与TCP相同:API_ID#TIMESTAMP#NONCE#SIGN_VERSION#SIGN#VERSION,
+// 仅用于服务端或原生应用;浏览器不能持有商户密钥,应使用会员的访问令牌(MAUTH)
+// 或客服令牌(SAUTH)认证,两者均有较短的有效期
+func (w *WebSocketServer) auth(c *wsClient, cmd string, data string) error {
+	switch cmd {
+	case "MAUTH":
+		c.cli = w.newClient(c, 0)
+		if err := w.memberAuth(c, data); err != nil {
+			c.cli = nil
+			return err
+		}
+		return nil
+	case "SAUTH":
+		mchId, _, err := rsi.MssService.CheckChatAgentToken(data)
+		if err == nil {
+			c.cli = w.newClient(c, int64(mchId))
+			if err = w.agentAuth(c, data); err != nil {
+				c.cli = nil
+			}
+		}
+		return err
+	}
+	arr := strings.Split(data, "#")
+	if cmd != "AUTH" || len(arr) != 6 {
+		return errors.New("conn reject")
+	}
+	mchId, err := checkApiAuth(arr[:5])
+	if err != nil {
+		return err
+	}
+	c.cli = w.newClient(c, mchId)
+	w.s.Printf("[ WS][ CLIENT] - Version = %s", arr[5])
+	return nil
+}
+
+func (w *WebSocketServer) newClient(c *wsClient, mchId int64) *nc.Client {
+	return &nc.Client{
+		Conn:              c.ws,
+		Source:            mchId,
+		LatestConnectTime: time.Now(),
+	}
+}
+
+// 会员认证,data格式:MEMBER_ID#TOKEN
+func (w *WebSocketServer) memberAuth(c *wsClient, data string) error {
+	arr := strings.Split(data, "#")
+	if len(arr) != 2 {
+		return errors.New("auth fail")
+	}
+	memberId, err := checkMemberToken(arr[0], arr[1])
+	if err == nil {
+		w.unbind(c)
+		c.cli.User = memberId
+		// 默认订阅所有主题
+		c.mux.Lock()
+		for k := range wsTopics {
+			c.topics[k] = true
+		}
+		c.mux.Unlock()
+		w.bind(c)
+	}
+	return err
+}
+
+// 订阅或取消订阅,data为以逗号分隔的主题,如:order,account
+func (w *WebSocketServer) subscribe(c *wsClient, data string, sub bool) error {
+	if c.cli.User <= 0 {
+		return errors.New("member not auth")
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	for _, t := range strings.Split(data, ",") {
+		t = strings.TrimSpace(t)
+		if !wsTopics[t] {
+			return errors.New("unknown topic:" + t)
+		}
+		if sub {
+			c.topics[t] = true
+		} else {
+			delete(c.topics, t)
+		}
+	}
+	return nil
+}
+
+func (w *WebSocketServer) bind(c *wsClient) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	m, ok := w.clients[c.cli.User]
+	if !ok {
+		m = map[*wsClient]bool{}
+		w.clients[c.cli.User] = m
+	}
+	m[c] = true
+}
+
+func (w *WebSocketServer) unbind(c *wsClient) {
+	if c.cli == nil || c.cli.User <= 0 {
+		return
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if m, ok := w.clients[c.cli.User]; ok {
+		delete(m, c)
+		if len(m) == 0 {
+			delete(w.clients, c.cli.User)
+		}
+	}
+}
+
+// 获取订阅了主题的会员客户端
+func (w *WebSocketServer) subscribers(memberId int64, topic string) []*wsClient {
+	w.mux.RLock()
+	defer w.mux.RUnlock()
+	list := []*wsClient{}
+	for c := range w.clients[memberId] {
+		c.mux.Lock()
+		if c.topics[topic] {
+			list = append(list, c)
+		}
+		c.mux.Unlock()
+	}
+	return list
+}
+
+// 推送给订阅了主题的会员客户端
+func (w *WebSocketServer) push(list []*wsClient, e *WsEvent) {
+	for _, c := range list {
+		c.send(e)
+	}
+}
+
+// 发送JSON数据
+func (c *wsClient) send(v interface{}) error {
+	c.wmux.Lock()
+	defer c.wmux.Unlock()
+	return websocket.JSON.Send(c.ws, v)
+}
+
+// 转换命令的返回内容,带类型前缀的内容(如:MACC:{...})拆分为类型和数据
+func wsData(d []byte) (string, json.RawMessage) {
+	if m := wsTypedReg.FindSubmatch(d); m != nil && isJson(d[len(m[0]):]) {
+		return string(m[1]), json.RawMessage(d[len(m[0]):])
+	}
+	if isJson(d) {
+		return "", json.RawMessage(d)
+	}
+	s, _ := json.Marshal(string(d))
+	return "", json.RawMessage(s)
+}
+
+func isJson(d []byte) bool {
+	var v json.RawMessage
+	return json.Unmarshal(d, &v) == nil
+}
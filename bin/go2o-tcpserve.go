@@ -0,0 +1,46 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : go2o-tcpserve.go
+ * author : jarryliu
+ * date : 2026-10-19 16:20
+ * description : Socket服务(TCP/WebSocket),需在配置中设置socket_serve_on=1,
+ *               以将订单、站内信等通知写入推送队列
+ * history :
+ */
+package main
+
+import (
+	"flag"
+	"github.com/jsix/gof"
+	"go2o/app"
+	"go2o/app/tcpserve"
+	"go2o/core"
+	"go2o/core/service/rsi"
+	"go2o/core/variable"
+	"log"
+	"os"
+)
+
+func main() {
+	var (
+		addr  string
+		conf  string
+		debug bool
+	)
+
+	flag.StringVar(&addr, "addr", ":14197", "Address to listen to")
+	flag.StringVar(&conf, "conf", "app.conf", "Config file path")
+	flag.BoolVar(&debug, "debug", false, "Enable debug")
+	flag.Parse()
+
+	newApp := core.NewApp(conf)
+	if !core.Init(newApp, debug, false) {
+		os.Exit(1)
+	}
+	gof.CurrentApp = newApp
+	rsi.Init(newApp, app.FlagTcpServe)
+	if newApp.Config().GetString(variable.SocketServeOn) != "1" {
+		log.Println("[ Go2o][ Socket]: socket_serve_on is not set, no notify will be pushed")
+	}
+	tcpserve.Serve(addr, newApp.Config().GetString(variable.WebSocketServeAddr), debug)
+}
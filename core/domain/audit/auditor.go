@@ -0,0 +1,92 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : auditor
+ * author : jarryliu
+ * date : 2026-10-20 11:20
+ * description : 审计日志,每条日志包含上一条日志的散列值,散列值使用服务端密钥计算
+ * history :
+ */
+package audit
+
+import (
+	"encoding/json"
+	"go2o/core/domain/interface/audit"
+	"sync"
+	"time"
+)
+
+var _ audit.IAuditor = new(auditorImpl)
+
+type auditorImpl struct {
+	rep audit.IAuditRepo
+	key []byte
+	mux sync.Mutex
+}
+
+// 创建审计,key为计算散列值的密钥
+func NewAuditor(rep audit.IAuditRepo, key []byte) audit.IAuditor {
+	return &auditorImpl{
+		rep: rep,
+		key: key,
+	}
+}
+
+// 记录操作,before和after为操作前后的值(结构体或map),
+// 仅记录有变更的字段
+func (a *auditorImpl) Record(actor *audit.Actor, action string, targetType string,
+	targetId string, before interface{}, after interface{}, remark string) (*audit.Log, error) {
+	if actor == nil {
+		actor = audit.SystemActor
+	}
+	diff, err := json.Marshal(audit.Diff(before, after))
+	if err != nil {
+		return nil, err
+	}
+	v := &audit.Log{
+		ActorType:  actor.Type,
+		ActorId:    actor.Id,
+		ActorRole:  actor.Role,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		Diff:       string(diff),
+		Remark:     remark,
+		Ip:         actor.Ip,
+		CreateTime: time.Now().Unix(),
+	}
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	// 上一条日志的散列值唯一,多个进程同时写入时将保存失败,重新获取后重试
+	for i := 0; i < 3; i++ {
+		v.PrevHash = ""
+		if l := a.rep.GetLatestLog(); l != nil {
+			v.PrevHash = l.Hash
+		}
+		v.Hash = v.ComputeHash(a.key)
+		if v.Id, err = a.rep.SaveLog(v); err == nil {
+			return v, nil
+		}
+		v.Id = 0
+	}
+	return nil, err
+}
+
+// 校验从fromId开始的size条日志,返回被篡改的日志编号及ErrChainBroken
+func (a *auditorImpl) Verify(fromId int64, size int) (int64, error) {
+	list := a.rep.GetLogs(fromId, size)
+	for i, v := range list {
+		if v.Hash != v.ComputeHash(a.key) {
+			return v.Id, audit.ErrChainBroken
+		}
+		// 与上一条日志串联,删除日志后将无法串联
+		if i > 0 && v.PrevHash != list[i-1].Hash {
+			return v.Id, audit.ErrChainBroken
+		}
+		if i == 0 && v.PrevHash != "" {
+			if prev := a.rep.GetLogByHash(v.PrevHash); prev == nil || prev.Id >= v.Id {
+				return v.Id, audit.ErrChainBroken
+			}
+		}
+	}
+	return 0, nil
+}
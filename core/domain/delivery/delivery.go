@@ -11,6 +11,8 @@ package delivery
 import (
 	"go2o/core/domain/interface/delivery"
 	"go2o/core/infrastructure/domain"
+	"go2o/core/infrastructure/lbs"
+	"time"
 )
 
 var _ delivery.IDelivery = new(Delivery)
@@ -102,3 +104,192 @@ func (d *Delivery) GetDeliveryInfo(coverageId int32) (shopId, deliverUsrId int32
 	}
 	return -1, -1, nil
 }
+
+// 保存配送员
+func (d *Delivery) SaveStaff(v *delivery.DeliveryStaff) (int32, error) {
+	if v.Id > 0 {
+		origin := d.GetStaff(v.PersonId)
+		if origin == nil || origin.Id != v.Id {
+			return 0, delivery.ErrNoSuchStaff
+		}
+	} else if d.GetStaff(v.PersonId) != nil {
+		return 0, delivery.ErrStaffExists
+	}
+	v.MchId = d.id
+	v.UpdateTime = time.Now().Unix()
+	return d.rep.SaveStaff(v)
+}
+
+// 获取配送员
+func (d *Delivery) GetStaff(personId int32) *delivery.DeliveryStaff {
+	return d.rep.GetStaff(d.id, personId)
+}
+
+// 获取所有配送员
+func (d *Delivery) GetStaffList() []*delivery.DeliveryStaff {
+	return d.rep.GetStaffList(d.id)
+}
+
+// 配送员上线或离线
+func (d *Delivery) SetStaffState(personId int32, state int) error {
+	s := d.GetStaff(personId)
+	if s == nil {
+		return delivery.ErrNoSuchStaff
+	}
+	s.State = state
+	s.UpdateTime = time.Now().Unix()
+	_, err := d.rep.SaveStaff(s)
+	return err
+}
+
+// 上报配送员位置
+func (d *Delivery) ReportLocation(personId int32, lng, lat float64) error {
+	s := d.GetStaff(personId)
+	if s == nil {
+		return delivery.ErrNoSuchStaff
+	}
+	unix := time.Now().Unix()
+	s.Lng = lng
+	s.Lat = lat
+	s.LocateTime = unix
+	s.UpdateTime = unix
+	_, err := d.rep.SaveStaff(s)
+	return err
+}
+
+// 派单给配送员,lng,lat为收货地址的坐标;
+// 配送员拒单后可重新派单
+func (d *Delivery) Dispatch(subOrderId int64, staffId int32,
+	lng, lat float64) (delivery.IDispatchOrder, error) {
+	s := d.GetStaff(staffId)
+	if s == nil {
+		return nil, delivery.ErrNoSuchStaff
+	}
+	if s.State != delivery.StaffOnline {
+		return nil, delivery.ErrStaffOffline
+	}
+	return d.assign(subOrderId, staffId, lng, lat, 0)
+}
+
+// 自动派单,mode为派单方式,如:AssignRoundRobin
+func (d *Delivery) AutoDispatch(subOrderId int64, lng, lat float64,
+	mode int) (delivery.IDispatchOrder, error) {
+	if mode != delivery.AssignRoundRobin && mode != delivery.AssignNearest {
+		return nil, delivery.ErrNotSupportAssignMode
+	}
+	// 排除已拒单的配送员
+	var rejectStaff int32
+	if v := d.rep.GetDispatchOrderBySubOrder(subOrderId); v != nil &&
+		v.State == delivery.DispatchRejected {
+		rejectStaff = v.StaffId
+	}
+	list := []*delivery.DeliveryStaff{}
+	for _, v := range d.GetStaffList() {
+		if v.State == delivery.StaffOnline && v.PersonId != rejectStaff {
+			list = append(list, v)
+		}
+	}
+	if len(list) == 0 {
+		return nil, delivery.ErrNoAvailableStaff
+	}
+	var staff *delivery.DeliveryStaff
+	if mode == delivery.AssignNearest {
+		staff = d.nearestStaff(list, lng, lat)
+	}
+	// 无法就近派单时,轮流派单
+	if staff == nil {
+		staff = d.nextStaff(list)
+	}
+	return d.assign(subOrderId, staff.PersonId, lng, lat, mode)
+}
+
+// 获取位置有效且离收货地址最近的配送员
+func (d *Delivery) nearestStaff(list []*delivery.DeliveryStaff,
+	lng, lat float64) *delivery.DeliveryStaff {
+	if lng == 0 && lat == 0 {
+		return nil
+	}
+	var staff *delivery.DeliveryStaff
+	var distance float64 = -1
+	unix := time.Now().Unix()
+	for _, v := range list {
+		if unix-v.LocateTime > delivery.StaffLocationExpires {
+			continue
+		}
+		dis := lbs.GetLocDistance(v.Lng, v.Lat, lng, lat)
+		if distance < 0 || dis < distance {
+			distance = dis
+			staff = v
+		}
+	}
+	return staff
+}
+
+// 按人员编号轮流获取下一个配送员,list已按人员编号排序
+func (d *Delivery) nextStaff(list []*delivery.DeliveryStaff) *delivery.DeliveryStaff {
+	if last := d.rep.GetLatestDispatchOrder(d.id); last != nil {
+		for _, v := range list {
+			if v.PersonId > last.StaffId {
+				return v
+			}
+		}
+	}
+	return list[0]
+}
+
+// 生成或更新配送单
+func (d *Delivery) assign(subOrderId int64, staffId int32, lng, lat float64,
+	mode int) (delivery.IDispatchOrder, error) {
+	v := d.rep.GetDispatchOrderBySubOrder(subOrderId)
+	if v == nil {
+		v = &delivery.DispatchOrder{
+			MchId:       d.id,
+			SubOrderId:  subOrderId,
+			DeliverCode: domain.GenerateRandomIntPwd(6),
+		}
+	} else if v.MchId != d.id {
+		return nil, delivery.ErrNoSuchDispatch
+	} else if v.State == delivery.DispatchCancelled {
+		// 已取消的配送单重新派单,重置配送进度及签收码
+		v.DeliverCode = domain.GenerateRandomIntPwd(6)
+		v.AcceptTime = 0
+		v.PickupTime = 0
+		v.DeliverTime = 0
+		v.ProofImage = ""
+	} else if v.State != delivery.DispatchAssigned &&
+		v.State != delivery.DispatchRejected {
+		return nil, delivery.ErrDispatchExists
+	}
+	unix := time.Now().Unix()
+	v.StaffId = staffId
+	v.AssignMode = mode
+	v.DestLng = lng
+	v.DestLat = lat
+	v.State = delivery.DispatchAssigned
+	v.Remark = ""
+	v.AssignTime = unix
+	v.UpdateTime = unix
+	id, err := d.rep.SaveDispatchOrder(v)
+	if err != nil {
+		return nil, err
+	}
+	v.Id = id
+	return d.rep.CreateDispatchOrder(v), nil
+}
+
+// 获取配送单
+func (d *Delivery) GetDispatch(id int32) delivery.IDispatchOrder {
+	if v := d.rep.GetDispatchOrder(id); v != nil && v.MchId == d.id {
+		return d.rep.CreateDispatchOrder(v)
+	}
+	return nil
+}
+
+// 获取子订单的配送单
+func (d *Delivery) GetDispatchBySubOrder(subOrderId int64) delivery.IDispatchOrder {
+	v := d.rep.GetDispatchOrderBySubOrder(subOrderId)
+	if v != nil && v.MchId == d.id {
+		return d.rep.CreateDispatchOrder(v)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : dispatch
+ * author : jarryliu
+ * date : 2026-10-19 13:45
+ * description : 同城配送单
+ * history :
+ */
+package delivery
+
+import (
+	"go2o/core/domain/interface/delivery"
+	"go2o/core/infrastructure/lbs"
+	"time"
+)
+
+var _ delivery.IDispatchOrder = new(dispatchOrderImpl)
+
+type dispatchOrderImpl struct {
+	value *delivery.DispatchOrder
+	rep   delivery.IDeliveryRepo
+}
+
+func NewDispatchOrder(v *delivery.DispatchOrder, rep delivery.IDeliveryRepo) delivery.IDispatchOrder {
+	return &dispatchOrderImpl{
+		value: v,
+		rep:   rep,
+	}
+}
+
+// 获取聚合根编号
+func (d *dispatchOrderImpl) GetAggregateRootId() int32 {
+	return d.value.Id
+}
+
+// 获取值
+func (d *dispatchOrderImpl) GetValue() delivery.DispatchOrder {
+	return *d.value
+}
+
+// 检查配送员及配送单状态
+func (d *dispatchOrderImpl) check(staffId int32, state int) error {
+	if d.value.StaffId != staffId {
+		return delivery.ErrNotDispatchStaff
+	}
+	if d.value.State != state {
+		return delivery.ErrDispatchState
+	}
+	return nil
+}
+
+// 保存配送单,并记录配送员签到的位置
+func (d *dispatchOrderImpl) saveWithCheckIn(lng, lat float64) error {
+	unix := time.Now().Unix()
+	d.value.UpdateTime = unix
+	_, err := d.rep.SaveDispatchOrder(d.value)
+	if err == nil {
+		err = d.checkIn(lng, lat, unix)
+	}
+	return err
+}
+
+// 记录签到,同时更新配送员的位置
+func (d *dispatchOrderImpl) checkIn(lng, lat float64, unix int64) error {
+	if lng == 0 && lat == 0 {
+		return nil
+	}
+	_, err := d.rep.SaveDispatchCheckIn(&delivery.DispatchCheckIn{
+		DispatchId: d.GetAggregateRootId(),
+		StaffId:    d.value.StaffId,
+		State:      d.value.State,
+		Lng:        lng,
+		Lat:        lat,
+		CreateTime: unix,
+	})
+	if err == nil {
+		if s := d.rep.GetStaff(d.value.MchId, d.value.StaffId); s != nil {
+			s.Lng = lng
+			s.Lat = lat
+			s.LocateTime = unix
+			s.UpdateTime = unix
+			_, err = d.rep.SaveStaff(s)
+		}
+	}
+	return err
+}
+
+// 配送员接单
+func (d *dispatchOrderImpl) Accept(staffId int32, lng, lat float64) error {
+	if err := d.check(staffId, delivery.DispatchAssigned); err != nil {
+		return err
+	}
+	d.value.State = delivery.DispatchAccepted
+	d.value.AcceptTime = time.Now().Unix()
+	return d.saveWithCheckIn(lng, lat)
+}
+
+// 配送员拒单
+func (d *dispatchOrderImpl) Reject(staffId int32, remark string) error {
+	if err := d.check(staffId, delivery.DispatchAssigned); err != nil {
+		return err
+	}
+	d.value.State = delivery.DispatchRejected
+	d.value.Remark = remark
+	d.value.UpdateTime = time.Now().Unix()
+	_, err := d.rep.SaveDispatchOrder(d.value)
+	return err
+}
+
+// 取货
+func (d *dispatchOrderImpl) Pickup(staffId int32, lng, lat float64) error {
+	if err := d.check(staffId, delivery.DispatchAccepted); err != nil {
+		return err
+	}
+	d.value.State = delivery.DispatchPickedUp
+	d.value.PickupTime = time.Now().Unix()
+	return d.saveWithCheckIn(lng, lat)
+}
+
+// 送达,需提供签收照片或签收码
+func (d *dispatchOrderImpl) Deliver(staffId int32, lng, lat float64,
+	proof *delivery.DeliveryProof) error {
+	if err := d.check(staffId, delivery.DispatchPickedUp); err != nil {
+		return err
+	}
+	if proof == nil || (proof.Image == "" && proof.Code == "") {
+		return delivery.ErrNoDeliveryProof
+	}
+	if proof.Code != "" && proof.Code != d.value.DeliverCode {
+		return delivery.ErrDeliveryCode
+	}
+	// 收货地址有坐标时,校验配送员是否在收货地址附近
+	if d.value.DestLng != 0 || d.value.DestLat != 0 {
+		distance := lbs.GetLocDistance(d.value.DestLng, d.value.DestLat, lng, lat)
+		if distance > delivery.DeliverRange {
+			return delivery.ErrDispatchOutOfRange
+		}
+	}
+	d.value.State = delivery.DispatchDelivered
+	d.value.ProofImage = proof.Image
+	d.value.DeliverTime = time.Now().Unix()
+	return d.saveWithCheckIn(lng, lat)
+}
+
+// 配送途中签到(上报位置)
+func (d *dispatchOrderImpl) CheckIn(staffId int32, lng, lat float64) error {
+	if d.value.StaffId != staffId {
+		return delivery.ErrNotDispatchStaff
+	}
+	if d.value.State != delivery.DispatchAccepted &&
+		d.value.State != delivery.DispatchPickedUp {
+		return delivery.ErrDispatchState
+	}
+	return d.checkIn(lng, lat, time.Now().Unix())
+}
+
+// 取消配送
+func (d *dispatchOrderImpl) Cancel(remark string) error {
+	if d.value.State == delivery.DispatchDelivered ||
+		d.value.State == delivery.DispatchCancelled {
+		return delivery.ErrDispatchState
+	}
+	d.value.State = delivery.DispatchCancelled
+	d.value.Remark = remark
+	d.value.UpdateTime = time.Now().Unix()
+	_, err := d.rep.SaveDispatchOrder(d.value)
+	return err
+}
+
+// 获取签到记录
+func (d *dispatchOrderImpl) GetCheckIns() []*delivery.DispatchCheckIn {
+	return d.rep.GetDispatchCheckIns(d.GetAggregateRootId())
+}
@@ -0,0 +1,193 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : bus
+ * author : jarryliu
+ * date : 2026-10-20 20:20
+ * description : 事件总线,按发件箱中的编号顺序将事件发布给订阅者,
+ *               每个订阅者单独记录位置,处理失败时下次从失败的事件继续;
+ *               编号不连续时等待较小编号的事务提交,超时视为已回滚
+ * history :
+ */
+package event
+
+import (
+	"fmt"
+	"go2o/core/domain/interface/event"
+	"log"
+	"sync"
+	"time"
+)
+
+var _ event.IEventBus = new(eventBusImpl)
+
+const (
+	// 同一事件处理失败的最大次数,达到后移入死信
+	maxHandleFails = 5
+	// 编号不连续时等待事务提交的秒数,超过后视为事务已回滚
+	gapWaitSeconds = 10
+)
+
+type subscriber struct {
+	consumer string
+	types    map[string]bool
+	handler  event.Handler
+}
+
+type eventBusImpl struct {
+	rep  event.IEventRepo
+	subs []*subscriber
+	mux  sync.Mutex
+}
+
+func NewEventBus(rep event.IEventRepo) event.IEventBus {
+	return &eventBusImpl{
+		rep:  rep,
+		subs: []*subscriber{},
+	}
+}
+
+// 订阅事件,types为空时订阅所有事件,
+// 首次订阅时从最新的事件开始处理
+func (b *eventBusImpl) Subscribe(consumer string, types []string, h event.Handler) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	for _, v := range b.subs {
+		if v.consumer == consumer {
+			return event.ErrConsumerExists
+		}
+	}
+	if b.rep.GetOffset(consumer) == nil {
+		err := b.rep.SaveOffset(&event.Offset{
+			Consumer:   consumer,
+			Offset:     b.rep.GetLatestId(),
+			UpdateTime: time.Now().Unix(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	s := &subscriber{consumer: consumer, handler: h}
+	if len(types) > 0 {
+		s.types = make(map[string]bool, len(types))
+		for _, t := range types {
+			s.types[t] = true
+		}
+	}
+	b.subs = append(b.subs, s)
+	return nil
+}
+
+// 将发件箱中的事件发布给订阅者,返回发布的事件数量
+func (b *eventBusImpl) Relay(size int) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	total := 0
+	for _, s := range b.subs {
+		n, err := b.relayTo(s, size)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (b *eventBusImpl) relayTo(s *subscriber, size int) (int, error) {
+	off := b.rep.GetOffset(s.consumer)
+	if off == nil {
+		return 0, event.ErrNoSuchConsumer
+	}
+	origin := *off
+	list := b.rep.GetEvents(off.Offset, size)
+	n := 0
+	now := time.Now().Unix()
+	for _, e := range list {
+		// 自增编号按插入分配而非按提交,较小编号的事务可能尚未提交,
+		// 编号不连续时等待,超过等待时间视为事务已回滚
+		if e.Id != off.Offset+1 && now-e.CreateTime < gapWaitSeconds {
+			break
+		}
+		if s.types == nil || s.types[e.Type] {
+			if err := b.invoke(s, e); err != nil {
+				log.Println("[ Go2o][ Event][ Error]:", s.consumer, "handle event",
+					e.Id, e.Type, "failed:", err.Error())
+				if !b.handleFail(off, e, err) {
+					break
+				}
+			} else {
+				n++
+			}
+		}
+		off.Offset = e.Id
+		off.FailId, off.FailTimes = 0, 0
+	}
+	if *off != origin {
+		off.UpdateTime = now
+		return n, b.rep.SaveOffset(off)
+	}
+	return n, nil
+}
+
+// 记录处理失败,失败次数达到上限时将事件移入死信并返回true,以继续处理后续事件
+func (b *eventBusImpl) handleFail(off *event.Offset, e *event.Event, err error) bool {
+	if off.FailId != e.Id {
+		off.FailId, off.FailTimes = e.Id, 0
+	}
+	off.FailTimes++
+	if off.FailTimes < maxHandleFails {
+		return false
+	}
+	msg := []rune(err.Error())
+	if len(msg) > 255 {
+		msg = msg[:255]
+	}
+	_, err2 := b.rep.SaveDeadEvent(&event.DeadEvent{
+		Consumer:   off.Consumer,
+		EventId:    e.Id,
+		Type:       e.Type,
+		Attempts:   off.FailTimes,
+		Error:      string(msg),
+		CreateTime: time.Now().Unix(),
+	})
+	if err2 != nil {
+		log.Println("[ Go2o][ Event][ Error]: save dead event", e.Id, "failed:", err2.Error())
+		return false
+	}
+	log.Println("[ Go2o][ Event]:", off.Consumer, "event", e.Id, "moved to dead letter after",
+		off.FailTimes, "attempts")
+	return true
+}
+
+func (b *eventBusImpl) invoke(s *subscriber, e *event.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.handler(e)
+}
+
+// 从指定位置重放事件,offset为已处理的事件编号
+func (b *eventBusImpl) Replay(consumer string, offset int64) error {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	off := b.rep.GetOffset(consumer)
+	if off == nil {
+		return event.ErrNoSuchConsumer
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	off.Offset = offset
+	off.FailId, off.FailTimes = 0, 0
+	off.UpdateTime = time.Now().Unix()
+	return b.rep.SaveOffset(off)
+}
+
+// 获取订阅者的位置
+func (b *eventBusImpl) GetOffset(consumer string) int64 {
+	if off := b.rep.GetOffset(consumer); off != nil {
+		return off.Offset
+	}
+	return -1
+}
@@ -0,0 +1,187 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : audit
+ * author : jarryliu
+ * date : 2026-10-20 11:00
+ * description : 审计日志,记录管理及资金相关的操作;日志以HMAC散列串联,
+ *               密钥仅保存在服务端,修改或删除任意一条日志都可被检测到
+ * history :
+ */
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"go2o/core/infrastructure/domain"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// 系统
+	ActorSystem = "system"
+	// 平台管理员
+	ActorAdmin = "admin"
+	// 会员
+	ActorMember = "member"
+	// 商户
+	ActorMerchant = "merchant"
+	// 商户员工
+	ActorStaff = "staff"
+)
+
+const (
+	// 会员
+	TargetMember = "member"
+	// 会员账户
+	TargetMemberAccount = "member_account"
+	// 商户
+	TargetMerchant = "merchant"
+	// 商户入驻申请
+	TargetMchSignUp = "mch_signup"
+	// 订单
+	TargetOrder = "order"
+	// 支付单
+	TargetPaymentOrder = "payment_order"
+	// 风控
+	TargetRisk = "risk"
+)
+
+var (
+	ErrChainBroken *domain.DomainError = domain.NewDomainError(
+		"err_audit_chain_broken", "审计日志已被篡改")
+)
+
+// 系统操作人
+var SystemActor = &Actor{Type: ActorSystem}
+
+type (
+	// 操作人
+	Actor struct {
+		// 类型,如:admin
+		Type string
+		// 编号
+		Id int64
+		// 角色
+		Role string
+		// IP地址
+		Ip string
+	}
+
+	// 审计日志
+	Log struct {
+		// 编号
+		Id int64 `db:"id" pk:"yes" auto:"yes"`
+		// 操作人类型
+		ActorType string `db:"actor_type"`
+		// 操作人编号
+		ActorId int64 `db:"actor_id"`
+		// 操作人角色
+		ActorRole string `db:"actor_role"`
+		// 操作,如:member.lock
+		Action string `db:"action"`
+		// 目标聚合类型
+		TargetType string `db:"target_type"`
+		// 目标聚合编号
+		TargetId string `db:"target_id"`
+		// 变更内容(JSON),格式为:{"字段":[修改前,修改后]}
+		Diff string `db:"diff"`
+		// 备注
+		Remark string `db:"remark"`
+		// IP地址
+		Ip string `db:"ip"`
+		// 操作时间
+		CreateTime int64 `db:"create_time"`
+		// 上一条日志的散列值
+		PrevHash string `db:"prev_hash"`
+		// 散列值
+		Hash string `db:"hash"`
+	}
+
+	// 审计
+	IAuditor interface {
+		// 记录操作,before和after为操作前后的值(结构体或map),
+		// 仅记录有变更的字段
+		Record(actor *Actor, action string, targetType string, targetId string,
+			before interface{}, after interface{}, remark string) (*Log, error)
+		// 校验从fromId开始的size条日志,返回被篡改的日志编号及ErrChainBroken
+		Verify(fromId int64, size int) (int64, error)
+	}
+
+	IAuditRepo interface {
+		// 获取最新的日志
+		GetLatestLog() *Log
+		// 根据散列值获取日志
+		GetLogByHash(hash string) *Log
+		// 保存日志
+		SaveLog(v *Log) (int64, error)
+		// 获取从编号fromId(包含)开始的日志
+		GetLogs(fromId int64, size int) []*Log
+		// 查询目标的日志
+		QueryByTarget(targetType string, targetId string, begin, size int) (int, []*Log)
+		// 查询操作人的日志
+		QueryByActor(actorType string, actorId int64, begin, size int) (int, []*Log)
+	}
+)
+
+// 计算日志的HMAC-SHA256散列值,包含上一条日志的散列值;
+// 不知道密钥时无法重新计算整条日志链
+func (l *Log) ComputeHash(key []byte) string {
+	s := strings.Join([]string{
+		l.PrevHash,
+		l.ActorType,
+		strconv.FormatInt(l.ActorId, 10),
+		l.ActorRole,
+		l.Action,
+		l.TargetType,
+		l.TargetId,
+		l.Diff,
+		l.Remark,
+		l.Ip,
+		strconv.FormatInt(l.CreateTime, 10),
+	}, "\n")
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// 转换为map
+func toMap(v interface{}) map[string]interface{} {
+	dst := map[string]interface{}{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return dst
+	}
+	if data, err := json.Marshal(v); err == nil {
+		if json.Unmarshal(data, &dst) != nil {
+			// 非对象的值
+			dst = map[string]interface{}{"value": v}
+		}
+	}
+	return dst
+}
+
+// 比较操作前后的值,返回变更的字段及修改前后的值
+func Diff(before, after interface{}) map[string][2]interface{} {
+	b, a := toMap(before), toMap(after)
+	keys := make([]string, 0, len(b)+len(a))
+	for k := range b {
+		keys = append(keys, k)
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	diff := map[string][2]interface{}{}
+	for _, k := range keys {
+		if !reflect.DeepEqual(b[k], a[k]) {
+			diff[k] = [2]interface{}{b[k], a[k]}
+		}
+	}
+	return diff
+}
@@ -32,4 +32,32 @@ type IDelivery interface {
 
 	// 获取配送信息
 	GetDeliveryInfo(coverageId int32) (shopId, deliverUsrId int32, err error)
+
+	// 保存配送员
+	SaveStaff(v *DeliveryStaff) (int32, error)
+
+	// 获取配送员
+	GetStaff(personId int32) *DeliveryStaff
+
+	// 获取所有配送员
+	GetStaffList() []*DeliveryStaff
+
+	// 配送员上线或离线
+	SetStaffState(personId int32, state int) error
+
+	// 上报配送员位置
+	ReportLocation(personId int32, lng, lat float64) error
+
+	// 派单给配送员,lng,lat为收货地址的坐标;
+	// 配送员拒单后可重新派单
+	Dispatch(subOrderId int64, staffId int32, lng, lat float64) (IDispatchOrder, error)
+
+	// 自动派单,mode为派单方式,如:AssignRoundRobin
+	AutoDispatch(subOrderId int64, lng, lat float64, mode int) (IDispatchOrder, error)
+
+	// 获取配送单
+	GetDispatch(id int32) IDispatchOrder
+
+	// 获取子订单的配送单
+	GetDispatchBySubOrder(subOrderId int64) IDispatchOrder
 }
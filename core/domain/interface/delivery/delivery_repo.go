@@ -26,4 +26,34 @@ type IDeliveryRepo interface {
 
 	// 获取配送绑定
 	GetDeliveryBind(mchId, coverageId int32) *MerchantDeliverBind
+
+	// 保存配送员
+	SaveStaff(v *DeliveryStaff) (int32, error)
+
+	// 获取配送员
+	GetStaff(mchId, personId int32) *DeliveryStaff
+
+	// 获取商户的配送员,按人员编号排序
+	GetStaffList(mchId int32) []*DeliveryStaff
+
+	// 创建配送单
+	CreateDispatchOrder(v *DispatchOrder) IDispatchOrder
+
+	// 获取配送单
+	GetDispatchOrder(id int32) *DispatchOrder
+
+	// 获取子订单的配送单
+	GetDispatchOrderBySubOrder(subOrderId int64) *DispatchOrder
+
+	// 获取商户最近的一个配送单
+	GetLatestDispatchOrder(mchId int32) *DispatchOrder
+
+	// 保存配送单
+	SaveDispatchOrder(v *DispatchOrder) (int32, error)
+
+	// 保存配送签到记录
+	SaveDispatchCheckIn(v *DispatchCheckIn) (int32, error)
+
+	// 获取配送签到记录
+	GetDispatchCheckIns(dispatchId int32) []*DispatchCheckIn
 }
@@ -0,0 +1,182 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : dispatch
+ * author : jarryliu
+ * date : 2026-10-19 13:20
+ * description : 同城配送派单
+ * history :
+ */
+package delivery
+
+import (
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 已派单,待配送员接单
+	DispatchAssigned = 1
+	// 配送员已接单
+	DispatchAccepted = 2
+	// 已取货
+	DispatchPickedUp = 3
+	// 已送达
+	DispatchDelivered = 4
+	// 配送员拒单,等待重新派单
+	DispatchRejected = 5
+	// 已取消
+	DispatchCancelled = 6
+)
+
+const (
+	// 轮流派单
+	AssignRoundRobin = 1
+	// 派给最近的配送员
+	AssignNearest = 2
+)
+
+const (
+	// 配送员离线
+	StaffOffline = 0
+	// 配送员在线(接单中)
+	StaffOnline = 1
+)
+
+const (
+	// 配送员位置的有效时间(秒),超过后不参与就近派单
+	StaffLocationExpires int64 = 600
+	// 送达时允许与收货地址相差的距离(米)
+	DeliverRange float64 = 1000
+)
+
+var (
+	ErrNoSuchStaff *domain.DomainError = domain.NewDomainError(
+		"err_delivery_no_such_staff", "配送员不存在")
+	ErrStaffExists *domain.DomainError = domain.NewDomainError(
+		"err_delivery_staff_exists", "配送员已存在")
+	ErrStaffOffline *domain.DomainError = domain.NewDomainError(
+		"err_delivery_staff_offline", "配送员未上线")
+	ErrNoAvailableStaff *domain.DomainError = domain.NewDomainError(
+		"err_delivery_no_available_staff", "暂无可接单的配送员")
+	ErrNoSuchDispatch *domain.DomainError = domain.NewDomainError(
+		"err_delivery_no_such_dispatch", "配送单不存在")
+	ErrDispatchExists *domain.DomainError = domain.NewDomainError(
+		"err_delivery_dispatch_exists", "订单已派单")
+	ErrNotDispatchStaff *domain.DomainError = domain.NewDomainError(
+		"err_delivery_not_dispatch_staff", "非该配送单的配送员")
+	ErrDispatchState *domain.DomainError = domain.NewDomainError(
+		"err_delivery_dispatch_state", "配送单状态不正确")
+	ErrDispatchOutOfRange *domain.DomainError = domain.NewDomainError(
+		"err_delivery_dispatch_out_of_range", "当前位置距离收货地址过远")
+	ErrNoDeliveryProof *domain.DomainError = domain.NewDomainError(
+		"err_delivery_no_proof", "请上传签收照片或输入签收码")
+	ErrDeliveryCode *domain.DomainError = domain.NewDomainError(
+		"err_delivery_code", "签收码不正确")
+	ErrNotSupportAssignMode *domain.DomainError = domain.NewDomainError(
+		"err_delivery_not_support_assign_mode", "不支持的派单方式")
+)
+
+type (
+	// 配送单
+	IDispatchOrder interface {
+		// 获取聚合根编号
+		GetAggregateRootId() int32
+		// 获取值
+		GetValue() DispatchOrder
+		// 配送员接单
+		Accept(staffId int32, lng, lat float64) error
+		// 配送员拒单
+		Reject(staffId int32, remark string) error
+		// 取货
+		Pickup(staffId int32, lng, lat float64) error
+		// 送达,需提供签收照片或签收码
+		Deliver(staffId int32, lng, lat float64, proof *DeliveryProof) error
+		// 配送途中签到(上报位置)
+		CheckIn(staffId int32, lng, lat float64) error
+		// 取消配送
+		Cancel(remark string) error
+		// 获取签到记录
+		GetCheckIns() []*DispatchCheckIn
+	}
+
+	// 配送员
+	DeliveryStaff struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 商户编号
+		MchId int32 `db:"mch_id"`
+		// 人员编号
+		PersonId int32 `db:"person_id"`
+		// 状态
+		State int `db:"state"`
+		// 最后位置经度
+		Lng float64 `db:"lng"`
+		// 最后位置纬度
+		Lat float64 `db:"lat"`
+		// 最后定位时间
+		LocateTime int64 `db:"locate_time"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time"`
+	}
+
+	// 配送单
+	DispatchOrder struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 商户编号
+		MchId int32 `db:"mch_id"`
+		// 子订单编号
+		SubOrderId int64 `db:"sub_order_id"`
+		// 配送员(人员编号)
+		StaffId int32 `db:"staff_id"`
+		// 派单方式,0为手动派单
+		AssignMode int `db:"assign_mode"`
+		// 收货地址经度
+		DestLng float64 `db:"dest_lng"`
+		// 收货地址纬度
+		DestLat float64 `db:"dest_lat"`
+		// 签收码
+		DeliverCode string `db:"deliver_code"`
+		// 签收照片
+		ProofImage string `db:"proof_image"`
+		// 状态
+		State int `db:"state"`
+		// 备注,如拒单或取消原因
+		Remark string `db:"remark"`
+		// 派单时间
+		AssignTime int64 `db:"assign_time"`
+		// 接单时间
+		AcceptTime int64 `db:"accept_time"`
+		// 取货时间
+		PickupTime int64 `db:"pickup_time"`
+		// 送达时间
+		DeliverTime int64 `db:"deliver_time"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time"`
+	}
+
+	// 配送签到记录
+	DispatchCheckIn struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 配送单编号
+		DispatchId int32 `db:"dispatch_id"`
+		// 配送员
+		StaffId int32 `db:"staff_id"`
+		// 签到时配送单的状态
+		State int `db:"state"`
+		// 经度
+		Lng float64 `db:"lng"`
+		// 纬度
+		Lat float64 `db:"lat"`
+		// 签到时间
+		CreateTime int64 `db:"create_time"`
+	}
+
+	// 签收凭证,照片和签收码至少提供一项
+	DeliveryProof struct {
+		// 签收照片
+		Image string
+		// 签收码
+		Code string
+	}
+)
@@ -0,0 +1,218 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : event
+ * author : jarryliu
+ * date : 2026-10-20 20:00
+ * description : 领域事件,聚合保存时在同一事务中将事件写入发件箱(sys_event_outbox),
+ *               由中继按编号顺序发布给订阅者,订阅者可从指定位置重放;
+ *               多次处理失败的事件移入死信(sys_event_dead)
+ * history :
+ */
+package event
+
+import (
+	"encoding/json"
+	"go2o/core/domain/interface/order"
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 订单已提交
+	OrderSubmitted = "order.submitted"
+	// 订单已支付
+	OrderPaid = "order.paid"
+	// 订单已发货
+	OrderShipped = "order.shipped"
+	// 订单已完成
+	OrderCompleted = "order.completed"
+	// 订单已取消
+	OrderCancelled = "order.cancelled"
+	// 订单已退款
+	OrderRefunded = "order.refunded"
+	// 订单状态变更
+	OrderStateChanged = "order.state_changed"
+	// 会员已注册
+	MemberRegistered = "member.registered"
+	// 会员资料变更
+	MemberUpdated = "member.updated"
+	// 会员账户变更
+	AccountChanged = "account.changed"
+	// 支付单已完成
+	PaymentFinished = "payment.finished"
+	// 商品库存变更
+	ItemStockChanged = "item.stock_changed"
+)
+
+const (
+	// 订单
+	AggregateOrder = "order"
+	// 子订单
+	AggregateSubOrder = "sub_order"
+	// 会员
+	AggregateMember = "member"
+	// 会员账户
+	AggregateAccount = "account"
+	// 支付单
+	AggregatePaymentOrder = "payment_order"
+	// 商品
+	AggregateItem = "item"
+)
+
+var (
+	ErrNoSuchConsumer *domain.DomainError = domain.NewDomainError(
+		"err_event_no_such_consumer", "事件订阅者不存在")
+	ErrConsumerExists *domain.DomainError = domain.NewDomainError(
+		"err_event_consumer_exists", "已存在相同名称的订阅者")
+)
+
+type (
+	// 领域事件
+	Event struct {
+		// 编号,即事件的位置
+		Id int64 `db:"id" pk:"yes" auto:"yes"`
+		// 事件类型,如:OrderPaid
+		Type string `db:"type"`
+		// 聚合类型
+		AggregateType string `db:"aggregate_type"`
+		// 聚合编号
+		AggregateId string `db:"aggregate_id"`
+		// 事件数据(JSON)
+		Data string `db:"data"`
+		// 创建时间
+		CreateTime int64 `db:"create_time"`
+	}
+
+	// 订阅者的位置
+	Offset struct {
+		// 订阅者名称
+		Consumer string `db:"consumer" pk:"yes"`
+		// 已处理的事件编号
+		Offset int64 `db:"last_id"`
+		// 处理失败的事件编号
+		FailId int64 `db:"fail_id"`
+		// 处理失败的次数
+		FailTimes int `db:"fail_times"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time"`
+	}
+
+	// 多次处理失败的事件
+	DeadEvent struct {
+		// 编号
+		Id int64 `db:"id" pk:"yes" auto:"yes"`
+		// 订阅者名称
+		Consumer string `db:"consumer"`
+		// 事件编号
+		EventId int64 `db:"event_id"`
+		// 事件类型
+		Type string `db:"type"`
+		// 处理次数
+		Attempts int `db:"attempts"`
+		// 最后的错误
+		Error string `db:"error"`
+		// 创建时间
+		CreateTime int64 `db:"create_time"`
+	}
+
+	// 订单事件数据
+	OrderData struct {
+		// 订单号
+		OrderNo string `json:"orderNo"`
+		// 是否为子订单
+		Sub bool `json:"sub"`
+		// 买家编号
+		BuyerId int64 `json:"buyerId"`
+		// 商户编号
+		VendorId int32 `json:"vendorId"`
+		// 订单状态
+		State int32 `json:"state"`
+	}
+
+	// 会员事件数据
+	MemberData struct {
+		// 会员编号
+		MemberId int64 `json:"memberId"`
+		// 更新时间
+		UpdateTime int64 `json:"updateTime"`
+	}
+
+	// 支付单事件数据
+	PaymentData struct {
+		// 支付单编号
+		PaymentOrderId int32 `json:"paymentOrderId"`
+		// 交易号
+		TradeNo string `json:"tradeNo"`
+	}
+
+	// 商品事件数据
+	ItemData struct {
+		// 商品编号
+		ItemId int32 `json:"itemId"`
+		// 商户编号
+		VendorId int32 `json:"vendorId"`
+		// 库存
+		StockNum int32 `json:"stockNum"`
+	}
+
+	// 事件处理函数,返回错误时将停止处理,下次从该事件重新处理;
+	// 同一事件失败次数达到上限后移入死信,继续处理后续事件
+	Handler func(e *Event) error
+
+	// 事件总线
+	IEventBus interface {
+		// 订阅事件,types为空时订阅所有事件,
+		// 首次订阅时从最新的事件开始处理
+		Subscribe(consumer string, types []string, h Handler) error
+		// 将发件箱中的事件发布给订阅者,返回发布的事件数量
+		Relay(size int) (int, error)
+		// 从指定位置重放事件,offset为已处理的事件编号
+		Replay(consumer string, offset int64) error
+		// 获取订阅者的位置,订阅者不存在时返回-1
+		GetOffset(consumer string) int64
+	}
+
+	IEventRepo interface {
+		// 获取事件总线
+		GetBus() IEventBus
+		// 保存事件
+		SaveEvent(v *Event) (int64, error)
+		// 获取编号大于offset的事件
+		GetEvents(offset int64, size int) []*Event
+		// 获取最新的事件编号
+		GetLatestId() int64
+		// 查询聚合的事件
+		QueryByAggregate(aggregateType string, aggregateId string, begin, size int) (int, []*Event)
+		// 获取订阅者的位置
+		GetOffset(consumer string) *Offset
+		// 保存订阅者的位置
+		SaveOffset(v *Offset) error
+		// 保存死信
+		SaveDeadEvent(v *DeadEvent) (int64, error)
+		// 查询订阅者的死信
+		QueryDeadEvents(consumer string, begin, size int) (int, []*DeadEvent)
+	}
+)
+
+// 解析事件数据
+func (e *Event) Unmarshal(dst interface{}) error {
+	return json.Unmarshal([]byte(e.Data), dst)
+}
+
+// 根据订单状态获取事件类型
+func OrderEventType(state int32) string {
+	switch state {
+	case order.StatAwaitingPayment:
+		return OrderSubmitted
+	case order.StatAwaitingConfirm:
+		return OrderPaid
+	case order.StatShipped:
+		return OrderShipped
+	case order.StatCompleted:
+		return OrderCompleted
+	case order.StatCancelled:
+		return OrderCancelled
+	case order.StatRefunded:
+		return OrderRefunded
+	}
+	return OrderStateChanged
+}
@@ -198,10 +198,15 @@ type (
 		// 完成退款
 		FinishBackBalance(id int32, tradeNo string) error
 
-		// 申请提现,applyType：提现方式,返回info_id,交易号 及错误
-		RequestTakeOut(takeKind int32, title string, amount float32, commission float32) (int32, string, error)
+		// 检查是否可以提现,不满足提现条件时返回错误
+		CheckTakeOut(takeKind int32, amount float32) error
 
-		// 确认提现
+		// 申请提现,applyType：提现方式,mustReview为true时提现将等待审核,
+		// 返回info_id,交易号 及错误
+		RequestTakeOut(takeKind int32, title string, amount float32,
+			commission float32, mustReview bool) (int32, string, error)
+
+		// 确认提现,提现到余额的审核通过后将充值到余额
 		ConfirmTakeOut(id int32, pass bool, remark string) error
 
 		// 完成提现
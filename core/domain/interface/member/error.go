@@ -144,6 +144,15 @@ var (
 	ErrTakeOutNotTrust *domain.DomainError = domain.NewDomainError(
 		"err_account_take_out_not_trust", "必须通过实名认证后才可提现")
 
+	ErrNotAwaitingTrustReview *domain.DomainError = domain.NewDomainError(
+		"err_member_not_awaiting_trust_review", "实名认证不在待审核状态")
+
+	ErrTrustVerifyFailed *domain.DomainError = domain.NewDomainError(
+		"err_member_trust_verify_failed", "实名信息校验未通过,请核对后重新提交")
+
+	ErrTransferNotTrust *domain.DomainError = domain.NewDomainError(
+		"err_account_transfer_not_trust", "转账金额超过%s元,必须通过实名认证")
+
 	ErrIncorrectQuota *domain.DomainError = domain.NewDomainError(
 		"err_member_incorrent_quote", "数量错误")
 
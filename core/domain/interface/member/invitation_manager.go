@@ -28,4 +28,10 @@ type IInvitationManager interface {
 
 	// 获取邀请我的会员
 	GetInvitationMeMember() *Member
+
+	// 获取指定时间后注册的受邀会员数量
+	InvitationNumSince(unix int64) int
+
+	// 获取指定IP注册的受邀会员数量
+	InvitationNumByIp(ip string) int
 }
@@ -12,6 +12,8 @@ package member
 const (
 	// 默认操作用户
 	DefaultRelateUser int64 = 0
+	// 验证码有效时间(分钟)
+	CheckCodeMinutes = 10
 )
 const (
 	StateStopped = 0 //已停用
@@ -52,16 +54,16 @@ type (
 		GiftCard() IGiftCardManager
 		// 邀请管理
 		Invitation() IInvitationManager
+		// 第三方账号管理
+		Social() ISocialManager
 		// 获取值
 		GetValue() Member
 		// 设置值
 		SetValue(*Member) error
 		// 获取账户
 		GetAccount() IAccount
-		// 发送验证码,传入操作及消息类型,并返回验证码,及错误
-		SendCheckCode(operation string, mssType int) (string, error)
-		// 对比验证码
-		CompareCode(code string) error
+		// 发送验证码通知,验证码由调用方保存并校验
+		NotifyCheckCode(operation string, code string, mssType int) error
 		// 锁定会员
 		Lock() error
 		// 解锁会员
@@ -125,6 +127,12 @@ type (
 		// 修改交易密码，旧密码可为空; 传入原始密码。密码均为密文
 		ModifyTradePassword(newPwd, oldPwd string) error
 
+		// 验证密码,旧版本的密码验证通过后将重新散列保存。密码为密文
+		CheckPassword(pwd string) error
+
+		// 验证交易密码,旧版本的密码验证通过后将重新散列保存。密码为密文
+		CheckTradePassword(pwd string) error
+
 		// 获取提现银行信息
 		GetBank() BankInfo
 
@@ -143,6 +151,10 @@ type (
 		// 审核实名认证,若重复审核将返回错误
 		ReviewTrustedInfo(pass bool, remark string) error
 
+		// 根据自动认证的风险评分审核实名认证,风险较低时自动通过,
+		// 较高时自动拒绝,其他情况等待人工审核。返回审核状态
+		AutoReviewTrustedInfo(riskScore int32, factors []string) (int32, error)
+
 		// 创建配送地址
 		CreateDeliver(*Address) IDeliverAddress
 
@@ -339,6 +351,10 @@ type (
 		ReviewTime int64 `db:"review_time"`
 		//审核备注
 		Remark string `db:"remark"`
+		//风险分数,自动认证时评定
+		RiskScore int32 `db:"risk_score"`
+		//风险因素,以逗号分隔
+		RiskFactors string `db:"risk_factors"`
 		//更新时间
 		UpdateTime int64 `db:"update_time"`
 	}
@@ -143,6 +143,12 @@ type IMemberRepo interface {
 	// 获取推荐我的人
 	GetInvitationMeMember(memberId int64) *Member
 
+	// 获取指定时间后注册的受邀会员数量
+	GetInvitationNumSince(memberId int64, unix int64) int
+
+	// 获取指定IP注册的受邀会员数量
+	GetInvitationNumByIp(memberId int64, ip string) int
+
 	// 根据编号获取余额变动信息
 	GetBalanceInfo(id int32) *BalanceInfo
 
@@ -167,6 +173,18 @@ type IMemberRepo interface {
 
 	// 获取会员分页的优惠券列表
 	GetMemberPagedCoupon(memberId int64, start, end int, where string) (total int, rows []*dto.SimpleCoupon)
+	// 获取等待人工审核的实名认证,按风险分数从高到低排列
+	GetAwaitingTrustedInfos(begin, size int) (int, []*TrustedInfo)
+	// 根据平台及账号标识获取第三方账号
+	GetSocialIdentity(provider string, openId string) *SocialIdentity
+	// 根据平台及UnionID获取第三方账号
+	GetSocialIdentityByUnionId(provider string, unionId string) *SocialIdentity
+	// 获取会员绑定的第三方账号
+	GetSocialIdentities(memberId int64) []*SocialIdentity
+	// 保存第三方账号
+	SaveSocialIdentity(v *SocialIdentity) (int32, error)
+	// 删除第三方账号
+	DeleteSocialIdentity(memberId int64, provider string) error
 	// Select MmBuyerGroup
 	SelectMmBuyerGroup(where string, v ...interface{}) []*BuyerGroup
 	// Save MmBuyerGroup
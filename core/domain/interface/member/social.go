@@ -0,0 +1,74 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : social
+ * author : jarryliu
+ * date : 2026-10-20 00:10
+ * description : 第三方账号绑定,会员可绑定多个第三方账号,并使用任一账号登录
+ * history :
+ */
+package member
+
+import (
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 通过第三方账号注册的会员,注册来源的前缀,如:social:wechat
+	SocialRegFromPrefix = "social:"
+)
+
+var (
+	ErrNoSuchSocialIdentity *domain.DomainError = domain.NewDomainError(
+		"err_member_no_such_social_identity", "未绑定该账号")
+	ErrSocialIdentityBound *domain.DomainError = domain.NewDomainError(
+		"err_member_social_identity_bound", "该账号已绑定其他会员")
+	ErrSocialProviderBound *domain.DomainError = domain.NewDomainError(
+		"err_member_social_provider_bound", "已绑定同类型的账号,请先解绑")
+	ErrSocialLastIdentity *domain.DomainError = domain.NewDomainError(
+		"err_member_social_last_identity", "请先绑定手机,再解绑唯一的登录账号")
+	ErrSocialMergeSource *domain.DomainError = domain.NewDomainError(
+		"err_member_social_merge_source", "仅能合并通过第三方账号注册的会员")
+	ErrSocialMergeSelf *domain.DomainError = domain.NewDomainError(
+		"err_member_social_merge_self", "不能合并到当前会员")
+	ErrSocialMergeBalance *domain.DomainError = domain.NewDomainError(
+		"err_member_social_merge_balance", "账户仍有余额,不能合并")
+)
+
+type (
+	// 第三方账号管理
+	ISocialManager interface {
+		// 获取绑定的第三方账号
+		Identities() []*SocialIdentity
+		// 获取指定平台绑定的账号
+		GetIdentity(provider string) *SocialIdentity
+		// 绑定账号,每个平台仅能绑定一个账号
+		Bind(v *SocialIdentity) error
+		// 解绑账号
+		Unbind(provider string) error
+		// 合并到其他会员(如:已注册的手机会员),仅能合并通过第三方账号
+		// 注册且无余额的会员;合并后第三方账号绑定到目标会员,当前会员将被停用
+		MergeInto(target IMember) error
+	}
+
+	// 第三方账号
+	SocialIdentity struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 会员编号
+		MemberId int64 `db:"member_id"`
+		// 平台,如:wechat
+		Provider string `db:"provider"`
+		// 用户在应用下的唯一标识
+		OpenId string `db:"open_id"`
+		// 用户在平台下的唯一标识
+		UnionId string `db:"union_id"`
+		// 昵称
+		Nickname string `db:"nickname"`
+		// 头像
+		Avatar string `db:"avatar"`
+		// 绑定时间
+		BindTime int64 `db:"bind_time"`
+		// 最后登录时间
+		LoginTime int64 `db:"login_time"`
+	}
+)
@@ -8,6 +8,45 @@
  */
 package merchant
 
+import (
+	"go2o/core/domain/interface/event"
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 默认接口分组
+	ApiGroupDefault = "default"
+	// 会员接口
+	ApiGroupMember = "member"
+	// 商户接口
+	ApiGroupMerchant = "merchant"
+	// 资源获取接口,如:二维码
+	ApiGroupGet = "get"
+	// TCP及WebSocket命令
+	ApiGroupTcp = "tcp"
+)
+
+// 未设置限流的接口分组不限制请求,以免升级后限制已接入的商户
+const (
+	// 默认每秒请求数,0表示不限制
+	DefaultApiRate = 0
+	// 默认突发请求数
+	DefaultApiBurst = 0
+	// 默认每日请求配额,0表示不限制
+	DefaultApiDailyQuota = 0
+)
+
+var (
+	ErrApiGroup *domain.DomainError = domain.NewDomainError(
+		"err_api_group", "接口分组不存在")
+	ErrApiLimitRate *domain.DomainError = domain.NewDomainError(
+		"err_api_limit_rate", "每秒请求数不能小于0,且不能大于突发请求数")
+)
+
+// 接口分组
+var ApiGroups = []string{ApiGroupDefault, ApiGroupMember,
+	ApiGroupMerchant, ApiGroupGet, ApiGroupTcp}
+
 type (
 	// 商户接口信息
 	ApiInfo struct {
@@ -17,6 +56,10 @@ type (
 		ApiId string `db:"api_id"`
 		// 密钥
 		ApiSecret string `db:"api_secret"`
+		// 轮换前的密钥,在过期前仍可用于签名
+		OldSecret string `db:"old_secret"`
+		// 旧密钥的过期时间
+		OldSecretExpires int64 `db:"old_secret_expires"`
 		// IP白名单
 		WhiteList string `db:"white_list"`
 		// 是否启用,0:停用,1启用
@@ -36,5 +79,77 @@ type (
 
 		// 禁用API权限
 		DisableApiPerm() error
+
+		// 轮换密钥,旧密钥在overlap秒内仍然可用,返回新的密钥
+		RotateSecret(overlap int64) (string, error)
+
+		// 获取接口分组的限流配置,未设置时返回默认配置
+		GetApiLimit(group string) ApiLimit
+
+		// 获取所有接口分组的限流配置
+		GetApiLimits() []ApiLimit
+
+		// 保存接口分组的限流配置
+		SaveApiLimit(v *ApiLimit) error
+
+		// 获取推送地址
+		GetWebhooks() []*Webhook
+
+		// 获取推送地址
+		GetWebhook(id int32) *Webhook
+
+		// 保存推送地址,未设置密钥时生成新的密钥
+		SaveWebhook(v *Webhook) (int32, error)
+
+		// 删除推送地址
+		DeleteWebhook(id int32) error
+
+		// 为订阅了事件的推送地址创建推送记录,未开通接口权限时不推送
+		CreateDeliveries(e *event.Event) ([]*WebhookDelivery, error)
+
+		// 推送,推送失败且需要重试时,返回的记录状态为DeliveryRetrying
+		Deliver(deliveryId int64) (*WebhookDelivery, error)
+
+		// 重新推送,以原推送内容创建新的推送记录
+		Redeliver(deliveryId int64) (*WebhookDelivery, error)
+
+		// 查询推送记录,webhookId及state为0时不作为条件
+		QueryDeliveries(webhookId int32, state int, begin, size int) (int, []*WebhookDelivery)
+	}
+
+	// 接口限流配置,按令牌桶算法限制请求速率
+	ApiLimit struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 商户编号
+		MchId int32 `db:"mch_id"`
+		// 接口分组
+		Group string `db:"api_group"`
+		// 每秒请求数(令牌生成速率),0表示不限制
+		Rate int `db:"rate"`
+		// 突发请求数(令牌桶容量)
+		Burst int `db:"burst"`
+		// 每日请求配额,0表示不限制
+		DailyQuota int `db:"daily_quota"`
+	}
+
+	// 接口用量
+	ApiUsage struct {
+		// 接口分组
+		Group string `json:"group"`
+		// 日期,如:20171019
+		Date string `json:"date"`
+		// 请求次数
+		Count int `json:"count"`
+		// 每日请求配额
+		DailyQuota int `json:"dailyQuota"`
 	}
 )
+
+// 获取可用于校验签名的密钥
+func (a ApiInfo) ValidSecrets(unix int64) []string {
+	if a.OldSecret != "" && a.OldSecretExpires > unix {
+		return []string{a.ApiSecret, a.OldSecret}
+	}
+	return []string{a.ApiSecret}
+}
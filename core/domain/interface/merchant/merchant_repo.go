@@ -54,6 +54,31 @@ type IMerchantRepo interface {
 	// 根据API编号获取商户编号
 	GetMerchantIdByApiId(apiId string) int32
 
+	// 获取接口限流配置
+	GetApiLimits(mchId int32) []*ApiLimit
+
+	// 保存接口限流配置
+	SaveApiLimit(v *ApiLimit) (int32, error)
+
+	// 获取商户的推送地址
+	GetWebhooks(mchId int32) []*Webhook
+
+	// 保存推送地址
+	SaveWebhook(v *Webhook) (int32, error)
+
+	// 删除推送地址
+	DeleteWebhook(mchId int32, id int32) error
+
+	// 获取推送记录
+	GetWebhookDelivery(id int64) *WebhookDelivery
+
+	// 保存推送记录
+	SaveWebhookDelivery(v *WebhookDelivery) (int64, error)
+
+	// 查询推送记录,webhookId及state为0时不作为条件
+	QueryWebhookDeliveries(mchId int32, webhookId int32, state int,
+		begin, size int) (int, []*WebhookDelivery)
+
 	// 获取键值
 	GetKeyValue(mchId int32, indent string, k string) string
 
@@ -64,5 +64,8 @@ type (
 
 		// 修改密码
 		ModifyPassword(newPwd, oldPwd string) error
+
+		// 验证密码,旧版本的密码验证通过后将重新散列保存
+		CheckPassword(pwd string) error
 	}
 )
@@ -8,5 +8,8 @@
  */
 package user
 
+// 配送员
 type IDeliveryStaff interface {
+	// 获取人员信息
+	GetPerson() IPerson
 }
@@ -0,0 +1,93 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : permission
+ * author : jarryliu
+ * date : 2026-10-20 09:10
+ * description : 商户员工权限,角色包含多个权限,员工通过授予的角色获得权限,
+ *               角色可限定在指定的店铺
+ * history :
+ */
+package user
+
+import (
+	"go2o/core/infrastructure/domain"
+	"strings"
+)
+
+const (
+	// 编辑商品
+	PermItemEdit = "item.edit"
+	// 修改价格
+	PermPriceChange = "item.price"
+	// 订单发货
+	PermOrderShip = "order.ship"
+	// 审核退款
+	PermRefundApprove = "order.refund"
+	// 查看财务
+	PermFinanceView = "finance.view"
+	// 管理接口密钥
+	PermApiKey = "mch.api_key"
+	// 客服接待
+	PermCustomerService = "service.chat"
+)
+
+var (
+	// 权限列表
+	Permissions = []string{
+		PermItemEdit,
+		PermPriceChange,
+		PermOrderShip,
+		PermRefundApprove,
+		PermFinanceView,
+		PermApiKey,
+		PermCustomerService,
+	}
+
+	// 权限名称
+	PermissionNames = map[string]string{
+		PermItemEdit:        "编辑商品",
+		PermPriceChange:     "修改价格",
+		PermOrderShip:       "订单发货",
+		PermRefundApprove:   "审核退款",
+		PermFinanceView:     "查看财务",
+		PermApiKey:          "管理接口密钥",
+		PermCustomerService: "客服接待",
+	}
+)
+
+var (
+	ErrPermissionDenied *domain.DomainError = domain.NewDomainError(
+		"err_user_permission_denied", "没有操作权限")
+	ErrNoSuchPermission *domain.DomainError = domain.NewDomainError(
+		"err_user_no_such_permission", "权限不存在")
+	ErrNoSuchRole *domain.DomainError = domain.NewDomainError(
+		"err_user_no_such_role", "角色不存在")
+	ErrRoleName *domain.DomainError = domain.NewDomainError(
+		"err_user_role_name", "请填写角色名称")
+	ErrNoSuchPerson *domain.DomainError = domain.NewDomainError(
+		"err_user_no_such_person", "员工不存在")
+)
+
+// 角色授予,ShopId为0表示在商户的所有店铺拥有该角色
+type RoleGrant struct {
+	Id int32 `db:"id" pk:"yes" auto:"yes"`
+	// 人员编号
+	PersonId int32 `db:"person_id"`
+	// 角色编号
+	RoleId int32 `db:"role_id"`
+	// 店铺编号
+	ShopId int32 `db:"shop_id"`
+	// 授予时间
+	CreateTime int64 `db:"create_time"`
+}
+
+// 拆分权限,权限以逗号分隔
+func SplitPermissions(s string) []string {
+	arr := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			arr = append(arr, v)
+		}
+	}
+	return arr
+}
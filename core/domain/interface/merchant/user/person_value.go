@@ -11,10 +11,13 @@ package user
 // 人员资料
 type PersonValue struct {
 	Id       int32  `db:"id" pk:"yes" auto:"yes"`
+	MchId    int32  `db:"mch_id"`
 	Name     string `db:"name"`
 	RealName string `db:"real_name"`
 	Phone    string `db:"phone"`
 	Sex      int    `db:"sex"`
 	BirthDay int    `db:"birth_day"`
-	Enabled  int    `db:"enabled`
+	Enabled  int    `db:"enabled"`
+	// 拥有的角色位值
+	RoleFlag int `db:"role_flag"`
 }
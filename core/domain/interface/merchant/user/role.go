@@ -16,5 +16,14 @@ type IRole interface {
 
 	SetValue(*RoleValue) error
 
+	// 获取包含的权限
+	Permissions() []string
+
+	// 设置包含的权限
+	SetPermissions(perms []string) error
+
+	// 是否包含权限
+	HasPermission(perm string) bool
+
 	Save() (int32, error)
 }
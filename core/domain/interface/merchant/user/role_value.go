@@ -9,9 +9,15 @@
 package user
 
 type RoleValue struct {
-	Id   int32  `db:"id" pk:"yes" auto:"no"`
-	Name string `db:"name"`
+	Id int32 `db:"id" pk:"yes" auto:"yes"`
+	// 商户编号,为0时表示系统角色
+	MchId int32  `db:"mch_id"`
+	Name  string `db:"name"`
 	// 表示角色位值
 	Flag    int `db:"flag"`
 	Enabled int `db:"enabled"`
+	// 是否强制启用两步验证
+	RequireTwoFactor int `db:"require_2fa"`
+	// 包含的权限,以逗号分隔
+	Permissions string `db:"permissions"`
 }
@@ -8,10 +8,38 @@
  */
 package user
 
+import (
+	"go2o/core/domain/interface/security"
+	"go2o/core/infrastructure/domain"
+)
+
+var (
+	ErrCredential *domain.DomainError = domain.NewDomainError(
+		"err_user_credential", "用户名或密码不正确")
+	ErrUserDisabled *domain.DomainError = domain.NewDomainError(
+		"err_user_disabled", "用户已停用")
+)
+
 type IUser interface {
 	// 获取人员信息
 	GetPerson() IPerson
 
+	// 获取两步验证
+	TwoFactor() security.ITwoFactor
+
+	// 角色是否要求启用两步验证
+	RequireTwoFactor() bool
+
+	// 获取授予的角色
+	Grants() []*RoleGrant
+
+	// 是否拥有店铺的操作权限,shopId为0表示商户级别的操作,
+	// 仅在所有店铺拥有权限时返回true
+	HasPermission(perm string, shopId int32) bool
+
+	// 检查权限,没有权限时返回ErrPermissionDenied
+	CheckPermission(perm string, shopId int32) error
+
 	// 获取凭据
 	GetCredential(sign string) *CredentialValue
 
@@ -12,6 +12,28 @@ type IUserManager interface {
 	// 获取单个用户
 	GetUser(id int32) IUser
 
+	// 用户登录,启用两步验证时需传入验证码;角色要求两步验证
+	// 但未启用时,返回用户及security.ErrTwoFactorNotEnrolled,应引导用户绑定
+	Login(usr string, pwd string, code string) (IUser, error)
+
 	// 获取所有配送员
 	GetDeliveryStaff() []IDeliveryStaff
+
+	// 获取商户的角色
+	GetRoles() []IRole
+
+	// 获取角色
+	GetRole(id int32) IRole
+
+	// 创建角色
+	CreateRole(v *RoleValue) IRole
+
+	// 删除角色,并移除已授予的角色
+	DeleteRole(id int32) error
+
+	// 授予员工角色,shopId为0表示所有店铺
+	GrantRole(personId int32, roleId int32, shopId int32) error
+
+	// 移除员工的角色
+	RevokeRole(personId int32, roleId int32, shopId int32) error
 }
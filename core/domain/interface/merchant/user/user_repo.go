@@ -8,6 +8,10 @@
  */
 package user
 
+import (
+	"go2o/core/domain/interface/security"
+)
+
 type IUserRepo interface {
 	// 保存角色
 	SaveRole(*RoleValue) (int32, error)
@@ -23,4 +27,34 @@ type IUserRepo interface {
 
 	// 获取配送人员
 	GetDeliveryStaffPersons(mchId int32) []*PersonValue
+
+	// 获取所有角色
+	GetRoles() []*RoleValue
+
+	// 获取角色
+	GetRole(id int32) *RoleValue
+
+	// 获取商户的角色
+	GetMchRoles(mchId int32) []*RoleValue
+
+	// 删除角色
+	DeleteRole(id int32) error
+
+	// 获取人员授予的角色
+	GetRoleGrants(personId int32) []*RoleGrant
+
+	// 保存角色授予
+	SaveRoleGrant(v *RoleGrant) (int32, error)
+
+	// 删除角色授予
+	DeleteRoleGrant(personId int32, roleId int32, shopId int32) error
+
+	// 删除角色的所有授予
+	DeleteRoleGrants(roleId int32) error
+
+	// 根据用户名获取凭据
+	GetCredentialByUsr(usr string) *CredentialValue
+
+	// 获取人员的两步验证
+	GetTwoFactor(personId int32) security.ITwoFactor
 }
@@ -0,0 +1,134 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : webhook
+ * author : jarryliu
+ * date : 2026-10-20 21:40
+ * description : 商户事件推送,商户订阅事件后,事件以签名的请求推送到商户的地址,
+ *               推送失败按指数退避重试,每次推送均记录日志
+ * history :
+ */
+package merchant
+
+import (
+	"go2o/core/domain/interface/event"
+	"go2o/core/infrastructure/domain"
+	"strings"
+)
+
+const (
+	// 等待推送
+	DeliveryPending = 1
+	// 推送成功
+	DeliverySuccess = 2
+	// 推送失败,等待重试
+	DeliveryRetrying = 3
+	// 推送失败,不再重试
+	DeliveryFailed = 4
+)
+
+const (
+	// 最大推送次数
+	WebhookMaxAttempts = 8
+	// 首次重试的间隔(秒)
+	WebhookRetryBase = 60
+	// 最大重试间隔(秒)
+	WebhookRetryMax = 6 * 3600
+	// 每个商户的推送地址数量
+	WebhookMaxNum = 10
+)
+
+// 可订阅的事件
+var WebhookEvents = []string{
+	event.OrderPaid,
+	event.OrderShipped,
+	event.OrderCompleted,
+	event.OrderCancelled,
+	event.OrderRefunded,
+	event.ItemStockChanged,
+}
+
+var (
+	ErrWebhookUrl *domain.DomainError = domain.NewDomainError(
+		"err_webhook_url", "推送地址须以http://或https://开头")
+	ErrWebhookForbidden *domain.DomainError = domain.NewDomainError(
+		"err_webhook_forbidden", "推送地址不能为内网地址")
+	ErrWebhookEvent *domain.DomainError = domain.NewDomainError(
+		"err_webhook_event", "请选择要订阅的事件")
+	ErrWebhookNum *domain.DomainError = domain.NewDomainError(
+		"err_webhook_num", "推送地址数量已达上限")
+	ErrNoSuchWebhook *domain.DomainError = domain.NewDomainError(
+		"err_no_such_webhook", "推送地址不存在")
+	ErrNoSuchDelivery *domain.DomainError = domain.NewDomainError(
+		"err_no_such_delivery", "推送记录不存在")
+	ErrApiNotEnabled *domain.DomainError = domain.NewDomainError(
+		"err_api_not_enabled", "商户未开通接口权限")
+)
+
+type (
+	// 事件推送地址
+	Webhook struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 商户编号
+		MchId int32 `db:"mch_id" json:"mchId"`
+		// 推送地址
+		Url string `db:"url" json:"url"`
+		// 签名密钥,仅在创建时返回给商户
+		Secret string `db:"secret" json:"-"`
+		// 订阅的事件,以逗号分隔
+		Events string `db:"events" json:"events"`
+		// 是否启用
+		Enabled int `db:"enabled" json:"enabled"`
+		// 创建时间
+		CreateTime int64 `db:"create_time" json:"createTime"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time" json:"updateTime"`
+	}
+
+	// 推送记录
+	WebhookDelivery struct {
+		// 编号
+		Id int64 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 推送地址编号
+		WebhookId int32 `db:"webhook_id" json:"webhookId"`
+		// 商户编号
+		MchId int32 `db:"mch_id" json:"mchId"`
+		// 事件编号
+		EventId int64 `db:"event_id" json:"eventId"`
+		// 事件类型
+		EventType string `db:"event_type" json:"eventType"`
+		// 推送内容
+		Payload string `db:"payload" json:"payload"`
+		// 状态
+		State int `db:"state" json:"state"`
+		// 推送次数
+		Attempts int `db:"attempts" json:"attempts"`
+		// 响应状态码
+		ResponseCode int `db:"response_code" json:"responseCode"`
+		// 响应内容,仅供排查,不返回给商户
+		ResponseBody string `db:"response_body" json:"-"`
+		// 错误信息
+		Error string `db:"error" json:"error"`
+		// 耗时(毫秒)
+		Duration int64 `db:"duration" json:"duration"`
+		// 下次推送时间
+		NextTime int64 `db:"next_time" json:"nextTime"`
+		// 创建时间
+		CreateTime int64 `db:"create_time" json:"createTime"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time" json:"updateTime"`
+	}
+)
+
+// 是否订阅了事件
+func (w Webhook) Subscribed(eventType string) bool {
+	if w.Enabled != 1 {
+		return false
+	}
+	for _, v := range strings.Split(w.Events, ",") {
+		if v == eventType {
+			return true
+		}
+	}
+	return false
+}
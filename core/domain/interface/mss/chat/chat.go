@@ -0,0 +1,273 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : chat
+ * author : jarryliu
+ * date : 2026-10-22 09:30
+ * description : 会员与商户客服的在线会话,会话由系统分配给在线的客服;
+ *               消息保存后通过TCP/WebSocket推送,离线时可通过接口获取历史消息
+ * history :
+ */
+package chat
+
+import (
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 等待客服接入
+	SessionWaiting = 1
+	// 客服接待中
+	SessionServing = 2
+	// 已结束
+	SessionClosed = 3
+)
+
+const (
+	// 文本
+	MsgText = 1 + iota
+	// 图片
+	MsgImage
+	// 商品卡片
+	MsgProduct
+	// 订单卡片
+	MsgOrder
+	// 系统提示,如:客服已接入
+	MsgSystem
+)
+
+const (
+	// 客服离线
+	AgentOffline = 0
+	// 客服在线,可接入会话
+	AgentOnline = 1
+	// 客服忙碌,不再接入新的会话
+	AgentBusy = 2
+)
+
+const (
+	// 客服默认同时接待的会话数量
+	DefaultMaxSessions = 5
+	// 文本消息的最大长度
+	MaxTextLength = 1000
+	// 获取历史消息的最大数量
+	MaxPageSize = 50
+)
+
+var (
+	ErrNoSuchSession *domain.DomainError = domain.NewDomainError(
+		"err_chat_no_such_session", "会话不存在")
+	ErrSessionClosed *domain.DomainError = domain.NewDomainError(
+		"err_chat_session_closed", "会话已结束")
+	ErrNotSessionUser *domain.DomainError = domain.NewDomainError(
+		"err_chat_not_session_user", "无权访问该会话")
+	ErrSessionNotServing *domain.DomainError = domain.NewDomainError(
+		"err_chat_session_not_serving", "会话未接入客服")
+	ErrNotSupportMsgType *domain.DomainError = domain.NewDomainError(
+		"err_chat_not_support_msg_type", "不支持的消息类型")
+	ErrEmptyContent *domain.DomainError = domain.NewDomainError(
+		"err_chat_empty_content", "消息内容不能为空")
+	ErrContentTooLong *domain.DomainError = domain.NewDomainError(
+		"err_chat_content_too_long", "消息内容过长")
+	ErrIncorrectCard *domain.DomainError = domain.NewDomainError(
+		"err_chat_incorrect_card", "商品或订单信息不正确")
+	ErrIncorrectImage *domain.DomainError = domain.NewDomainError(
+		"err_chat_incorrect_image", "图片地址不正确")
+	ErrNoSuchAgent *domain.DomainError = domain.NewDomainError(
+		"err_chat_no_such_agent", "客服不存在")
+	ErrAgentOffline *domain.DomainError = domain.NewDomainError(
+		"err_chat_agent_offline", "客服不在线")
+	ErrAgentState *domain.DomainError = domain.NewDomainError(
+		"err_chat_agent_state", "客服状态不正确")
+	ErrAgentFull *domain.DomainError = domain.NewDomainError(
+		"err_chat_agent_full", "客服接待的会话已满")
+)
+
+type (
+	// 客服会话管理
+	IChatManager interface {
+		// 会员发起会话,已有未结束的会话时返回该会话;
+		// 新的会话将分配给在线的客服,无可用客服时等待接入
+		OpenSession(memberId int64, mchId int32) (*Session, error)
+
+		// 获取会话
+		GetSession(id int32) *Session
+
+		// 获取会员的会话
+		GetMemberSessions(memberId int64) []*Session
+
+		// 获取客服接待中的会话
+		GetAgentSessions(mchId int32, personId int32) []*Session
+
+		// 获取商户等待接入的会话
+		GetWaitingSessions(mchId int32) []*Session
+
+		// 发送消息,senderRole为mss.RoleMember或mss.RoleMerchant,
+		// 会员发送时senderId为会员编号,客服发送时为人员编号
+		Send(sessionId int32, senderRole int, senderId int64,
+			msgType int, content string) (*Message, error)
+
+		// 获取消息
+		GetMessage(id int64) *Message
+
+		// 获取会话中编号小于beforeId的消息,beforeId为0时获取最新的消息
+		GetMessages(sessionId int32, beforeId int64, size int) []*Message
+
+		// 标记已读到指定的消息(已读回执)
+		MarkRead(sessionId int32, role int, readerId int64, msgId int64) error
+
+		// 结束会话,会员或接待的客服均可结束
+		Close(sessionId int32, role int, operatorId int64) error
+
+		// 将会话转接给其他在线且未满接待数量的客服
+		Transfer(sessionId int32, fromAgent int32, toAgent int32) error
+
+		// 设置客服的状态及同时接待的会话数量,上线后接入等待中的会话,
+		// 离线时接待中的会话将转给其他客服
+		SetAgentState(mchId int32, personId int32, state int, maxSessions int) error
+
+		// 获取客服
+		GetAgent(mchId int32, personId int32) *Agent
+
+		// 获取商户的客服
+		GetAgents(mchId int32) []*Agent
+
+		// 设置客服的检查,如客服权限,未通过检查的客服不会被分配会话
+		SetAgentChecker(f func(mchId int32, personId int32) bool)
+	}
+
+	IChatRepo interface {
+		// 保存会话
+		SaveSession(v *Session) (int32, error)
+
+		// 获取会话
+		GetSession(id int32) *Session
+
+		// 获取会员与商户未结束的会话
+		GetActiveSession(memberId int64, mchId int32) *Session
+
+		// 获取会员最近的会话
+		GetMemberSessions(memberId int64) []*Session
+
+		// 获取商户指定状态的会话,agentId为0时不限客服,按编号顺序
+		GetSessions(mchId int32, agentId int32, state int) []*Session
+
+		// 获取商户各客服接待中的会话数量
+		GetAgentLoad(mchId int32) map[int32]int
+
+		// 保存消息
+		SaveMessage(v *Message) (int64, error)
+
+		// 获取消息
+		GetMessage(id int64) *Message
+
+		// 获取会话中编号小于beforeId的消息,按编号倒序
+		GetMessages(sessionId int32, beforeId int64, size int) []*Message
+
+		// 统计编号大于afterId且不是由指定角色发送的消息数量
+		CountUnread(sessionId int32, afterId int64, readerRole int) int
+
+		// 保存客服
+		SaveAgent(v *Agent) (int32, error)
+
+		// 获取客服
+		GetAgent(mchId int32, personId int32) *Agent
+
+		// 获取商户的客服,按人员编号排序
+		GetAgents(mchId int32) []*Agent
+
+		// 通知推送服务会话有更新,msgId为0表示会话状态变更
+		PushNotify(sessionId int32, msgId int64)
+	}
+
+	// 会话
+	Session struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 会员编号
+		MemberId int64 `db:"member_id" json:"memberId"`
+		// 商户编号
+		MchId int32 `db:"mch_id" json:"mchId"`
+		// 接待的客服(人员编号)
+		AgentId int32 `db:"agent_id" json:"agentId"`
+		// 状态
+		State int `db:"state" json:"state"`
+		// 最后一条消息编号
+		LastMsgId int64 `db:"last_msg_id" json:"lastMsgId"`
+		// 最后一条消息的时间
+		LastMsgTime int64 `db:"last_msg_time" json:"lastMsgTime"`
+		// 会员未读消息数量
+		MemberUnread int `db:"member_unread" json:"memberUnread"`
+		// 客服未读消息数量
+		AgentUnread int `db:"agent_unread" json:"agentUnread"`
+		// 会员已读到的消息编号
+		MemberReadId int64 `db:"member_read_id" json:"memberReadId"`
+		// 客服已读到的消息编号
+		AgentReadId int64 `db:"agent_read_id" json:"agentReadId"`
+		// 创建时间
+		CreateTime int64 `db:"create_time" json:"createTime"`
+		// 接入时间
+		AssignTime int64 `db:"assign_time" json:"assignTime"`
+		// 结束时间
+		CloseTime int64 `db:"close_time" json:"closeTime"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time" json:"updateTime"`
+	}
+
+	// 消息
+	Message struct {
+		// 编号
+		Id int64 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 会话编号
+		SessionId int32 `db:"session_id" json:"sessionId"`
+		// 发送者角色
+		SenderRole int `db:"sender_role" json:"senderRole"`
+		// 发送者编号
+		SenderId int64 `db:"sender_id" json:"senderId"`
+		// 消息类型
+		MsgType int `db:"msg_type" json:"msgType"`
+		// 内容,图片为地址,卡片为JSON格式
+		Content string `db:"content" json:"content"`
+		// 发送时间
+		CreateTime int64 `db:"create_time" json:"createTime"`
+	}
+
+	// 客服
+	Agent struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 商户编号
+		MchId int32 `db:"mch_id" json:"mchId"`
+		// 人员编号
+		PersonId int32 `db:"person_id" json:"personId"`
+		// 状态
+		State int `db:"state" json:"state"`
+		// 同时接待的会话数量
+		MaxSessions int `db:"max_sessions" json:"maxSessions"`
+		// 最后接入会话的时间
+		AssignTime int64 `db:"assign_time" json:"assignTime"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time" json:"updateTime"`
+	}
+
+	// 商品卡片
+	ProductCard struct {
+		// 商品编号
+		ItemId int64 `json:"itemId"`
+		// 商品名称
+		Title string `json:"title"`
+		// 图片
+		Image string `json:"image"`
+		// 价格
+		Price float32 `json:"price"`
+	}
+
+	// 订单卡片
+	OrderCard struct {
+		// 订单号
+		OrderNo string `json:"orderNo"`
+		// 订单金额
+		Amount float32 `json:"amount"`
+		// 订单状态
+		State string `json:"state"`
+	}
+)
@@ -0,0 +1,201 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : inbox
+ * author : jarryliu
+ * date : 2026-10-23 09:40
+ * description : 会员站内信,单独发送的消息直接写入收件箱;
+ *               广播消息只保存一份,会员读取收件箱时按目标分群同步到收件箱
+ * history :
+ */
+package inbox
+
+import (
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 广播中
+	BroadcastActive = 1
+	// 已撤回
+	BroadcastRevoked = 2
+)
+
+const (
+	// 标题的最大长度
+	MaxSubjectLength = 100
+	// 获取消息的最大数量
+	MaxPageSize = 50
+	// 每次同步的广播数量
+	SyncBatchSize = 100
+	// 标签的最大长度
+	MaxTagLength = 20
+)
+
+var (
+	ErrNoSuchBroadcast *domain.DomainError = domain.NewDomainError(
+		"err_inbox_no_such_broadcast", "广播消息不存在")
+	ErrBroadcastRevoked *domain.DomainError = domain.NewDomainError(
+		"err_inbox_broadcast_revoked", "广播消息已撤回")
+	ErrEmptySubject *domain.DomainError = domain.NewDomainError(
+		"err_inbox_empty_subject", "消息标题不能为空")
+	ErrSubjectTooLong *domain.DomainError = domain.NewDomainError(
+		"err_inbox_subject_too_long", "消息标题过长")
+	ErrEmptyContent *domain.DomainError = domain.NewDomainError(
+		"err_inbox_empty_content", "消息内容不能为空")
+	ErrExpiresTime *domain.DomainError = domain.NewDomainError(
+		"err_inbox_expires_time", "过期时间不正确")
+	ErrIncorrectSegment *domain.DomainError = domain.NewDomainError(
+		"err_inbox_incorrect_segment", "目标会员设置不正确")
+	ErrIncorrectTag *domain.DomainError = domain.NewDomainError(
+		"err_inbox_incorrect_tag", "会员标签不正确")
+	ErrNoSuchMember *domain.DomainError = domain.NewDomainError(
+		"err_inbox_no_such_member", "会员不存在")
+)
+
+type (
+	// 站内信管理
+	IInboxManager interface {
+		// 广播消息,seg为空时发送给所有会员,expiresTime为0时不过期
+		Broadcast(subject string, content string, seg *Segment,
+			expiresTime int64) (*Broadcast, error)
+		// 获取广播消息
+		GetBroadcast(id int32) *Broadcast
+		// 获取广播消息
+		GetBroadcasts(begin, size int) (total int, list []*Broadcast)
+		// 撤回广播消息,已同步到收件箱的消息将被删除
+		Revoke(id int32) error
+		// 发送消息给会员
+		Send(memberId int64, subject string, content string,
+			expiresTime int64) (*Message, error)
+		// 获取会员收件箱的消息,unreadOnly为true时只获取未读的消息
+		GetMessages(memberId int64, unreadOnly bool, begin, size int) (
+			total int, list []*Message)
+		// 获取会员的消息
+		GetMessage(memberId int64, id int64) *Message
+		// 获取未读消息数量
+		UnreadCount(memberId int64) int
+		// 标记已读,ids为空时标记全部消息
+		MarkRead(memberId int64, ids []int64) error
+		// 删除消息
+		Delete(memberId int64, ids []int64) error
+		// 清理已过期的消息,返回清理的数量
+		ClearExpired() (int, error)
+		// 设置会员的买家分组,用于定向发送
+		SetMemberGroup(memberId int64, groupId int32) error
+		// 设置会员的标签,用于定向发送
+		SetMemberTags(memberId int64, tags []string) error
+		// 获取会员的标签
+		GetMemberTags(memberId int64) []string
+	}
+
+	IInboxRepo interface {
+		// 保存广播消息
+		SaveBroadcast(v *Broadcast) (int32, error)
+		// 获取广播消息
+		GetBroadcast(id int32) *Broadcast
+		// 获取广播消息
+		GetBroadcasts(begin, size int) (total int, list []*Broadcast)
+		// 获取编号大于lastId且未过期的广播消息,包含已撤回的消息
+		GetBroadcastsAfter(lastId int32, unix int64, size int) []*Broadcast
+		// 获取会员已同步的广播消息编号
+		GetCursor(memberId int64) int32
+		// 将会员已同步的广播消息编号由from更新为to,
+		// 编号已被其他同步更新时返回false
+		ClaimCursor(memberId int64, from int32, to int32) bool
+		// 获取会员用于匹配目标分群的信息
+		GetTarget(memberId int64) *Target
+		// 保存消息
+		SaveMessage(v *Message) (int64, error)
+		// 保存广播同步到收件箱的消息,会员已有该广播的消息时忽略
+		SaveBroadcastMessage(v *Message) error
+		// 获取消息
+		GetMessage(id int64) *Message
+		// 获取会员未删除且未过期的消息
+		GetMessages(memberId int64, unreadOnly bool, unix int64,
+			begin, size int) (total int, list []*Message)
+		// 统计未读的消息数量
+		CountUnread(memberId int64, unix int64) int
+		// 标记消息已读,ids为空时标记全部
+		MarkRead(memberId int64, ids []int64, unix int64) error
+		// 删除消息,ids为空时不删除
+		DeleteMessages(memberId int64, ids []int64) error
+		// 删除广播同步到收件箱的消息
+		DeleteBroadcastMessages(broadcastId int32) error
+		// 删除已过期的消息
+		DeleteExpired(unix int64) (int, error)
+		// 保存会员的买家分组
+		SaveMemberGroup(memberId int64, groupId int32) error
+		// 保存会员的标签
+		SaveMemberTags(memberId int64, tags []string) error
+		// 获取会员的标签
+		GetMemberTags(memberId int64) []string
+	}
+
+	// 目标分群,各条件之间为且的关系,条件内的值为或的关系;条件为空时不限制
+	Segment struct {
+		// 会员等级
+		Levels []int32 `json:"levels,omitempty"`
+		// 买家分组,未设置分组的会员属于默认分组
+		Groups []int32 `json:"groups,omitempty"`
+		// 地区,匹配会员资料的省,市或区
+		Regions []int32 `json:"regions,omitempty"`
+		// 会员标签
+		Tags []string `json:"tags,omitempty"`
+	}
+
+	// 用于匹配目标分群的会员信息
+	Target struct {
+		MemberId int64
+		Level    int32
+		// 买家分组,未设置时为默认分组
+		GroupId  int32
+		Province int32
+		City     int32
+		District int32
+		Tags     []string
+		// 注册时间,注册前发送的广播不同步给会员
+		RegTime int64
+	}
+
+	// 广播消息
+	Broadcast struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 标题
+		Subject string `db:"subject" json:"subject"`
+		// 内容
+		Content string `db:"content" json:"content"`
+		// 目标分群,JSON格式,为空时发送给所有会员
+		Segment string `db:"segment" json:"segment"`
+		// 状态
+		State int `db:"state" json:"state"`
+		// 发送时间
+		CreateTime int64 `db:"create_time" json:"createTime"`
+		// 过期时间,为0时不过期
+		ExpiresTime int64 `db:"expires_time" json:"expiresTime"`
+	}
+
+	// 收件箱消息
+	Message struct {
+		// 编号
+		Id int64 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 会员编号
+		MemberId int64 `db:"member_id" json:"memberId"`
+		// 广播消息编号,为0时为单独发送的消息
+		BroadcastId int32 `db:"broadcast_id" json:"broadcastId"`
+		// 标题
+		Subject string `db:"subject" json:"subject"`
+		// 内容
+		Content string `db:"content" json:"content"`
+		// 是否已读
+		HasRead int32 `db:"has_read" json:"hasRead"`
+		// 阅读时间
+		ReadTime int64 `db:"read_time" json:"readTime"`
+		// 是否删除
+		Deleted int32 `db:"deleted" json:"-"`
+		// 发送时间
+		CreateTime int64 `db:"create_time" json:"createTime"`
+		// 过期时间,为0时不过期
+		ExpiresTime int64 `db:"expires_time" json:"expiresTime"`
+	}
+)
@@ -9,6 +9,8 @@
 package mss
 
 import (
+	"go2o/core/domain/interface/mss/chat"
+	"go2o/core/domain/interface/mss/inbox"
 	"go2o/core/domain/interface/mss/notify"
 	"go2o/core/infrastructure/domain"
 )
@@ -102,6 +104,12 @@ type (
 		// 通知服务
 		NotifyManager() notify.INotifyManager
 
+		// 客服会话
+		ChatManager() chat.IChatManager
+
+		// 站内信
+		InboxManager() inbox.IInboxManager
+
 		// 获取消息设置
 		GetConfig(userId int32) *Config
 
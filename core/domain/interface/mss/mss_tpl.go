@@ -8,6 +8,8 @@
  */
 package mss
 
+import "encoding/json"
+
 // 邮件模版
 type MailTemplate struct {
 	// 编号
@@ -30,6 +32,29 @@ type MailTemplate struct {
 	UpdateTime int64 `db:"update_time"`
 }
 
+const (
+	// 邮件最大发送次数
+	MailMaxAttempts = 6
+	// 首次重试的间隔(秒)
+	MailRetryBase = 60
+	// 最大重试间隔(秒)
+	MailRetryMax = 2 * 3600
+)
+
+// 邮件附件
+type MailAttachment struct {
+	// 文件名
+	Name string `json:"name"`
+	// 内容类型
+	ContentType string `json:"contentType,omitempty"`
+	// 文件路径
+	Path string `json:"path,omitempty"`
+	// 数据
+	Data []byte `json:"data,omitempty"`
+}
+
+// 邮件任务,is_send为0且is_failed为1时等待重试,
+// is_send及is_failed均为1时已达到最大发送次数
 type MailTask struct {
 	// 编号
 	Id int32 `db:"id" pk:"yes" auto:"yes"`
@@ -41,14 +66,46 @@ type MailTask struct {
 	SendTo string `db:"send_to"`
 	// 主题
 	Subject string `db:"subject"`
-	// 内容
+	// HTML内容
 	Body string `db:"body"`
+	// 纯文本内容
+	TextBody string `db:"text_body"`
+	// 附件,JSON格式
+	Attachments string `db:"attachments"`
 	// 是否发送(0,1)
 	IsSend int `db:"is_send"`
 	// 是否失败(0,1)
 	IsFailed int `db:"is_failed"`
+	// 发送次数
+	Attempts int `db:"attempts"`
+	// 下次发送时间
+	NextTime int64 `db:"next_time"`
+	// 错误信息
+	Error string `db:"error"`
 	// 创建时间
 	CreateTime int64 `db:"create_time"`
 	// 发送时间
-	SendTime int64 `db:"update_time`
+	SendTime int64 `db:"send_time"`
+}
+
+// 获取附件
+func (m *MailTask) GetAttachments() []*MailAttachment {
+	list := []*MailAttachment{}
+	if m.Attachments != "" {
+		json.Unmarshal([]byte(m.Attachments), &list)
+	}
+	return list
+}
+
+// 设置附件
+func (m *MailTask) SetAttachments(list []*MailAttachment) error {
+	if len(list) == 0 {
+		m.Attachments = ""
+		return nil
+	}
+	data, err := json.Marshal(list)
+	if err == nil {
+		m.Attachments = string(data)
+	}
+	return err
 }
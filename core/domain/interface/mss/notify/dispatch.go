@@ -0,0 +1,179 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : dispatch
+ * author : jarryliu
+ * date : 2026-10-20 23:10
+ * description : 通知分发,按通知项、会员偏好及免打扰时段选择渠道,
+ *               以本地化模板生成内容,渠道发送失败时依次使用下一渠道
+ * history :
+ */
+package notify
+
+import (
+	"go2o/core/infrastructure/domain"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 发送成功
+	DeliverySuccess = 1
+	// 所有渠道均发送失败
+	DeliveryFailed = 2
+	// 会员已关闭通知
+	DeliveryMuted = 3
+)
+
+var (
+	ErrNoSuchTemplate *domain.DomainError = domain.NewDomainError(
+		"err_no_such_notify_template", "通知模板不存在")
+	ErrTemplateChannel *domain.DomainError = domain.NewDomainError(
+		"err_notify_template_channel", "不支持的通知渠道")
+	ErrTemplateSyntax *domain.DomainError = domain.NewDomainError(
+		"err_notify_template_syntax", "模板语法错误")
+	ErrNoSender *domain.DomainError = domain.NewDomainError(
+		"err_notify_no_sender", "通知渠道未配置")
+	ErrNoAddress *domain.DomainError = domain.NewDomainError(
+		"err_notify_no_address", "接收人未设置该渠道的地址")
+	ErrNoChannel *domain.DomainError = domain.NewDomainError(
+		"err_notify_no_channel", "没有可用的通知渠道")
+	ErrQuietHours *domain.DomainError = domain.NewDomainError(
+		"err_notify_quiet_hours", "免打扰时段格式不正确")
+	ErrChannelReadonly *domain.DomainError = domain.NewDomainError(
+		"err_notify_channel_readonly", "该通知不允许修改接收方式")
+)
+
+type (
+	// 渠道发送器
+	ISender interface {
+		// 发送通知,key为通知项,subject及body为已生成的内容
+		Send(key string, to *Recipient, subject string, body string,
+			data map[string]interface{}) error
+	}
+
+	// 通知接收人
+	Recipient struct {
+		// 会员编号,站内信及推送使用
+		MemberId int64
+		// 手机号码
+		Phone string
+		// 电子邮箱
+		Email string
+		// 语言,为空时使用会员偏好的语言
+		Locale string
+	}
+
+	// 通知模板,内容支持{{if}}及{{range}}等语法,并兼容{tag}标签
+	Template struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 通知项
+		Key string `db:"notify_key" json:"key"`
+		// 渠道,为0时用于所有渠道
+		Channel int `db:"channel" json:"channel"`
+		// 语言,为空时为默认语言
+		Locale string `db:"locale" json:"locale"`
+		// 主题
+		Subject string `db:"subject" json:"subject"`
+		// 内容
+		Body string `db:"body" json:"body"`
+		// 是否启用
+		Enabled int `db:"enabled" json:"enabled"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time" json:"updateTime"`
+	}
+
+	// 会员的通知偏好
+	Preference struct {
+		// 会员编号
+		MemberId int64 `db:"member_id" pk:"yes" auto:"no" json:"memberId"`
+		// 语言
+		Locale string `db:"locale" json:"locale"`
+		// 免打扰开始时间,为当天的分钟数
+		QuietStart int `db:"quiet_start" json:"quietStart"`
+		// 免打扰结束时间,与开始时间相同时不启用
+		QuietEnd int `db:"quiet_end" json:"quietEnd"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time" json:"updateTime"`
+	}
+
+	// 会员对通知项设置的渠道
+	EventPref struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 会员编号
+		MemberId int64 `db:"member_id" json:"memberId"`
+		// 通知项
+		Key string `db:"notify_key" json:"key"`
+		// 按顺序使用的渠道,以逗号分隔,为空时不接收
+		Channels string `db:"channels" json:"channels"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time" json:"updateTime"`
+	}
+
+	// 通知发送记录
+	Delivery struct {
+		// 编号
+		Id int64 `db:"id" pk:"yes" auto:"yes" json:"id"`
+		// 通知项
+		Key string `db:"notify_key" json:"key"`
+		// 会员编号
+		MemberId int64 `db:"member_id" json:"memberId"`
+		// 最终发送的渠道
+		Channel int `db:"channel" json:"channel"`
+		// 语言
+		Locale string `db:"locale" json:"locale"`
+		// 主题
+		Subject string `db:"subject" json:"subject"`
+		// 内容
+		Body string `db:"body" json:"body"`
+		// 状态
+		State int `db:"state" json:"state"`
+		// 各渠道的发送结果,如:"3:err_notify_no_address;1:ok"
+		Trace string `db:"trace" json:"trace"`
+		// 创建时间
+		CreateTime int64 `db:"create_time" json:"createTime"`
+	}
+)
+
+// 是否为打扰用户的渠道,免打扰时段内不使用
+func Intrusive(channel int) bool {
+	return channel == TypePhoneMessage || channel == TypePushMessage
+}
+
+// 是否处于免打扰时段,时段可跨越零点
+func (p Preference) InQuiet(t time.Time) bool {
+	if p.QuietStart == p.QuietEnd {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if p.QuietStart < p.QuietEnd {
+		return m >= p.QuietStart && m < p.QuietEnd
+	}
+	return m >= p.QuietStart || m < p.QuietEnd
+}
+
+// 获取设置的渠道
+func (e EventPref) ChannelList() []int {
+	arr := []int{}
+	for _, v := range strings.Split(e.Channels, ",") {
+		if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && i > 0 {
+			arr = append(arr, i)
+		}
+	}
+	return arr
+}
+
+// 解析时间,如"22:30"返回当天的分钟数
+func ParseClock(s string) (int, error) {
+	arr := strings.Split(strings.TrimSpace(s), ":")
+	if len(arr) == 2 {
+		h, err1 := strconv.Atoi(arr[0])
+		m, err2 := strconv.Atoi(arr[1])
+		if err1 == nil && err2 == nil && h >= 0 && h < 24 && m >= 0 && m < 60 {
+			return h*60 + m, nil
+		}
+	}
+	return 0, ErrQuietHours
+}
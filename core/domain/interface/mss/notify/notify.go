@@ -11,14 +11,19 @@ package notify
 import (
 	"errors"
 	"go2o/core/infrastructure/domain"
+	"net/url"
 )
 
 const (
 	TypeSiteMessage = 1 + iota
 	TypeEmailMessage
 	TypePhoneMessage
+	TypePushMessage
 )
 
+// 敏感通知在发送记录中保存的内容
+const MaskedContent = "******"
+
 var (
 	ErrNoSuchNotifyItem *domain.DomainError = domain.NewDomainError(
 		"err_no_such_notify_item", "通知项不存在")
@@ -28,6 +33,7 @@ var (
 		TypeSiteMessage:  "站内信",
 		TypeEmailMessage: "邮件",
 		TypePhoneMessage: "短信",
+		TypePushMessage:  "推送",
 	}
 
 	// 类型顺序
@@ -35,6 +41,7 @@ var (
 		TypeSiteMessage,
 		TypeEmailMessage,
 		TypePhoneMessage,
+		TypePushMessage,
 	}
 
 	// 默认通知项
@@ -50,6 +57,7 @@ var (
 			Key:        "验证手机",
 			TplId:      -1,
 			ReadonlyBy: true,
+			Urgent:     true,
 			NotifyBy:   TypePhoneMessage,
 			Content:    "您好,本次{operation}验证码为{code},有效期为{minutes}分钟。",
 			Tags: map[string]string{
@@ -62,7 +70,9 @@ var (
 			Key:        "验证邮箱",
 			TplId:      -1,
 			ReadonlyBy: true,
+			Urgent:     true,
 			NotifyBy:   TypeEmailMessage,
+			Subject:    "{operation}验证码",
 			Content:    "您好,本次{operation}验证码为{code},有效期为{minutes}分钟。",
 			Tags: map[string]string{
 				"operation": "操作,如找回密码,重置手机等",
@@ -70,6 +80,40 @@ var (
 				"minutes":   "有效时间",
 			},
 		},
+		&NotifyItem{
+			Key:      "站内信",
+			TplId:    -1,
+			NotifyBy: TypePushMessage,
+			Subject:  "{subject}",
+			Content:  "{message}",
+			Tags: map[string]string{
+				"subject": "站内信主题",
+				"message": "站内信内容",
+			},
+		},
+		&NotifyItem{
+			Key:      "订单状态变更",
+			TplId:    -1,
+			NotifyBy: TypePushMessage,
+			Fallback: []int{TypeSiteMessage},
+			Subject:  "订单{state}",
+			Content:  "您的订单{orderNo}状态已更新为:{state}。",
+			Tags: map[string]string{
+				"orderNo": "订单号",
+				"state":   "订单状态",
+			},
+		},
+		&NotifyItem{
+			Key:      "账户变动",
+			TplId:    -1,
+			NotifyBy: TypePushMessage,
+			Subject:  "账户变动",
+			Content:  "您的账户已变动,当前余额为{balance}元,积分为{integral}。",
+			Tags: map[string]string{
+				"balance":  "账户余额",
+				"integral": "积分",
+			},
+		},
 	}
 )
 
@@ -115,6 +159,32 @@ type (
 		SendPhoneMessage(phone string, msg PhoneMessage, data map[string]interface{}) error
 		// 发送邮件
 		SendEmail(to string, msg *MailMessage, data map[string]interface{}) error
+		// 注册通知渠道的发送器
+		RegisterSender(channel int, s ISender)
+		// 获取通知项的模板
+		GetTemplates(key string) []*Template
+		// 保存模板
+		SaveTemplate(v *Template) (int32, error)
+		// 删除模板
+		DeleteTemplate(id int32) error
+		// 获取会员的通知偏好
+		GetPreference(memberId int64) *Preference
+		// 保存会员的通知偏好
+		SavePreference(v *Preference) error
+		// 获取会员对通知项设置的渠道
+		GetEventPrefs(memberId int64) []*EventPref
+		// 设置会员接收通知项的渠道,channels为空时不接收
+		SaveEventPref(memberId int64, key string, channels []int) error
+		// 发送通知,按会员偏好及免打扰时段选择渠道,渠道发送失败时使用下一个渠道
+		Dispatch(key string, to *Recipient, data map[string]interface{}) (*Delivery, error)
+		// 查询会员的通知发送记录
+		QueryDeliveries(memberId int64, begin, size int) (int, []*Delivery)
+		// 推送站内信到会员的设备,会员关闭推送或处于免打扰时段时不推送
+		PushSiteMessage(memberId int64, msg *SiteMessage) error
+		// 处理短信服务商的状态报告,返回更新的短信数量
+		HandleSmsReceipt(provider int, values url.Values) (int, error)
+		// 查询短信发送记录,phone为空时查询所有
+		QuerySmsMessages(phone string, begin, size int) (int, []*SmsMessage)
 	}
 
 	INotifyRepo interface {
@@ -126,6 +196,48 @@ type (
 
 		// 保存通知项
 		SaveNotifyItem(v *NotifyItem) error
+
+		// 获取通知项的模板
+		GetTemplates(key string) []*Template
+
+		// 获取模板
+		GetTemplate(id int32) *Template
+
+		// 保存模板
+		SaveTemplate(v *Template) (int32, error)
+
+		// 删除模板
+		DeleteTemplate(id int32) error
+
+		// 获取会员的通知偏好
+		GetPreference(memberId int64) *Preference
+
+		// 保存会员的通知偏好
+		SavePreference(v *Preference) error
+
+		// 获取会员对通知项设置的渠道
+		GetEventPrefs(memberId int64) []*EventPref
+
+		// 获取会员对通知项设置的渠道
+		GetEventPref(memberId int64, key string) *EventPref
+
+		// 保存会员对通知项设置的渠道
+		SaveEventPref(v *EventPref) (int32, error)
+
+		// 保存发送记录
+		SaveDelivery(v *Delivery) (int64, error)
+
+		// 查询会员的通知发送记录
+		QueryDeliveries(memberId int64, begin, size int) (int, []*Delivery)
+
+		// 保存短信发送记录
+		SaveSmsMessage(v *SmsMessage) (int64, error)
+
+		// 根据服务商的短信编号获取短信发送记录
+		GetSmsMessage(provider int, msgId string) *SmsMessage
+
+		// 查询短信发送记录
+		QuerySmsMessages(phone string, begin, size int) (int, []*SmsMessage)
 	}
 
 	// 通知项
@@ -135,11 +247,22 @@ type (
 		NotifyBy int
 		// 不允许修改发送方式
 		ReadonlyBy bool
-		TplId      int
-		Content    string
-		Tags       map[string]string
+		// 紧急通知,不受免打扰时段限制
+		Urgent bool
+		// 发送失败时依次使用的渠道
+		Fallback []int
+		TplId    int
+		// 主题,用于邮件及站内信
+		Subject string
+		Content string
+		Tags    map[string]string
 	}
 
 	// 通知项集合
 	NotifyItemSet []*NotifyItem
 )
+
+// 是否为敏感通知,如验证码;敏感通知的发送记录不保存内容
+func (n *NotifyItem) Sensitive() bool {
+	return n.ReadonlyBy || n.Urgent
+}
@@ -0,0 +1,55 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : sms
+ * author : jarryliu
+ * date : 2026-10-21 11:20
+ * description : 短信发送记录,服务商回执后更新送达状态
+ * history :
+ */
+package notify
+
+import (
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 已提交到服务商
+	SmsSubmitted = 1
+	// 已送达
+	SmsDelivered = 2
+	// 所有服务商均发送失败
+	SmsFailed = 3
+	// 服务商回执未送达
+	SmsUndelivered = 4
+)
+
+var (
+	ErrNoSuchSmsProvider *domain.DomainError = domain.NewDomainError(
+		"err_no_such_sms_provider", "短信服务商不存在")
+)
+
+// 短信发送记录
+type SmsMessage struct {
+	// 编号
+	Id int64 `db:"id" pk:"yes" auto:"yes" json:"id"`
+	// 通知项
+	Key string `db:"notify_key" json:"key"`
+	// 手机号码
+	Phone string `db:"phone" json:"phone"`
+	// 最终发送的服务商
+	Provider int `db:"provider" json:"provider"`
+	// 服务商的短信编号
+	MsgId string `db:"msg_id" json:"msgId"`
+	// 内容
+	Content string `db:"content" json:"content"`
+	// 状态
+	State int `db:"state" json:"state"`
+	// 服务商回执的状态
+	ReportStatus string `db:"report_status" json:"reportStatus"`
+	// 各服务商的发送结果,如:"2:err;3:ok"
+	Trace string `db:"trace" json:"trace"`
+	// 创建时间
+	CreateTime int64 `db:"create_time" json:"createTime"`
+	// 更新时间
+	UpdateTime int64 `db:"update_time" json:"updateTime"`
+}
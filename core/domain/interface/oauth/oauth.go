@@ -0,0 +1,217 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : oauth
+ * author : jarryliu
+ * date : 2026-10-19 21:30
+ * description : OAuth2授权服务,第三方应用(客户端)通过授权码模式获取会员授权
+ * history :
+ */
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"go2o/core/infrastructure/domain"
+	"strings"
+)
+
+const (
+	// OpenID Connect,返回身份令牌
+	ScopeOpenId = "openid"
+	// 会员资料
+	ScopeProfile = "profile"
+	// 会员账户
+	ScopeAccount = "account"
+	// 会员订单
+	ScopeOrders = "orders"
+	// 离线访问,返回刷新令牌
+	ScopeOffline = "offline_access"
+)
+
+const (
+	// PKCE: 使用SHA256
+	PkceS256 = "S256"
+	// PKCE: 明文,仅非公开客户端可用
+	PkcePlain = "plain"
+)
+
+const (
+	// 停用
+	ClientDisabled = 0
+	// 启用
+	ClientEnabled = 1
+)
+
+var (
+	// 支持的授权范围
+	Scopes = []string{ScopeOpenId, ScopeProfile, ScopeAccount,
+		ScopeOrders, ScopeOffline}
+
+	// 授权范围说明,用于授权确认
+	ScopeNames = map[string]string{
+		ScopeOpenId:  "获取您的身份标识",
+		ScopeProfile: "获取您的昵称、头像等资料",
+		ScopeAccount: "获取您的账户余额及积分",
+		ScopeOrders:  "获取您的订单",
+		ScopeOffline: "在您离线时保持访问",
+	}
+)
+
+var (
+	ErrNoSuchClient *domain.DomainError = domain.NewDomainError(
+		"err_oauth_no_such_client", "应用不存在")
+	ErrClientDisabled *domain.DomainError = domain.NewDomainError(
+		"err_oauth_client_disabled", "应用已停用")
+	ErrClientName *domain.DomainError = domain.NewDomainError(
+		"err_oauth_client_name", "应用名称不能为空")
+	ErrRedirectUri *domain.DomainError = domain.NewDomainError(
+		"err_oauth_redirect_uri", "回调地址不正确")
+	ErrClientSecret *domain.DomainError = domain.NewDomainError(
+		"err_oauth_client_secret", "应用密钥不正确")
+	ErrScope *domain.DomainError = domain.NewDomainError(
+		"err_oauth_scope", "不支持的授权范围")
+	ErrPkceRequired *domain.DomainError = domain.NewDomainError(
+		"err_oauth_pkce_required", "公开应用必须使用PKCE(S256)")
+	ErrCodeVerifier *domain.DomainError = domain.NewDomainError(
+		"err_oauth_code_verifier", "PKCE校验失败")
+	ErrInsufficientScope *domain.DomainError = domain.NewDomainError(
+		"err_oauth_insufficient_scope", "未授权访问该数据")
+)
+
+type (
+	// 第三方应用
+	IClient interface {
+		// 获取聚合根编号
+		GetAggregateRootId() int32
+		// 获取值
+		GetValue() Client
+		// 设置值,应用编号及密钥不能修改
+		SetValue(v *Client) error
+		// 是否启用
+		Enabled() bool
+		// 重置密钥,返回新的密钥;密钥仅保存散列值,只能在此时获取
+		ResetSecret() (string, error)
+		// 校验密钥
+		VerifySecret(secret string) bool
+		// 校验回调地址,须与登记的地址完全一致
+		CheckRedirectUri(uri string) error
+		// 校验授权范围,返回去重后的范围
+		CheckScope(scope string) (string, error)
+		// 校验PKCE参数,公开应用必须使用S256
+		CheckPkce(challenge string, method string) error
+		// 保存
+		Save() (int32, error)
+	}
+
+	IOAuthRepo interface {
+		// 创建应用
+		CreateClient(v *Client) IClient
+		// 获取应用
+		GetClient(clientId string) IClient
+		// 获取商户的应用,mchId为0时返回平台的应用
+		GetClients(mchId int32) []*Client
+		// 保存应用
+		SaveClient(v *Client) (int32, error)
+		// 获取会员对应用的授权
+		GetConsent(memberId int64, clientId string) *Consent
+		// 获取会员授权的所有应用
+		GetConsents(memberId int64) []*Consent
+		// 保存授权
+		SaveConsent(v *Consent) (int32, error)
+		// 删除授权
+		DeleteConsent(memberId int64, clientId string) error
+	}
+
+	// 第三方应用(客户端)
+	Client struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 应用编号(client_id)
+		ClientId string `db:"client_id"`
+		// 应用密钥(散列后)
+		Secret string `db:"client_secret"`
+		// 应用名称
+		Name string `db:"name"`
+		// 图标
+		Logo string `db:"logo"`
+		// 商户编号,平台应用为0
+		MchId int32 `db:"mch_id"`
+		// 回调地址,多个以换行分隔
+		RedirectUris string `db:"redirect_uris"`
+		// 允许的授权范围,以空格分隔
+		Scopes string `db:"scopes"`
+		// 是否为公开应用(如:手机应用,单页应用),不能保存密钥,须使用PKCE
+		Public int `db:"public"`
+		// 是否为受信任的应用,无需会员确认授权
+		Trusted int `db:"trusted"`
+		// 状态
+		State int `db:"state"`
+		// 创建时间
+		CreateTime int64 `db:"create_time"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time"`
+	}
+
+	// 会员授权
+	Consent struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 会员编号
+		MemberId int64 `db:"member_id"`
+		// 应用编号
+		ClientId string `db:"client_id"`
+		// 已授权的范围,以空格分隔
+		Scope string `db:"scope"`
+		// 创建时间
+		CreateTime int64 `db:"create_time"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time"`
+	}
+)
+
+// 是否包含授权范围
+func HasScope(scope string, s string) bool {
+	for _, v := range strings.Fields(scope) {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// 已授权的范围是否包含请求的所有范围
+func ScopeCovered(granted string, requested string) bool {
+	for _, v := range strings.Fields(requested) {
+		if !HasScope(granted, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// 合并授权范围
+func MergeScope(a string, b string) string {
+	arr := strings.Fields(a)
+	for _, v := range strings.Fields(b) {
+		if !HasScope(a, v) {
+			arr = append(arr, v)
+		}
+	}
+	return strings.Join(arr, " ")
+}
+
+// 校验PKCE(RFC 7636)的code_verifier
+func VerifyCodeChallenge(challenge string, method string, verifier string) bool {
+	if challenge == "" || len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	v := verifier
+	if method == PkceS256 {
+		sum := sha256.Sum256([]byte(verifier))
+		v = base64.RawURLEncoding.EncodeToString(sum[:])
+	} else if method != PkcePlain && method != "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(v), []byte(challenge)) == 1
+}
@@ -148,6 +148,9 @@ var (
 	ErrOrderPayed *domain.DomainError = domain.NewDomainError(
 		"err_order_payed ", "订单已支付")
 
+	ErrPayByManager *domain.DomainError = domain.NewDomainError(
+		"err_order_pay_by_manager", "应使用支付单进行人工付款")
+
 	ErrNoYetCreated *domain.DomainError = domain.NewDomainError(
 		"err_order_not_yet_created ", "订单尚未生成")
 
@@ -302,6 +305,8 @@ type (
 		PickUp() error
 		// 发货
 		Ship(spId int32, spOrder string) error
+		// 同城配送送达,由商户的配送员配送,不生成快递发货单
+		LocalDelivered() error
 		// 已收货
 		BuyerReceived() error
 		// 获取订单的日志
@@ -0,0 +1,269 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : risk
+ * author : jarryliu
+ * date : 2026-10-20 15:10
+ * description : 风险控制,对提现、转账及注册按规则评估,
+ *               结果为通过、人工审核或拒绝,评估结果均被记录;
+ *               需人工审核的转账进入审核队列,审核通过后执行
+ * history :
+ */
+package risk
+
+import (
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 提现
+	OpTakeOut = "take_out"
+	// 转账
+	OpTransfer = "transfer"
+	// 注册
+	OpRegister = "register"
+)
+
+const (
+	// 通过
+	DecisionAllow = 1
+	// 人工审核
+	DecisionReview = 2
+	// 拒绝
+	DecisionDeny = 3
+)
+
+const (
+	// 银行卡
+	ListBankCard = "bank_card"
+	// 手机号码
+	ListPhone = "phone"
+	// IP地址
+	ListIp = "ip"
+	// 设备
+	ListDevice = "device"
+)
+
+const (
+	// 按会员统计
+	FieldMember = "member_id"
+	// 按IP统计
+	FieldIp = "ip"
+	// 按设备统计
+	FieldDevice = "device_id"
+)
+
+var (
+	// 决策文本
+	DecisionText = map[int]string{
+		DecisionAllow:  "通过",
+		DecisionReview: "人工审核",
+		DecisionDeny:   "拒绝",
+	}
+
+	// 名单类型
+	ListKinds = []string{ListBankCard, ListPhone, ListIp, ListDevice}
+)
+
+var (
+	ErrRiskDenied *domain.DomainError = domain.NewDomainError(
+		"err_risk_denied", "操作存在风险,已被拒绝")
+	ErrRiskReview *domain.DomainError = domain.NewDomainError(
+		"err_risk_review", "操作存在风险,请联系客服处理")
+	ErrListKind *domain.DomainError = domain.NewDomainError(
+		"err_risk_list_kind", "名单类型不正确")
+	ErrListValue *domain.DomainError = domain.NewDomainError(
+		"err_risk_list_value", "请填写名单内容")
+	ErrNoSuchRecord *domain.DomainError = domain.NewDomainError(
+		"err_risk_no_such_record", "风控记录不存在")
+	ErrReviewSubmitted *domain.DomainError = domain.NewDomainError(
+		"err_risk_review_submitted", "操作已提交审核,审核通过后将自动完成")
+	ErrNoSuchReview *domain.DomainError = domain.NewDomainError(
+		"err_risk_no_such_review", "审核不存在")
+	ErrReviewed *domain.DomainError = domain.NewDomainError(
+		"err_risk_reviewed", "已审核,不能重复审核")
+)
+
+type (
+	// 风控设置
+	Settings struct {
+		// 统计频率的时间窗口(秒)
+		VelocityWindow int64
+		// 时间窗口内每个会员的操作次数,超过将转人工审核,超过两倍将拒绝
+		MemberVelocity int
+		// 时间窗口内每个IP的操作次数
+		IpVelocity int
+		// 时间窗口内每个设备的操作次数
+		DeviceVelocity int
+		// 新会员的冷静期(秒),冷静期内提现及转账将转人工审核
+		CoolingPeriod int64
+		// 冷静期内不超过该金额的操作不受限制
+		CoolingAmount float32
+		// 统计邀请的时间窗口(秒)
+		InviteWindow int64
+		// 时间窗口内邀请的会员数量,超过将转人工审核
+		InviteLimit int
+		// 同一IP注册的受邀会员数量,超过将拒绝
+		SameIpInviteLimit int
+	}
+
+	// 客户端信息
+	Client struct {
+		// IP地址
+		Ip string
+		// 设备编号
+		DeviceId string
+	}
+
+	// 风控评估的操作
+	Context struct {
+		Client
+		// 操作,如:OpTakeOut
+		Operation string
+		// 会员编号,注册时为0
+		MemberId int64
+		// 金额
+		Amount float32
+		// 手机号码
+		Phone string
+		// 银行卡号
+		BankCard string
+		// 邀请人编号
+		InviterId int64
+	}
+
+	// 规则命中
+	Hit struct {
+		// 规则名称
+		Rule string
+		// 决策
+		Decision int
+		// 说明
+		Message string
+	}
+
+	// 风控规则
+	IRule interface {
+		// 规则名称
+		Name() string
+		// 评估操作,未命中返回nil
+		Evaluate(c *Context, s *Settings) *Hit
+	}
+
+	// 风控记录
+	Record struct {
+		// 编号
+		Id int64 `db:"id" pk:"yes" auto:"yes"`
+		// 操作
+		Operation string `db:"operation"`
+		// 会员编号
+		MemberId int64 `db:"member_id"`
+		// 金额
+		Amount float32 `db:"amount"`
+		// IP地址
+		Ip string `db:"ip"`
+		// 设备编号
+		DeviceId string `db:"device_id"`
+		// 决策
+		Decision int `db:"decision"`
+		// 命中的规则,以逗号分隔
+		Rules string `db:"rules"`
+		// 说明
+		Message string `db:"message"`
+		// 关联的业务编号,如:提现编号
+		RefId string `db:"ref_id"`
+		// 创建时间
+		CreateTime int64 `db:"create_time"`
+	}
+
+	// 人工审核的操作
+	Review struct {
+		// 编号
+		Id int64 `db:"id" pk:"yes" auto:"yes"`
+		// 风控记录编号
+		RecordId int64 `db:"record_id"`
+		// 操作
+		Operation string `db:"operation"`
+		// 会员编号
+		MemberId int64 `db:"member_id"`
+		// 金额
+		Amount float32 `db:"amount"`
+		// 审核通过后执行操作的参数(JSON)
+		Data string `db:"data"`
+		// 审核状态,如:enum.ReviewAwaiting
+		State int32 `db:"state"`
+		// 审核备注
+		Remark string `db:"remark"`
+		// 创建时间
+		CreateTime int64 `db:"create_time"`
+		// 审核时间
+		ReviewTime int64 `db:"review_time"`
+	}
+
+	// 黑名单
+	BlackItem struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 类型
+		Kind string `db:"kind"`
+		// 内容
+		Value string `db:"value"`
+		// 备注
+		Remark string `db:"remark"`
+		// 创建时间
+		CreateTime int64 `db:"create_time"`
+	}
+
+	// 风控管理
+	IRiskManager interface {
+		// 获取设置
+		GetSettings() Settings
+		// 保存设置
+		SaveSettings(v *Settings) error
+		// 评估操作并记录
+		Evaluate(c *Context) (*Record, error)
+		// 关联业务编号
+		BindRecord(id int64, refId string) error
+		// 将需人工审核的操作加入审核队列,data为审核通过后执行操作的参数
+		SubmitReview(r *Record, data string) (int64, error)
+		// 审核操作,仅等待审核的操作可审核,返回审核的操作;
+		// 多人同时审核时,仅一人可审核成功
+		Review(id int64, pass bool, remark string) (*Review, error)
+		// 加入黑名单
+		AddBlackItem(kind string, value string, remark string) error
+		// 移出黑名单
+		RemoveBlackItem(kind string, value string) error
+		// 是否在黑名单中
+		IsBlacklisted(kind string, value string) bool
+	}
+
+	IRiskRepo interface {
+		// 获取风控管理
+		GetManager() IRiskManager
+		// 获取设置
+		GetSettings() Settings
+		// 保存设置
+		SaveSettings(v *Settings) error
+		// 获取记录
+		GetRecord(id int64) *Record
+		// 保存记录
+		SaveRecord(v *Record) (int64, error)
+		// 统计指定时间后的操作次数,field为统计的字段,如:FieldIp
+		CountRecords(operation string, field string, value interface{}, since int64) int
+		// 查询记录,decision为0时查询所有
+		QueryRecords(decision int, begin, size int) (int, []*Record)
+		// 获取审核
+		GetReview(id int64) *Review
+		// 保存审核
+		SaveReview(v *Review) (int64, error)
+		// 更新等待审核的操作的状态,返回是否更新
+		UpdateReviewState(id int64, state int32, remark string, reviewTime int64) (bool, error)
+		// 查询审核,state为0时查询所有
+		QueryReviews(state int32, begin, size int) (int, []*Review)
+		// 获取黑名单
+		GetBlackItem(kind string, value string) *BlackItem
+		// 保存黑名单
+		SaveBlackItem(v *BlackItem) (int32, error)
+		// 删除黑名单
+		DeleteBlackItem(kind string, value string) error
+	}
+)
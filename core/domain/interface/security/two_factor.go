@@ -0,0 +1,118 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : two_factor
+ * author : jarryliu
+ * date : 2026-10-19 19:30
+ * description : 两步验证(TOTP),用于会员及商户员工
+ * history :
+ */
+package security
+
+import (
+	"go2o/core/infrastructure/domain"
+)
+
+const (
+	// 会员
+	UserMember = 1
+	// 商户员工
+	UserMerchantStaff = 2
+)
+
+const (
+	// 未启用
+	TwoFactorDisabled = 0
+	// 已生成密钥,等待验证后启用
+	TwoFactorPending = 1
+	// 已启用
+	TwoFactorEnabled = 2
+)
+
+const (
+	// 恢复码数量
+	RecoveryCodeNum = 10
+	// 验证码允许前后偏差的步数
+	TwoFactorSkew = 1
+	// 连续验证失败的最大次数,超过后锁定
+	TwoFactorMaxFails = 5
+	// 验证失败过多时锁定的时间(秒)
+	TwoFactorLockSeconds int64 = 900
+)
+
+var (
+	ErrTwoFactorNotEnabled *domain.DomainError = domain.NewDomainError(
+		"err_two_factor_not_enabled", "未启用两步验证")
+	ErrTwoFactorEnabled *domain.DomainError = domain.NewDomainError(
+		"err_two_factor_enabled", "已启用两步验证")
+	ErrTwoFactorNotEnrolled *domain.DomainError = domain.NewDomainError(
+		"err_two_factor_not_enrolled", "请先绑定两步验证")
+	ErrTwoFactorCode *domain.DomainError = domain.NewDomainError(
+		"err_two_factor_code", "验证码不正确或已使用")
+	ErrTwoFactorRequired *domain.DomainError = domain.NewDomainError(
+		"err_two_factor_required", "请输入两步验证的验证码")
+	ErrTwoFactorLocked *domain.DomainError = domain.NewDomainError(
+		"err_two_factor_locked", "验证失败次数过多,请15分钟后再试")
+)
+
+type (
+	// 两步验证
+	ITwoFactor interface {
+		// 获取聚合根编号
+		GetAggregateRootId() int32
+		// 获取值
+		GetValue() TwoFactor
+		// 是否已启用
+		Enabled() bool
+		// 生成密钥并返回验证器应用绑定的地址,验证后启用
+		Enroll(issuer string, account string) (string, error)
+		// 生成绑定地址的二维码(PNG)
+		QrCode(issuer string, account string) ([]byte, error)
+		// 使用验证码启用,并返回恢复码
+		Activate(code string) ([]string, error)
+		// 校验验证码或恢复码,恢复码仅能使用一次
+		Verify(code string) error
+		// 敏感操作时校验验证码或恢复码,每次操作均需验证;未启用时不需要验证
+		CheckCode(code string) error
+		// 重新生成恢复码
+		ResetRecoveryCodes(code string) ([]string, error)
+		// 停用
+		Disable(code string) error
+	}
+
+	ITwoFactorRepo interface {
+		// 创建两步验证,如已存在则返回已有的
+		CreateTwoFactor(userType int, userId int64) ITwoFactor
+		// 获取两步验证
+		GetTwoFactor(userType int, userId int64) *TwoFactor
+		// 保存两步验证
+		SaveTwoFactor(v *TwoFactor) (int32, error)
+	}
+
+	// 两步验证
+	TwoFactor struct {
+		// 编号
+		Id int32 `db:"id" pk:"yes" auto:"yes"`
+		// 用户类型
+		UserType int `db:"user_type"`
+		// 用户编号
+		UserId int64 `db:"user_id"`
+		// 密钥
+		Secret string `db:"secret"`
+		// 状态
+		State int `db:"state"`
+		// 最后使用的验证码步数,用于防止重复使用
+		LastStep int64 `db:"last_step"`
+		// 恢复码(散列后),以逗号分隔
+		RecoveryCodes string `db:"recovery_codes"`
+		// 最后验证时间
+		VerifyTime int64 `db:"verify_time"`
+		// 连续验证失败的次数
+		FailTimes int `db:"fail_times"`
+		// 锁定到期时间
+		LockTime int64 `db:"lock_time"`
+		// 创建时间
+		CreateTime int64 `db:"create_time"`
+		// 更新时间
+		UpdateTime int64 `db:"update_time"`
+	}
+)
@@ -53,6 +53,8 @@ type (
 		GetShipmentOrder(id int64) IShipmentOrder
 		// 获取订单对应的发货单
 		GetShipOrders(orderId int64) []IShipmentOrder
+		// 获取子订单对应的发货单
+		GetSubOrderShipOrders(subOrderId int64) []IShipmentOrder
 		// 保存发货单
 		SaveShipmentOrder(o *ShipmentOrder) (int, error)
 		// 保存发货商品项
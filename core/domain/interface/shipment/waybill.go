@@ -0,0 +1,99 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : waybill
+ * author : jarryliu
+ * date : 2026-10-19 10:30
+ * description : 电子面单
+ * history :
+ */
+package shipment
+
+import (
+	"go2o/core/infrastructure/domain"
+	"go2o/core/infrastructure/printer"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PDF面单
+	WaybillFormatPdf = printer.FormatPdf
+	// 斑马打印机面单(ZPL)
+	WaybillFormatZpl = printer.FormatZpl
+)
+
+var (
+	ErrNotSupportWaybillFormat *domain.DomainError = domain.NewDomainError(
+		"err_shipment_not_support_waybill_format", "不支持的面单格式")
+	ErrWaybillNotShipped *domain.DomainError = domain.NewDomainError(
+		"err_shipment_waybill_not_shipped", "订单未发货,无法打印面单")
+	ErrWaybillNoSender *domain.DomainError = domain.NewDomainError(
+		"err_shipment_waybill_no_sender", "商户未设置门店,无法获取寄件人信息")
+	ErrWaybillNoOrders *domain.DomainError = domain.NewDomainError(
+		"err_shipment_waybill_no_orders", "请选择需要打印面单的订单")
+)
+
+type (
+	// 面单模板,模板只负责排版,输出格式由画布决定
+	IWaybillTemplate interface {
+		// 面单尺寸(毫米)
+		Size() (width float64, height float64)
+		// 在画布的当前页上绘制面单
+		Draw(c printer.ICanvas, w *Waybill) error
+	}
+
+	// 电子面单
+	Waybill struct {
+		// 发货单编号
+		ShipOrderId int64
+		// 订单号
+		OrderNo string
+		// 快递公司编号
+		SpId int32
+		// 快递公司名称
+		SpName string
+		// 快递公司编码
+		SpCode string
+		// 快递单号
+		SpOrder string
+		// 寄件人
+		Sender *WaybillContact
+		// 收件人
+		Receiver *WaybillContact
+		// 商品项
+		Items []*WaybillItem
+		// 买家备注
+		Remark string
+		// 生成时间
+		CreateTime int64
+	}
+
+	// 面单联系人
+	WaybillContact struct {
+		// 姓名或门店名称
+		Name string
+		// 电话
+		Phone string
+		// 地区(省市区)
+		Area string
+		// 详细地址
+		Address string
+	}
+
+	// 面单商品项
+	WaybillItem struct {
+		// 商品标题
+		Title string
+		// 数量
+		Quantity int32
+	}
+)
+
+// 商品汇总,如:"商品A x1; 商品B x2"
+func (w Waybill) ItemSummary() string {
+	arr := make([]string, len(w.Items))
+	for i, v := range w.Items {
+		arr[i] = v.Title + " x" + strconv.Itoa(int(v.Quantity))
+	}
+	return strings.Join(arr, "; ")
+}
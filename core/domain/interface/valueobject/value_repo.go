@@ -21,15 +21,18 @@ var (
 	SmsHttp   = 1
 	SmsAli    = 2 //阿里大鱼
 	Sms253Com = 3 //创蓝
+	SmsFake   = 9 //本地短信,仅记录短信,用于开发及测试
 	SmsIndex  = []int{
 		SmsAli,
 		Sms253Com,
 		SmsHttp,
+		SmsFake,
 	}
 	SmsTextMap = map[int]string{
 		SmsHttp:   "HTTP短信",
 		SmsAli:    "阿里大鱼",
 		Sms253Com: "创蓝",
+		SmsFake:   "本地短信",
 	}
 )
 
@@ -40,6 +43,12 @@ const (
 	RKMemberTransferAccountsOn = "MemberTransferAccountsOn"
 	// 会员转账提示信息
 	RKMemberTransferAccountsMessage = "MemberTransferAccountsMessage"
+	// 提现及转账超过该金额时必须实名认证,小于0表示不限制
+	RKMemberTrustRequiredAmount = "MemberTrustRequiredAmount"
+	// 实名认证风险分数低于该值时自动通过
+	RKKycAutoPassRisk = "KycAutoPassRisk"
+	// 实名认证风险分数不低于该值时自动拒绝
+	RKKycAutoRejectRisk = "KycAutoRejectRisk"
 )
 
 type (
@@ -372,6 +381,14 @@ type (
 		SuccessChar string
 		//是否默认的接口使用
 		Default bool
+		//故障转移的顺序,默认接口始终优先,为0时不参与故障转移
+		Priority int
+		//短信签名
+		Signature string
+		//通知项对应的服务商模板编号
+		Templates map[string]string
+		//回执通知的令牌,回执地址须带上token参数;未设置时不接收回执
+		ReceiptToken string
 	}
 
 	// 短信接口设置
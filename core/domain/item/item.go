@@ -594,3 +594,37 @@ func (g *itemImpl) Destroy() error {
 	//g.goodsRepo.
 	return nil
 }
+
+// 商品或SKU的价格是否与已保存的不同,origin为nil时为新建商品;
+// SKU按编号匹配,未保存编号时按规格数据匹配,新增且设置了价格的SKU视为改价
+func PriceChanged(origin *item.GoodsItem, originSku []*item.Sku,
+	v *item.GoodsItem) bool {
+	if origin == nil {
+		origin = &item.GoodsItem{}
+	}
+	if v.Price != origin.Price || v.RetailPrice != origin.RetailPrice {
+		return true
+	}
+	idMap := make(map[int32]*item.Sku, len(originSku))
+	specMap := make(map[string]*item.Sku, len(originSku))
+	for _, s := range originSku {
+		idMap[s.ID] = s
+		specMap[s.SpecData] = s
+	}
+	for _, s := range v.SkuArray {
+		o := idMap[s.ID]
+		if s.ID <= 0 || o == nil {
+			o = specMap[s.SpecData]
+		}
+		if o == nil {
+			if s.Price != 0 || s.RetailPrice != 0 {
+				return true
+			}
+			continue
+		}
+		if s.Price != o.Price || s.RetailPrice != o.RetailPrice {
+			return true
+		}
+	}
+	return false
+}
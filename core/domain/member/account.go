@@ -18,6 +18,7 @@ import (
 	"go2o/core/infrastructure/domain"
 	"go2o/core/infrastructure/format"
 	"math"
+	"strconv"
 	"time"
 )
 
@@ -661,59 +662,70 @@ func (a *accountImpl) FinishBackBalance(id int32, tradeNo string) error {
 	return errors.New("kind not match")
 }
 
-// 请求提现,返回info_id,交易号及错误
-func (a *accountImpl) RequestTakeOut(takeKind int32, title string,
-	amount float32, commission float32) (int32, string, error) {
+// 检查是否可以提现,不满足提现条件时返回错误
+func (a *accountImpl) CheckTakeOut(takeKind int32, amount float32) error {
 	if takeKind != member.KindWalletTakeOutToBalance &&
 		takeKind != member.KindWalletTakeOutToBankCard &&
 		takeKind != member.KindWalletTakeOutToThirdPart {
-		return 0, "", member.ErrNotSupportTakeOutBusinessKind
+		return member.ErrNotSupportTakeOutBusinessKind
 	}
 	if amount <= 0 || math.IsNaN(float64(amount)) {
-		return 0, "", member.ErrIncorrectAmount
+		return member.ErrIncorrectAmount
 	}
 	// 检测是否开启提现
 	conf := a.valueRepo.GetRegistry()
 	if !conf.MemberTakeOutOn {
-		return 0, "", errors.New(conf.MemberTakeOutMessage)
+		return errors.New(conf.MemberTakeOutMessage)
 	}
 
-	// 检测是否实名
-	if conf.TakeOutMustTrust {
-		trust := a.member.Profile().GetTrustedInfo()
-		if trust.Reviewed != enum.ReviewPass {
-			return 0, "", member.ErrTakeOutNotTrust
+	// 检测是否实名,未要求提现实名时,超过设定金额仍需实名
+	if !a.trusted() {
+		if conf.TakeOutMustTrust {
+			return member.ErrTakeOutNotTrust
+		}
+		if required, _ := a.trustRequired(amount); required {
+			return member.ErrTakeOutNotTrust
 		}
 	}
 
 	// 检测非正式会员提现
 	lv := a.mm.LevelManager().GetLevelById(a.member.GetValue().Level)
 	if lv != nil && lv.IsOfficial == 0 {
-		return 0, "", errors.New(fmt.Sprintf(
+		return errors.New(fmt.Sprintf(
 			member.ErrTakeOutLevelNoPerm.Error(), lv.Name))
 	}
 	// 检测余额
 	if a.value.WalletBalance < amount {
-		return 0, "", member.ErrOutOfBalance
+		return member.ErrOutOfBalance
 	}
 	// 检测提现金额是否超过限制
 	conf2 := a.valueRepo.GetGlobNumberConf()
 	if amount < conf2.MinTakeOutAmount {
-		return 0, "", errors.New(fmt.Sprintf(member.ErrLessTakeAmount.Error(),
+		return errors.New(fmt.Sprintf(member.ErrLessTakeAmount.Error(),
 			format.FormatFloat(conf2.MinTakeOutAmount)))
 	}
 	if amount > conf2.MaxTakeOutAmount {
-		return 0, "", errors.New(fmt.Sprintf(member.ErrOutTakeAmount.Error(),
+		return errors.New(fmt.Sprintf(member.ErrOutTakeAmount.Error(),
 			format.FormatFloat(conf2.MaxTakeOutAmount)))
 	}
 	// 检测是否超过限制
 	if maxTimes := conf2.MaxTakeOutTimesOfDay; maxTimes > 0 {
 		takeTimes := a.rep.GetTodayTakeOutTimes(a.GetDomainId())
 		if takeTimes >= maxTimes {
-			return 0, "", member.ErrAccountOutOfTakeOutTimes
+			return member.ErrAccountOutOfTakeOutTimes
 		}
 	}
 
+	return nil
+}
+
+// 请求提现,mustReview为true时提现将等待审核,返回info_id,交易号及错误
+func (a *accountImpl) RequestTakeOut(takeKind int32, title string,
+	amount float32, commission float32, mustReview bool) (int32, string, error) {
+	if err := a.CheckTakeOut(takeKind, amount); err != nil {
+		return 0, "", err
+	}
+
 	tradeNo := domain.NewTradeNo(00000)
 	csnAmount := amount * commission
 	finalAmount := amount - csnAmount
@@ -736,10 +748,13 @@ func (a *accountImpl) RequestTakeOut(takeKind int32, title string,
 	}
 
 	// 提现至余额
-	if takeKind == member.KindWalletTakeOutToBalance {
+	if takeKind == member.KindWalletTakeOutToBalance && !mustReview {
 		a.value.Balance += amount
 		v.State = enum.ReviewPass
 	}
+	if mustReview {
+		v.Remark = "等待风控审核"
+	}
 
 	id, err := a.rep.SavePresentLog(v)
 	if err == nil {
@@ -761,6 +776,13 @@ func (a *accountImpl) ConfirmTakeOut(id int32, pass bool, remark string) error {
 	}
 	if pass {
 		v.State = enum.ReviewPass
+		// 提现至余额
+		if v.BusinessKind == member.KindWalletTakeOutToBalance {
+			a.value.Balance += v.CsnFee + (-v.Amount)
+			if _, err := a.Save(); err != nil {
+				return err
+			}
+		}
 	} else {
 		v.Remark += "失败:" + remark
 		v.State = enum.ReviewReject
@@ -899,6 +921,23 @@ func (a *accountImpl) presentFreezeExpired(amount float32, remark string) error
 	return err
 }
 
+// 金额是否超过必须实名认证的金额,并返回设定的金额
+func (a *accountImpl) trustRequired(amount float32) (bool, string) {
+	key := valueobject.RKMemberTrustRequiredAmount
+	s := a.valueRepo.GetsRegistryMap([]string{key})[key]
+	limit, err := strconv.ParseFloat(s, 32)
+	if err != nil {
+		limit, s = 0, "0"
+	}
+	return limit >= 0 && float64(amount) > limit, s
+}
+
+// 是否已通过实名认证
+func (a *accountImpl) trusted() bool {
+	trust := a.member.Profile().GetTrustedInfo()
+	return trust.Reviewed == enum.ReviewPass
+}
+
 // 获取会员名称
 func (a *accountImpl) getMemberName(m member.IMember) string {
 	if tr := m.Profile().GetTrustedInfo(); tr.RealName != "" &&
@@ -930,6 +969,10 @@ func (a *accountImpl) TransferAccount(accountKind int, toMember int64, amount fl
 	if b := registry[keys[0]]; b != "true" && b != "1" {
 		return errors.New(registry[keys[1]])
 	}
+	// 检测是否实名
+	if required, limit := a.trustRequired(amount); required && !a.trusted() {
+		return member.ErrTransferNotTrust.Format(limit)
+	}
 
 	switch accountKind {
 	case member.AccountWallet:
@@ -1202,6 +1245,9 @@ func (a *accountImpl) TransferFlowTo(memberId int64, kind int32,
 	if m == nil {
 		return member.ErrNoSuchMember
 	}
+	if required, limit := a.trustRequired(amount); required && !a.trusted() {
+		return member.ErrTransferNotTrust.Format(limit)
+	}
 	acc2 := m.GetAccount()
 
 	if kind == member.KindBalanceFlow {
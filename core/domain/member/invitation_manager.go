@@ -71,3 +71,16 @@ func (i *invitationManager) GetSubInvitationNum(memberIdArr []int32) map[int32]i
 func (i *invitationManager) GetInvitationMeMember() *member.Member {
 	return i.member.rep.GetInvitationMeMember(i.member.GetAggregateRootId())
 }
+
+// 获取指定时间后注册的受邀会员数量
+func (i *invitationManager) InvitationNumSince(unix int64) int {
+	return i.member.rep.GetInvitationNumSince(i.member.GetAggregateRootId(), unix)
+}
+
+// 获取指定IP注册的受邀会员数量
+func (i *invitationManager) InvitationNumByIp(ip string) int {
+	if ip == "" {
+		return 0
+	}
+	return i.member.rep.GetInvitationNumByIp(i.member.GetAggregateRootId(), ip)
+}
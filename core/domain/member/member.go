@@ -46,6 +46,7 @@ type memberImpl struct {
 	profileManager  member.IProfileManager
 	favoriteManager member.IFavoriteManager
 	giftCardManager member.IGiftCardManager
+	socialManager   member.ISocialManager
 }
 
 func NewMember(manager member.IMemberManager, val *member.Member, rep member.IMemberRepo,
@@ -138,6 +139,16 @@ func (m *memberImpl) Invitation() member.IInvitationManager {
 	return m.invitation
 }
 
+// 第三方账号管理
+func (m *memberImpl) Social() member.ISocialManager {
+	if m.socialManager == nil {
+		m.socialManager = &socialManagerImpl{
+			member: m,
+		}
+	}
+	return m.socialManager
+}
+
 // 获取值
 func (m *memberImpl) GetValue() member.Member {
 	return *m.value
@@ -180,58 +191,37 @@ func (m *memberImpl) SetValue(v *member.Member) error {
 	return nil
 }
 
-// 发送验证码,并返回验证码
-func (m *memberImpl) SendCheckCode(operation string, mssType int) (string, error) {
-	const expiresMinutes = 10 //10分钟生效
-	code := domain.NewCheckCode()
-	m.value.CheckCode = code
-	m.value.CheckExpires = time.Now().Add(time.Minute * expiresMinutes).Unix()
-	_, err := m.Save()
-	if err == nil {
-		mgr := m.mssRepo.NotifyManager()
-		pro := m.Profile().GetProfile()
-
-		// 创建参数
-		data := map[string]interface{}{
-			"code":      code,
-			"operation": operation,
-			"minutes":   expiresMinutes,
-		}
+// 发送验证码通知,验证码由调用方保存并校验
+func (m *memberImpl) NotifyCheckCode(operation string, code string, mssType int) error {
+	mgr := m.mssRepo.NotifyManager()
+	pro := m.Profile().GetProfile()
 
-		// 根据消息类型发送信息
-		switch mssType {
-		case notify.TypePhoneMessage:
-			// 某些短信平台要求传入模板ID,在这里附加参数
-			provider, _ := m.valRepo.GetDefaultSmsApiPerm()
-			data = sms.AppendCheckPhoneParams(provider, data)
-
-			// 构造并发送短信
-			n := mgr.GetNotifyItem("验证手机")
-			c := notify.PhoneMessage(n.Content)
-			err = mgr.SendPhoneMessage(pro.Phone, c, data)
-
-		default:
-		case notify.TypeEmailMessage:
-			n := mgr.GetNotifyItem("验证邮箱")
-			c := &notify.MailMessage{
-				Subject: operation + "验证码",
-				Body:    n.Content,
-			}
-			err = mgr.SendEmail(pro.Phone, c, data)
-		}
+	// 创建参数
+	data := map[string]interface{}{
+		"code":      code,
+		"operation": operation,
+		"minutes":   member.CheckCodeMinutes,
 	}
-	return code, err
-}
 
-// 对比验证码
-func (m *memberImpl) CompareCode(code string) error {
-	if m.value.CheckCode != strings.TrimSpace(code) {
-		return member.ErrCheckCodeError
+	to := &notify.Recipient{
+		MemberId: m.GetAggregateRootId(),
+		Phone:    pro.Phone,
+		Email:    pro.Email,
 	}
-	if m.value.CheckExpires < time.Now().Unix() {
-		return member.ErrCheckCodeExpires
+	var err error
+	// 根据消息类型发送信息
+	switch mssType {
+	case notify.TypePhoneMessage:
+		// 某些短信平台要求传入模板ID,在这里附加参数
+		provider, _ := m.valRepo.GetDefaultSmsApiPerm()
+		data = sms.AppendCheckPhoneParams(provider, data)
+		_, err = mgr.Dispatch("验证手机", to, data)
+
+	default:
+	case notify.TypeEmailMessage:
+		_, err = mgr.Dispatch("验证邮箱", to, data)
 	}
-	return nil
+	return err
 }
 
 // 获取账户
@@ -488,6 +478,10 @@ func (m *memberImpl) create(v *member.Member, pro *member.Profile) (int64, error
 	v.LastLoginTime = t
 	v.Level = 1
 	v.Exp = 0
+	// 保存散列后的密码
+	if err := hashMemberPwd(v); err != nil {
+		return 0, err
+	}
 	v.DynamicToken = v.Pwd
 	if len(v.RegFrom) == 0 {
 		v.RegFrom = "API-INTERNAL"
@@ -503,6 +497,19 @@ func (m *memberImpl) create(v *member.Member, pro *member.Profile) (int64, error
 	return id, err
 }
 
+// 散列会员的登录及交易密码,已散列的密码不再处理
+func hashMemberPwd(v *member.Member) (err error) {
+	if len(v.Pwd) != 0 && domain.IsLegacyPwd(v.Pwd) {
+		if v.Pwd, err = domain.HashPwd(v.Pwd); err != nil {
+			return err
+		}
+	}
+	if len(v.TradePwd) != 0 && domain.IsLegacyPwd(v.TradePwd) {
+		v.TradePwd, err = domain.HashPwd(v.TradePwd)
+	}
+	return err
+}
+
 // 会员初始化
 func (m *memberImpl) memberInit() {
 	conf := m.valRepo.GetRegistry()
@@ -21,7 +21,9 @@ import (
 	"go2o/core/domain/tmp"
 	dm "go2o/core/infrastructure/domain"
 	"go2o/core/infrastructure/domain/util"
+	"go2o/core/infrastructure/kyc"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -289,12 +291,15 @@ func (p *profileManagerImpl) ModifyPassword(newPwd, oldPwd string) error {
 		if newPwd == oldPwd {
 			return domain.ErrPwdCannotSame
 		}
-		if oldPwd != p.member.value.Pwd {
+		if ok, _ := dm.VerifyPwd(p.member.value.Pwd, oldPwd); !ok {
 			return domain.ErrPwdOldPwdNotRight
 		}
 	}
-	p.member.value.Pwd = newPwd
-	_, err := p.member.Save()
+	hash, err := dm.HashPwd(newPwd)
+	if err == nil {
+		p.member.value.Pwd = hash
+		_, err = p.member.Save()
+	}
 	return err
 }
 
@@ -307,14 +312,52 @@ func (p *profileManagerImpl) ModifyTradePassword(newPwd, oldPwd string) error {
 		return err
 	}
 	// 已经设置过旧密码
-	if len(oldPwd) != 0 && p.member.value.TradePwd != oldPwd {
-		return domain.ErrPwdOldPwdNotRight
+	if len(oldPwd) != 0 {
+		if ok, _ := dm.VerifyPwd(p.member.value.TradePwd, oldPwd); !ok {
+			return domain.ErrPwdOldPwdNotRight
+		}
+	}
+	hash, err := dm.HashPwd(newPwd)
+	if err == nil {
+		p.member.value.TradePwd = hash
+		_, err = p.member.Save()
 	}
-	p.member.value.TradePwd = newPwd
-	_, err := p.member.Save()
 	return err
 }
 
+// 验证密码,旧版本的密码验证通过后将重新散列保存
+func (p *profileManagerImpl) CheckPassword(pwd string) error {
+	ok, rehash := dm.VerifyPwd(p.member.value.Pwd, pwd)
+	if !ok {
+		return member.ErrCredential
+	}
+	if rehash {
+		if hash, err := dm.HashPwd(pwd); err == nil {
+			p.member.value.Pwd = hash
+			p.member.Save()
+		}
+	}
+	return nil
+}
+
+// 验证交易密码,旧版本的密码验证通过后将重新散列保存
+func (p *profileManagerImpl) CheckTradePassword(pwd string) error {
+	if len(p.member.value.TradePwd) == 0 {
+		return member.ErrNotSetTradePwd
+	}
+	ok, rehash := dm.VerifyPwd(p.member.value.TradePwd, pwd)
+	if !ok {
+		return member.ErrIncorrectTradePwd
+	}
+	if rehash {
+		if hash, err := dm.HashPwd(pwd); err == nil {
+			p.member.value.TradePwd = hash
+			p.member.Save()
+		}
+	}
+	return nil
+}
+
 // 获取提现银行信息
 func (p *profileManagerImpl) GetBank() member.BankInfo {
 	if p.bank == nil {
@@ -532,6 +575,8 @@ func (p *profileManagerImpl) SaveTrustedInfo(v *member.TrustedInfo) error {
 	err = p.copyTrustedInfo(v, p.trustedInfo)
 	if err == nil {
 		p.trustedInfo.Remark = ""
+		p.trustedInfo.RiskScore = 0
+		p.trustedInfo.RiskFactors = ""
 		p.trustedInfo.Reviewed = enum.ReviewAwaiting //标记为待处理
 		p.trustedInfo.UpdateTime = time.Now().Unix()
 		_, err = orm.Save(tmp.Db().GetOrm(), p.trustedInfo,
@@ -559,6 +604,38 @@ func (p *profileManagerImpl) ReviewTrustedInfo(pass bool, remark string) error {
 	return err
 }
 
+// 根据自动认证的风险评分审核实名认证,风险较低时自动通过,
+// 较高时自动拒绝,其他情况等待人工审核。返回审核状态
+func (p *profileManagerImpl) AutoReviewTrustedInfo(riskScore int32,
+	factors []string) (int32, error) {
+	p.GetTrustedInfo()
+	if p.trustedInfo.Reviewed != enum.ReviewAwaiting {
+		return p.trustedInfo.Reviewed, member.ErrNotAwaitingTrustReview
+	}
+	keys := []string{valueobject.RKKycAutoPassRisk, valueobject.RKKycAutoRejectRisk}
+	mp := p.valRepo.GetsRegistryMap(keys)
+	passBelow, _ := strconv.Atoi(mp[keys[0]])
+	rejectFrom, err := strconv.Atoi(mp[keys[1]])
+	if err != nil {
+		rejectFrom = 100
+	}
+	p.trustedInfo.RiskScore = riskScore
+	p.trustedInfo.RiskFactors = strings.Join(factors, ",")
+	switch kyc.Decide(int(riskScore), passBelow, rejectFrom) {
+	case kyc.DecisionPass:
+		p.trustedInfo.Reviewed = enum.ReviewPass
+		p.trustedInfo.Remark = ""
+		p.trustedInfo.ReviewTime = time.Now().Unix()
+	case kyc.DecisionReject:
+		p.trustedInfo.Reviewed = enum.ReviewReject
+		p.trustedInfo.Remark = member.ErrTrustVerifyFailed.Error()
+		p.trustedInfo.ReviewTime = time.Now().Unix()
+	}
+	_, err = orm.Save(tmp.Db().GetOrm(), p.trustedInfo,
+		int(p.trustedInfo.MemberId))
+	return p.trustedInfo.Reviewed, err
+}
+
 var _ member.IDeliverAddress = new(addressImpl)
 
 type addressImpl struct {
@@ -0,0 +1,112 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : social_manager
+ * author : jarryliu
+ * date : 2026-10-20 00:20
+ * description : 第三方账号管理
+ * history :
+ */
+package member
+
+import (
+	"go2o/core/domain/interface/member"
+	"strings"
+	"time"
+)
+
+var _ member.ISocialManager = new(socialManagerImpl)
+
+type socialManagerImpl struct {
+	member *memberImpl
+}
+
+// 获取绑定的第三方账号
+func (s *socialManagerImpl) Identities() []*member.SocialIdentity {
+	return s.member.rep.GetSocialIdentities(s.member.GetAggregateRootId())
+}
+
+// 获取指定平台绑定的账号
+func (s *socialManagerImpl) GetIdentity(provider string) *member.SocialIdentity {
+	for _, v := range s.Identities() {
+		if v.Provider == provider {
+			return v
+		}
+	}
+	return nil
+}
+
+// 绑定账号,每个平台仅能绑定一个账号
+func (s *socialManagerImpl) Bind(v *member.SocialIdentity) error {
+	memberId := s.member.GetAggregateRootId()
+	e := s.member.rep.GetSocialIdentity(v.Provider, v.OpenId)
+	if e != nil {
+		if e.MemberId != memberId {
+			return member.ErrSocialIdentityBound
+		}
+		// 已绑定时更新昵称及头像
+		e.Nickname = v.Nickname
+		e.Avatar = v.Avatar
+		if v.UnionId != "" {
+			e.UnionId = v.UnionId
+		}
+		_, err := s.member.rep.SaveSocialIdentity(e)
+		return err
+	}
+	if s.GetIdentity(v.Provider) != nil {
+		return member.ErrSocialProviderBound
+	}
+	v.Id = 0
+	v.MemberId = memberId
+	v.BindTime = time.Now().Unix()
+	_, err := s.member.rep.SaveSocialIdentity(v)
+	return err
+}
+
+// 是否通过第三方账号注册
+func (s *socialManagerImpl) registeredBySocial() bool {
+	return strings.HasPrefix(s.member.GetValue().RegFrom, member.SocialRegFromPrefix)
+}
+
+// 解绑账号
+func (s *socialManagerImpl) Unbind(provider string) error {
+	if s.GetIdentity(provider) == nil {
+		return member.ErrNoSuchSocialIdentity
+	}
+	// 通过第三方账号注册且未绑定手机的会员,不能解绑唯一的账号
+	if s.registeredBySocial() && len(s.Identities()) == 1 &&
+		s.member.Profile().GetProfile().Phone == "" {
+		return member.ErrSocialLastIdentity
+	}
+	return s.member.rep.DeleteSocialIdentity(s.member.GetAggregateRootId(), provider)
+}
+
+// 合并到其他会员(如:已注册的手机会员),仅能合并通过第三方账号
+// 注册且无余额的会员;合并后第三方账号绑定到目标会员,当前会员将被停用
+func (s *socialManagerImpl) MergeInto(target member.IMember) error {
+	if !s.registeredBySocial() {
+		return member.ErrSocialMergeSource
+	}
+	targetId := target.GetAggregateRootId()
+	if targetId <= 0 || targetId == s.member.GetAggregateRootId() {
+		return member.ErrSocialMergeSelf
+	}
+	acc := s.member.GetAccount().GetValue()
+	if acc.Balance > 0 || acc.FreezeBalance > 0 ||
+		acc.WalletBalance > 0 || acc.FreezeWallet > 0 {
+		return member.ErrSocialMergeBalance
+	}
+	list := s.Identities()
+	ts := target.Social()
+	for _, v := range list {
+		if ts.GetIdentity(v.Provider) != nil {
+			return member.ErrSocialProviderBound
+		}
+	}
+	for _, v := range list {
+		v.MemberId = targetId
+		if _, err := s.member.rep.SaveSocialIdentity(v); err != nil {
+			return err
+		}
+	}
+	return s.member.Lock()
+}
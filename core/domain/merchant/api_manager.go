@@ -10,7 +10,9 @@ package merchant
 
 import (
 	"go2o/core/domain/interface/merchant"
+	"go2o/core/infrastructure/apisign"
 	"go2o/core/infrastructure/domain"
+	"time"
 )
 
 var _ merchant.IApiManager = new(apiManagerImpl)
@@ -71,3 +73,64 @@ func (a *apiManagerImpl) DisableApiPerm() error {
 	v.Enabled = 0
 	return a.SaveApiInfo(v)
 }
+
+// 轮换密钥,旧密钥在overlap秒内仍然可用,返回新的密钥
+func (a *apiManagerImpl) RotateSecret(overlap int64) (string, error) {
+	v := a.getApiInfo()
+	unix := time.Now().Unix()
+	secret := apisign.NewSecret()
+	v.OldSecret = v.ApiSecret
+	v.OldSecretExpires = unix + overlap
+	v.ApiSecret = secret
+	return secret, a.SaveApiInfo(v)
+}
+
+// 获取接口分组的限流配置,未设置时返回默认配置
+func (a *apiManagerImpl) GetApiLimit(group string) merchant.ApiLimit {
+	for _, v := range a._rep.GetApiLimits(a.GetAggregateRootId()) {
+		if v.Group == group {
+			return *v
+		}
+	}
+	return merchant.ApiLimit{
+		MchId:      a.GetAggregateRootId(),
+		Group:      group,
+		Rate:       merchant.DefaultApiRate,
+		Burst:      merchant.DefaultApiBurst,
+		DailyQuota: merchant.DefaultApiDailyQuota,
+	}
+}
+
+// 获取所有接口分组的限流配置
+func (a *apiManagerImpl) GetApiLimits() []merchant.ApiLimit {
+	list := make([]merchant.ApiLimit, len(merchant.ApiGroups))
+	for i, g := range merchant.ApiGroups {
+		list[i] = a.GetApiLimit(g)
+	}
+	return list
+}
+
+// 保存接口分组的限流配置
+func (a *apiManagerImpl) SaveApiLimit(v *merchant.ApiLimit) error {
+	valid := false
+	for _, g := range merchant.ApiGroups {
+		if g == v.Group {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return merchant.ErrApiGroup
+	}
+	if v.Rate < 0 || v.Burst < v.Rate {
+		return merchant.ErrApiLimitRate
+	}
+	if v.DailyQuota < 0 {
+		v.DailyQuota = 0
+	}
+	origin := a.GetApiLimit(v.Group)
+	v.Id = origin.Id
+	v.MchId = a.GetAggregateRootId()
+	_, err := a._rep.SaveApiLimit(v)
+	return err
+}
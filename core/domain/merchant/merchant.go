@@ -339,7 +339,17 @@ func (m *merchantImpl) SetValue(v *merchant.Merchant) error {
 		if len(v.CompanyName) != 0 {
 			tv.CompanyName = v.CompanyName
 		}
-		tv.Pwd = v.Pwd
+		// 密码变更时散列后保存
+		if v.Pwd != tv.Pwd {
+			tv.Pwd = v.Pwd
+			if len(v.Pwd) != 0 && domain.IsLegacyPwd(v.Pwd) {
+				hash, err := domain.HashPwd(v.Pwd)
+				if err != nil {
+					return err
+				}
+				tv.Pwd = hash
+			}
+		}
 		tv.UpdateTime = time.Now().Unix()
 	}
 	return nil
@@ -458,6 +468,14 @@ func (m *merchantImpl) createMerchant() (int32, error) {
 	if id := m.GetAggregateRootId(); id > 0 {
 		return id, nil
 	}
+	// 保存散列后的密码
+	if v := m._value; len(v.Pwd) != 0 && domain.IsLegacyPwd(v.Pwd) {
+		hash, err := domain.HashPwd(v.Pwd)
+		if err != nil {
+			return 0, err
+		}
+		v.Pwd = hash
+	}
 
 	id, err := m._rep.SaveMerchant(m._value)
 	if err != nil {
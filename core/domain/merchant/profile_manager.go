@@ -14,6 +14,7 @@ import (
 	"github.com/jsix/gof/util"
 	"go2o/core/domain"
 	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/member"
 	"go2o/core/domain/interface/merchant"
 	"go2o/core/domain/interface/valueobject"
 	"go2o/core/domain/tmp"
@@ -135,15 +136,33 @@ func (p *profileManagerImpl) ModifyPassword(newPwd, oldPwd string) error {
 		if newPwd == oldPwd {
 			return domain.ErrPwdCannotSame
 		}
-		if oldPwd != p._value.Pwd {
+		if ok, _ := dm.VerifyPwd(p._value.Pwd, oldPwd); !ok {
 			return domain.ErrPwdOldPwdNotRight
 		}
 	}
-	p._value.Pwd = newPwd
-	_, err := p.Save()
+	hash, err := dm.HashPwd(newPwd)
+	if err == nil {
+		p._value.Pwd = hash
+		_, err = p.Save()
+	}
 	return err
 }
 
+// 验证密码,旧版本的密码验证通过后将重新散列保存
+func (p *profileManagerImpl) CheckPassword(pwd string) error {
+	ok, rehash := dm.VerifyPwd(p._value.Pwd, pwd)
+	if !ok {
+		return member.ErrCredential
+	}
+	if rehash {
+		if hash, err := dm.HashPwd(pwd); err == nil {
+			p._value.Pwd = hash
+			p.Save()
+		}
+	}
+	return nil
+}
+
 func (p *profileManagerImpl) save(e *merchant.EnterpriseInfo) error {
 	_, err := orm.Save(tmp.Db().GetOrm(), e, int(e.ID))
 	return err
@@ -11,6 +11,7 @@ package user
 import (
 	"errors"
 	"go2o/core/domain/interface/merchant/user"
+	"strings"
 )
 
 var _ user.IRole = new(Role)
@@ -43,6 +44,54 @@ func (this *Role) SetValue(v *user.RoleValue) error {
 	return errors.New("no such value")
 }
 
+// 获取包含的权限
+func (this *Role) Permissions() []string {
+	return user.SplitPermissions(this.value.Permissions)
+}
+
+// 设置包含的权限
+func (this *Role) SetPermissions(perms []string) error {
+	arr := make([]string, 0, len(perms))
+	for _, p := range perms {
+		if _, ok := user.PermissionNames[p]; !ok {
+			return user.ErrNoSuchPermission
+		}
+		exists := false
+		for _, v := range arr {
+			if v == p {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			arr = append(arr, p)
+		}
+	}
+	this.value.Permissions = strings.Join(arr, ",")
+	return nil
+}
+
+// 是否包含权限
+func (this *Role) HasPermission(perm string) bool {
+	if this.value.Enabled != 1 {
+		return false
+	}
+	for _, v := range this.Permissions() {
+		if v == perm {
+			return true
+		}
+	}
+	return false
+}
+
 func (this *Role) Save() (int32, error) {
-	return this.rep.SaveRole(this.value)
+	this.value.Name = strings.TrimSpace(this.value.Name)
+	if this.value.Name == "" {
+		return 0, user.ErrRoleName
+	}
+	id, err := this.rep.SaveRole(this.value)
+	if err == nil {
+		this.value.Id = id
+	}
+	return id, err
 }
@@ -10,6 +10,8 @@ package user
 
 import (
 	"go2o/core/domain/interface/merchant/user"
+	"go2o/core/domain/interface/security"
+	"go2o/core/infrastructure/domain"
 )
 
 var _ user.IUser = new(User)
@@ -33,6 +35,68 @@ func (this *User) GetPerson() user.IPerson {
 	return this.person
 }
 
+// 获取两步验证
+func (this *User) TwoFactor() security.ITwoFactor {
+	return this.rep.GetTwoFactor(this.person.GetDomainId())
+}
+
+// 角色是否要求启用两步验证,仅检查人员所属商户的角色及系统角色
+func (this *User) RequireTwoFactor() bool {
+	pv := this.person.GetValue()
+	if pv.RoleFlag != 0 {
+		for _, v := range this.rep.GetRoles() {
+			if v.MchId != 0 && v.MchId != pv.MchId {
+				continue
+			}
+			if v.Enabled == 1 && v.RequireTwoFactor == 1 && pv.RoleFlag&v.Flag != 0 {
+				return true
+			}
+		}
+	}
+	// 授予的角色要求两步验证
+	for _, g := range this.Grants() {
+		r := this.rep.GetRole(g.RoleId)
+		if r != nil && r.MchId == pv.MchId && r.Enabled == 1 &&
+			r.RequireTwoFactor == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// 获取授予的角色
+func (this *User) Grants() []*user.RoleGrant {
+	return this.rep.GetRoleGrants(this.person.GetDomainId())
+}
+
+// 是否拥有店铺的操作权限,shopId为0表示商户级别的操作,
+// 仅在所有店铺拥有权限时返回true
+func (this *User) HasPermission(perm string, shopId int32) bool {
+	mchId := this.person.GetValue().MchId
+	for _, g := range this.Grants() {
+		if g.ShopId != 0 && g.ShopId != shopId {
+			continue
+		}
+		if v := this.rep.GetRole(g.RoleId); v != nil && v.MchId == mchId {
+			if newRole(v, this.rep).HasPermission(perm) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// 检查权限,没有权限时返回ErrPermissionDenied
+func (this *User) CheckPermission(perm string, shopId int32) error {
+	if _, ok := user.PermissionNames[perm]; !ok {
+		return user.ErrNoSuchPermission
+	}
+	if !this.HasPermission(perm, shopId) {
+		return user.ErrPermissionDenied
+	}
+	return nil
+}
+
 // 获取凭据
 func (this *User) GetCredential(sign string) *user.CredentialValue {
 	//todo: not will used
@@ -41,6 +105,14 @@ func (this *User) GetCredential(sign string) *user.CredentialValue {
 
 // 保存凭据
 func (this *User) SaveCredential(v *user.CredentialValue) error {
+	// 保存散列后的密码
+	if len(v.Pwd) != 0 && domain.IsLegacyPwd(v.Pwd) {
+		hash, err := domain.HashPwd(v.Pwd)
+		if err != nil {
+			return err
+		}
+		v.Pwd = hash
+	}
 	_, err := this.rep.SaveCredential(v)
 	return err
 }
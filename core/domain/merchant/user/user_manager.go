@@ -10,6 +10,10 @@ package user
 
 import (
 	"go2o/core/domain/interface/merchant/user"
+	"go2o/core/domain/interface/security"
+	"go2o/core/infrastructure/domain"
+	"strings"
+	"time"
 )
 
 var _ user.IUserManager = new(UserManager)
@@ -44,3 +48,122 @@ func (u *UserManager) GetDeliveryStaff() []user.IDeliveryStaff {
 	}
 	return staffs
 }
+
+// 用户登录,启用两步验证时需传入验证码;角色要求两步验证
+// 但未启用时,返回用户及security.ErrTwoFactorNotEnrolled,应引导用户绑定
+func (u *UserManager) Login(usr string, pwd string, code string) (user.IUser, error) {
+	c := u.rep.GetCredentialByUsr(strings.TrimSpace(usr))
+	if c == nil {
+		return nil, user.ErrCredential
+	}
+	ok, rehash := domain.VerifyPwd(c.Pwd, pwd)
+	if !ok {
+		return nil, user.ErrCredential
+	}
+	if c.Enabled != 1 {
+		return nil, user.ErrUserDisabled
+	}
+	// 旧版本的密码重新散列保存
+	if rehash {
+		if hash, err := domain.HashPwd(pwd); err == nil {
+			c.Pwd = hash
+			u.rep.SaveCredential(c)
+		}
+	}
+	iu := u.GetUser(int32(c.PersonId))
+	if iu == nil || !u.belongTo(iu.GetPerson().GetValue()) {
+		return nil, user.ErrCredential
+	}
+	tf := iu.TwoFactor()
+	if tf.Enabled() {
+		if strings.TrimSpace(code) == "" {
+			return iu, security.ErrTwoFactorRequired
+		}
+		return iu, tf.Verify(code)
+	}
+	if iu.RequireTwoFactor() {
+		return iu, security.ErrTwoFactorNotEnrolled
+	}
+	return iu, nil
+}
+
+// 人员是否属于当前商户,未指定商户的人员不能登录任何商户
+func (u *UserManager) belongTo(v user.PersonValue) bool {
+	return v.MchId > 0 && v.MchId == u.mchId
+}
+
+// 获取商户的角色
+func (u *UserManager) GetRoles() []user.IRole {
+	list := u.rep.GetMchRoles(u.mchId)
+	arr := make([]user.IRole, len(list))
+	for i, v := range list {
+		arr[i] = newRole(v, u.rep)
+	}
+	return arr
+}
+
+// 获取角色
+func (u *UserManager) GetRole(id int32) user.IRole {
+	v := u.rep.GetRole(id)
+	if v != nil && v.MchId == u.mchId {
+		return newRole(v, u.rep)
+	}
+	return nil
+}
+
+// 创建角色
+func (u *UserManager) CreateRole(v *user.RoleValue) user.IRole {
+	v.Id = 0
+	v.MchId = u.mchId
+	return newRole(v, u.rep)
+}
+
+// 删除角色,并移除已授予的角色
+func (u *UserManager) DeleteRole(id int32) error {
+	if u.GetRole(id) == nil {
+		return user.ErrNoSuchRole
+	}
+	if err := u.rep.DeleteRoleGrants(id); err != nil {
+		return err
+	}
+	return u.rep.DeleteRole(id)
+}
+
+// 获取商户的员工
+func (u *UserManager) getStaff(personId int32) (*user.PersonValue, error) {
+	v := u.rep.GetPersonValue(personId)
+	if v == nil || v.MchId != u.mchId {
+		return nil, user.ErrNoSuchPerson
+	}
+	return v, nil
+}
+
+// 授予员工角色,shopId为0表示所有店铺
+func (u *UserManager) GrantRole(personId int32, roleId int32, shopId int32) error {
+	if _, err := u.getStaff(personId); err != nil {
+		return err
+	}
+	if u.GetRole(roleId) == nil {
+		return user.ErrNoSuchRole
+	}
+	for _, g := range u.rep.GetRoleGrants(personId) {
+		if g.RoleId == roleId && g.ShopId == shopId {
+			return nil
+		}
+	}
+	_, err := u.rep.SaveRoleGrant(&user.RoleGrant{
+		PersonId:   personId,
+		RoleId:     roleId,
+		ShopId:     shopId,
+		CreateTime: time.Now().Unix(),
+	})
+	return err
+}
+
+// 移除员工的角色
+func (u *UserManager) RevokeRole(personId int32, roleId int32, shopId int32) error {
+	if _, err := u.getStaff(personId); err != nil {
+		return err
+	}
+	return u.rep.DeleteRoleGrant(personId, roleId, shopId)
+}
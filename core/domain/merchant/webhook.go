@@ -0,0 +1,254 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : webhook.go
+ * author : jarryliu
+ * date : 2026-10-20 22:00
+ * description : 商户事件推送
+ * history :
+ */
+package merchant
+
+import (
+	"encoding/json"
+	"go2o/core/domain/interface/event"
+	"go2o/core/domain/interface/merchant"
+	"go2o/core/infrastructure/apisign"
+	"go2o/core/infrastructure/webhook"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 推送内容
+type webhookPayload struct {
+	// 事件类型
+	Event string `json:"event"`
+	// 事件编号
+	EventId int64 `json:"eventId"`
+	// 商户编号
+	MchId int32 `json:"mchId"`
+	// 事件时间
+	CreateTime int64 `json:"createTime"`
+	// 事件数据
+	Data json.RawMessage `json:"data"`
+}
+
+// 获取推送地址
+func (a *apiManagerImpl) GetWebhooks() []*merchant.Webhook {
+	return a._rep.GetWebhooks(a.GetAggregateRootId())
+}
+
+// 获取推送地址
+func (a *apiManagerImpl) GetWebhook(id int32) *merchant.Webhook {
+	for _, v := range a.GetWebhooks() {
+		if v.Id == id {
+			return v
+		}
+	}
+	return nil
+}
+
+// 检查订阅的事件,返回去重后的事件
+func (a *apiManagerImpl) checkWebhookEvents(events string) (string, error) {
+	arr := []string{}
+	for _, v := range strings.Split(events, ",") {
+		v = strings.TrimSpace(v)
+		valid := false
+		for _, e := range merchant.WebhookEvents {
+			if e == v {
+				valid = true
+				break
+			}
+		}
+		for _, e := range arr {
+			if e == v {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			arr = append(arr, v)
+		}
+	}
+	if len(arr) == 0 {
+		return "", merchant.ErrWebhookEvent
+	}
+	return strings.Join(arr, ","), nil
+}
+
+// 保存推送地址,未设置密钥时生成新的密钥
+func (a *apiManagerImpl) SaveWebhook(v *merchant.Webhook) (int32, error) {
+	v.Url = strings.TrimSpace(v.Url)
+	if err := webhook.CheckUrl(v.Url); err != nil {
+		if err == webhook.ErrForbidden {
+			return 0, merchant.ErrWebhookForbidden
+		}
+		return 0, merchant.ErrWebhookUrl
+	}
+	events, err := a.checkWebhookEvents(v.Events)
+	if err != nil {
+		return 0, err
+	}
+	unix := time.Now().Unix()
+	if v.Id > 0 {
+		origin := a.GetWebhook(v.Id)
+		if origin == nil {
+			return 0, merchant.ErrNoSuchWebhook
+		}
+		v.CreateTime = origin.CreateTime
+		if v.Secret == "" {
+			v.Secret = origin.Secret
+		}
+	} else {
+		if len(a.GetWebhooks()) >= merchant.WebhookMaxNum {
+			return 0, merchant.ErrWebhookNum
+		}
+		v.CreateTime = unix
+	}
+	if v.Secret == "" {
+		v.Secret = apisign.NewSecret()
+	}
+	v.MchId = a.GetAggregateRootId()
+	v.Events = events
+	v.UpdateTime = unix
+	return a._rep.SaveWebhook(v)
+}
+
+// 删除推送地址
+func (a *apiManagerImpl) DeleteWebhook(id int32) error {
+	if a.GetWebhook(id) == nil {
+		return merchant.ErrNoSuchWebhook
+	}
+	return a._rep.DeleteWebhook(a.GetAggregateRootId(), id)
+}
+
+// 为订阅了事件的推送地址创建推送记录,未开通接口权限时不推送
+func (a *apiManagerImpl) CreateDeliveries(e *event.Event) ([]*merchant.WebhookDelivery, error) {
+	list := []*merchant.WebhookDelivery{}
+	if a.getApiInfo().Enabled != 1 {
+		return list, nil
+	}
+	payload, err := json.Marshal(&webhookPayload{
+		Event:      e.Type,
+		EventId:    e.Id,
+		MchId:      a.GetAggregateRootId(),
+		CreateTime: e.CreateTime,
+		Data:       json.RawMessage(e.Data),
+	})
+	if err != nil {
+		return list, err
+	}
+	for _, w := range a.GetWebhooks() {
+		if !w.Subscribed(e.Type) {
+			continue
+		}
+		d, err := a.createDelivery(w.Id, e.Id, e.Type, string(payload))
+		if err != nil {
+			return list, err
+		}
+		list = append(list, d)
+	}
+	return list, nil
+}
+
+func (a *apiManagerImpl) createDelivery(webhookId int32, eventId int64,
+	eventType string, payload string) (*merchant.WebhookDelivery, error) {
+	unix := time.Now().Unix()
+	d := &merchant.WebhookDelivery{
+		WebhookId:  webhookId,
+		MchId:      a.GetAggregateRootId(),
+		EventId:    eventId,
+		EventType:  eventType,
+		Payload:    payload,
+		State:      merchant.DeliveryPending,
+		NextTime:   unix,
+		CreateTime: unix,
+		UpdateTime: unix,
+	}
+	var err error
+	d.Id, err = a._rep.SaveWebhookDelivery(d)
+	return d, err
+}
+
+func (a *apiManagerImpl) getDelivery(id int64) *merchant.WebhookDelivery {
+	d := a._rep.GetWebhookDelivery(id)
+	if d == nil || d.MchId != a.GetAggregateRootId() {
+		return nil
+	}
+	return d
+}
+
+// 重试间隔,按推送次数指数增长
+func webhookBackoff(attempts int) int64 {
+	sec := int64(merchant.WebhookRetryBase)
+	for i := 1; i < attempts && sec < merchant.WebhookRetryMax; i++ {
+		sec *= 2
+	}
+	if sec > merchant.WebhookRetryMax {
+		sec = merchant.WebhookRetryMax
+	}
+	return sec
+}
+
+// 推送,推送失败且需要重试时,返回的记录状态为DeliveryRetrying
+func (a *apiManagerImpl) Deliver(deliveryId int64) (*merchant.WebhookDelivery, error) {
+	d := a.getDelivery(deliveryId)
+	if d == nil {
+		return nil, merchant.ErrNoSuchDelivery
+	}
+	if d.State == merchant.DeliverySuccess || d.State == merchant.DeliveryFailed {
+		return d, nil
+	}
+	w := a.GetWebhook(d.WebhookId)
+	if w == nil {
+		// 推送地址已删除
+		d.State = merchant.DeliveryFailed
+		d.Error = merchant.ErrNoSuchWebhook.Error()
+		d.NextTime = 0
+		d.UpdateTime = time.Now().Unix()
+		_, err := a._rep.SaveWebhookDelivery(d)
+		return d, err
+	}
+	r := webhook.Post(nil, w.Url, w.Secret, d.EventType,
+		strconv.FormatInt(d.Id, 10), []byte(d.Payload))
+	unix := time.Now().Unix()
+	d.Attempts++
+	d.ResponseCode = r.Code
+	d.ResponseBody = r.Body
+	d.Duration = r.Duration
+	d.Error = ""
+	if r.Err != nil {
+		d.Error = r.Err.Error()
+	}
+	d.NextTime = 0
+	if r.Success() {
+		d.State = merchant.DeliverySuccess
+	} else if d.Attempts >= merchant.WebhookMaxAttempts {
+		d.State = merchant.DeliveryFailed
+	} else {
+		d.State = merchant.DeliveryRetrying
+		d.NextTime = unix + webhookBackoff(d.Attempts)
+	}
+	d.UpdateTime = unix
+	_, err := a._rep.SaveWebhookDelivery(d)
+	return d, err
+}
+
+// 重新推送,以原推送内容创建新的推送记录
+func (a *apiManagerImpl) Redeliver(deliveryId int64) (*merchant.WebhookDelivery, error) {
+	d := a.getDelivery(deliveryId)
+	if d == nil {
+		return nil, merchant.ErrNoSuchDelivery
+	}
+	if a.GetWebhook(d.WebhookId) == nil {
+		return nil, merchant.ErrNoSuchWebhook
+	}
+	return a.createDelivery(d.WebhookId, d.EventId, d.EventType, d.Payload)
+}
+
+// 查询推送记录,webhookId及state为0时不作为条件
+func (a *apiManagerImpl) QueryDeliveries(webhookId int32, state int,
+	begin, size int) (int, []*merchant.WebhookDelivery) {
+	return a._rep.QueryWebhookDeliveries(a.GetAggregateRootId(),
+		webhookId, state, begin, size)
+}
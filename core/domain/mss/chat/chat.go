@@ -0,0 +1,382 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : chat
+ * author : jarryliu
+ * date : 2026-10-22 10:10
+ * description : 客服会话,会话分配给接待数量最少的在线客服,
+ *               数量相同时分配给最久未接入会话的客服
+ * history :
+ */
+package chat
+
+import (
+	"encoding/json"
+	"go2o/core/domain/interface/mss"
+	"go2o/core/domain/interface/mss/chat"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var _ chat.IChatManager = new(chatManagerImpl)
+
+type chatManagerImpl struct {
+	rep     chat.IChatRepo
+	checker func(mchId int32, personId int32) bool
+}
+
+func NewChatManager(rep chat.IChatRepo) chat.IChatManager {
+	return &chatManagerImpl{
+		rep: rep,
+	}
+}
+
+// 会员发起会话
+func (c *chatManagerImpl) OpenSession(memberId int64, mchId int32) (*chat.Session, error) {
+	if s := c.rep.GetActiveSession(memberId, mchId); s != nil {
+		return s, nil
+	}
+	unix := time.Now().Unix()
+	s := &chat.Session{
+		MemberId:   memberId,
+		MchId:      mchId,
+		State:      chat.SessionWaiting,
+		CreateTime: unix,
+		UpdateTime: unix,
+	}
+	id, err := c.rep.SaveSession(s)
+	if err != nil {
+		return nil, err
+	}
+	s.Id = id
+	c.route(s)
+	return s, nil
+}
+
+// 获取会话
+func (c *chatManagerImpl) GetSession(id int32) *chat.Session {
+	return c.rep.GetSession(id)
+}
+
+// 获取会员的会话
+func (c *chatManagerImpl) GetMemberSessions(memberId int64) []*chat.Session {
+	return c.rep.GetMemberSessions(memberId)
+}
+
+// 获取客服接待中的会话
+func (c *chatManagerImpl) GetAgentSessions(mchId int32, personId int32) []*chat.Session {
+	return c.rep.GetSessions(mchId, personId, chat.SessionServing)
+}
+
+// 获取商户等待接入的会话
+func (c *chatManagerImpl) GetWaitingSessions(mchId int32) []*chat.Session {
+	return c.rep.GetSessions(mchId, 0, chat.SessionWaiting)
+}
+
+// 检查发送者或操作者是否为会话的会员或接待的客服
+func (c *chatManagerImpl) checkUser(s *chat.Session, role int, userId int64) error {
+	switch role {
+	case mss.RoleMember:
+		if s.MemberId == userId {
+			return nil
+		}
+	case mss.RoleMerchant:
+		if s.State != chat.SessionServing && s.State != chat.SessionClosed {
+			return chat.ErrSessionNotServing
+		}
+		if int64(s.AgentId) == userId {
+			return nil
+		}
+	}
+	return chat.ErrNotSessionUser
+}
+
+// 发送消息
+func (c *chatManagerImpl) Send(sessionId int32, senderRole int, senderId int64,
+	msgType int, content string) (*chat.Message, error) {
+	s := c.rep.GetSession(sessionId)
+	if s == nil {
+		return nil, chat.ErrNoSuchSession
+	}
+	if err := c.checkUser(s, senderRole, senderId); err != nil {
+		return nil, err
+	}
+	if s.State == chat.SessionClosed {
+		return nil, chat.ErrSessionClosed
+	}
+	content, err := checkContent(msgType, content)
+	if err != nil {
+		return nil, err
+	}
+	return c.save(s, senderRole, senderId, msgType, content)
+}
+
+// 保存消息并更新会话的未读数量
+func (c *chatManagerImpl) save(s *chat.Session, senderRole int, senderId int64,
+	msgType int, content string) (*chat.Message, error) {
+	unix := time.Now().Unix()
+	m := &chat.Message{
+		SessionId:  s.Id,
+		SenderRole: senderRole,
+		SenderId:   senderId,
+		MsgType:    msgType,
+		Content:    content,
+		CreateTime: unix,
+	}
+	id, err := c.rep.SaveMessage(m)
+	if err != nil {
+		return nil, err
+	}
+	m.Id = id
+	s.LastMsgId = id
+	s.LastMsgTime = unix
+	s.UpdateTime = unix
+	if senderRole == mss.RoleMember {
+		s.AgentUnread++
+	} else {
+		s.MemberUnread++
+	}
+	if _, err = c.rep.SaveSession(s); err == nil {
+		c.rep.PushNotify(s.Id, m.Id)
+	}
+	return m, err
+}
+
+// 检查消息内容,返回处理后的内容
+func checkContent(msgType int, content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", chat.ErrEmptyContent
+	}
+	switch msgType {
+	case chat.MsgText:
+		if utf8.RuneCountInString(content) > chat.MaxTextLength {
+			return "", chat.ErrContentTooLong
+		}
+	case chat.MsgImage:
+		if len(content) > 255 || !(strings.HasPrefix(content, "http://") ||
+			strings.HasPrefix(content, "https://") || strings.HasPrefix(content, "/")) {
+			return "", chat.ErrIncorrectImage
+		}
+	case chat.MsgProduct:
+		v := chat.ProductCard{}
+		if json.Unmarshal([]byte(content), &v) != nil || v.ItemId <= 0 {
+			return "", chat.ErrIncorrectCard
+		}
+		d, _ := json.Marshal(v)
+		return string(d), nil
+	case chat.MsgOrder:
+		v := chat.OrderCard{}
+		if json.Unmarshal([]byte(content), &v) != nil || v.OrderNo == "" {
+			return "", chat.ErrIncorrectCard
+		}
+		d, _ := json.Marshal(v)
+		return string(d), nil
+	default:
+		return "", chat.ErrNotSupportMsgType
+	}
+	return content, nil
+}
+
+// 获取消息
+func (c *chatManagerImpl) GetMessage(id int64) *chat.Message {
+	return c.rep.GetMessage(id)
+}
+
+// 获取会话中的历史消息
+func (c *chatManagerImpl) GetMessages(sessionId int32, beforeId int64, size int) []*chat.Message {
+	if size <= 0 || size > chat.MaxPageSize {
+		size = chat.MaxPageSize
+	}
+	return c.rep.GetMessages(sessionId, beforeId, size)
+}
+
+// 标记已读,已读编号只增不减
+func (c *chatManagerImpl) MarkRead(sessionId int32, role int, readerId int64, msgId int64) error {
+	s := c.rep.GetSession(sessionId)
+	if s == nil {
+		return chat.ErrNoSuchSession
+	}
+	if err := c.checkUser(s, role, readerId); err != nil {
+		return err
+	}
+	if msgId > s.LastMsgId || msgId <= 0 {
+		msgId = s.LastMsgId
+	}
+	if role == mss.RoleMember {
+		if msgId <= s.MemberReadId {
+			return nil
+		}
+		s.MemberReadId = msgId
+		s.MemberUnread = c.rep.CountUnread(s.Id, msgId, role)
+	} else {
+		if msgId <= s.AgentReadId {
+			return nil
+		}
+		s.AgentReadId = msgId
+		s.AgentUnread = c.rep.CountUnread(s.Id, msgId, role)
+	}
+	s.UpdateTime = time.Now().Unix()
+	_, err := c.rep.SaveSession(s)
+	if err == nil {
+		c.rep.PushNotify(s.Id, 0)
+	}
+	return err
+}
+
+// 结束会话
+func (c *chatManagerImpl) Close(sessionId int32, role int, operatorId int64) error {
+	s := c.rep.GetSession(sessionId)
+	if s == nil {
+		return chat.ErrNoSuchSession
+	}
+	if err := c.checkUser(s, role, operatorId); err != nil {
+		return err
+	}
+	if s.State == chat.SessionClosed {
+		return chat.ErrSessionClosed
+	}
+	agentId := s.AgentId
+	unix := time.Now().Unix()
+	s.State = chat.SessionClosed
+	s.CloseTime = unix
+	s.UpdateTime = unix
+	_, err := c.rep.SaveSession(s)
+	if err == nil {
+		c.rep.PushNotify(s.Id, 0)
+		// 客服有空闲后接入等待中的会话
+		if agentId > 0 {
+			c.assignWaiting(s.MchId)
+		}
+	}
+	return err
+}
+
+// 转接会话
+func (c *chatManagerImpl) Transfer(sessionId int32, fromAgent int32, toAgent int32) error {
+	s := c.rep.GetSession(sessionId)
+	if s == nil {
+		return chat.ErrNoSuchSession
+	}
+	if err := c.checkUser(s, mss.RoleMerchant, int64(fromAgent)); err != nil {
+		return err
+	}
+	if s.State != chat.SessionServing {
+		return chat.ErrSessionNotServing
+	}
+	a := c.rep.GetAgent(s.MchId, toAgent)
+	if a == nil {
+		return chat.ErrNoSuchAgent
+	}
+	if a.State == chat.AgentOffline || !c.checkAgent(a) {
+		return chat.ErrAgentOffline
+	}
+	if c.rep.GetAgentLoad(s.MchId)[a.PersonId] >= a.MaxSessions {
+		return chat.ErrAgentFull
+	}
+	return c.assign(s, a, "会话已转接给其他客服")
+}
+
+// 设置客服状态
+func (c *chatManagerImpl) SetAgentState(mchId int32, personId int32,
+	state int, maxSessions int) error {
+	if state != chat.AgentOffline && state != chat.AgentOnline &&
+		state != chat.AgentBusy {
+		return chat.ErrAgentState
+	}
+	a := c.rep.GetAgent(mchId, personId)
+	if a == nil {
+		a = &chat.Agent{
+			MchId:       mchId,
+			PersonId:    personId,
+			MaxSessions: chat.DefaultMaxSessions,
+		}
+	}
+	if maxSessions > 0 {
+		a.MaxSessions = maxSessions
+	}
+	a.State = state
+	a.UpdateTime = time.Now().Unix()
+	id, err := c.rep.SaveAgent(a)
+	if err != nil {
+		return err
+	}
+	a.Id = id
+	// 离线时将接待中的会话转给其他客服
+	if state == chat.AgentOffline {
+		for _, s := range c.GetAgentSessions(mchId, personId) {
+			s.State = chat.SessionWaiting
+			s.AgentId = 0
+			s.UpdateTime = a.UpdateTime
+			c.rep.SaveSession(s)
+			c.rep.PushNotify(s.Id, 0)
+		}
+	}
+	c.assignWaiting(mchId)
+	return nil
+}
+
+// 获取客服
+func (c *chatManagerImpl) GetAgent(mchId int32, personId int32) *chat.Agent {
+	return c.rep.GetAgent(mchId, personId)
+}
+
+// 获取商户的客服
+func (c *chatManagerImpl) GetAgents(mchId int32) []*chat.Agent {
+	return c.rep.GetAgents(mchId)
+}
+
+// 设置客服的检查
+func (c *chatManagerImpl) SetAgentChecker(f func(mchId int32, personId int32) bool) {
+	c.checker = f
+}
+
+// 客服是否通过检查,未设置检查时均通过
+func (c *chatManagerImpl) checkAgent(a *chat.Agent) bool {
+	return c.checker == nil || c.checker(a.MchId, a.PersonId)
+}
+
+// 按创建顺序接入等待中的会话,直到没有可用的客服
+func (c *chatManagerImpl) assignWaiting(mchId int32) {
+	for _, s := range c.GetWaitingSessions(mchId) {
+		if !c.route(s) {
+			break
+		}
+	}
+}
+
+// 分配会话给可用的客服,无可用客服时返回false
+func (c *chatManagerImpl) route(s *chat.Session) bool {
+	load := c.rep.GetAgentLoad(s.MchId)
+	var agent *chat.Agent
+	for _, a := range c.rep.GetAgents(s.MchId) {
+		if a.State != chat.AgentOnline || load[a.PersonId] >= a.MaxSessions ||
+			!c.checkAgent(a) {
+			continue
+		}
+		if agent == nil || load[a.PersonId] < load[agent.PersonId] ||
+			(load[a.PersonId] == load[agent.PersonId] && a.AssignTime < agent.AssignTime) {
+			agent = a
+		}
+	}
+	if agent == nil {
+		return false
+	}
+	return c.assign(s, agent, "客服已接入,请问有什么可以帮您?") == nil
+}
+
+// 将会话分配给客服,并发送系统提示
+func (c *chatManagerImpl) assign(s *chat.Session, a *chat.Agent, tip string) error {
+	unix := time.Now().Unix()
+	s.AgentId = a.PersonId
+	s.State = chat.SessionServing
+	s.AssignTime = unix
+	s.UpdateTime = unix
+	if _, err := c.rep.SaveSession(s); err != nil {
+		return err
+	}
+	a.AssignTime = unix
+	c.rep.SaveAgent(a)
+	_, err := c.save(s, mss.RoleSystem, 0, chat.MsgSystem, tip)
+	return err
+}
@@ -0,0 +1,338 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : inbox
+ * author : jarryliu
+ * date : 2026-10-23 10:20
+ * description : 站内信,广播消息在会员读取收件箱时同步(读扩散),
+ *               避免发送给全部会员时写入大量的数据
+ * history :
+ */
+package inbox
+
+import (
+	"encoding/json"
+	"go2o/core/domain/interface/mss/inbox"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var _ inbox.IInboxManager = new(inboxManagerImpl)
+
+type inboxManagerImpl struct {
+	rep inbox.IInboxRepo
+}
+
+func NewInboxManager(rep inbox.IInboxRepo) inbox.IInboxManager {
+	return &inboxManagerImpl{
+		rep: rep,
+	}
+}
+
+// 广播消息
+func (i *inboxManagerImpl) Broadcast(subject string, content string,
+	seg *inbox.Segment, expiresTime int64) (*inbox.Broadcast, error) {
+	unix := time.Now().Unix()
+	subject, content, err := checkMessage(subject, content, expiresTime, unix)
+	if err != nil {
+		return nil, err
+	}
+	segment, err := formatSegment(seg)
+	if err != nil {
+		return nil, err
+	}
+	b := &inbox.Broadcast{
+		Subject:     subject,
+		Content:     content,
+		Segment:     segment,
+		State:       inbox.BroadcastActive,
+		CreateTime:  unix,
+		ExpiresTime: expiresTime,
+	}
+	b.Id, err = i.rep.SaveBroadcast(b)
+	return b, err
+}
+
+// 获取广播消息
+func (i *inboxManagerImpl) GetBroadcast(id int32) *inbox.Broadcast {
+	return i.rep.GetBroadcast(id)
+}
+
+// 获取广播消息
+func (i *inboxManagerImpl) GetBroadcasts(begin, size int) (int, []*inbox.Broadcast) {
+	begin, size = checkPage(begin, size)
+	return i.rep.GetBroadcasts(begin, size)
+}
+
+// 撤回广播消息
+func (i *inboxManagerImpl) Revoke(id int32) error {
+	b := i.rep.GetBroadcast(id)
+	if b == nil {
+		return inbox.ErrNoSuchBroadcast
+	}
+	if b.State == inbox.BroadcastRevoked {
+		return inbox.ErrBroadcastRevoked
+	}
+	b.State = inbox.BroadcastRevoked
+	if _, err := i.rep.SaveBroadcast(b); err != nil {
+		return err
+	}
+	return i.rep.DeleteBroadcastMessages(id)
+}
+
+// 发送消息给会员
+func (i *inboxManagerImpl) Send(memberId int64, subject string, content string,
+	expiresTime int64) (*inbox.Message, error) {
+	unix := time.Now().Unix()
+	subject, content, err := checkMessage(subject, content, expiresTime, unix)
+	if err != nil {
+		return nil, err
+	}
+	if i.rep.GetTarget(memberId) == nil {
+		return nil, inbox.ErrNoSuchMember
+	}
+	m := &inbox.Message{
+		MemberId:    memberId,
+		Subject:     subject,
+		Content:     content,
+		CreateTime:  unix,
+		ExpiresTime: expiresTime,
+	}
+	m.Id, err = i.rep.SaveMessage(m)
+	return m, err
+}
+
+// 获取会员收件箱的消息
+func (i *inboxManagerImpl) GetMessages(memberId int64, unreadOnly bool,
+	begin, size int) (int, []*inbox.Message) {
+	i.sync(memberId)
+	begin, size = checkPage(begin, size)
+	return i.rep.GetMessages(memberId, unreadOnly, time.Now().Unix(), begin, size)
+}
+
+// 获取会员的消息
+func (i *inboxManagerImpl) GetMessage(memberId int64, id int64) *inbox.Message {
+	m := i.rep.GetMessage(id)
+	if m == nil || m.MemberId != memberId || m.Deleted == 1 {
+		return nil
+	}
+	return m
+}
+
+// 获取未读消息数量
+func (i *inboxManagerImpl) UnreadCount(memberId int64) int {
+	i.sync(memberId)
+	return i.rep.CountUnread(memberId, time.Now().Unix())
+}
+
+// 标记已读
+func (i *inboxManagerImpl) MarkRead(memberId int64, ids []int64) error {
+	if len(ids) == 0 {
+		// 全部已读时包含尚未同步的广播
+		i.sync(memberId)
+	}
+	return i.rep.MarkRead(memberId, ids, time.Now().Unix())
+}
+
+// 删除消息
+func (i *inboxManagerImpl) Delete(memberId int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return i.rep.DeleteMessages(memberId, ids)
+}
+
+// 清理已过期的消息
+func (i *inboxManagerImpl) ClearExpired() (int, error) {
+	return i.rep.DeleteExpired(time.Now().Unix())
+}
+
+// 设置会员的买家分组
+func (i *inboxManagerImpl) SetMemberGroup(memberId int64, groupId int32) error {
+	if i.rep.GetTarget(memberId) == nil {
+		return inbox.ErrNoSuchMember
+	}
+	if groupId < 0 {
+		groupId = 0
+	}
+	return i.rep.SaveMemberGroup(memberId, groupId)
+}
+
+// 设置会员的标签
+func (i *inboxManagerImpl) SetMemberTags(memberId int64, tags []string) error {
+	if i.rep.GetTarget(memberId) == nil {
+		return inbox.ErrNoSuchMember
+	}
+	tags, err := formatTags(tags)
+	if err != nil {
+		return err
+	}
+	return i.rep.SaveMemberTags(memberId, tags)
+}
+
+// 获取会员的标签
+func (i *inboxManagerImpl) GetMemberTags(memberId int64) []string {
+	return i.rep.GetMemberTags(memberId)
+}
+
+// 同步会员未接收的广播消息,先写入消息再更新同步的编号;
+// 消息按会员及广播编号唯一,并发同步时重复的消息被忽略,写入失败时不更新编号
+func (i *inboxManagerImpl) sync(memberId int64) {
+	t := i.rep.GetTarget(memberId)
+	if t == nil {
+		return
+	}
+	unix := time.Now().Unix()
+	from := i.rep.GetCursor(memberId)
+	for {
+		list := i.rep.GetBroadcastsAfter(from, unix, inbox.SyncBatchSize)
+		if len(list) == 0 {
+			break
+		}
+		for _, b := range list {
+			if b.State == inbox.BroadcastActive && b.CreateTime >= t.RegTime &&
+				matchSegment(b.Segment, t) {
+				err := i.rep.SaveBroadcastMessage(&inbox.Message{
+					MemberId:    memberId,
+					BroadcastId: b.Id,
+					Subject:     b.Subject,
+					Content:     b.Content,
+					CreateTime:  b.CreateTime,
+					ExpiresTime: b.ExpiresTime,
+				})
+				if err != nil {
+					log.Println("[ Go2o][ Inbox][ Error]: sync broadcast", b.Id,
+						"to member", memberId, "failed:", err.Error())
+					return
+				}
+			}
+		}
+		to := list[len(list)-1].Id
+		if !i.rep.ClaimCursor(memberId, from, to) {
+			break
+		}
+		if len(list) < inbox.SyncBatchSize {
+			break
+		}
+		from = to
+	}
+}
+
+func checkPage(begin, size int) (int, int) {
+	if begin < 0 {
+		begin = 0
+	}
+	if size <= 0 || size > inbox.MaxPageSize {
+		size = inbox.MaxPageSize
+	}
+	return begin, size
+}
+
+// 检查消息的标题,内容及过期时间
+func checkMessage(subject, content string, expiresTime int64, unix int64) (
+	string, string, error) {
+	subject = strings.TrimSpace(subject)
+	content = strings.TrimSpace(content)
+	if subject == "" {
+		return "", "", inbox.ErrEmptySubject
+	}
+	if utf8.RuneCountInString(subject) > inbox.MaxSubjectLength {
+		return "", "", inbox.ErrSubjectTooLong
+	}
+	if content == "" {
+		return "", "", inbox.ErrEmptyContent
+	}
+	if expiresTime != 0 && expiresTime <= unix {
+		return "", "", inbox.ErrExpiresTime
+	}
+	return subject, content, nil
+}
+
+// 检查目标分群并返回JSON,不限制目标时返回空
+func formatSegment(seg *inbox.Segment) (string, error) {
+	if seg == nil {
+		return "", nil
+	}
+	for _, arr := range [][]int32{seg.Levels, seg.Groups, seg.Regions} {
+		for _, v := range arr {
+			if v <= 0 {
+				return "", inbox.ErrIncorrectSegment
+			}
+		}
+	}
+	tags, err := formatTags(seg.Tags)
+	if err != nil {
+		return "", inbox.ErrIncorrectSegment
+	}
+	seg.Tags = tags
+	if len(seg.Levels) == 0 && len(seg.Groups) == 0 &&
+		len(seg.Regions) == 0 && len(seg.Tags) == 0 {
+		return "", nil
+	}
+	d, err := json.Marshal(seg)
+	return string(d), err
+}
+
+// 去除标签的空白及重复的标签
+func formatTags(tags []string) ([]string, error) {
+	list := []string{}
+	exists := map[string]bool{}
+	for _, v := range tags {
+		v = strings.TrimSpace(v)
+		if v == "" || utf8.RuneCountInString(v) > inbox.MaxTagLength ||
+			strings.Contains(v, ",") {
+			return nil, inbox.ErrIncorrectTag
+		}
+		if !exists[v] {
+			exists[v] = true
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+// 会员是否属于目标分群
+func matchSegment(segment string, t *inbox.Target) bool {
+	if segment == "" {
+		return true
+	}
+	seg := inbox.Segment{}
+	if json.Unmarshal([]byte(segment), &seg) != nil {
+		return false
+	}
+	if len(seg.Levels) > 0 && !containsInt32(seg.Levels, t.Level) {
+		return false
+	}
+	if len(seg.Groups) > 0 && !containsInt32(seg.Groups, t.GroupId) {
+		return false
+	}
+	if len(seg.Regions) > 0 && !containsInt32(seg.Regions, t.Province) &&
+		!containsInt32(seg.Regions, t.City) &&
+		!containsInt32(seg.Regions, t.District) {
+		return false
+	}
+	if len(seg.Tags) > 0 {
+		for _, v := range t.Tags {
+			for _, tag := range seg.Tags {
+				if v == tag {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsInt32(arr []int32, v int32) bool {
+	if v <= 0 {
+		return false
+	}
+	for _, a := range arr {
+		if a == v {
+			return true
+		}
+	}
+	return false
+}
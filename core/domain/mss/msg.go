@@ -305,7 +305,18 @@ func (s *siteMessageImpl) Send(d mss.Data) error {
 		var contentId int32 //内容编号
 		if contentId, err = s.saveContent(v); err == nil {
 			s.saveUserMsg(contentId, 0) //站内信默认未读
+			s.push()
 		}
 	}
 	return err
 }
+
+// 推送站内信到会员的设备,推送失败不影响站内信
+func (s *siteMessageImpl) push() {
+	mgr := s._rep.NotifyManager()
+	for _, v := range s.msg.To {
+		if v.Role == mss.RoleMember {
+			mgr.PushSiteMessage(int64(v.Id), s._val)
+		}
+	}
+}
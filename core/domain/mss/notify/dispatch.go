@@ -0,0 +1,303 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : dispatch.go
+ * author : jarryliu
+ * date : 2026-10-20 23:40
+ * description : 通知分发
+ * history :
+ */
+package notify
+
+import (
+	"fmt"
+	"go2o/core/domain/interface/mss/notify"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 注册通知渠道的发送器
+func (n *notifyManagerImpl) RegisterSender(channel int, s notify.ISender) {
+	n.mux.Lock()
+	n.senders[channel] = s
+	n.mux.Unlock()
+}
+
+func (n *notifyManagerImpl) getSender(channel int) notify.ISender {
+	n.mux.RLock()
+	defer n.mux.RUnlock()
+	return n.senders[channel]
+}
+
+// 获取通知项的模板
+func (n *notifyManagerImpl) GetTemplates(key string) []*notify.Template {
+	return n.rep.GetTemplates(key)
+}
+
+// 保存模板
+func (n *notifyManagerImpl) SaveTemplate(v *notify.Template) (int32, error) {
+	if n.rep.GetNotifyItem(v.Key) == nil {
+		return 0, notify.ErrNoSuchNotifyItem
+	}
+	if _, ok := notify.NotifyTypeMap[v.Channel]; !ok && v.Channel != 0 {
+		return 0, notify.ErrTemplateChannel
+	}
+	if v.Id > 0 {
+		if origin := n.rep.GetTemplate(v.Id); origin == nil || origin.Key != v.Key {
+			return 0, notify.ErrNoSuchTemplate
+		}
+	}
+	if _, err := parseTemplate(v.Subject); err != nil {
+		return 0, err
+	}
+	if _, err := parseTemplate(v.Body); err != nil {
+		return 0, err
+	}
+	v.Locale = strings.TrimSpace(v.Locale)
+	v.UpdateTime = time.Now().Unix()
+	return n.rep.SaveTemplate(v)
+}
+
+// 删除模板
+func (n *notifyManagerImpl) DeleteTemplate(id int32) error {
+	if n.rep.GetTemplate(id) == nil {
+		return notify.ErrNoSuchTemplate
+	}
+	return n.rep.DeleteTemplate(id)
+}
+
+// 获取会员的通知偏好
+func (n *notifyManagerImpl) GetPreference(memberId int64) *notify.Preference {
+	if v := n.rep.GetPreference(memberId); v != nil {
+		return v
+	}
+	return &notify.Preference{MemberId: memberId}
+}
+
+// 保存会员的通知偏好
+func (n *notifyManagerImpl) SavePreference(v *notify.Preference) error {
+	const dayMinutes = 24 * 60
+	if v.QuietStart < 0 || v.QuietStart >= dayMinutes ||
+		v.QuietEnd < 0 || v.QuietEnd >= dayMinutes {
+		return notify.ErrQuietHours
+	}
+	v.Locale = strings.TrimSpace(v.Locale)
+	v.UpdateTime = time.Now().Unix()
+	return n.rep.SavePreference(v)
+}
+
+// 获取会员对通知项设置的渠道
+func (n *notifyManagerImpl) GetEventPrefs(memberId int64) []*notify.EventPref {
+	return n.rep.GetEventPrefs(memberId)
+}
+
+// 设置会员接收通知项的渠道,channels为空时不接收
+func (n *notifyManagerImpl) SaveEventPref(memberId int64, key string, channels []int) error {
+	item := n.rep.GetNotifyItem(key)
+	if item == nil {
+		return notify.ErrNoSuchNotifyItem
+	}
+	if item.ReadonlyBy {
+		return notify.ErrChannelReadonly
+	}
+	arr := []string{}
+	for _, c := range channels {
+		if _, ok := notify.NotifyTypeMap[c]; !ok {
+			return notify.ErrTemplateChannel
+		}
+		arr = append(arr, strconv.Itoa(c))
+	}
+	v := n.rep.GetEventPref(memberId, key)
+	if v == nil {
+		v = &notify.EventPref{MemberId: memberId, Key: key}
+	}
+	v.Channels = strings.Join(arr, ",")
+	v.UpdateTime = time.Now().Unix()
+	_, err := n.rep.SaveEventPref(v)
+	return err
+}
+
+// 查询会员的通知发送记录
+func (n *notifyManagerImpl) QueryDeliveries(memberId int64, begin, size int) (int, []*notify.Delivery) {
+	return n.rep.QueryDeliveries(memberId, begin, size)
+}
+
+// 获取语言的查找顺序,如:zh-CN,zh,默认语言
+func localeChain(locale string) []string {
+	arr := []string{}
+	if locale != "" {
+		arr = append(arr, locale)
+		if i := strings.IndexAny(locale, "-_"); i > 0 {
+			arr = append(arr, locale[:i])
+		}
+	}
+	return append(arr, "")
+}
+
+// 获取渠道的模板,优先使用渠道及语言匹配的模板,未设置模板时使用通知项的内容
+func (n *notifyManagerImpl) resolveTemplate(item *notify.NotifyItem,
+	channel int, locale string) (subject string, body string) {
+	list := n.rep.GetTemplates(item.Key)
+	for _, l := range localeChain(locale) {
+		for _, c := range []int{channel, 0} {
+			for _, t := range list {
+				if t.Enabled == 1 && t.Channel == c && strings.EqualFold(t.Locale, l) {
+					return t.Subject, t.Body
+				}
+			}
+		}
+	}
+	subject = item.Subject
+	if subject == "" {
+		subject = item.Key
+	}
+	return subject, item.Content
+}
+
+// 获取发送渠道,返回的muted为true时表示会员已关闭该通知
+func (n *notifyManagerImpl) resolveChannels(item *notify.NotifyItem,
+	memberId int64, pref *notify.Preference) (channels []int, muted bool) {
+	list := append([]int{item.NotifyBy}, item.Fallback...)
+	if memberId > 0 && !item.ReadonlyBy {
+		if ep := n.rep.GetEventPref(memberId, item.Key); ep != nil {
+			if list = ep.ChannelList(); len(list) == 0 {
+				return nil, true
+			}
+		}
+	}
+	quiet := !item.Urgent && pref != nil && pref.InQuiet(time.Now())
+	channels = []int{}
+	for _, c := range list {
+		if quiet && notify.Intrusive(c) {
+			continue
+		}
+		exists := false
+		for _, v := range channels {
+			if v == c {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			channels = append(channels, c)
+		}
+	}
+	// 免打扰时段内没有可用的渠道时,改为发送站内信
+	if quiet && len(channels) == 0 && memberId > 0 {
+		channels = append(channels, notify.TypeSiteMessage)
+	}
+	return channels, false
+}
+
+// 通过渠道发送
+func (n *notifyManagerImpl) sendBy(item *notify.NotifyItem, channel int,
+	to *notify.Recipient, d *notify.Delivery, data map[string]interface{}) error {
+	s := n.getSender(channel)
+	if s == nil {
+		return notify.ErrNoSender
+	}
+	subject, body := n.resolveTemplate(item, channel, d.Locale)
+	subject, err := Render(subject, data)
+	if err == nil {
+		body, err = Render(body, data)
+	}
+	if err == nil {
+		err = s.Send(item.Key, to, subject, body, data)
+	}
+	if err == nil {
+		d.Subject = subject
+		d.Body = body
+		if item.Sensitive() {
+			d.Body = notify.MaskedContent
+		}
+	}
+	return err
+}
+
+// 发送通知,按会员偏好及免打扰时段选择渠道,渠道发送失败时使用下一个渠道
+func (n *notifyManagerImpl) Dispatch(key string, to *notify.Recipient,
+	data map[string]interface{}) (*notify.Delivery, error) {
+	item := n.rep.GetNotifyItem(key)
+	if item == nil {
+		return nil, notify.ErrNoSuchNotifyItem
+	}
+	d := &notify.Delivery{
+		Key:        key,
+		MemberId:   to.MemberId,
+		Locale:     to.Locale,
+		State:      notify.DeliveryFailed,
+		CreateTime: time.Now().Unix(),
+	}
+	var pref *notify.Preference
+	if to.MemberId > 0 {
+		pref = n.rep.GetPreference(to.MemberId)
+	}
+	if d.Locale == "" && pref != nil {
+		d.Locale = pref.Locale
+	}
+	channels, muted := n.resolveChannels(item, to.MemberId, pref)
+	if muted {
+		d.State = notify.DeliveryMuted
+		_, err := n.rep.SaveDelivery(d)
+		return d, err
+	}
+	var err error = notify.ErrNoChannel
+	trace := []string{}
+	for _, c := range channels {
+		if err = n.sendBy(item, c, to, d, data); err == nil {
+			d.Channel = c
+			d.State = notify.DeliverySuccess
+			trace = append(trace, fmt.Sprintf("%d:ok", c))
+			break
+		}
+		trace = append(trace, fmt.Sprintf("%d:%s", c, err.Error()))
+	}
+	d.Trace = strings.Join(trace, ";")
+	d.Id, _ = n.rep.SaveDelivery(d)
+	if d.State != notify.DeliverySuccess {
+		return d, err
+	}
+	return d, nil
+}
+
+// 推送站内信到会员的设备,会员关闭推送或处于免打扰时段时不推送
+func (n *notifyManagerImpl) PushSiteMessage(memberId int64, msg *notify.SiteMessage) error {
+	item := n.rep.GetNotifyItem("站内信")
+	if item == nil || memberId <= 0 {
+		return nil
+	}
+	pref := n.rep.GetPreference(memberId)
+	channels, muted := n.resolveChannels(item, memberId, pref)
+	push := false
+	for _, c := range channels {
+		push = push || c == notify.TypePushMessage
+	}
+	if muted || !push {
+		return nil
+	}
+	d := &notify.Delivery{
+		Key:        item.Key,
+		MemberId:   memberId,
+		Channel:    notify.TypePushMessage,
+		State:      notify.DeliveryFailed,
+		CreateTime: time.Now().Unix(),
+	}
+	if pref != nil {
+		d.Locale = pref.Locale
+	}
+	err := n.sendBy(item, notify.TypePushMessage, &notify.Recipient{
+		MemberId: memberId,
+		Locale:   d.Locale,
+	}, d, map[string]interface{}{
+		"subject": msg.Subject,
+		"message": msg.Message,
+	})
+	if err == nil {
+		d.State = notify.DeliverySuccess
+		d.Trace = fmt.Sprintf("%d:ok", notify.TypePushMessage)
+	} else {
+		d.Trace = fmt.Sprintf("%d:%s", notify.TypePushMessage, err.Error())
+	}
+	d.Id, _ = n.rep.SaveDelivery(d)
+	return err
+}
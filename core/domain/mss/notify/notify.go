@@ -11,7 +11,7 @@ package notify
 import (
 	"go2o/core/domain/interface/mss/notify"
 	"go2o/core/domain/interface/valueobject"
-	"go2o/core/infrastructure/tool/sms"
+	"sync"
 )
 
 var _ notify.INotifyManager = new(notifyManagerImpl)
@@ -19,14 +19,19 @@ var _ notify.INotifyManager = new(notifyManagerImpl)
 type notifyManagerImpl struct {
 	rep       notify.INotifyRepo
 	valueRepo valueobject.IValueRepo
+	senders   map[int]notify.ISender
+	mux       sync.RWMutex
 }
 
 func NewNotifyManager(rep notify.INotifyRepo,
 	valueRepo valueobject.IValueRepo) notify.INotifyManager {
-	return &notifyManagerImpl{
+	n := &notifyManagerImpl{
 		rep:       rep,
 		valueRepo: valueRepo,
+		senders:   map[int]notify.ISender{},
 	}
+	n.senders[notify.TypePhoneMessage] = &phoneSender{n: n}
+	return n
 }
 
 // 获取所有的通知项
@@ -48,19 +53,46 @@ func (n *notifyManagerImpl) SaveNotifyItem(item *notify.NotifyItem) error {
 	v.Content = item.Content
 	v.TplId = item.TplId
 	v.NotifyBy = item.NotifyBy
+	v.Subject = item.Subject
+	v.Fallback = item.Fallback
 	return n.rep.SaveNotifyItem(v)
 }
 
 // 发送手机短信
 func (n *notifyManagerImpl) SendPhoneMessage(phone string,
 	msg notify.PhoneMessage, data map[string]interface{}) error {
-	i, api := n.valueRepo.GetDefaultSmsApiPerm()
-	return sms.SendSms(i, api.ApiKey, api.ApiSecret, phone,
-		api.ApiUrl, api.Encoding, api.SuccessChar, string(msg), data)
+	return n.sendSms("", phone, string(msg), data)
 }
 
 // 发送邮件
 func (n *notifyManagerImpl) SendEmail(to string,
 	msg *notify.MailMessage, data map[string]interface{}) error {
-	return nil
+	s := n.getSender(notify.TypeEmailMessage)
+	if s == nil {
+		return notify.ErrNoSender
+	}
+	subject, err := Render(msg.Subject, data)
+	if err != nil {
+		return err
+	}
+	body, err := Render(msg.Body, data)
+	if err != nil {
+		return err
+	}
+	return s.Send("", &notify.Recipient{Email: to}, subject, body, data)
+}
+
+var _ notify.ISender = new(phoneSender)
+
+// 短信发送器
+type phoneSender struct {
+	n *notifyManagerImpl
+}
+
+func (p *phoneSender) Send(key string, to *notify.Recipient, subject string,
+	body string, data map[string]interface{}) error {
+	if to.Phone == "" {
+		return notify.ErrNoAddress
+	}
+	return p.n.sendSms(key, to.Phone, body, data)
 }
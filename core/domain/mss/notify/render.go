@@ -0,0 +1,54 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : render.go
+ * author : jarryliu
+ * date : 2026-10-20 23:30
+ * description : 通知模板渲染
+ * history :
+ */
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"go2o/core/domain/interface/mss/notify"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var tagReg = regexp.MustCompile("\\{([^\\{\\}]+)\\}")
+
+// 解析模板,模板包含"{{"时按text/template语法解析
+func parseTemplate(text string) (*template.Template, error) {
+	if !strings.Contains(text, "{{") {
+		return nil, nil
+	}
+	t, err := template.New("").Parse(text)
+	if err != nil {
+		return nil, notify.ErrTemplateSyntax
+	}
+	return t, nil
+}
+
+// 渲染模板,支持{{if .vip}}、{{range .items}}等语法,
+// 渲染后再替换旧版本的{tag}标签
+func Render(text string, data map[string]interface{}) (string, error) {
+	t, err := parseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	if t != nil {
+		buf := bytes.NewBuffer(nil)
+		if err = t.Execute(buf, data); err != nil {
+			return "", err
+		}
+		text = buf.String()
+	}
+	return tagReg.ReplaceAllStringFunc(text, func(k string) string {
+		if v, ok := data[k[1:len(k)-1]]; ok {
+			return fmt.Sprint(v)
+		}
+		return k
+	}), nil
+}
@@ -0,0 +1,85 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : sms.go
+ * author : jarryliu
+ * date : 2026-10-21 11:30
+ * description : 短信发送,按设置的顺序在服务商间故障转移,并记录送达状态
+ * history :
+ */
+package notify
+
+import (
+	"go2o/core/domain/interface/mss/notify"
+	"go2o/core/infrastructure/tool/sms"
+	"net/url"
+	"time"
+)
+
+// 发送短信并保存发送记录,key为通知项,用于匹配服务商的模板;
+// 敏感通知的发送记录不保存短信内容
+func (n *notifyManagerImpl) sendSms(key string, phone string,
+	content string, data map[string]interface{}) error {
+	content, err := Render(content, data)
+	if err != nil {
+		return err
+	}
+	r := sms.NewRouterFromSet(n.valueRepo.GetSmsApiSet())
+	ret, err := r.Send(key, phone, content, data)
+	unix := time.Now().Unix()
+	v := &notify.SmsMessage{
+		Key:        key,
+		Phone:      phone,
+		Provider:   ret.Provider,
+		MsgId:      ret.MsgId,
+		Content:    content,
+		State:      notify.SmsSubmitted,
+		Trace:      ret.Trace,
+		CreateTime: unix,
+		UpdateTime: unix,
+	}
+	if err != nil {
+		v.State = notify.SmsFailed
+	}
+	if key != "" {
+		if item := n.rep.GetNotifyItem(key); item != nil && item.Sensitive() {
+			v.Content = notify.MaskedContent
+		}
+	}
+	n.rep.SaveSmsMessage(v)
+	return err
+}
+
+// 处理短信服务商的状态报告,返回更新的短信数量
+func (n *notifyManagerImpl) HandleSmsReceipt(provider int, values url.Values) (int, error) {
+	perm, ok := n.valueRepo.GetSmsApiSet()[provider]
+	if !ok {
+		return 0, notify.ErrNoSuchSmsProvider
+	}
+	list, err := sms.ParseReceipt(provider, perm, values)
+	if err != nil {
+		return 0, err
+	}
+	i := 0
+	for _, r := range list {
+		v := n.rep.GetSmsMessage(provider, r.MsgId)
+		if v == nil {
+			continue
+		}
+		v.State = notify.SmsUndelivered
+		if r.Delivered {
+			v.State = notify.SmsDelivered
+		}
+		v.ReportStatus = r.Status
+		v.UpdateTime = r.ReportTime
+		if _, err = n.rep.SaveSmsMessage(v); err != nil {
+			return i, err
+		}
+		i++
+	}
+	return i, nil
+}
+
+// 查询短信发送记录,phone为空时查询所有
+func (n *notifyManagerImpl) QuerySmsMessages(phone string, begin, size int) (int, []*notify.SmsMessage) {
+	return n.rep.QuerySmsMessages(phone, begin, size)
+}
@@ -0,0 +1,58 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : sender.go
+ * author : jarryliu
+ * date : 2026-10-20 23:50
+ * description : 站内信及邮件的通知发送器
+ * history :
+ */
+package mss
+
+import (
+	"go2o/core/domain/interface/mss"
+	"go2o/core/domain/interface/mss/notify"
+	"time"
+)
+
+var _ notify.ISender = new(siteSender)
+var _ notify.ISender = new(mailSender)
+
+// 站内信发送器,消息写入会员的收件箱
+type siteSender struct {
+	rep mss.IMssRepo
+}
+
+func NewSiteSender(rep mss.IMssRepo) notify.ISender {
+	return &siteSender{rep: rep}
+}
+
+func (s *siteSender) Send(key string, to *notify.Recipient, subject string,
+	body string, data map[string]interface{}) error {
+	if to.MemberId <= 0 {
+		return notify.ErrNoAddress
+	}
+	_, err := s.rep.InboxManager().Send(to.MemberId, subject, body, 0)
+	return err
+}
+
+// 邮件发送器,邮件加入到发送队列
+type mailSender struct {
+	rep mss.IMssRepo
+}
+
+func NewMailSender(rep mss.IMssRepo) notify.ISender {
+	return &mailSender{rep: rep}
+}
+
+func (m *mailSender) Send(key string, to *notify.Recipient, subject string,
+	body string, data map[string]interface{}) error {
+	if to.Email == "" {
+		return notify.ErrNoAddress
+	}
+	return m.rep.JoinMailTaskToQueen(&mss.MailTask{
+		SendTo:     to.Email,
+		Subject:    subject,
+		Body:       body,
+		CreateTime: time.Now().Unix(),
+	})
+}
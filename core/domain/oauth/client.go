@@ -0,0 +1,188 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : client
+ * author : jarryliu
+ * date : 2026-10-19 21:45
+ * description : 第三方应用
+ * history :
+ */
+package oauth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"go2o/core/domain/interface/oauth"
+	"go2o/core/infrastructure/domain"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var _ oauth.IClient = new(clientImpl)
+
+type clientImpl struct {
+	value *oauth.Client
+	rep   oauth.IOAuthRepo
+}
+
+func NewClient(v *oauth.Client, rep oauth.IOAuthRepo) oauth.IClient {
+	return &clientImpl{
+		value: v,
+		rep:   rep,
+	}
+}
+
+// 获取聚合根编号
+func (c *clientImpl) GetAggregateRootId() int32 {
+	return c.value.Id
+}
+
+// 获取值
+func (c *clientImpl) GetValue() oauth.Client {
+	return *c.value
+}
+
+// 设置值,应用编号及密钥不能修改
+func (c *clientImpl) SetValue(v *oauth.Client) error {
+	v.Name = strings.TrimSpace(v.Name)
+	if v.Name == "" {
+		return oauth.ErrClientName
+	}
+	uris := []string{}
+	for _, s := range strings.Split(v.RedirectUris, "\n") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		if !checkUri(s) {
+			return oauth.ErrRedirectUri
+		}
+		uris = append(uris, s)
+	}
+	if len(uris) == 0 {
+		return oauth.ErrRedirectUri
+	}
+	scopes := strings.Fields(v.Scopes)
+	for _, s := range scopes {
+		if _, ok := oauth.ScopeNames[s]; !ok {
+			return oauth.ErrScope
+		}
+	}
+	c.value.Name = v.Name
+	c.value.Logo = v.Logo
+	c.value.RedirectUris = strings.Join(uris, "\n")
+	c.value.Scopes = strings.Join(scopes, " ")
+	c.value.Public = v.Public
+	c.value.Trusted = v.Trusted
+	c.value.State = v.State
+	if c.value.Id <= 0 {
+		c.value.MchId = v.MchId
+	}
+	return nil
+}
+
+// 回调地址须为绝对地址,且不能包含#片段;
+// 手机应用可使用自定义协议,如:com.example.app:/callback
+func checkUri(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" || u.Fragment != "" {
+		return false
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return u.Host != ""
+	}
+	return true
+}
+
+// 是否启用
+func (c *clientImpl) Enabled() bool {
+	return c.value.State == oauth.ClientEnabled
+}
+
+// 重置密钥,返回新的密钥;密钥仅保存散列值,只能在此时获取
+func (c *clientImpl) ResetSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	secret := hex.EncodeToString(b)
+	hash, err := domain.HashPwd(secret)
+	if err != nil {
+		return "", err
+	}
+	c.value.Secret = hash
+	return secret, nil
+}
+
+// 校验密钥
+func (c *clientImpl) VerifySecret(secret string) bool {
+	ok, _ := domain.VerifyPwd(c.value.Secret, secret)
+	return ok
+}
+
+// 校验回调地址,须与登记的地址完全一致
+func (c *clientImpl) CheckRedirectUri(uri string) error {
+	for _, s := range strings.Split(c.value.RedirectUris, "\n") {
+		if s != "" && s == uri {
+			return nil
+		}
+	}
+	return oauth.ErrRedirectUri
+}
+
+// 校验授权范围,返回去重后的范围
+func (c *clientImpl) CheckScope(scope string) (string, error) {
+	arr := []string{}
+	for _, s := range strings.Fields(scope) {
+		if _, ok := oauth.ScopeNames[s]; !ok {
+			return "", oauth.ErrScope
+		}
+		// 应用未限制授权范围时,可申请所有范围
+		if c.value.Scopes != "" && !oauth.HasScope(c.value.Scopes, s) {
+			return "", oauth.ErrScope
+		}
+		if !oauth.HasScope(strings.Join(arr, " "), s) {
+			arr = append(arr, s)
+		}
+	}
+	if len(arr) == 0 {
+		return "", oauth.ErrScope
+	}
+	return strings.Join(arr, " "), nil
+}
+
+// 校验PKCE参数,公开应用必须使用S256
+func (c *clientImpl) CheckPkce(challenge string, method string) error {
+	if challenge == "" {
+		if c.value.Public == 1 {
+			return oauth.ErrPkceRequired
+		}
+		return nil
+	}
+	if method == "" {
+		method = oauth.PkcePlain
+	}
+	if method == oauth.PkceS256 ||
+		(method == oauth.PkcePlain && c.value.Public != 1) {
+		return nil
+	}
+	return oauth.ErrPkceRequired
+}
+
+// 保存
+func (c *clientImpl) Save() (int32, error) {
+	unix := time.Now().Unix()
+	if c.value.Id <= 0 {
+		c.value.CreateTime = unix
+		if c.value.ClientId == "" {
+			b := make([]byte, 8)
+			rand.Read(b)
+			c.value.ClientId = hex.EncodeToString(b)
+		}
+	}
+	c.value.UpdateTime = unix
+	id, err := c.rep.SaveClient(c.value)
+	if err == nil {
+		c.value.Id = id
+	}
+	return id, err
+}
@@ -1191,6 +1191,27 @@ func (o *subOrderImpl) Ship(spId int32, spOrder string) error {
 	return err
 }
 
+// 同城配送送达,由商户的配送员配送,不生成快递发货单
+func (o *subOrderImpl) LocalDelivered() error {
+	if o.value.State < order.StatAwaitingShipment {
+		return order.ErrOrderNotPickUp
+	}
+	if o.value.State >= order.StatShipped {
+		return order.ErrOrderShipped
+	}
+	for _, v := range o.Items() {
+		v.IsShipped = 1
+	}
+	o.value.State = order.StatShipped
+	o.value.UpdateTime = time.Now().Unix()
+	err := o.saveSubOrder()
+	if err == nil {
+		err = o.saveOrderItems()
+		o.AppendLog(order.LogSetup, true, "{shipped}")
+	}
+	return err
+}
+
 func (o *subOrderImpl) createShipmentOrder(items []*order.SubOrderItem) shipment.IShipmentOrder {
 	if items == nil || len(items) == 0 {
 		return nil
@@ -0,0 +1,168 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : manager
+ * author : jarryliu
+ * date : 2026-10-20 15:30
+ * description : 风控管理,按顺序执行规则,取最严格的决策
+ * history :
+ */
+package risk
+
+import (
+	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/member"
+	"go2o/core/domain/interface/risk"
+	"strings"
+	"time"
+)
+
+var _ risk.IRiskManager = new(riskManagerImpl)
+
+type riskManagerImpl struct {
+	rep   risk.IRiskRepo
+	rules []risk.IRule
+}
+
+func NewRiskManager(rep risk.IRiskRepo, memberRepo member.IMemberRepo) risk.IRiskManager {
+	m := &riskManagerImpl{rep: rep}
+	m.rules = []risk.IRule{
+		&blacklistRule{manager: m},
+		&velocityRule{rep: rep},
+		&coolingRule{memberRepo: memberRepo},
+		&inviteFarmRule{memberRepo: memberRepo},
+	}
+	return m
+}
+
+// 获取设置
+func (r *riskManagerImpl) GetSettings() risk.Settings {
+	return r.rep.GetSettings()
+}
+
+// 保存设置
+func (r *riskManagerImpl) SaveSettings(v *risk.Settings) error {
+	return r.rep.SaveSettings(v)
+}
+
+// 评估操作并记录
+func (r *riskManagerImpl) Evaluate(c *risk.Context) (*risk.Record, error) {
+	s := r.rep.GetSettings()
+	v := &risk.Record{
+		Operation:  c.Operation,
+		MemberId:   c.MemberId,
+		Amount:     c.Amount,
+		Ip:         c.Ip,
+		DeviceId:   c.DeviceId,
+		Decision:   risk.DecisionAllow,
+		CreateTime: time.Now().Unix(),
+	}
+	rules := []string{}
+	messages := []string{}
+	for _, rule := range r.rules {
+		if hit := rule.Evaluate(c, &s); hit != nil {
+			rules = append(rules, hit.Rule)
+			messages = append(messages, hit.Message)
+			if hit.Decision > v.Decision {
+				v.Decision = hit.Decision
+			}
+		}
+	}
+	v.Rules = strings.Join(rules, ",")
+	v.Message = strings.Join(messages, ";")
+	id, err := r.rep.SaveRecord(v)
+	v.Id = id
+	return v, err
+}
+
+// 关联业务编号
+func (r *riskManagerImpl) BindRecord(id int64, refId string) error {
+	v := r.rep.GetRecord(id)
+	if v == nil {
+		return risk.ErrNoSuchRecord
+	}
+	v.RefId = refId
+	_, err := r.rep.SaveRecord(v)
+	return err
+}
+
+// 将需人工审核的操作加入审核队列,data为审核通过后执行操作的参数
+func (r *riskManagerImpl) SubmitReview(rc *risk.Record, data string) (int64, error) {
+	return r.rep.SaveReview(&risk.Review{
+		RecordId:   rc.Id,
+		Operation:  rc.Operation,
+		MemberId:   rc.MemberId,
+		Amount:     rc.Amount,
+		Data:       data,
+		State:      enum.ReviewAwaiting,
+		CreateTime: time.Now().Unix(),
+	})
+}
+
+// 审核操作,仅等待审核的操作可审核,返回审核的操作;
+// 多人同时审核时,仅一人可审核成功
+func (r *riskManagerImpl) Review(id int64, pass bool, remark string) (*risk.Review, error) {
+	v := r.rep.GetReview(id)
+	if v == nil {
+		return nil, risk.ErrNoSuchReview
+	}
+	if v.State != enum.ReviewAwaiting {
+		return nil, risk.ErrReviewed
+	}
+	v.State = enum.ReviewReject
+	if pass {
+		v.State = enum.ReviewPass
+	}
+	v.Remark = remark
+	v.ReviewTime = time.Now().Unix()
+	ok, err := r.rep.UpdateReviewState(id, v.State, remark, v.ReviewTime)
+	if err == nil && !ok {
+		err = risk.ErrReviewed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (r *riskManagerImpl) checkListKind(kind string) error {
+	for _, v := range risk.ListKinds {
+		if v == kind {
+			return nil
+		}
+	}
+	return risk.ErrListKind
+}
+
+// 加入黑名单
+func (r *riskManagerImpl) AddBlackItem(kind string, value string, remark string) error {
+	if err := r.checkListKind(kind); err != nil {
+		return err
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return risk.ErrListValue
+	}
+	if r.IsBlacklisted(kind, value) {
+		return nil
+	}
+	_, err := r.rep.SaveBlackItem(&risk.BlackItem{
+		Kind:       kind,
+		Value:      value,
+		Remark:     remark,
+		CreateTime: time.Now().Unix(),
+	})
+	return err
+}
+
+// 移出黑名单
+func (r *riskManagerImpl) RemoveBlackItem(kind string, value string) error {
+	if err := r.checkListKind(kind); err != nil {
+		return err
+	}
+	return r.rep.DeleteBlackItem(kind, strings.TrimSpace(value))
+}
+
+// 是否在黑名单中
+func (r *riskManagerImpl) IsBlacklisted(kind string, value string) bool {
+	return r.rep.GetBlackItem(kind, value) != nil
+}
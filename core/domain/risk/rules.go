@@ -0,0 +1,150 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : rules
+ * author : jarryliu
+ * date : 2026-10-20 15:40
+ * description : 风控规则
+ * history :
+ */
+package risk
+
+import (
+	"fmt"
+	"go2o/core/domain/interface/member"
+	"go2o/core/domain/interface/risk"
+	"time"
+)
+
+var _ risk.IRule = new(blacklistRule)
+var _ risk.IRule = new(velocityRule)
+var _ risk.IRule = new(coolingRule)
+var _ risk.IRule = new(inviteFarmRule)
+
+// 黑名单
+type blacklistRule struct {
+	manager risk.IRiskManager
+}
+
+func (b *blacklistRule) Name() string {
+	return "blacklist"
+}
+
+func (b *blacklistRule) Evaluate(c *risk.Context, s *risk.Settings) *risk.Hit {
+	values := map[string]string{
+		risk.ListBankCard: c.BankCard,
+		risk.ListPhone:    c.Phone,
+		risk.ListIp:       c.Ip,
+		risk.ListDevice:   c.DeviceId,
+	}
+	for _, kind := range risk.ListKinds {
+		if v := values[kind]; v != "" && b.manager.IsBlacklisted(kind, v) {
+			return &risk.Hit{Rule: b.Name(), Decision: risk.DecisionDeny,
+				Message: fmt.Sprintf("%s in blacklist", kind)}
+		}
+	}
+	return nil
+}
+
+// 操作频率,超过限制转人工审核,超过两倍拒绝
+type velocityRule struct {
+	rep risk.IRiskRepo
+}
+
+func (v *velocityRule) Name() string {
+	return "velocity"
+}
+
+func (v *velocityRule) Evaluate(c *risk.Context, s *risk.Settings) *risk.Hit {
+	if s.VelocityWindow <= 0 {
+		return nil
+	}
+	since := time.Now().Unix() - s.VelocityWindow
+	checks := []struct {
+		field string
+		value interface{}
+		limit int
+	}{
+		{risk.FieldMember, c.MemberId, s.MemberVelocity},
+		{risk.FieldIp, c.Ip, s.IpVelocity},
+		{risk.FieldDevice, c.DeviceId, s.DeviceVelocity},
+	}
+	var hit *risk.Hit
+	for _, ck := range checks {
+		if ck.limit <= 0 || ck.value == "" || ck.value == int64(0) {
+			continue
+		}
+		n := v.rep.CountRecords(c.Operation, ck.field, ck.value, since)
+		if n < ck.limit {
+			continue
+		}
+		decision := risk.DecisionReview
+		if n >= ck.limit*2 {
+			decision = risk.DecisionDeny
+		}
+		if hit == nil || decision > hit.Decision {
+			hit = &risk.Hit{Rule: v.Name(), Decision: decision,
+				Message: fmt.Sprintf("%s exceed %d times", ck.field, ck.limit)}
+		}
+	}
+	return hit
+}
+
+// 新会员冷静期,冷静期内提现及转账转人工审核
+type coolingRule struct {
+	memberRepo member.IMemberRepo
+}
+
+func (r *coolingRule) Name() string {
+	return "cooling"
+}
+
+func (r *coolingRule) Evaluate(c *risk.Context, s *risk.Settings) *risk.Hit {
+	if c.Operation == risk.OpRegister || s.CoolingPeriod <= 0 ||
+		c.Amount <= s.CoolingAmount {
+		return nil
+	}
+	m := r.memberRepo.GetMember(c.MemberId)
+	if m != nil && time.Now().Unix()-m.GetValue().RegTime < s.CoolingPeriod {
+		return &risk.Hit{Rule: r.Name(), Decision: risk.DecisionReview,
+			Message: "new member in cooling period"}
+	}
+	return nil
+}
+
+// 邀请刷单,注册时检查邀请人短时间内邀请的会员数量及同一IP的受邀会员数量;
+// 提现及转账时检查会员短时间内邀请的会员数量
+type inviteFarmRule struct {
+	memberRepo member.IMemberRepo
+}
+
+func (r *inviteFarmRule) Name() string {
+	return "invite_farm"
+}
+
+func (r *inviteFarmRule) Evaluate(c *risk.Context, s *risk.Settings) *risk.Hit {
+	inviterId := c.MemberId
+	if c.Operation == risk.OpRegister {
+		inviterId = c.InviterId
+	}
+	if inviterId <= 0 {
+		return nil
+	}
+	m := r.memberRepo.GetMember(inviterId)
+	if m == nil {
+		return nil
+	}
+	iv := m.Invitation()
+	if c.Operation == risk.OpRegister && s.SameIpInviteLimit > 0 &&
+		iv.InvitationNumByIp(c.Ip) >= s.SameIpInviteLimit {
+		return &risk.Hit{Rule: r.Name(), Decision: risk.DecisionDeny,
+			Message: "too many invitees from same ip"}
+	}
+	if s.InviteLimit > 0 && s.InviteWindow > 0 {
+		since := time.Now().Unix() - s.InviteWindow
+		if iv.InvitationNumSince(since) >= s.InviteLimit {
+			return &risk.Hit{Rule: r.Name(), Decision: risk.DecisionReview,
+				Message: "too many invitees in short time"}
+		}
+	}
+	return nil
+}
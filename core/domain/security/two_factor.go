@@ -0,0 +1,224 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : two_factor
+ * author : jarryliu
+ * date : 2026-10-19 19:45
+ * description : 两步验证
+ * history :
+ */
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"go2o/core/domain/interface/security"
+	"go2o/core/infrastructure/gen"
+	"go2o/core/infrastructure/totp"
+	"strings"
+	"time"
+)
+
+var _ security.ITwoFactor = new(twoFactorImpl)
+
+type twoFactorImpl struct {
+	value *security.TwoFactor
+	rep   security.ITwoFactorRepo
+}
+
+func NewTwoFactor(v *security.TwoFactor, rep security.ITwoFactorRepo) security.ITwoFactor {
+	return &twoFactorImpl{
+		value: v,
+		rep:   rep,
+	}
+}
+
+// 获取聚合根编号
+func (t *twoFactorImpl) GetAggregateRootId() int32 {
+	return t.value.Id
+}
+
+// 获取值
+func (t *twoFactorImpl) GetValue() security.TwoFactor {
+	return *t.value
+}
+
+// 是否已启用
+func (t *twoFactorImpl) Enabled() bool {
+	return t.value.State == security.TwoFactorEnabled
+}
+
+func (t *twoFactorImpl) save() error {
+	unix := time.Now().Unix()
+	if t.value.CreateTime == 0 {
+		t.value.CreateTime = unix
+	}
+	t.value.UpdateTime = unix
+	id, err := t.rep.SaveTwoFactor(t.value)
+	if err == nil {
+		t.value.Id = id
+	}
+	return err
+}
+
+// 生成密钥并返回验证器应用绑定的地址,验证后启用
+func (t *twoFactorImpl) Enroll(issuer string, account string) (string, error) {
+	if t.Enabled() {
+		return "", security.ErrTwoFactorEnabled
+	}
+	t.value.Secret = totp.NewSecret()
+	t.value.State = security.TwoFactorPending
+	t.value.LastStep = 0
+	t.value.RecoveryCodes = ""
+	t.value.FailTimes = 0
+	if err := t.save(); err != nil {
+		return "", err
+	}
+	return totp.ProvisioningUri(issuer, account, t.value.Secret), nil
+}
+
+// 生成绑定地址的二维码(PNG)
+func (t *twoFactorImpl) QrCode(issuer string, account string) ([]byte, error) {
+	if t.value.State != security.TwoFactorPending {
+		return nil, security.ErrTwoFactorNotEnrolled
+	}
+	uri := totp.ProvisioningUri(issuer, account, t.value.Secret)
+	return gen.BuildQrCodeForUrl(uri, 5), nil
+}
+
+// 校验验证码或恢复码,连续失败过多时锁定一段时间,以防止穷举
+func (t *twoFactorImpl) attempt(check func() bool) error {
+	unix := time.Now().Unix()
+	if t.value.LockTime > unix {
+		return security.ErrTwoFactorLocked
+	}
+	if check() {
+		t.value.FailTimes = 0
+		return nil
+	}
+	t.value.FailTimes++
+	if t.value.FailTimes >= security.TwoFactorMaxFails {
+		t.value.FailTimes = 0
+		t.value.LockTime = unix + security.TwoFactorLockSeconds
+	}
+	if err := t.save(); err != nil {
+		return err
+	}
+	return security.ErrTwoFactorCode
+}
+
+// 校验验证码,同一验证码仅能使用一次
+func (t *twoFactorImpl) checkCode(code string) bool {
+	step, ok := totp.Validate(t.value.Secret, code, time.Now(), security.TwoFactorSkew)
+	if !ok || step <= t.value.LastStep {
+		return false
+	}
+	t.value.LastStep = step
+	return true
+}
+
+// 使用验证码启用,并返回恢复码
+func (t *twoFactorImpl) Activate(code string) ([]string, error) {
+	if t.Enabled() {
+		return nil, security.ErrTwoFactorEnabled
+	}
+	if t.value.State != security.TwoFactorPending {
+		return nil, security.ErrTwoFactorNotEnrolled
+	}
+	if err := t.attempt(func() bool { return t.checkCode(code) }); err != nil {
+		return nil, err
+	}
+	t.value.State = security.TwoFactorEnabled
+	t.value.VerifyTime = time.Now().Unix()
+	codes := t.newRecoveryCodes()
+	return codes, t.save()
+}
+
+// 校验验证码或恢复码,恢复码仅能使用一次
+func (t *twoFactorImpl) Verify(code string) error {
+	if !t.Enabled() {
+		return security.ErrTwoFactorNotEnabled
+	}
+	code = strings.TrimSpace(code)
+	err := t.attempt(func() bool {
+		return t.checkCode(code) || t.useRecoveryCode(code)
+	})
+	if err != nil {
+		return err
+	}
+	t.value.VerifyTime = time.Now().Unix()
+	return t.save()
+}
+
+// 敏感操作时校验验证码或恢复码,每次操作均需验证,
+// 不以验证时间放行,避免其他会话的令牌在验证后进行敏感操作;未启用时不需要验证
+func (t *twoFactorImpl) CheckCode(code string) error {
+	if !t.Enabled() {
+		return nil
+	}
+	if strings.TrimSpace(code) == "" {
+		return security.ErrTwoFactorRequired
+	}
+	return t.Verify(code)
+}
+
+// 重新生成恢复码
+func (t *twoFactorImpl) ResetRecoveryCodes(code string) ([]string, error) {
+	if !t.Enabled() {
+		return nil, security.ErrTwoFactorNotEnabled
+	}
+	if err := t.attempt(func() bool { return t.checkCode(code) }); err != nil {
+		return nil, err
+	}
+	codes := t.newRecoveryCodes()
+	return codes, t.save()
+}
+
+// 停用
+func (t *twoFactorImpl) Disable(code string) error {
+	if err := t.Verify(code); err != nil {
+		return err
+	}
+	t.value.State = security.TwoFactorDisabled
+	t.value.Secret = ""
+	t.value.RecoveryCodes = ""
+	return t.save()
+}
+
+// 生成恢复码,仅保存散列值
+func (t *twoFactorImpl) newRecoveryCodes() []string {
+	codes := make([]string, security.RecoveryCodeNum)
+	hashes := make([]string, len(codes))
+	for i := range codes {
+		b := make([]byte, 5)
+		rand.Read(b)
+		s := hex.EncodeToString(b)
+		codes[i] = s[:5] + "-" + s[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	t.value.RecoveryCodes = strings.Join(hashes, ",")
+	return codes
+}
+
+// 使用恢复码,使用后删除
+func (t *twoFactorImpl) useRecoveryCode(code string) bool {
+	if t.value.RecoveryCodes == "" || code == "" {
+		return false
+	}
+	h := hashRecoveryCode(code)
+	arr := strings.Split(t.value.RecoveryCodes, ",")
+	for i, v := range arr {
+		if v == h {
+			arr = append(arr[:i], arr[i+1:]...)
+			t.value.RecoveryCodes = strings.Join(arr, ",")
+			return true
+		}
+	}
+	return false
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.Replace(strings.TrimSpace(code), "-", "", -1))
+	s := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(s[:])
+}
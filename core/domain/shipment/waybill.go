@@ -0,0 +1,147 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : waybill
+ * author : jarryliu
+ * date : 2026-10-19 10:52
+ * description : 电子面单生成
+ * history :
+ */
+package shipment
+
+import (
+	"go2o/core/domain/interface/shipment"
+	"go2o/core/infrastructure/printer"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	waybillMux sync.RWMutex
+	// 快递公司编码与面单模板的映射
+	waybillTemplates = map[string]shipment.IWaybillTemplate{}
+	// 默认面单模板
+	DefaultWaybillTemplate shipment.IWaybillTemplate = &defaultWaybillTemplate{}
+)
+
+// 注册快递公司的面单模板,spCode为快递公司编码,如:SF
+func RegisterWaybillTemplate(spCode string, t shipment.IWaybillTemplate) {
+	waybillMux.Lock()
+	defer waybillMux.Unlock()
+	waybillTemplates[strings.ToUpper(spCode)] = t
+}
+
+// 获取快递公司的面单模板,未注册时返回默认模板
+func GetWaybillTemplate(spCode string) shipment.IWaybillTemplate {
+	waybillMux.RLock()
+	defer waybillMux.RUnlock()
+	if t, ok := waybillTemplates[strings.ToUpper(spCode)]; ok {
+		return t
+	}
+	return DefaultWaybillTemplate
+}
+
+// 批量生成面单,每张面单占用一页(标签),按快递公司的模板排版
+func RenderWaybills(format string, list []*shipment.Waybill) ([]byte, error) {
+	if len(list) == 0 {
+		return nil, shipment.ErrWaybillNoOrders
+	}
+	c := printer.NewCanvas(format)
+	if c == nil {
+		return nil, shipment.ErrNotSupportWaybillFormat
+	}
+	for _, w := range list {
+		if w.SpOrder == "" {
+			return nil, shipment.ErrWaybillNotShipped
+		}
+		if w.CreateTime <= 0 {
+			w.CreateTime = time.Now().Unix()
+		}
+		t := GetWaybillTemplate(w.SpCode)
+		c.NewPage(t.Size())
+		if err := t.Draw(c, w); err != nil {
+			return nil, err
+		}
+	}
+	return c.Bytes(), nil
+}
+
+var _ shipment.IWaybillTemplate = new(defaultWaybillTemplate)
+
+// 默认面单模板(100x150毫米)
+type defaultWaybillTemplate struct {
+}
+
+// 面单尺寸(毫米)
+func (d *defaultWaybillTemplate) Size() (width float64, height float64) {
+	return printer.DefaultPageWidth, printer.DefaultPageHeight
+}
+
+// 在画布的当前页上绘制面单
+func (d *defaultWaybillTemplate) Draw(c printer.ICanvas, w *shipment.Waybill) error {
+	// 快递公司及条码
+	c.Text(4, 4, 16, w.SpName)
+	c.Text(60, 6, 9, time.Unix(w.CreateTime, 0).Format("2006-01-02 15:04"))
+	c.Line(2, 14, 98, 14, 0.4)
+	if err := c.Barcode(10, 17, 80, 16, w.SpOrder); err != nil {
+		return err
+	}
+	c.Text(30, 35, 11, w.SpOrder)
+	c.Line(2, 41, 98, 41, 0.4)
+	// 收件人
+	y := d.drawContact(c, 44, "收", w.Receiver, 12)
+	c.Line(2, y, 98, y, 0.2)
+	// 寄件人
+	y = d.drawContact(c, y+3, "寄", w.Sender, 9)
+	c.Line(2, y, 98, y, 0.4)
+	// 商品及备注
+	y += 3
+	c.Text(4, y, 9, "订单号:"+w.OrderNo)
+	y += 5
+	for _, line := range d.wrap("内容:"+w.ItemSummary(), 28) {
+		if y > 118 {
+			break
+		}
+		c.Text(4, y, 9, line)
+		y += 4.5
+	}
+	if w.Remark != "" && y <= 118 {
+		c.Text(4, y, 9, "备注:"+w.Remark)
+	}
+	// 底部二维码,便于扫码查询物流
+	c.Line(2, 122, 98, 122, 0.2)
+	c.Text(4, 130, 9, "签收人:")
+	c.Text(4, 138, 9, "签收时间:")
+	return c.QrCode(74, 124, 24, w.SpCode+":"+w.SpOrder)
+}
+
+// 绘制联系人,返回绘制结束的位置
+func (d *defaultWaybillTemplate) drawContact(c printer.ICanvas, y float64,
+	label string, v *shipment.WaybillContact, size float64) float64 {
+	lh := size * 0.5
+	c.Text(4, y, size, label)
+	if v == nil {
+		return y + lh + 4
+	}
+	c.Text(12, y, size, v.Name+"  "+v.Phone)
+	y += lh
+	for _, line := range d.wrap(v.Area+v.Address, int(240/size)) {
+		c.Text(12, y, size, line)
+		y += lh
+	}
+	return y + 2
+}
+
+// 按字符数折行
+func (d *defaultWaybillTemplate) wrap(s string, n int) []string {
+	runes := []rune(s)
+	lines := []string{}
+	for len(runes) > n {
+		lines = append(lines, string(runes[:n]))
+		runes = runes[n:]
+	}
+	if len(runes) > 0 {
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
@@ -13,6 +13,12 @@ type LoginMember struct {
 	Id         int
 	Token      string
 	UpdateTime int64
+	// 会话编号
+	SessionId string
+	// 访问令牌(Token)的有效时间(秒)
+	ExpiresIn int64
+	// 刷新令牌,用于访问令牌过期后获取新的令牌
+	RefreshToken string
 }
 
 // 会员登录返回结果
@@ -20,4 +26,6 @@ type MemberLoginResult struct {
 	Result  bool
 	Message string
 	Member  *LoginMember
+	// 是否需要图形验证码
+	CaptchaRequired bool
 }
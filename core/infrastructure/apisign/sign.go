@@ -0,0 +1,215 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : sign
+ * author : jarryliu
+ * date : 2026-10-19 16:20
+ * description : 商户接口请求签名,签名 = 算法(密钥, 规范请求),
+ *               规范请求由请求方法,路径,排序后的参数,时间戳和随机串组成。
+ * history :
+ */
+package apisign
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HMAC-SHA256签名
+	SignVersion1 = "1"
+	// 当前默认的签名版本
+	DefaultVersion = SignVersion1
+
+	// 请求中签名相关的参数名
+	ParamTimestamp = "timestamp"
+	ParamNonce     = "nonce"
+	ParamVersion   = "sign_version"
+	ParamSign      = "sign"
+
+	// 时间戳允许的偏差(秒)
+	DefaultTimeSkew int64 = 300
+)
+
+var (
+	ErrNotSupportVersion = errors.New("not support sign version")
+	ErrMissingParams     = errors.New("missing sign params")
+	ErrStaleTimestamp    = errors.New("timestamp expired")
+	ErrReusedNonce       = errors.New("nonce has been used")
+	ErrSignature         = errors.New("incorrect signature")
+)
+
+var (
+	mux sync.RWMutex
+	// 签名版本对应的算法
+	algorithms = map[string]func() hash.Hash{
+		SignVersion1: sha256.New,
+	}
+)
+
+// 注册签名算法,用于升级签名版本
+func RegisterAlgorithm(version string, h func() hash.Hash) {
+	mux.Lock()
+	defer mux.Unlock()
+	algorithms[version] = h
+}
+
+func getAlgorithm(version string) func() hash.Hash {
+	mux.RLock()
+	defer mux.RUnlock()
+	return algorithms[version]
+}
+
+// 随机串存储,用于防止重放
+type NonceStore interface {
+	// 使用随机串,已使用过返回false,expires为保存的时间(秒)
+	Use(key string, expires int64) bool
+}
+
+// 签名请求
+type Request struct {
+	// 请求方法,如:POST
+	Method string
+	// 请求路径,如:/go2o_api_v1/mm_login
+	Path string
+	// 请求参数(不包含签名)
+	Params url.Values
+	// 时间戳(秒)
+	Timestamp int64
+	// 随机串
+	Nonce string
+	// 签名版本
+	Version string
+}
+
+// 从请求参数创建签名请求
+func NewRequest(method, path string, params url.Values) (*Request, string, error) {
+	r := &Request{
+		Method:  method,
+		Path:    path,
+		Params:  url.Values{},
+		Nonce:   params.Get(ParamNonce),
+		Version: params.Get(ParamVersion),
+	}
+	sign := params.Get(ParamSign)
+	ts, err := strconv.ParseInt(params.Get(ParamTimestamp), 10, 64)
+	if err != nil || r.Nonce == "" || sign == "" {
+		return nil, "", ErrMissingParams
+	}
+	r.Timestamp = ts
+	if r.Version == "" {
+		r.Version = DefaultVersion
+	}
+	for k, v := range params {
+		switch k {
+		case ParamTimestamp, ParamNonce, ParamVersion, ParamSign:
+		default:
+			r.Params[k] = v
+		}
+	}
+	return r, sign, nil
+}
+
+// 规范请求,格式为:
+// METHOD\nPATH\nk1=v1&k2=v2\nTIMESTAMP\nNONCE
+func (r *Request) Canonical() string {
+	keys := make([]string, 0, len(r.Params))
+	for k := range r.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, k := range keys {
+		values := append([]string{}, r.Params[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join([]string{
+		strings.ToUpper(r.Method),
+		r.Path,
+		strings.Join(pairs, "&"),
+		strconv.FormatInt(r.Timestamp, 10),
+		r.Nonce,
+	}, "\n")
+}
+
+// 使用密钥签名
+func (r *Request) Sign(secret string) (string, error) {
+	h := getAlgorithm(r.Version)
+	if h == nil {
+		return "", ErrNotSupportVersion
+	}
+	m := hmac.New(h, []byte(secret))
+	m.Write([]byte(r.Canonical()))
+	return hex.EncodeToString(m.Sum(nil)), nil
+}
+
+// 签名校验
+type Verifier struct {
+	// 时间戳允许的偏差(秒)
+	TimeSkew int64
+	// 随机串存储
+	Store NonceStore
+}
+
+func NewVerifier(store NonceStore) *Verifier {
+	return &Verifier{
+		TimeSkew: DefaultTimeSkew,
+		Store:    store,
+	}
+}
+
+// 校验签名,secrets为可用的密钥,轮换密钥期间新旧密钥均可用;
+// nonceScope用于区分随机串的使用者,如:接口编号
+func (v *Verifier) Verify(r *Request, sign string, nonceScope string, secrets ...string) error {
+	if getAlgorithm(r.Version) == nil {
+		return ErrNotSupportVersion
+	}
+	now := time.Now().Unix()
+	if r.Timestamp < now-v.TimeSkew || r.Timestamp > now+v.TimeSkew {
+		return ErrStaleTimestamp
+	}
+	matched := false
+	for _, s := range secrets {
+		if s == "" {
+			continue
+		}
+		if exp, _ := r.Sign(s); hmac.Equal([]byte(exp), []byte(strings.ToLower(sign))) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return ErrSignature
+	}
+	// 签名正确后再记录随机串,避免伪造请求占用随机串
+	if v.Store != nil && !v.Store.Use(nonceScope+":"+r.Nonce, v.TimeSkew*2) {
+		return ErrReusedNonce
+	}
+	return nil
+}
+
+// 生成随机串
+func NewNonce() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// 生成随机密钥(16位)
+func NewSecret() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
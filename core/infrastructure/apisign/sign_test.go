@@ -0,0 +1,60 @@
+package apisign
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type memNonceStore map[string]bool
+
+func (m memNonceStore) Use(key string, expires int64) bool {
+	if m[key] {
+		return false
+	}
+	m[key] = true
+	return true
+}
+
+func signedParams(secret string, ts int64, nonce string) url.Values {
+	p := url.Values{}
+	p.Set("merchant_id", "6000037440")
+	p.Set("usr", "jarry")
+	r := &Request{Method: "POST", Path: "/go2o_api_v1/mm_login",
+		Params: p, Timestamp: ts, Nonce: nonce, Version: DefaultVersion}
+	sign, _ := r.Sign(secret)
+	p.Set(ParamTimestamp, strconv.FormatInt(ts, 10))
+	p.Set(ParamNonce, nonce)
+	p.Set(ParamSign, sign)
+	return p
+}
+
+func TestVerify(t *testing.T) {
+	v := NewVerifier(memNonceStore{})
+	now := time.Now().Unix()
+	verify := func(p url.Values, secrets ...string) error {
+		r, sign, err := NewRequest("POST", "/go2o_api_v1/mm_login", p)
+		if err != nil {
+			return err
+		}
+		return v.Verify(r, sign, "6000037440", secrets...)
+	}
+	if err := verify(signedParams("old", now, "n1"), "new", "old"); err != nil {
+		t.Error("old secret should be accepted:", err)
+	}
+	if err := verify(signedParams("old", now, "n1"), "new", "old"); err != ErrReusedNonce {
+		t.Error("nonce reused:", err)
+	}
+	if err := verify(signedParams("old", now, "n2"), "new"); err != ErrSignature {
+		t.Error("rotated secret should be rejected:", err)
+	}
+	if err := verify(signedParams("new", now-3600, "n3"), "new"); err != ErrStaleTimestamp {
+		t.Error("stale timestamp:", err)
+	}
+	p := signedParams("new", now, "n4")
+	p.Set("usr", "other")
+	if err := verify(p, "new"); err != ErrSignature {
+		t.Error("tampered params:", err)
+	}
+}
@@ -34,7 +34,7 @@ func Sha1(s string) string {
 }
 
 // 加密会员密码,因为可能会使用手机号码登录，
-// 所以密码不能依据用户名作为生成凭据
+// 所以密码不能依据用户名作为生成凭据;保存时须使用HashPwd再次散列
 func MemberSha1Pwd(pwd string) string {
 	if pwd == "" {
 		return ""
@@ -10,6 +10,7 @@
 package domain
 
 import (
+	"fmt"
 	"github.com/jsix/gof"
 )
 
@@ -41,3 +42,8 @@ func (this *DomainError) Error() string {
 func (this *DomainError) Set(msg string) {
 	this.DefaultError = msg
 }
+
+// 使用参数格式化错误信息,返回新的错误,错误键保持不变
+func (this *DomainError) Format(args ...interface{}) *DomainError {
+	return NewDomainError(this.Key, fmt.Sprintf(this.DefaultError, args...))
+}
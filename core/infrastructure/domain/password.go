@@ -0,0 +1,140 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : password
+ * author : jarryliu
+ * date : 2026-10-19 17:50
+ * description : 密码散列,使用带独立盐值的bcrypt或argon2id算法,
+ *               散列值以算法前缀开头,如:$2a$10$...,$argon2id$v=19$...
+ *               旧版本的SHA1密码无前缀,验证通过后应重新散列保存。
+ * history :
+ */
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+const (
+	// bcrypt算法
+	PwdBcrypt = "bcrypt"
+	// argon2id算法
+	PwdArgon2id = "argon2id"
+
+	argon2idPrefix = "$argon2id$"
+)
+
+var (
+	// 新密码使用的算法
+	PwdAlgorithm = PwdBcrypt
+	// bcrypt计算成本
+	PwdBcryptCost = bcrypt.DefaultCost
+	// argon2id迭代次数
+	PwdArgon2Time uint32 = 1
+	// argon2id内存(KB)
+	PwdArgon2Memory uint32 = 64 * 1024
+	// argon2id并行数
+	PwdArgon2Threads uint8 = 2
+
+	argon2SaltLen   = 16
+	argon2KeyLen    = 32
+	errPwdAlgorithm = errors.New("not support password algorithm")
+	errPwdHash      = errors.New("incorrect password hash")
+)
+
+// 散列密码,每次散列使用不同的盐值
+func HashPwd(pwd string) (string, error) {
+	switch PwdAlgorithm {
+	case PwdBcrypt:
+		b, err := bcrypt.GenerateFromPassword([]byte(pwd), PwdBcryptCost)
+		return string(b), err
+	case PwdArgon2id:
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(pwd), salt, PwdArgon2Time,
+			PwdArgon2Memory, PwdArgon2Threads, uint32(argon2KeyLen))
+		return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix,
+			argon2.Version, PwdArgon2Memory, PwdArgon2Time, PwdArgon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key)), nil
+	}
+	return "", errPwdAlgorithm
+}
+
+// 是否为旧版本(SHA1)的密码
+func IsLegacyPwd(hash string) bool {
+	return !strings.HasPrefix(hash, "$")
+}
+
+// 验证密码,rehash表示密码为旧版本或算法参数已变更,
+// 验证通过后应使用HashPwd重新散列并保存
+func VerifyPwd(hash, pwd string) (ok bool, rehash bool) {
+	if hash == "" || pwd == "" {
+		return false, false
+	}
+	if IsLegacyPwd(hash) {
+		ok = subtle.ConstantTimeCompare([]byte(hash), []byte(pwd)) == 1 ||
+			subtle.ConstantTimeCompare([]byte(hash), []byte(Sha1(pwd))) == 1
+		return ok, ok
+	}
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		p, err := parseArgon2id(hash)
+		if err != nil {
+			return false, false
+		}
+		key := argon2.IDKey([]byte(pwd), p.salt, p.time, p.memory,
+			p.threads, uint32(len(p.key)))
+		ok = subtle.ConstantTimeCompare(key, p.key) == 1
+		return ok, ok && (PwdAlgorithm != PwdArgon2id ||
+			p.time != PwdArgon2Time || p.memory != PwdArgon2Memory ||
+			p.threads != PwdArgon2Threads)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(pwd)) != nil {
+		return false, false
+	}
+	cost, _ := bcrypt.Cost([]byte(hash))
+	return true, PwdAlgorithm != PwdBcrypt || cost != PwdBcryptCost
+}
+
+type argon2Params struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+// 解析argon2id散列值,格式:$argon2id$v=19$m=65536,t=1,p=2$salt$key
+func parseArgon2id(hash string) (*argon2Params, error) {
+	arr := strings.Split(hash, "$")
+	if len(arr) != 6 {
+		return nil, errPwdHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(arr[2], "v=%d", &version); err != nil ||
+		version != argon2.Version {
+		return nil, errPwdHash
+	}
+	p := &argon2Params{}
+	if _, err := fmt.Sscanf(arr[3], "m=%d,t=%d,p=%d",
+		&p.memory, &p.time, &p.threads); err != nil {
+		return nil, errPwdHash
+	}
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(arr[4]); err != nil {
+		return nil, errPwdHash
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(arr[5]); err != nil ||
+		len(p.key) == 0 {
+		return nil, errPwdHash
+	}
+	return p, nil
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestVerifyPwd(t *testing.T) {
+	pwd := MemberSha1Pwd("123456")
+	// 旧版本密码验证通过后需要重新散列
+	if ok, rehash := VerifyPwd(pwd, pwd); !ok || !rehash {
+		t.Fatal("legacy password should be verified and rehashed")
+	}
+	if ok, _ := VerifyPwd(pwd, MemberSha1Pwd("123000")); ok {
+		t.Fatal("legacy password should not match")
+	}
+	defer func(alg string) { PwdAlgorithm = alg }(PwdAlgorithm)
+	for _, alg := range []string{PwdBcrypt, PwdArgon2id} {
+		PwdAlgorithm = alg
+		h1, err := HashPwd(pwd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h2, _ := HashPwd(pwd)
+		if h1 == h2 || IsLegacyPwd(h1) {
+			t.Fatalf("%s: hash should be salted and prefixed: %s", alg, h1)
+		}
+		if ok, rehash := VerifyPwd(h1, pwd); !ok || rehash {
+			t.Fatalf("%s: verify failed, ok=%v rehash=%v", alg, ok, rehash)
+		}
+		if ok, _ := VerifyPwd(h1, MemberSha1Pwd("123000")); ok {
+			t.Fatalf("%s: wrong password verified", alg)
+		}
+	}
+	// 更换算法后,旧算法的散列需要重新散列
+	PwdAlgorithm = PwdBcrypt
+	h, _ := HashPwd(pwd)
+	PwdAlgorithm = PwdArgon2id
+	if ok, rehash := VerifyPwd(h, pwd); !ok || !rehash {
+		t.Fatal("hash of previous algorithm should be rehashed")
+	}
+}
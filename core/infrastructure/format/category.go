@@ -29,3 +29,11 @@ func IdArrJoinStr32(ids []int32) string {
 	}
 	return strings.Join(strIds, ",")
 }
+
+func IdArrJoinStr64(ids []int64) string {
+	var strIds []string = make([]string, len(ids))
+	for i, v := range ids {
+		strIds[i] = strconv.FormatInt(v, 10)
+	}
+	return strings.Join(strIds, ",")
+}
@@ -13,32 +13,46 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 )
 
 //https://www.253.com/api-docs-10.html
 // 签名要在后台添加，并在短信中包含。
-const url = "http://sms.253.com/msg/send"
+const apiUrl = "http://sms.253.com/msg/send"
 
 func SendMsgToMobile(account, pwd, phone, content string) error {
+	_, err := SendMsg(account, pwd, phone, content)
+	return err
+}
+
+// 发送短信,返回短信编号,用于匹配状态报告
+func SendMsg(account, pwd, phone, content string) (string, error) {
 	strUrl := fmt.Sprintf("%s?un=%s&pw=%s&phone=%s&msg=%s&rd=1",
-		url, account, pwd, phone, content)
+		apiUrl, url.QueryEscape(account), url.QueryEscape(pwd),
+		phone, url.QueryEscape(content))
 	rsp, err := http.Get(strUrl)
-	if err == nil {
-		defer rsp.Body.Close()
-		if rsp.StatusCode != http.StatusOK {
-			err = errors.New("error : " + strconv.Itoa(rsp.StatusCode))
-		}
-		var data []byte
-		data, err = ioutil.ReadAll(rsp.Body)
-		if err == nil {
-			arr := strings.Split(string(data), ",")
-			if arr[1][0] != '0' {
-				err = errors.New("status code : " + arr[1] +
-					" ; response : " + string(data))
-			}
-		}
+	if err != nil {
+		return "", err
 	}
-	return err
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return "", errors.New("error : " + strconv.Itoa(rsp.StatusCode))
+	}
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", err
+	}
+	// 返回格式为:"时间,状态码"及换行后的短信编号
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	arr := strings.Split(lines[0], ",")
+	if len(arr) < 2 || !strings.HasPrefix(strings.TrimSpace(arr[1]), "0") {
+		return "", errors.New("status code : " + lines[0] +
+			" ; response : " + string(data))
+	}
+	if len(lines) > 1 {
+		return strings.TrimSpace(lines[1]), nil
+	}
+	return "", nil
 }
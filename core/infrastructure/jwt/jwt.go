@@ -0,0 +1,247 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : jwt
+ * author : jarryliu
+ * date : 2026-10-19 21:10
+ * description : JSON Web Token(RS256)签名及校验,以及公钥集(JWKS)
+ * history :
+ */
+package jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	// 签名算法
+	AlgRS256 = "RS256"
+)
+
+var (
+	ErrToken     = errors.New("malformed token")
+	ErrAlgorithm = errors.New("unsupported token algorithm")
+	ErrKeyId     = errors.New("unknown token key id")
+	ErrSignature = errors.New("token signature invalid")
+	ErrExpired   = errors.New("token is expired")
+	ErrKey       = errors.New("incorrect rsa private key")
+)
+
+// 声明
+type Claims map[string]interface{}
+
+// 获取过期时间
+func (c Claims) ExpiresAt() int64 {
+	if v, ok := c["exp"].(float64); ok {
+		return int64(v)
+	}
+	if v, ok := c["exp"].(int64); ok {
+		return v
+	}
+	return 0
+}
+
+// 获取字符串声明
+func (c Claims) String(name string) string {
+	if v, ok := c[name].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// 签名密钥
+type Key struct {
+	// 密钥编号
+	Id      string
+	private *rsa.PrivateKey
+}
+
+// 生成RSA密钥
+func GenerateKey(bits int) (*Key, error) {
+	pk, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(pk), nil
+}
+
+// 创建签名密钥,密钥编号根据公钥生成
+func NewKey(pk *rsa.PrivateKey) *Key {
+	der, _ := x509.MarshalPKIXPublicKey(&pk.PublicKey)
+	sum := sha256.Sum256(der)
+	return &Key{
+		Id:      base64.RawURLEncoding.EncodeToString(sum[:12]),
+		private: pk,
+	}
+}
+
+// 从PEM格式解析密钥,支持PKCS1及PKCS8格式
+func ParseKey(data []byte) (*Key, error) {
+	b, _ := pem.Decode(data)
+	if b == nil {
+		return nil, ErrKey
+	}
+	if pk, err := x509.ParsePKCS1PrivateKey(b.Bytes); err == nil {
+		return NewKey(pk), nil
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(b.Bytes); err == nil {
+		if pk, ok := k.(*rsa.PrivateKey); ok {
+			return NewKey(pk), nil
+		}
+	}
+	return nil, ErrKey
+}
+
+// 导出为PEM格式
+func (k *Key) Pem() []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(k.private),
+	})
+}
+
+// 获取公钥
+func (k *Key) Public() *rsa.PublicKey {
+	return &k.private.PublicKey
+}
+
+// 签名,返回令牌
+func (k *Key) Sign(claims Claims) (string, error) {
+	header, _ := json.Marshal(map[string]string{
+		"alg": AlgRS256,
+		"typ": "JWT",
+		"kid": k.Id,
+	})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	s := encodeSegment(header) + "." + encodeSegment(payload)
+	sum := sha256.Sum256([]byte(s))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, k.private, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return s + "." + encodeSegment(sig), nil
+}
+
+// 公钥(RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// 公钥集
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// 获取公钥
+func (k *Key) JWK() *JWK {
+	pub := k.Public()
+	e := make([]byte, 8)
+	binary.BigEndian.PutUint64(e, uint64(pub.E))
+	i := 0
+	for i < len(e)-1 && e[i] == 0 {
+		i++
+	}
+	return &JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: AlgRS256,
+		Kid: k.Id,
+		N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(e[i:]),
+	}
+}
+
+// 转换为RSA公钥
+func (j *JWK) PublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, ErrKey
+	}
+	e, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil || len(e) == 0 || len(e) > 8 {
+		return nil, ErrKey
+	}
+	var ev uint64
+	for _, b := range e {
+		ev = ev<<8 | uint64(b)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(ev)}, nil
+}
+
+// 根据密钥编号获取公钥
+func (s *JWKSet) Get(kid string) *JWK {
+	for _, v := range s.Keys {
+		if v.Kid == kid {
+			return v
+		}
+	}
+	return nil
+}
+
+// 校验令牌并返回声明,已过期的令牌返回ErrExpired
+func (s *JWKSet) Verify(token string) (Claims, error) {
+	arr := strings.Split(token, ".")
+	if len(arr) != 3 {
+		return nil, ErrToken
+	}
+	header := map[string]string{}
+	if b, err := decodeSegment(arr[0]); err != nil ||
+		json.Unmarshal(b, &header) != nil {
+		return nil, ErrToken
+	}
+	if header["alg"] != AlgRS256 {
+		return nil, ErrAlgorithm
+	}
+	jwk := s.Get(header["kid"])
+	if jwk == nil {
+		return nil, ErrKeyId
+	}
+	pub, err := jwk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := decodeSegment(arr[2])
+	if err != nil {
+		return nil, ErrToken
+	}
+	sum := sha256.Sum256([]byte(arr[0] + "." + arr[1]))
+	if rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig) != nil {
+		return nil, ErrSignature
+	}
+	claims := Claims{}
+	if b, err := decodeSegment(arr[1]); err != nil ||
+		json.Unmarshal(b, &claims) != nil {
+		return nil, ErrToken
+	}
+	if exp := claims.ExpiresAt(); exp > 0 && time.Now().Unix() >= exp {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
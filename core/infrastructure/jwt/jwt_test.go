@@ -0,0 +1,71 @@
+package jwt
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testKey *Key
+
+func init() {
+	testKey, _ = GenerateKey(1024)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	set := &JWKSet{Keys: []*JWK{testKey.JWK()}}
+	token, err := testKey.Sign(Claims{
+		"sub": "1",
+		"exp": time.Now().Unix() + 60,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := set.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.String("sub") != "1" {
+		t.Errorf("expect sub 1, got %s", claims.String("sub"))
+	}
+	// 篡改声明
+	arr := strings.Split(token, ".")
+	payload, _ := json.Marshal(Claims{"sub": "2", "exp": time.Now().Unix() + 60})
+	forged := arr[0] + "." + encodeSegment(payload) + "." + arr[2]
+	if _, err = set.Verify(forged); err != ErrSignature {
+		t.Errorf("expect signature error, got %v", err)
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	set := &JWKSet{Keys: []*JWK{testKey.JWK()}}
+	token, _ := testKey.Sign(Claims{"exp": time.Now().Unix() - 1})
+	if _, err := set.Verify(token); err != ErrExpired {
+		t.Errorf("expect expired, got %v", err)
+	}
+}
+
+func TestVerifyUnknownKey(t *testing.T) {
+	other, _ := GenerateKey(1024)
+	set := &JWKSet{Keys: []*JWK{other.JWK()}}
+	token, _ := testKey.Sign(Claims{"sub": "1"})
+	if _, err := set.Verify(token); err != ErrKeyId {
+		t.Errorf("expect key id error, got %v", err)
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	k, err := ParseKey(testKey.Pem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Id != testKey.Id {
+		t.Errorf("expect key id %s, got %s", testKey.Id, k.Id)
+	}
+	pub, err := k.JWK().PublicKey()
+	if err != nil || pub.E != testKey.Public().E ||
+		pub.N.Cmp(testKey.Public().N) != 0 {
+		t.Errorf("public key not match: %v", err)
+	}
+}
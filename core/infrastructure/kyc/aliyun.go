@@ -0,0 +1,92 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : aliyun
+ * author : jarryliu
+ * date : 2026-10-20 13:40
+ * description : 阿里云市场实名认证接口,使用APPCODE认证
+ * history :
+ */
+package kyc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var _ IdentityVerifier = new(AliyunVerifier)
+var _ FaceVerifier = new(AliyunVerifier)
+
+// 默认的HTTP客户端
+var DefaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// 阿里云市场实名认证,status为"01"表示一致
+type AliyunVerifier struct {
+	AppCode string
+	// 姓名与身份证号校验地址
+	IdentityUrl string
+	// 人脸比对地址
+	FaceUrl string
+	// HTTP客户端,为空时使用DefaultClient
+	Client *http.Client
+}
+
+type aliyunResult struct {
+	Status string  `json:"status"`
+	Msg    string  `json:"msg"`
+	Score  float64 `json:"score"`
+}
+
+func (a *AliyunVerifier) Name() string {
+	return "aliyun"
+}
+
+func (a *AliyunVerifier) VerifyIdentity(realName, cardId string) (*Result, error) {
+	form := url.Values{"name": {realName}, "idcard": {cardId}}
+	rsp, err := a.post(a.IdentityUrl, form)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Matched: rsp.Status == "01", Message: rsp.Msg}, nil
+}
+
+func (a *AliyunVerifier) MatchFace(realName, cardId, image string) (*Result, error) {
+	form := url.Values{"name": {realName}, "idcard": {cardId}, "image": {image}}
+	rsp, err := a.post(a.FaceUrl, form)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Matched: rsp.Status == "01", Score: rsp.Score,
+		Message: rsp.Msg}, nil
+}
+
+func (a *AliyunVerifier) post(api string, form url.Values) (*aliyunResult, error) {
+	req, err := http.NewRequest("POST", api, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "APPCODE "+a.AppCode)
+	client := a.Client
+	if client == nil {
+		client = DefaultClient
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kyc api response %d: %s", rsp.StatusCode, string(data))
+	}
+	r := &aliyunResult{}
+	return r, json.Unmarshal(data, r)
+}
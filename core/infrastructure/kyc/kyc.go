@@ -0,0 +1,272 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : kyc
+ * author : jarryliu
+ * date : 2026-10-20 13:10
+ * description : 实名认证(KYC),通过第三方接口校验姓名与身份证号、比对人脸,
+ *               并根据结果进行风险评分。未设置接口时不能自动认证,须人工审核;
+ *               StubVerifier仅用于测试
+ * history :
+ */
+package kyc
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// 自动通过
+	DecisionPass = 1
+	// 自动拒绝
+	DecisionReject = 2
+	// 人工审核
+	DecisionManual = 3
+)
+
+const (
+	// 姓名与身份证号不一致
+	FactorIdentityMismatch = "identity_mismatch"
+	// 姓名与身份证号无法校验
+	FactorIdentityError = "identity_error"
+	// 人脸与身份证照片不匹配
+	FactorFaceMismatch = "face_mismatch"
+	// 人脸相似度较低
+	FactorFaceLowScore = "face_low_score"
+	// 人脸无法比对
+	FactorFaceError = "face_error"
+	// 未成年
+	FactorMinor = "minor"
+	// 高龄
+	FactorElder = "elder"
+	// 港澳台及境外证件
+	FactorOverseas = "overseas"
+)
+
+var (
+	ErrCardId = errors.New("incorrect card id")
+	// 未设置认证接口
+	ErrNoVerifier = errors.New("kyc verifier not set")
+
+	cardIdRegexp = regexp.MustCompile(`^\d{17}[\dX]$`)
+	// 身份证号前17位的加权因子
+	cardIdWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	// 校验码
+	cardIdCheckCodes = "10X98765432"
+
+	mux              sync.RWMutex
+	identityVerifier IdentityVerifier
+	faceVerifier     FaceVerifier
+
+	// 风险因素对应的分数
+	factorScores = map[string]int{
+		FactorIdentityMismatch: 100,
+		FactorIdentityError:    50,
+		FactorFaceMismatch:     60,
+		FactorFaceLowScore:     30,
+		FactorFaceError:        40,
+		FactorMinor:            40,
+		FactorElder:            20,
+		FactorOverseas:         20,
+	}
+)
+
+// 校验结果
+type Result struct {
+	// 是否一致
+	Matched bool
+	// 相似度(0-100),仅人脸比对
+	Score float64
+	// 接口返回的信息
+	Message string
+}
+
+// 姓名与身份证号校验接口
+type IdentityVerifier interface {
+	// 接口名称
+	Name() string
+	// 校验姓名与身份证号是否一致
+	VerifyIdentity(realName, cardId string) (*Result, error)
+}
+
+// 人脸比对接口
+type FaceVerifier interface {
+	// 接口名称
+	Name() string
+	// 比对认证图片与身份证照片
+	MatchFace(realName, cardId, image string) (*Result, error)
+}
+
+// 设置姓名与身份证号校验接口
+func SetIdentityVerifier(v IdentityVerifier) {
+	mux.Lock()
+	defer mux.Unlock()
+	identityVerifier = v
+}
+
+// 设置人脸比对接口
+func SetFaceVerifier(v FaceVerifier) {
+	mux.Lock()
+	defer mux.Unlock()
+	faceVerifier = v
+}
+
+// 身份证信息
+type CardInfo struct {
+	// 地区代码
+	AreaCode int
+	// 出生日期
+	Birthday time.Time
+	// 性别,1:男 2:女
+	Gender int
+}
+
+// 年龄
+func (c *CardInfo) Age(now time.Time) int {
+	age := now.Year() - c.Birthday.Year()
+	if now.Month() < c.Birthday.Month() || (now.Month() == c.Birthday.Month() &&
+		now.Day() < c.Birthday.Day()) {
+		age--
+	}
+	return age
+}
+
+// 按GB 11643校验18位身份证号的校验码
+func CheckCardId(cardId string) bool {
+	if !cardIdRegexp.MatchString(cardId) {
+		return false
+	}
+	sum := 0
+	for i, w := range cardIdWeights {
+		sum += int(cardId[i]-'0') * w
+	}
+	return cardId[17] == cardIdCheckCodes[sum%11]
+}
+
+// 校验并解析身份证号
+func ParseCardId(cardId string) (*CardInfo, error) {
+	if !CheckCardId(cardId) {
+		return nil, ErrCardId
+	}
+	area, _ := strconv.Atoi(cardId[:2])
+	birth, err := time.ParseInLocation("20060102", cardId[6:14], time.Local)
+	if err != nil || area < 11 || birth.After(time.Now()) {
+		return nil, ErrCardId
+	}
+	c := &CardInfo{AreaCode: area, Birthday: birth, Gender: 2}
+	if n, _ := strconv.Atoi(cardId[16:17]); n%2 == 1 {
+		c.Gender = 1
+	}
+	return c, nil
+}
+
+// 认证报告
+type Report struct {
+	// 身份证信息
+	Card *CardInfo
+	// 姓名与身份证号校验结果
+	Identity *Result
+	// 人脸比对结果
+	Face *Result
+	// 风险分数(0-100),越高风险越大
+	RiskScore int
+	// 风险因素
+	Factors []string
+}
+
+// 认证,接口异常时不返回错误,而作为风险因素交由人工审核;
+// 未设置认证接口时返回ErrNoVerifier
+func Verify(realName, cardId, image string, now time.Time) (*Report, error) {
+	card, err := ParseCardId(cardId)
+	if err != nil {
+		return nil, err
+	}
+	mux.RLock()
+	iv, fv := identityVerifier, faceVerifier
+	mux.RUnlock()
+	if iv == nil || fv == nil {
+		return nil, ErrNoVerifier
+	}
+	r := &Report{Card: card, Factors: []string{}}
+	if r.Identity, err = iv.VerifyIdentity(realName, cardId); err != nil {
+		r.Factors = append(r.Factors, FactorIdentityError)
+	} else if !r.Identity.Matched {
+		r.Factors = append(r.Factors, FactorIdentityMismatch)
+	}
+	if r.Face, err = fv.MatchFace(realName, cardId, image); err != nil {
+		r.Factors = append(r.Factors, FactorFaceError)
+	} else if !r.Face.Matched {
+		r.Factors = append(r.Factors, FactorFaceMismatch)
+	} else if r.Face.Score < 80 {
+		r.Factors = append(r.Factors, FactorFaceLowScore)
+	}
+	if age := card.Age(now); age < 18 {
+		r.Factors = append(r.Factors, FactorMinor)
+	} else if age > 80 {
+		r.Factors = append(r.Factors, FactorElder)
+	}
+	if card.AreaCode > 70 {
+		r.Factors = append(r.Factors, FactorOverseas)
+	}
+	sort.Strings(r.Factors)
+	r.RiskScore = Score(r.Factors)
+	return r, nil
+}
+
+// 根据风险因素评分,最高为100
+func Score(factors []string) int {
+	score := 0
+	for _, f := range factors {
+		score += factorScores[f]
+	}
+	if score > 100 {
+		return 100
+	}
+	return score
+}
+
+// 根据风险分数判定,低于passBelow自动通过,
+// 不低于rejectFrom自动拒绝,其他情况交由人工审核
+func Decide(riskScore, passBelow, rejectFrom int) int {
+	if riskScore < passBelow {
+		return DecisionPass
+	}
+	if riskScore >= rejectFrom {
+		return DecisionReject
+	}
+	return DecisionManual
+}
+
+// 本地校验,仅校验身份证号,可设置不一致的身份证号及人脸相似度用于测试
+type StubVerifier struct {
+	// 不一致的身份证号
+	Mismatch map[string]bool
+	// 人脸相似度,默认为90
+	FaceScore float64
+}
+
+func (s *StubVerifier) Name() string {
+	return "stub"
+}
+
+func (s *StubVerifier) VerifyIdentity(realName, cardId string) (*Result, error) {
+	if realName == "" || !CheckCardId(cardId) || s.Mismatch[cardId] {
+		return &Result{Matched: false, Message: "mismatch"}, nil
+	}
+	return &Result{Matched: true}, nil
+}
+
+func (s *StubVerifier) MatchFace(realName, cardId, image string) (*Result, error) {
+	if image == "" || s.Mismatch[cardId] {
+		return &Result{Matched: false, Message: "mismatch"}, nil
+	}
+	score := s.FaceScore
+	if score == 0 {
+		score = 90
+	}
+	return &Result{Matched: score >= 60, Score: score}, nil
+}
@@ -0,0 +1,83 @@
+package kyc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseCardId(t *testing.T) {
+	c, err := ParseCardId("11010519491231002X")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.AreaCode != 11 || c.Gender != 2 || c.Birthday.Year() != 1949 {
+		t.Fatalf("parse card failed: %+v", c)
+	}
+	if age := c.Age(time.Date(2019, 12, 31, 0, 0, 0, 0, time.Local)); age != 70 {
+		t.Fatal("age should be 70, got:", age)
+	}
+	if _, err = ParseCardId("110105194912310021"); err != ErrCardId {
+		t.Fatal("checksum not validated")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	defer SetIdentityVerifier(&StubVerifier{})
+	defer SetFaceVerifier(&StubVerifier{})
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.Local)
+	r, err := Verify("张三", "11010519491231002X", "img.jpg", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.RiskScore != 0 || Decide(r.RiskScore, 30, 80) != DecisionPass {
+		t.Fatal("should pass, factors:", r.Factors)
+	}
+	stub := &StubVerifier{FaceScore: 70}
+	SetFaceVerifier(stub)
+	r, _ = Verify("张三", "11010519491231002X", "img.jpg", now)
+	if r.RiskScore != 30 || Decide(r.RiskScore, 30, 80) != DecisionManual {
+		t.Fatal("should review manually, factors:", r.Factors)
+	}
+	stub.Mismatch = map[string]bool{"11010519491231002X": true}
+	SetIdentityVerifier(stub)
+	r, _ = Verify("张三", "11010519491231002X", "img.jpg", now)
+	if Decide(r.RiskScore, 30, 80) != DecisionReject {
+		t.Fatal("should reject, factors:", r.Factors)
+	}
+}
+
+func TestAliyunVerifier(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "APPCODE code1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		if r.URL.Path == "/face" {
+			w.Write([]byte(`{"status":"01","msg":"ok","score":88.5}`))
+			return
+		}
+		if r.Form.Get("name") == "张三" {
+			w.Write([]byte(`{"status":"01","msg":"实名认证通过"}`))
+		} else {
+			w.Write([]byte(`{"status":"02","msg":"实名认证不通过"}`))
+		}
+	}))
+	defer s.Close()
+	v := &AliyunVerifier{AppCode: "code1", IdentityUrl: s.URL + "/id", FaceUrl: s.URL + "/face"}
+	if r, err := v.VerifyIdentity("张三", "11010519491231002X"); err != nil || !r.Matched {
+		t.Fatal("identity should match", err)
+	}
+	if r, err := v.VerifyIdentity("李四", "11010519491231002X"); err != nil || r.Matched {
+		t.Fatal("identity should not match", err)
+	}
+	if r, err := v.MatchFace("张三", "11010519491231002X", "img"); err != nil || r.Score != 88.5 {
+		t.Fatal("face match failed", err)
+	}
+	v.AppCode = "bad"
+	if _, err := v.VerifyIdentity("张三", "11010519491231002X"); err == nil {
+		t.Fatal("should return error")
+	}
+}
@@ -9,12 +9,10 @@
 package mss
 
 import (
-	"encoding/base64"
 	"fmt"
 	"github.com/jsix/gof"
 	"go2o/core/variable"
-	"net/smtp"
-	"strings"
+	"sync"
 )
 
 var (
@@ -24,29 +22,45 @@ var (
 	EMAIL_CREDENTIAL_USR        = ""
 	EMAIL_CREDENTIAL_PWD        = ""
 	EMAIL_FROM                  = ""
+	// 连接池保持的连接数
+	EMAIL_POOL_SIZE = 5
+
+	pools   = map[string]*SmtpPool{}
+	poolMux sync.Mutex
 )
 
-func SendMail(server, host, usr, pwd, from string, subject string, to []string, body []byte) error {
-	auth := smtp.PlainAuth("", usr, pwd, host)
-	header := make(map[string]string)
-	header["From"] = from
-	header["To"] = strings.Join(to, ";")
-	header["Subject"] = fmt.Sprintf("=?UTF-8?B?%s?=", base64.StdEncoding.EncodeToString([]byte(subject)))
-	header["MIME-Version"] = "1.0"
-	header["Content-Type"] = "text/html; charset=utf-8"
-	header["Content-Transfer-Encoding"] = "base64"
-
-	message := ""
-	for k, v := range header {
-		message += fmt.Sprintf("%s: %s\r\n", k, v)
+// 获取SMTP配置对应的连接池
+func GetSmtpPool(conf SmtpConfig) *SmtpPool {
+	key := fmt.Sprintf("%s|%s|%s|%s", conf.Server, conf.User, conf.Pwd, conf.From)
+	poolMux.Lock()
+	defer poolMux.Unlock()
+	p, ok := pools[key]
+	if !ok {
+		p = NewSmtpPool(conf, EMAIL_POOL_SIZE)
+		pools[key] = p
 	}
-	message += "\r\n" + base64.StdEncoding.EncodeToString(body)
+	return p
+}
 
-	return smtp.SendMail(server, auth, from, to, []byte(message))
+// 发送HTML邮件,连接将被复用
+func SendMail(server, host, usr, pwd, from string, subject string, to []string, body []byte) error {
+	p := GetSmtpPool(SmtpConfig{
+		Server: server,
+		Host:   host,
+		User:   usr,
+		Pwd:    pwd,
+		From:   from,
+	})
+	return p.Send(&Mail{
+		From:    from,
+		To:      to,
+		Subject: subject,
+		Html:    string(body),
+	})
 }
 
-// 使用默认的配置发送邮件
-func SendMailWithDefaultConfig(subject string, to []string, body []byte) error {
+// 获取默认的SMTP配置
+func DefaultSmtpConfig() SmtpConfig {
 	if !loaded {
 		cfg := gof.CurrentApp.Config()
 		EMAIL_HOST = cfg.GetString(variable.SmtpHost)
@@ -54,8 +68,27 @@ func SendMailWithDefaultConfig(subject string, to []string, body []byte) error {
 		EMAIL_CREDENTIAL_USR = cfg.GetString(variable.SmtpCreUser)
 		EMAIL_CREDENTIAL_PWD = cfg.GetString(variable.SmtpCrePwd)
 		EMAIL_FROM = cfg.GetString(variable.SmtpFrom)
+		if n := cfg.GetInt(variable.SmtpPoolSize); n > 0 {
+			EMAIL_POOL_SIZE = n
+		}
 		loaded = true
 	}
-	return SendMail(EMAIL_SERVER, EMAIL_HOST, EMAIL_CREDENTIAL_USR, EMAIL_CREDENTIAL_PWD,
-		EMAIL_FROM, subject, to, body)
+	return SmtpConfig{
+		Server: EMAIL_SERVER,
+		Host:   EMAIL_HOST,
+		User:   EMAIL_CREDENTIAL_USR,
+		Pwd:    EMAIL_CREDENTIAL_PWD,
+		From:   EMAIL_FROM,
+	}
+}
+
+// 使用默认的配置发送邮件
+func SendMailWithDefaultConfig(subject string, to []string, body []byte) error {
+	c := DefaultSmtpConfig()
+	return SendMail(c.Server, c.Host, c.User, c.Pwd, c.From, subject, to, body)
+}
+
+// 使用默认的配置发送邮件,邮件可包含纯文本内容及附件
+func SendWithDefaultConfig(m *Mail) error {
+	return GetSmtpPool(DefaultSmtpConfig()).Send(m)
 }
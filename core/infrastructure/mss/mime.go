@@ -0,0 +1,181 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : mime.go
+ * author : jarryliu
+ * date : 2026-10-21 00:40
+ * description : 邮件内容,支持HTML及纯文本的多格式内容和附件
+ * history :
+ */
+package mss
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 邮件附件
+type Attachment struct {
+	// 文件名
+	Name string `json:"name"`
+	// 内容类型,为空时按文件名判断
+	ContentType string `json:"contentType,omitempty"`
+	// 文件路径,未设置数据时从文件读取
+	Path string `json:"path,omitempty"`
+	// 数据
+	Data []byte `json:"data,omitempty"`
+}
+
+// 邮件
+type Mail struct {
+	// 发件人
+	From string
+	// 收件人
+	To []string
+	// 主题
+	Subject string
+	// 纯文本内容
+	Text string
+	// HTML内容
+	Html string
+	// 附件
+	Attachments []*Attachment
+}
+
+// 按76个字符换行的Base64编码
+func base64Lines(data []byte) []byte {
+	const lineLen = 76
+	s := base64.StdEncoding.EncodeToString(data)
+	buf := bytes.NewBuffer(make([]byte, 0, len(s)+len(s)/lineLen*2+2))
+	for len(s) > lineLen {
+		buf.WriteString(s[:lineLen])
+		buf.WriteString("\r\n")
+		s = s[lineLen:]
+	}
+	buf.WriteString(s)
+	return buf.Bytes()
+}
+
+func textPart(contentType string, text string) (textproto.MIMEHeader, []byte) {
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", contentType+"; charset=utf-8")
+	h.Set("Content-Transfer-Encoding", "base64")
+	return h, base64Lines([]byte(text))
+}
+
+// 正文,同时有纯文本及HTML内容时使用multipart/alternative
+func (m *Mail) bodyPart() (textproto.MIMEHeader, []byte, error) {
+	if m.Text == "" || m.Html == "" {
+		if m.Html == "" {
+			h, b := textPart("text/plain", m.Text)
+			return h, b, nil
+		}
+		h, b := textPart("text/html", m.Html)
+		return h, b, nil
+	}
+	buf := bytes.NewBuffer(nil)
+	w := multipart.NewWriter(buf)
+	for _, v := range [][2]string{{"text/plain", m.Text}, {"text/html", m.Html}} {
+		h, b := textPart(v[0], v[1])
+		p, err := w.CreatePart(h)
+		if err == nil {
+			_, err = p.Write(b)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, nil, err
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", "multipart/alternative; boundary="+w.Boundary())
+	return h, buf.Bytes(), nil
+}
+
+func (a *Attachment) part() (textproto.MIMEHeader, []byte, error) {
+	data := a.Data
+	if data == nil && a.Path != "" {
+		d, err := ioutil.ReadFile(a.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		data = d
+	}
+	name := a.Name
+	if name == "" {
+		name = filepath.Base(a.Path)
+	}
+	ct := a.ContentType
+	if ct == "" {
+		if ct = mime.TypeByExtension(filepath.Ext(name)); ct == "" {
+			ct = "application/octet-stream"
+		}
+	}
+	encName := mime.BEncoding.Encode("utf-8", name)
+	h := textproto.MIMEHeader{}
+	h.Set("Content-Type", fmt.Sprintf("%s; name=\"%s\"", ct, encName))
+	h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", encName))
+	h.Set("Content-Transfer-Encoding", "base64")
+	return h, base64Lines(data), nil
+}
+
+func writeHeader(buf *bytes.Buffer, h textproto.MIMEHeader) {
+	for k, v := range h {
+		for _, s := range v {
+			fmt.Fprintf(buf, "%s: %s\r\n", k, s)
+		}
+	}
+}
+
+// 生成邮件内容
+func (m *Mail) Bytes() ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.BEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	h, body, err := m.bodyPart()
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Attachments) == 0 {
+		writeHeader(buf, h)
+		buf.WriteString("\r\n")
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+	parts := bytes.NewBuffer(nil)
+	w := multipart.NewWriter(parts)
+	p, err := w.CreatePart(h)
+	if err == nil {
+		_, err = p.Write(body)
+	}
+	for _, a := range m.Attachments {
+		if err != nil {
+			return nil, err
+		}
+		if h, body, err = a.part(); err == nil {
+			if p, err = w.CreatePart(h); err == nil {
+				_, err = p.Write(body)
+			}
+		}
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", w.Boundary())
+	buf.Write(parts.Bytes())
+	return buf.Bytes(), nil
+}
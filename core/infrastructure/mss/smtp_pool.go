@@ -0,0 +1,168 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : smtp_pool.go
+ * author : jarryliu
+ * date : 2026-10-21 01:00
+ * description : SMTP连接池,发送完成的连接放回连接池,使用前以RSET检查连接
+ * history :
+ */
+package mss
+
+import (
+	"crypto/tls"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTP配置
+type SmtpConfig struct {
+	// 服务器地址,如:smtp.qq.com:465,端口为465时使用SSL连接
+	Server string
+	// 主机名
+	Host string
+	// 用户名
+	User string
+	// 密码
+	Pwd string
+	// 发件人
+	From string
+}
+
+// SMTP连接池
+type SmtpPool struct {
+	conf SmtpConfig
+	idle chan *smtp.Client
+}
+
+func NewSmtpPool(conf SmtpConfig, size int) *SmtpPool {
+	if size <= 0 {
+		size = 1
+	}
+	return &SmtpPool{
+		conf: conf,
+		idle: make(chan *smtp.Client, size),
+	}
+}
+
+// 创建连接,服务器支持时使用STARTTLS
+func (s *SmtpPool) dial() (*smtp.Client, error) {
+	const timeout = time.Second * 10
+	var conn net.Conn
+	var err error
+	tlsConf := &tls.Config{ServerName: s.conf.Host}
+	if strings.HasSuffix(s.conf.Server, ":465") {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout},
+			"tcp", s.conf.Server, tlsConf)
+	} else {
+		conn, err = net.DialTimeout("tcp", s.conf.Server, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, err := smtp.NewClient(conn, s.conf.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, ok := conn.(*tls.Conn); !ok {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			err = c.StartTLS(tlsConf)
+		}
+	}
+	if ok, _ := c.Extension("AUTH"); err == nil && ok && s.conf.User != "" {
+		err = c.Auth(smtp.PlainAuth("", s.conf.User, s.conf.Pwd, s.conf.Host))
+	}
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// 获取连接,连接池中的连接失效时重新创建
+func (s *SmtpPool) get() (*smtp.Client, error) {
+	for {
+		select {
+		case c := <-s.idle:
+			if c.Reset() == nil {
+				return c, nil
+			}
+			c.Close()
+		default:
+			return s.dial()
+		}
+	}
+}
+
+// 放回连接池,连接池已满时断开
+func (s *SmtpPool) put(c *smtp.Client) {
+	select {
+	case s.idle <- c:
+	default:
+		c.Quit()
+	}
+}
+
+// 获取地址,如:"go2o <noreply@go2o.cc>"返回noreply@go2o.cc
+func envelopeAddress(s string) string {
+	if addr, err := mail.ParseAddress(s); err == nil {
+		return addr.Address
+	}
+	return s
+}
+
+// 发送邮件,出错的连接不再放回连接池
+func (s *SmtpPool) Send(m *Mail) error {
+	if m.From == "" {
+		m.From = s.conf.From
+	}
+	data, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+	c, err := s.get()
+	if err != nil {
+		return err
+	}
+	if err = s.send(c, m, data); err != nil {
+		c.Close()
+		return err
+	}
+	s.put(c)
+	return nil
+}
+
+func (s *SmtpPool) send(c *smtp.Client, m *Mail, data []byte) error {
+	if err := c.Mail(envelopeAddress(m.From)); err != nil {
+		return err
+	}
+	for _, to := range m.To {
+		if err := c.Rcpt(envelopeAddress(to)); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// 关闭连接池中的连接
+func (s *SmtpPool) Close() {
+	for {
+		select {
+		case c := <-s.idle:
+			c.Quit()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,187 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : pdf
+ * author : jarryliu
+ * date : 2026-10-19 09:40
+ * description : 简易的PDF画布,用于打印面单等标签
+ * history :
+ */
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"go2o/core/infrastructure/gen"
+	"go2o/core/infrastructure/gen/rsc/qr"
+	"strconv"
+	"unicode/utf16"
+)
+
+// 毫米转换为点(1/72英寸)
+const mmToPt = 72 / 25.4
+
+var _ ICanvas = new(PdfCanvas)
+
+type pdfPage struct {
+	width   float64
+	height  float64
+	content *bytes.Buffer
+}
+
+// PDF画布,坐标和尺寸均以毫米为单位,原点在页面左上角;
+// 文字使用PDF阅读器内置的宋体(STSong-Light),无需嵌入字体
+type PdfCanvas struct {
+	pages []*pdfPage
+	page  *pdfPage
+}
+
+func NewPdfCanvas() *PdfCanvas {
+	return &PdfCanvas{
+		pages: []*pdfPage{},
+	}
+}
+
+// 格式
+func (p *PdfCanvas) Format() string {
+	return FormatPdf
+}
+
+// 新建页面
+func (p *PdfCanvas) NewPage(width, height float64) {
+	p.page = &pdfPage{
+		width:   width * mmToPt,
+		height:  height * mmToPt,
+		content: bytes.NewBuffer(nil),
+	}
+	p.pages = append(p.pages, p.page)
+}
+
+func (p *PdfCanvas) ensurePage() *pdfPage {
+	if p.page == nil {
+		p.NewPage(DefaultPageWidth, DefaultPageHeight)
+	}
+	return p.page
+}
+
+// 转换为PDF坐标
+func (p *PdfCanvas) point(x, y float64) (float64, float64) {
+	pg := p.ensurePage()
+	return x * mmToPt, pg.height - y*mmToPt
+}
+
+// 输出文本,y为文字顶部位置,size为字号(点)
+func (p *PdfCanvas) Text(x, y float64, size float64, text string) {
+	if len(text) == 0 {
+		return
+	}
+	px, py := p.point(x, y)
+	py -= size * 0.88
+	buf := bytes.NewBufferString("<")
+	for _, u := range utf16.Encode([]rune(text)) {
+		buf.WriteString(fmt.Sprintf("%04X", u))
+	}
+	buf.WriteString(">")
+	fmt.Fprintf(p.page.content, "BT /F1 %s Tf %s %s Td %s Tj ET\n",
+		ftoa(size), ftoa(px), ftoa(py), buf.String())
+}
+
+// 填充矩形
+func (p *PdfCanvas) Rect(x, y, w, h float64) {
+	px, py := p.point(x, y+h)
+	fmt.Fprintf(p.page.content, "%s %s %s %s re f\n",
+		ftoa(px), ftoa(py), ftoa(w*mmToPt), ftoa(h*mmToPt))
+}
+
+// 画线,thickness为线宽(毫米)
+func (p *PdfCanvas) Line(x1, y1, x2, y2 float64, thickness float64) {
+	px1, py1 := p.point(x1, y1)
+	px2, py2 := p.point(x2, y2)
+	fmt.Fprintf(p.page.content, "%s w %s %s m %s %s l S\n",
+		ftoa(thickness*mmToPt), ftoa(px1), ftoa(py1), ftoa(px2), ftoa(py2))
+}
+
+// 绘制Code128条形码
+func (p *PdfCanvas) Barcode(x, y, w, h float64, content string) error {
+	modules, err := gen.Code128Modules(content)
+	if err != nil {
+		return err
+	}
+	mw := w / float64(len(modules))
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		// 合并相邻的条
+		j := i
+		for j < len(modules) && modules[j] {
+			j++
+		}
+		p.Rect(x+float64(i)*mw, y, float64(j-i)*mw, h)
+		i = j
+	}
+	return nil
+}
+
+// 绘制二维码
+func (p *PdfCanvas) QrCode(x, y, size float64, content string) error {
+	code, err := qr.Encode(content, qr.M)
+	if err != nil {
+		return err
+	}
+	mw := size / float64(code.Size)
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; col++ {
+			if code.Black(col, row) {
+				p.Rect(x+float64(col)*mw, y+float64(row)*mw, mw, mw)
+			}
+		}
+	}
+	return nil
+}
+
+// 生成PDF文件
+func (p *PdfCanvas) Bytes() []byte {
+	p.ensurePage()
+	buf := bytes.NewBufferString("%PDF-1.4\n")
+	offsets := []int{}
+	writeObj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	// 1:目录,2:页面树,3-5:字体,之后每页占用页面与内容两个对象
+	kids := bytes.NewBuffer(nil)
+	for i := range p.pages {
+		fmt.Fprintf(kids, "%d 0 R ", 6+i*2)
+	}
+	writeObj("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		kids.String(), len(p.pages)))
+	writeObj("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light" +
+		" /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	writeObj("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light" +
+		" /CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >>" +
+		" /FontDescriptor 5 0 R /DW 1000 >>")
+	writeObj("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6" +
+		" /FontBBox [-25 -254 1000 880] /ItalicAngle 0 /Ascent 880" +
+		" /Descent -120 /CapHeight 880 /StemV 93 >>")
+	for i, pg := range p.pages {
+		writeObj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s]"+
+			" /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			ftoa(pg.width), ftoa(pg.height), 7+i*2))
+		writeObj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream",
+			pg.content.Len(), pg.content.String()))
+	}
+	xref := buf.Len()
+	fmt.Fprintf(buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, v := range offsets {
+		fmt.Fprintf(buf, "%010d 00000 n \n", v)
+	}
+	fmt.Fprintf(buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+func ftoa(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
@@ -0,0 +1,54 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : printer
+ * author : jarryliu
+ * date : 2026-10-19 09:32
+ * description : 标签打印画布,支持PDF和ZPL(斑马打印机)
+ * history :
+ */
+package printer
+
+const (
+	// PDF文档
+	FormatPdf = "pdf"
+	// 斑马打印机指令
+	FormatZpl = "zpl"
+)
+
+const (
+	// 默认页面宽度(毫米),即100x150的热敏面单
+	DefaultPageWidth float64 = 100
+	// 默认页面高度(毫米)
+	DefaultPageHeight float64 = 150
+)
+
+// 画布,坐标和尺寸均以毫米为单位,原点在页面左上角
+type ICanvas interface {
+	// 格式
+	Format() string
+	// 新建页面(标签)
+	NewPage(width, height float64)
+	// 输出文本,y为文字顶部位置,size为字号(点)
+	Text(x, y float64, size float64, text string)
+	// 填充矩形
+	Rect(x, y, w, h float64)
+	// 画线,thickness为线宽
+	Line(x1, y1, x2, y2 float64, thickness float64)
+	// 绘制Code128条形码
+	Barcode(x, y, w, h float64, content string) error
+	// 绘制二维码
+	QrCode(x, y, size float64, content string) error
+	// 输出
+	Bytes() []byte
+}
+
+// 根据格式创建画布,不支持的格式返回nil
+func NewCanvas(format string) ICanvas {
+	switch format {
+	case FormatPdf:
+		return NewPdfCanvas()
+	case FormatZpl:
+		return NewZplCanvas(ZplDpi203)
+	}
+	return nil
+}
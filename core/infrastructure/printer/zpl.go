@@ -0,0 +1,178 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : zpl
+ * author : jarryliu
+ * date : 2026-10-19 10:05
+ * description : 斑马打印机(ZPL II)画布
+ * history :
+ */
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"go2o/core/infrastructure/gen"
+	"math"
+	"strings"
+)
+
+const (
+	// 203dpi,每毫米8点
+	ZplDpi203 = 8
+	// 300dpi,每毫米12点
+	ZplDpi300 = 12
+)
+
+// 默认字体,使用斑马打印机的简体中文字体,需配合^CI28(UTF-8)使用;
+// 打印机未安装该字体时,可通过SetZplFont指定其他已下载的字体
+var zplFont = "E:ANMDS.TTF"
+
+// 设置ZPL画布的默认字体,如:E:SIMSUN.TTF,打印机内置字体可传入"0"
+func SetZplFont(font string) {
+	if font != "" {
+		zplFont = font
+	}
+}
+
+var _ ICanvas = new(ZplCanvas)
+
+// 斑马打印机画布,每个页面对应一个^XA...^XZ标签
+type ZplCanvas struct {
+	// 每毫米的点数
+	dots int
+	// 字体,默认为中文字体,单字符表示打印机内置字体,如:0;
+	// 其他为打印机中已下载的字体,如:E:SIMSUN.TTF
+	Font     string
+	buf      *bytes.Buffer
+	pageOpen bool
+}
+
+func NewZplCanvas(dotsPerMm int) *ZplCanvas {
+	if dotsPerMm <= 0 {
+		dotsPerMm = ZplDpi203
+	}
+	return &ZplCanvas{
+		dots: dotsPerMm,
+		Font: zplFont,
+		buf:  bytes.NewBuffer(nil),
+	}
+}
+
+// 格式
+func (z *ZplCanvas) Format() string {
+	return FormatZpl
+}
+
+func (z *ZplCanvas) d(mm float64) int {
+	return int(math.Floor(mm*float64(z.dots) + 0.5))
+}
+
+// 新建页面
+func (z *ZplCanvas) NewPage(width, height float64) {
+	z.closePage()
+	fmt.Fprintf(z.buf, "^XA^CI28^PW%d^LL%d\n", z.d(width), z.d(height))
+	z.pageOpen = true
+}
+
+func (z *ZplCanvas) ensurePage() {
+	if !z.pageOpen {
+		z.NewPage(DefaultPageWidth, DefaultPageHeight)
+	}
+}
+
+func (z *ZplCanvas) closePage() {
+	if z.pageOpen {
+		z.buf.WriteString("^XZ\n")
+		z.pageOpen = false
+	}
+}
+
+// 输出文本,y为文字顶部位置,size为字号(点)
+func (z *ZplCanvas) Text(x, y float64, size float64, text string) {
+	if len(text) == 0 {
+		return
+	}
+	z.ensurePage()
+	h := z.d(size * 25.4 / 72)
+	var font string
+	if len(z.Font) <= 1 {
+		font = fmt.Sprintf("^A%sN,%d,%d", z.Font, h, h)
+	} else {
+		font = fmt.Sprintf("^A@N,%d,%d,%s", h, h, z.Font)
+	}
+	fmt.Fprintf(z.buf, "^FO%d,%d%s^FH^FD%s^FS\n", z.d(x), z.d(y),
+		font, zplEscape(text))
+}
+
+// 填充矩形
+func (z *ZplCanvas) Rect(x, y, w, h float64) {
+	z.ensurePage()
+	wd, hd := z.d(w), z.d(h)
+	t := wd
+	if hd < t {
+		t = hd
+	}
+	fmt.Fprintf(z.buf, "^FO%d,%d^GB%d,%d,%d^FS\n", z.d(x), z.d(y), wd, hd, t)
+}
+
+// 画线,仅支持水平线和垂直线
+func (z *ZplCanvas) Line(x1, y1, x2, y2 float64, thickness float64) {
+	z.ensurePage()
+	t := z.d(thickness)
+	if t < 1 {
+		t = 1
+	}
+	x, y := math.Min(x1, x2), math.Min(y1, y2)
+	w, h := z.d(math.Abs(x2-x1)), z.d(math.Abs(y2-y1))
+	if w < t {
+		w = t
+	}
+	if h < t {
+		h = t
+	}
+	fmt.Fprintf(z.buf, "^FO%d,%d^GB%d,%d,%d^FS\n", z.d(x), z.d(y), w, h, t)
+}
+
+// 绘制Code128条形码
+func (z *ZplCanvas) Barcode(x, y, w, h float64, content string) error {
+	modules, err := gen.Code128Modules(content)
+	if err != nil {
+		return err
+	}
+	z.ensurePage()
+	mw := z.d(w) / len(modules)
+	if mw < 1 {
+		mw = 1
+	}
+	fmt.Fprintf(z.buf, "^FO%d,%d^BY%d^BCN,%d,N,N,N^FH^FD%s^FS\n",
+		z.d(x), z.d(y), mw, z.d(h), zplEscape(content))
+	return nil
+}
+
+// 绘制二维码
+func (z *ZplCanvas) QrCode(x, y, size float64, content string) error {
+	z.ensurePage()
+	// 版本按25个模块估算放大倍数
+	mag := z.d(size) / 25
+	if mag < 1 {
+		mag = 1
+	} else if mag > 10 {
+		mag = 10
+	}
+	fmt.Fprintf(z.buf, "^FO%d,%d^BQN,2,%d^FH^FDMA,%s^FS\n",
+		z.d(x), z.d(y), mag, zplEscape(content))
+	return nil
+}
+
+// 输出
+func (z *ZplCanvas) Bytes() []byte {
+	z.closePage()
+	return z.buf.Bytes()
+}
+
+var zplReplacer = strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E")
+
+// 转义字段数据中的控制字符,需配合^FH使用
+func zplEscape(s string) string {
+	return zplReplacer.Replace(s)
+}
@@ -0,0 +1,185 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : apns.go
+ * author : jarryliu
+ * date : 2026-10-21 14:20
+ * description : 苹果推送(APNs),使用HTTP/2及基于令牌的认证(.p8密钥,ES256签名)
+ * history :
+ */
+package push
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// 正式环境
+	ApnsProductionUrl = "https://api.push.apple.com"
+	// 开发环境
+	ApnsSandboxUrl = "https://api.sandbox.push.apple.com"
+	// 认证令牌的刷新间隔,苹果要求20至60分钟内刷新
+	apnsTokenRefresh = 40 * time.Minute
+)
+
+var ErrApnsKey = errors.New("incorrect apns p8 key")
+
+var _ IProvider = new(ApnsProvider)
+
+// 苹果推送
+type ApnsProvider struct {
+	// 密钥编号
+	KeyId string
+	// 开发者团队编号
+	TeamId string
+	// 应用的Bundle ID
+	Topic string
+	// 服务地址,默认为正式环境
+	Url string
+
+	key       *ecdsa.PrivateKey
+	client    *http.Client
+	mux       sync.Mutex
+	token     string
+	tokenTime time.Time
+}
+
+// 解析.p8密钥(PKCS8格式的ECDSA私钥)
+func ParseApnsKey(data []byte) (*ecdsa.PrivateKey, error) {
+	b, _ := pem.Decode(data)
+	if b == nil {
+		return nil, ErrApnsKey
+	}
+	k, err := x509.ParsePKCS8PrivateKey(b.Bytes)
+	if err != nil {
+		return nil, ErrApnsKey
+	}
+	if ek, ok := k.(*ecdsa.PrivateKey); ok {
+		return ek, nil
+	}
+	return nil, ErrApnsKey
+}
+
+// 创建苹果推送,transport为空时使用HTTP/2连接
+func NewApnsProvider(keyId, teamId, topic string, key *ecdsa.PrivateKey,
+	sandbox bool, transport http.RoundTripper) *ApnsProvider {
+	a := &ApnsProvider{
+		KeyId:  keyId,
+		TeamId: teamId,
+		Topic:  topic,
+		Url:    ApnsProductionUrl,
+		key:    key,
+		client: newClient(transport),
+	}
+	if sandbox {
+		a.Url = ApnsSandboxUrl
+	}
+	return a
+}
+
+// 获取认证令牌,令牌在刷新间隔内重复使用
+func (a *ApnsProvider) authToken(force bool) (string, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if !force && a.token != "" && time.Since(a.tokenTime) < apnsTokenRefresh {
+		return a.token, nil
+	}
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": a.KeyId})
+	claims, _ := json.Marshal(map[string]interface{}{"iss": a.TeamId, "iat": now.Unix()})
+	s := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(s))
+	r, sv, err := ecdsa.Sign(rand.Reader, a.key, sum[:])
+	if err != nil {
+		return "", err
+	}
+	// ES256签名为定长的r及s拼接
+	sig := make([]byte, 64)
+	rb, sb := r.Bytes(), sv.Bytes()
+	copy(sig[32-len(rb):32], rb)
+	copy(sig[64-len(sb):], sb)
+	a.token = s + "." + base64.RawURLEncoding.EncodeToString(sig)
+	a.tokenTime = now
+	return a.token, nil
+}
+
+// 生成推送内容
+func (a *ApnsProvider) payload(m *Message) ([]byte, error) {
+	aps := map[string]interface{}{
+		"alert": map[string]string{"title": m.Title, "body": m.Body},
+		"sound": "default",
+	}
+	if m.Sound != "" {
+		aps["sound"] = m.Sound
+	}
+	if m.Badge > 0 {
+		aps["badge"] = m.Badge
+	}
+	v := map[string]interface{}{}
+	for k, d := range m.Data {
+		v[k] = d
+	}
+	v["aps"] = aps
+	return json.Marshal(v)
+}
+
+// 推送消息,认证令牌过期时重新签发并重试一次
+func (a *ApnsProvider) Push(token string, m *Message) error {
+	body, err := a.payload(m)
+	if err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		err = a.post(token, body, i > 0)
+		if e, ok := err.(*Error); ok && e.Reason == "ExpiredProviderToken" && i == 0 {
+			continue
+		}
+		return err
+	}
+}
+
+func (a *ApnsProvider) post(token string, body []byte, refresh bool) error {
+	auth, err := a.authToken(refresh)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", a.Url+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+auth)
+	req.Header.Set("apns-topic", a.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	req.Header.Set("apns-expiration", strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10))
+	req.Header.Set("content-type", "application/json")
+	rsp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusOK {
+		return nil
+	}
+	ret := struct {
+		Reason string `json:"reason"`
+	}{}
+	json.Unmarshal(readBody(rsp), &ret)
+	if rsp.StatusCode == http.StatusGone || ret.Reason == "BadDeviceToken" ||
+		ret.Reason == "Unregistered" || ret.Reason == "DeviceTokenNotForTopic" {
+		return ErrInvalidToken
+	}
+	return &Error{Code: rsp.StatusCode, Reason: ret.Reason}
+}
@@ -0,0 +1,189 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : fcm.go
+ * author : jarryliu
+ * date : 2026-10-21 14:50
+ * description : Firebase推送(FCM HTTP v1),使用服务账号签发的JWT换取访问令牌
+ * history :
+ */
+package push
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go2o/core/infrastructure/jwt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 推送接口地址
+	FcmUrl = "https://fcm.googleapis.com"
+	// 访问令牌的权限
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+var ErrFcmCredentials = errors.New("incorrect fcm service account credentials")
+
+var _ IProvider = new(FcmProvider)
+
+// 服务账号
+type FcmCredentials struct {
+	ProjectId    string `json:"project_id"`
+	PrivateKeyId string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenUri     string `json:"token_uri"`
+}
+
+// Firebase推送
+type FcmProvider struct {
+	// 服务地址
+	Url string
+
+	cred        *FcmCredentials
+	key         *jwt.Key
+	client      *http.Client
+	mux         sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// 根据服务账号(JSON)创建推送,transport为空时使用HTTP/2连接
+func NewFcmProvider(credentials []byte, transport http.RoundTripper) (*FcmProvider, error) {
+	c := &FcmCredentials{}
+	if err := json.Unmarshal(credentials, c); err != nil {
+		return nil, ErrFcmCredentials
+	}
+	if c.ProjectId == "" || c.ClientEmail == "" {
+		return nil, ErrFcmCredentials
+	}
+	key, err := jwt.ParseKey([]byte(c.PrivateKey))
+	if err != nil {
+		return nil, ErrFcmCredentials
+	}
+	key.Id = c.PrivateKeyId
+	if c.TokenUri == "" {
+		c.TokenUri = "https://oauth2.googleapis.com/token"
+	}
+	return &FcmProvider{
+		Url:    FcmUrl,
+		cred:   c,
+		key:    key,
+		client: newClient(transport),
+	}, nil
+}
+
+// 获取访问令牌,令牌过期前1分钟重新获取
+func (f *FcmProvider) getAccessToken(force bool) (string, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if !force && f.accessToken != "" && time.Now().Before(f.expiresAt) {
+		return f.accessToken, nil
+	}
+	now := time.Now().Unix()
+	assertion, err := f.key.Sign(jwt.Claims{
+		"iss":   f.cred.ClientEmail,
+		"scope": fcmScope,
+		"aud":   f.cred.TokenUri,
+		"iat":   now,
+		"exp":   now + 3600,
+	})
+	if err != nil {
+		return "", err
+	}
+	rsp, err := f.client.PostForm(f.cred.TokenUri, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	ret := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+		Error       string `json:"error"`
+	}{}
+	json.Unmarshal(readBody(rsp), &ret)
+	if rsp.StatusCode != http.StatusOK || ret.AccessToken == "" {
+		return "", &Error{Code: rsp.StatusCode, Reason: ret.Error}
+	}
+	f.accessToken = ret.AccessToken
+	f.expiresAt = time.Now().Add(time.Duration(ret.ExpiresIn-60) * time.Second)
+	return f.accessToken, nil
+}
+
+// 推送消息,访问令牌失效时重新获取并重试一次
+func (f *FcmProvider) Push(token string, m *Message) error {
+	msg := map[string]interface{}{
+		"token": token,
+		"notification": map[string]string{
+			"title": m.Title,
+			"body":  m.Body,
+		},
+	}
+	if len(m.Data) > 0 {
+		msg["data"] = m.Data
+	}
+	android := map[string]interface{}{}
+	if m.Sound != "" {
+		android["sound"] = m.Sound
+	}
+	if m.Badge > 0 {
+		android["notification_count"] = m.Badge
+	}
+	if len(android) > 0 {
+		msg["android"] = map[string]interface{}{"notification": android}
+	}
+	body, err := json.Marshal(map[string]interface{}{"message": msg})
+	if err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
+		err = f.post(body, i > 0)
+		if e, ok := err.(*Error); ok && e.Code == http.StatusUnauthorized && i == 0 {
+			continue
+		}
+		return err
+	}
+}
+
+func (f *FcmProvider) post(body []byte, refresh bool) error {
+	auth, err := f.getAccessToken(refresh)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", f.Url+"/v1/projects/"+
+		f.cred.ProjectId+"/messages:send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+auth)
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusOK {
+		return nil
+	}
+	data := readBody(rsp)
+	ret := struct {
+		Error struct {
+			Status string `json:"status"`
+		} `json:"error"`
+	}{}
+	json.Unmarshal(data, &ret)
+	// 令牌失效时返回404或错误详情中包含UNREGISTERED
+	if rsp.StatusCode == http.StatusNotFound ||
+		strings.Contains(string(data), "\"UNREGISTERED\"") {
+		return ErrInvalidToken
+	}
+	return &Error{Code: rsp.StatusCode, Reason: ret.Error.Status}
+}
@@ -0,0 +1,89 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : mock.go
+ * author : jarryliu
+ * date : 2026-10-21 15:10
+ * description : 用于测试的传输及推送平台,不连接推送服务器
+ * history :
+ */
+package push
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+var _ http.RoundTripper = new(MockTransport)
+
+// 模拟的传输,由Handler返回响应状态码及内容,并记录请求
+type MockTransport struct {
+	// 处理请求,返回状态码及响应内容
+	Handler func(r *http.Request, body []byte) (int, string)
+
+	mux      sync.Mutex
+	requests []*http.Request
+}
+
+func (m *MockTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		body, _ = ioutil.ReadAll(r.Body)
+		r.Body.Close()
+	}
+	m.mux.Lock()
+	m.requests = append(m.requests, r)
+	m.mux.Unlock()
+	code, content := http.StatusOK, ""
+	if m.Handler != nil {
+		code, content = m.Handler(r, body)
+	}
+	return &http.Response{
+		StatusCode: code,
+		Status:     http.StatusText(code),
+		Proto:      "HTTP/2.0",
+		ProtoMajor: 2,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(content)),
+		Request:    r,
+	}, nil
+}
+
+// 获取已发送的请求
+func (m *MockTransport) Requests() []*http.Request {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return append([]*http.Request{}, m.requests...)
+}
+
+var _ IProvider = new(MockProvider)
+
+// 模拟的推送平台,记录推送的消息,Invalid中的令牌返回ErrInvalidToken
+type MockProvider struct {
+	// 失效的设备令牌
+	Invalid map[string]bool
+
+	mux      sync.Mutex
+	messages map[string][]*Message
+}
+
+func (m *MockProvider) Push(token string, msg *Message) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.Invalid[token] {
+		return ErrInvalidToken
+	}
+	if m.messages == nil {
+		m.messages = map[string][]*Message{}
+	}
+	m.messages[token] = append(m.messages[token], msg)
+	return nil
+}
+
+// 获取推送到设备的消息
+func (m *MockProvider) Messages(token string) []*Message {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	return append([]*Message{}, m.messages[token]...)
+}
@@ -0,0 +1,83 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : push
+ * author : jarryliu
+ * date : 2026-10-21 14:00
+ * description : 移动设备推送,各推送平台以统一的接口发送,
+ *               设备令牌失效时返回ErrInvalidToken,调用方应删除该令牌
+ * history :
+ */
+package push
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// 苹果推送(APNs)
+	PlatformApns = "apns"
+	// Firebase推送(FCM)
+	PlatformFcm = "fcm"
+)
+
+var (
+	ErrInvalidToken = errors.New("device token is invalid or unregistered")
+	ErrNoPlatform   = errors.New("push platform not configured")
+)
+
+// 推送消息
+type Message struct {
+	// 标题
+	Title string `json:"title"`
+	// 内容
+	Body string `json:"body"`
+	// 附加数据,由客户端处理,如:{"type":"order","orderNo":"1000"}
+	Data map[string]string `json:"data,omitempty"`
+	// 角标数字,为0时不设置
+	Badge int `json:"badge,omitempty"`
+	// 提示音,为空时使用默认提示音
+	Sound string `json:"sound,omitempty"`
+}
+
+// 推送平台
+type IProvider interface {
+	// 推送消息到设备
+	Push(token string, m *Message) error
+}
+
+// 推送失败
+type Error struct {
+	// 响应状态码
+	Code int
+	// 平台返回的原因
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return "push failed: " + strconv.Itoa(e.Code) + " " + e.Reason
+}
+
+// 创建HTTP/2客户端,transport为空时使用支持HTTP/2的默认传输
+func newClient(transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		transport = &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConnsPerHost: 4,
+			IdleConnTimeout:     5 * time.Minute,
+		}
+	}
+	return &http.Client{Transport: transport, Timeout: 15 * time.Second}
+}
+
+// 读取响应内容,最多读取4KB
+func readBody(rsp *http.Response) []byte {
+	data, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 4096))
+	return data
+}
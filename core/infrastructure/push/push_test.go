@@ -0,0 +1,128 @@
+package push
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newApnsKey(t *testing.T) *ecdsa.PrivateKey {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(k)
+	pk, err := ParseApnsKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pk
+}
+
+// 校验ES256签名的认证令牌
+func verifyApnsToken(key *ecdsa.PrivateKey, token string) bool {
+	arr := strings.Split(token, ".")
+	if len(arr) != 3 {
+		return false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(arr[2])
+	if err != nil || len(sig) != 64 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(arr[0] + "." + arr[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	return ecdsa.Verify(&key.PublicKey, sum[:], r, s)
+}
+
+func TestApnsPush(t *testing.T) {
+	key := newApnsKey(t)
+	tr := &MockTransport{Handler: func(r *http.Request, body []byte) (int, string) {
+		auth := strings.TrimPrefix(r.Header.Get("authorization"), "bearer ")
+		if !verifyApnsToken(key, auth) || r.Header.Get("apns-topic") != "cc.go2o.app" {
+			return http.StatusForbidden, `{"reason":"InvalidProviderToken"}`
+		}
+		if strings.HasSuffix(r.URL.Path, "/expired") {
+			return http.StatusGone, `{"reason":"Unregistered"}`
+		}
+		v := map[string]map[string]interface{}{}
+		json.Unmarshal(body, &v)
+		if v["aps"]["badge"] != float64(2) {
+			return http.StatusBadRequest, `{"reason":"BadPayload"}`
+		}
+		return http.StatusOK, ""
+	}}
+	p := NewApnsProvider("KEY1", "TEAM1", "cc.go2o.app", key, true, tr)
+	m := &Message{Title: "订单已发货", Body: "您的订单已发货", Badge: 2}
+	if err := p.Push("device1", m); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Push("expired", m); err != ErrInvalidToken {
+		t.Fatal("expect invalid token, but", err)
+	}
+	reqs := tr.Requests()
+	if reqs[0].URL.String() != ApnsSandboxUrl+"/3/device/device1" {
+		t.Fatal("url:", reqs[0].URL.String())
+	}
+	// 认证令牌在刷新间隔内重复使用
+	if reqs[0].Header.Get("authorization") != reqs[1].Header.Get("authorization") {
+		t.Fatal("provider token should be reused")
+	}
+}
+
+func TestFcmPush(t *testing.T) {
+	rk, _ := rsa.GenerateKey(rand.Reader, 1024)
+	der, _ := x509.MarshalPKCS8PrivateKey(rk)
+	cred, _ := json.Marshal(&FcmCredentials{
+		ProjectId:    "go2o",
+		PrivateKeyId: "kid1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "push@go2o.iam.gserviceaccount.com",
+		TokenUri:     "https://oauth2.test/token",
+	})
+	tokens := 0
+	tr := &MockTransport{Handler: func(r *http.Request, body []byte) (int, string) {
+		if r.URL.Host == "oauth2.test" {
+			form, _ := url.ParseQuery(string(body))
+			if len(strings.Split(form.Get("assertion"), ".")) != 3 {
+				return http.StatusBadRequest, `{"error":"invalid_grant"}`
+			}
+			tokens++
+			return http.StatusOK, `{"access_token":"at","expires_in":3600}`
+		}
+		if r.Header.Get("Authorization") != "Bearer at" {
+			return http.StatusUnauthorized, `{"error":{"status":"UNAUTHENTICATED"}}`
+		}
+		if !strings.Contains(string(body), `"token":"device1"`) {
+			return http.StatusNotFound, `{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`
+		}
+		return http.StatusOK, `{"name":"projects/go2o/messages/1"}`
+	}}
+	p, err := NewFcmProvider(cred, tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Message{Title: "账户变动", Body: "您的余额已变动", Data: map[string]string{"type": "account"}}
+	if err = p.Push("device1", m); err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Push("device2", m); err != ErrInvalidToken {
+		t.Fatal("expect invalid token, but", err)
+	}
+	if tokens != 1 {
+		t.Fatal("access token should be cached, requested:", tokens)
+	}
+	if _, err = NewFcmProvider([]byte(`{}`), tr); err != ErrFcmCredentials {
+		t.Fatal("expect credentials error")
+	}
+}
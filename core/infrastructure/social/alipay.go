@@ -0,0 +1,183 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : alipay
+ * author : jarryliu
+ * date : 2026-10-19 23:40
+ * description : 支付宝快捷登录,请求使用应用私钥签名(RSA2)
+ * history :
+ */
+package social
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ Provider = new(AlipayProvider)
+
+// 支付宝登录
+type AlipayProvider struct {
+	// 应用编号
+	AppId string
+	// 应用私钥
+	PrivateKey *rsa.PrivateKey
+	// 网关地址,默认为:https://openapi.alipay.com/gateway.do
+	Gateway string
+	// HTTP客户端,为空时使用DefaultClient
+	Client *http.Client
+}
+
+type alipayError struct {
+	Code    string `json:"code"`
+	Msg     string `json:"msg"`
+	SubCode string `json:"sub_code"`
+	SubMsg  string `json:"sub_msg"`
+}
+
+func (a *alipayError) err() error {
+	if a.Code != "" && a.Code != "10000" {
+		return fmt.Errorf("alipay error %s: %s %s", a.Code, a.SubCode, a.SubMsg)
+	}
+	return nil
+}
+
+// 解析应用私钥,支持PKCS1及PKCS8格式;未包含PEM头时按Base64解码
+func ParseAlipayKey(data []byte) (*rsa.PrivateKey, error) {
+	der := data
+	if b, _ := pem.Decode(data); b != nil {
+		der = b.Bytes
+	} else if d, err := base64.StdEncoding.DecodeString(
+		strings.TrimSpace(string(data))); err == nil {
+		der = d
+	}
+	if pk, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return pk, nil
+	}
+	if k, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if pk, ok := k.(*rsa.PrivateKey); ok {
+			return pk, nil
+		}
+	}
+	return nil, errors.New("alipay: invalid private key")
+}
+
+func (a *AlipayProvider) Name() string {
+	return Alipay
+}
+
+// 签名,参数按名称排序后以&连接,不包含sign
+func (a *AlipayProvider) sign(v url.Values) (string, error) {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		if k != "sign" && v.Get(k) != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	arr := make([]string, len(keys))
+	for i, k := range keys {
+		arr[i] = k + "=" + v.Get(k)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(arr, "&")))
+	b, err := rsa.SignPKCS1v15(rand.Reader, a.PrivateKey, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// 调用接口,dst为响应中"{method}_response"节点
+func (a *AlipayProvider) call(method string, params map[string]string, dst interface{}) error {
+	v := url.Values{
+		"app_id":    {a.AppId},
+		"method":    {method},
+		"charset":   {"utf-8"},
+		"sign_type": {"RSA2"},
+		"timestamp": {time.Now().Format("2006-01-02 15:04:05")},
+		"version":   {"1.0"},
+	}
+	for k, p := range params {
+		v.Set(k, p)
+	}
+	sign, err := a.sign(v)
+	if err != nil {
+		return err
+	}
+	v.Set("sign", sign)
+	gateway := a.Gateway
+	if gateway == "" {
+		gateway = "https://openapi.alipay.com/gateway.do"
+	}
+	req, err := http.NewRequest("POST", gateway, strings.NewReader(v.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded;charset=utf-8")
+	rsp := map[string]json.RawMessage{}
+	if err = getJson(a.Client, req, &rsp); err != nil {
+		return err
+	}
+	if data, ok := rsp["error_response"]; ok {
+		e := alipayError{}
+		json.Unmarshal(data, &e)
+		return e.err()
+	}
+	data, ok := rsp[strings.Replace(method, ".", "_", -1)+"_response"]
+	if !ok {
+		return fmt.Errorf("alipay: missing response of %s", method)
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// 使用授权码换取账号信息
+func (a *AlipayProvider) Exchange(code string) (*Identity, error) {
+	token := struct {
+		alipayError
+		UserId      string `json:"user_id"`
+		OpenId      string `json:"open_id"`
+		AccessToken string `json:"access_token"`
+	}{}
+	err := a.call("alipay.system.oauth.token", map[string]string{
+		"grant_type": "authorization_code",
+		"code":       code,
+	}, &token)
+	if err == nil {
+		err = token.err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	id := &Identity{Provider: Alipay, OpenId: token.OpenId}
+	if id.OpenId == "" {
+		id.OpenId = token.UserId
+	}
+	if id.OpenId == "" {
+		return nil, ErrCode
+	}
+	info := struct {
+		alipayError
+		NickName string `json:"nick_name"`
+		Avatar   string `json:"avatar"`
+	}{}
+	// 未授权auth_user时无法获取用户信息
+	if a.call("alipay.user.info.share", map[string]string{
+		"auth_token": token.AccessToken,
+	}, &info) == nil && info.err() == nil {
+		id.Nickname = info.NickName
+		id.Avatar = info.Avatar
+	}
+	return id, nil
+}
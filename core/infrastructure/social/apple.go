@@ -0,0 +1,94 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : apple
+ * author : jarryliu
+ * date : 2026-10-19 23:50
+ * description : 通过Apple登录,校验客户端获取的identity_token
+ * history :
+ */
+package social
+
+import (
+	"errors"
+	"go2o/core/infrastructure/jwt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var _ Provider = new(AppleProvider)
+
+var ErrAppleToken = errors.New("apple identity token invalid")
+
+// 通过Apple登录
+type AppleProvider struct {
+	// 应用的Bundle ID或Services ID,即身份令牌的aud
+	ClientId string
+	// 公钥地址,默认为:https://appleid.apple.com/auth/keys
+	KeysUrl string
+	// 签发者,默认为:https://appleid.apple.com
+	Issuer string
+	// HTTP客户端,为空时使用DefaultClient
+	Client *http.Client
+
+	mux      sync.Mutex
+	keys     *jwt.JWKSet
+	keysTime time.Time
+}
+
+func (a *AppleProvider) Name() string {
+	return Apple
+}
+
+// 获取公钥,缓存1小时;force为true时重新获取
+func (a *AppleProvider) getKeys(force bool) (*jwt.JWKSet, error) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	if !force && a.keys != nil && time.Since(a.keysTime) < time.Hour {
+		return a.keys, nil
+	}
+	u := a.KeysUrl
+	if u == "" {
+		u = "https://appleid.apple.com/auth/keys"
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	set := &jwt.JWKSet{}
+	if err = getJson(a.Client, req, set); err != nil {
+		return nil, err
+	}
+	a.keys, a.keysTime = set, time.Now()
+	return set, nil
+}
+
+// 校验身份令牌,并返回账号信息
+func (a *AppleProvider) Exchange(idToken string) (*Identity, error) {
+	set, err := a.getKeys(false)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := set.Verify(idToken)
+	// Apple更换密钥后重新获取
+	if err == jwt.ErrKeyId {
+		if set, err = a.getKeys(true); err == nil {
+			claims, err = set.Verify(idToken)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	iss := a.Issuer
+	if iss == "" {
+		iss = "https://appleid.apple.com"
+	}
+	if claims.String("iss") != iss || claims.String("aud") != a.ClientId ||
+		claims.String("sub") == "" {
+		return nil, ErrAppleToken
+	}
+	return &Identity{
+		Provider: Apple,
+		OpenId:   claims.String("sub"),
+	}, nil
+}
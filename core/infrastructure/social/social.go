@@ -0,0 +1,133 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : social
+ * author : jarryliu
+ * date : 2026-10-19 23:20
+ * description : 第三方账号登录,各平台实现Provider接口,
+ *               测试时可注册MockProvider替换
+ * history :
+ */
+package social
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// 微信
+	WeChat = "wechat"
+	// 支付宝
+	Alipay = "alipay"
+	// Apple
+	Apple = "apple"
+)
+
+var (
+	ErrNoSuchProvider = errors.New("no such social provider")
+	ErrCode           = errors.New("social auth code invalid")
+
+	mux       sync.RWMutex
+	providers = map[string]Provider{}
+
+	// 默认的HTTP客户端
+	DefaultClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// 第三方账号信息
+type Identity struct {
+	// 平台,如:wechat
+	Provider string
+	// 用户在应用下的唯一标识
+	OpenId string
+	// 用户在平台下的唯一标识(如:微信UnionID),可为空
+	UnionId string
+	// 昵称
+	Nickname string
+	// 头像
+	Avatar string
+}
+
+// 第三方账号平台
+type Provider interface {
+	// 平台名称
+	Name() string
+	// 使用客户端获取的授权码换取账号信息;
+	// Apple传入客户端获取的identity_token
+	Exchange(code string) (*Identity, error)
+}
+
+// 注册平台,相同名称的平台将被替换
+func Register(p Provider) {
+	mux.Lock()
+	defer mux.Unlock()
+	providers[p.Name()] = p
+}
+
+// 获取平台
+func Get(name string) (Provider, error) {
+	mux.RLock()
+	defer mux.RUnlock()
+	if p, ok := providers[name]; ok {
+		return p, nil
+	}
+	return nil, ErrNoSuchProvider
+}
+
+// 获取账号信息
+func Exchange(provider string, code string) (*Identity, error) {
+	p, err := Get(provider)
+	if err != nil {
+		return nil, err
+	}
+	if code == "" {
+		return nil, ErrCode
+	}
+	return p.Exchange(code)
+}
+
+// 请求接口并解析JSON
+func getJson(client *http.Client, req *http.Request, dst interface{}) error {
+	if client == nil {
+		client = DefaultClient
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("social api response %d: %s", rsp.StatusCode, string(data))
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// 模拟的平台,用于测试
+type MockProvider struct {
+	// 平台名称
+	ProviderName string
+	// 授权码对应的账号
+	Identities map[string]*Identity
+}
+
+func (m *MockProvider) Name() string {
+	return m.ProviderName
+}
+
+func (m *MockProvider) Exchange(code string) (*Identity, error) {
+	if v, ok := m.Identities[code]; ok {
+		id := *v
+		id.Provider = m.ProviderName
+		return &id, nil
+	}
+	return nil, ErrCode
+}
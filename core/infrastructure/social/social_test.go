@@ -0,0 +1,146 @@
+package social
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"go2o/core/infrastructure/jwt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWeChatExchange(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/sns/oauth2/access_token":
+			if q.Get("code") != "code1" || q.Get("appid") != "wx1" {
+				w.Write([]byte(`{"errcode":40029,"errmsg":"invalid code"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"at","openid":"o1","scope":"snsapi_userinfo"}`))
+		case "/sns/userinfo":
+			w.Write([]byte(`{"nickname":"jarry","headimgurl":"http://img/1.jpg","unionid":"u1"}`))
+		}
+	}))
+	defer s.Close()
+	p := &WeChatProvider{AppId: "wx1", AppSecret: "secret", BaseUrl: s.URL}
+	id, err := p.Exchange("code1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.OpenId != "o1" || id.UnionId != "u1" || id.Nickname != "jarry" {
+		t.Errorf("unexpected identity: %+v", id)
+	}
+	if _, err = p.Exchange("bad"); err == nil {
+		t.Error("expect error for invalid code")
+	}
+}
+
+func TestAlipayExchange(t *testing.T) {
+	pk, _ := rsa.GenerateKey(rand.Reader, 1024)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		// 校验请求签名
+		keys := []string{}
+		for k := range r.PostForm {
+			if k != "sign" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		arr := []string{}
+		for _, k := range keys {
+			arr = append(arr, k+"="+r.PostForm.Get(k))
+		}
+		sum := sha256.Sum256([]byte(strings.Join(arr, "&")))
+		sig, _ := base64.StdEncoding.DecodeString(r.PostForm.Get("sign"))
+		if rsa.VerifyPKCS1v15(&pk.PublicKey, crypto.SHA256, sum[:], sig) != nil {
+			w.Write([]byte(`{"error_response":{"code":"40002","sub_code":"isv.invalid-signature"}}`))
+			return
+		}
+		switch r.PostForm.Get("method") {
+		case "alipay.system.oauth.token":
+			w.Write([]byte(`{"alipay_system_oauth_token_response":{"user_id":"2088","access_token":"at"}}`))
+		case "alipay.user.info.share":
+			w.Write([]byte(`{"alipay_user_info_share_response":{"code":"10000","nick_name":"jarry"}}`))
+		}
+	}))
+	defer s.Close()
+	p := &AlipayProvider{AppId: "2017", PrivateKey: pk, Gateway: s.URL}
+	id, err := p.Exchange("code1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.OpenId != "2088" || id.Nickname != "jarry" {
+		t.Errorf("unexpected identity: %+v", id)
+	}
+}
+
+func TestParseAlipayKey(t *testing.T) {
+	pk, _ := rsa.GenerateKey(rand.Reader, 1024)
+	der := x509.MarshalPKCS1PrivateKey(pk)
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: der})
+	for _, data := range [][]byte{pemData, []byte(base64.StdEncoding.EncodeToString(der))} {
+		k, err := ParseAlipayKey(data)
+		if err != nil || k.N.Cmp(pk.N) != 0 {
+			t.Errorf("parse key failed: %v", err)
+		}
+	}
+	if _, err := ParseAlipayKey([]byte("bad")); err == nil {
+		t.Error("expect error for invalid key")
+	}
+}
+
+func TestAppleExchange(t *testing.T) {
+	key, _ := jwt.GenerateKey(1024)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&jwt.JWKSet{Keys: []*jwt.JWK{key.JWK()}})
+	}))
+	defer s.Close()
+	p := &AppleProvider{ClientId: "to.go2o.app", KeysUrl: s.URL}
+	token, _ := key.Sign(jwt.Claims{
+		"iss": "https://appleid.apple.com",
+		"aud": "to.go2o.app",
+		"sub": "001.apple",
+		"exp": time.Now().Unix() + 60,
+	})
+	id, err := p.Exchange(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.OpenId != "001.apple" {
+		t.Errorf("unexpected identity: %+v", id)
+	}
+	token, _ = key.Sign(jwt.Claims{
+		"iss": "https://appleid.apple.com",
+		"aud": "other.app",
+		"sub": "001.apple",
+	})
+	if _, err = p.Exchange(token); err != ErrAppleToken {
+		t.Errorf("expect audience error, got %v", err)
+	}
+}
+
+func TestMockProvider(t *testing.T) {
+	Register(&MockProvider{
+		ProviderName: "mock",
+		Identities:   map[string]*Identity{"c1": {OpenId: "m1"}},
+	})
+	id, err := Exchange("mock", "c1")
+	if err != nil || id.OpenId != "m1" || id.Provider != "mock" {
+		t.Errorf("unexpected identity: %+v, %v", id, err)
+	}
+	if _, err = Exchange("none", "c1"); err != ErrNoSuchProvider {
+		t.Errorf("expect no such provider, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : wechat
+ * author : jarryliu
+ * date : 2026-10-19 23:30
+ * description : 微信网页授权及移动应用登录
+ * history :
+ */
+package social
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+var _ Provider = new(WeChatProvider)
+
+// 微信登录
+type WeChatProvider struct {
+	// 应用编号,参见:valueobject.WxApiConfig
+	AppId string
+	// 应用密钥
+	AppSecret string
+	// 接口地址,默认为:https://api.weixin.qq.com
+	BaseUrl string
+	// HTTP客户端,为空时使用DefaultClient
+	Client *http.Client
+}
+
+type wxResult struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+func (w *wxResult) err() error {
+	if w.ErrCode != 0 {
+		return fmt.Errorf("wechat error %d: %s", w.ErrCode, w.ErrMsg)
+	}
+	return nil
+}
+
+func (w *WeChatProvider) Name() string {
+	return WeChat
+}
+
+func (w *WeChatProvider) get(path string, v url.Values, dst interface{}) error {
+	base := w.BaseUrl
+	if base == "" {
+		base = "https://api.weixin.qq.com"
+	}
+	req, err := http.NewRequest("GET", base+path+"?"+v.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	return getJson(w.Client, req, dst)
+}
+
+// 使用授权码换取账号信息
+func (w *WeChatProvider) Exchange(code string) (*Identity, error) {
+	token := struct {
+		wxResult
+		AccessToken string `json:"access_token"`
+		OpenId      string `json:"openid"`
+		UnionId     string `json:"unionid"`
+		Scope       string `json:"scope"`
+	}{}
+	err := w.get("/sns/oauth2/access_token", url.Values{
+		"appid":      {w.AppId},
+		"secret":     {w.AppSecret},
+		"code":       {code},
+		"grant_type": {"authorization_code"},
+	}, &token)
+	if err == nil {
+		err = token.err()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if token.OpenId == "" {
+		return nil, ErrCode
+	}
+	id := &Identity{
+		Provider: WeChat,
+		OpenId:   token.OpenId,
+		UnionId:  token.UnionId,
+	}
+	// 静默授权(snsapi_base)无法获取用户信息
+	if token.Scope == "snsapi_base" {
+		return id, nil
+	}
+	info := struct {
+		wxResult
+		Nickname   string `json:"nickname"`
+		HeadImgUrl string `json:"headimgurl"`
+		UnionId    string `json:"unionid"`
+	}{}
+	err = w.get("/sns/userinfo", url.Values{
+		"access_token": {token.AccessToken},
+		"openid":       {token.OpenId},
+	}, &info)
+	if err == nil && info.err() == nil {
+		id.Nickname = info.Nickname
+		id.Avatar = info.HeadImgUrl
+		if id.UnionId == "" {
+			id.UnionId = info.UnionId
+		}
+	}
+	return id, nil
+}
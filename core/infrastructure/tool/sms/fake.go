@@ -0,0 +1,97 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : fake.go
+ * author : jarryliu
+ * date : 2026-10-21 11:00
+ * description : 本地短信,不发送短信,仅记录短信内容,用于开发及测试
+ * history :
+ */
+package sms
+
+import (
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 本地短信
+var Fake = NewFakeProvider()
+
+var _ IProvider = new(FakeProvider)
+var _ IReceiptParser = new(FakeProvider)
+
+// 本地记录的短信
+type FakeMessage struct {
+	MsgId   string
+	Key     string
+	Phone   string
+	Content string
+}
+
+// 本地短信服务商
+type FakeProvider struct {
+	mux      sync.Mutex
+	seq      int
+	err      error
+	messages []*FakeMessage
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{messages: []*FakeMessage{}}
+}
+
+// 设置发送返回的错误,为nil时发送成功
+func (f *FakeProvider) SetError(err error) {
+	f.mux.Lock()
+	f.err = err
+	f.mux.Unlock()
+}
+
+func (f *FakeProvider) Send(key string, phone string, content string,
+	param map[string]interface{}) (string, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.err != nil {
+		return "", f.err
+	}
+	f.seq++
+	m := &FakeMessage{
+		MsgId:   "fake-" + strconv.Itoa(f.seq),
+		Key:     key,
+		Phone:   phone,
+		Content: compile(content, param),
+	}
+	f.messages = append(f.messages, m)
+	return m.MsgId, nil
+}
+
+// 获取已发送的短信
+func (f *FakeProvider) Messages() []*FakeMessage {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return append([]*FakeMessage{}, f.messages...)
+}
+
+// 清除短信及错误
+func (f *FakeProvider) Reset() {
+	f.mux.Lock()
+	f.err = nil
+	f.messages = []*FakeMessage{}
+	f.mux.Unlock()
+}
+
+// 状态报告参数:msgid,phone,status,status为DELIVRD时表示已送达
+func (f *FakeProvider) ParseReceipt(values url.Values) ([]*Receipt, error) {
+	r := &Receipt{
+		MsgId:      values.Get("msgid"),
+		Phone:      values.Get("phone"),
+		Status:     values.Get("status"),
+		ReportTime: time.Now().Unix(),
+	}
+	if r.MsgId == "" || r.Status == "" {
+		return nil, ErrReceipt
+	}
+	r.Delivered = r.Status == "DELIVRD"
+	return []*Receipt{r}, nil
+}
@@ -0,0 +1,164 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : provider.go
+ * author : jarryliu
+ * date : 2026-10-21 10:10
+ * description : 短信服务商,各服务商以统一的接口发送短信及解析状态报告
+ * history :
+ */
+package sms
+
+import (
+	"crypto/subtle"
+	"errors"
+	"go2o/core/domain/interface/valueobject"
+	"go2o/core/infrastructure/iface/aliyu"
+	"go2o/core/infrastructure/iface/cl253"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrNoTemplate = errors.New("短信服务商未设置通知对应的模板")
+	ErrNoProvider = errors.New("没有可用的短信接口")
+	ErrReceipt    = errors.New("短信状态报告格式不正确")
+)
+
+type (
+	// 短信服务商
+	IProvider interface {
+		// 发送短信,key为通知项,用于获取服务商的模板编号;
+		// 返回服务商的短信编号,不支持时返回空
+		Send(key string, phone string, content string,
+			param map[string]interface{}) (msgId string, err error)
+	}
+
+	// 解析状态报告,服务商支持回执时实现
+	IReceiptParser interface {
+		ParseReceipt(values url.Values) ([]*Receipt, error)
+	}
+
+	// 短信状态报告
+	Receipt struct {
+		// 服务商的短信编号
+		MsgId string
+		// 手机号码
+		Phone string
+		// 是否已送达
+		Delivered bool
+		// 服务商返回的状态
+		Status string
+		// 报告时间
+		ReportTime int64
+	}
+)
+
+// 创建服务商
+func NewProvider(provider int, perm *valueobject.SmsApiPerm) (IProvider, error) {
+	switch provider {
+	case SmsHttp:
+		return &httpProvider{perm: perm}, nil
+	case SmsAli:
+		return &aliProvider{perm: perm}, nil
+	case SmsCl253:
+		return &cl253Provider{perm: perm}, nil
+	case SmsFake:
+		return Fake, nil
+	}
+	return nil, errors.New("未知的短信接口服务商" + strconv.Itoa(provider))
+}
+
+var _ IProvider = new(httpProvider)
+
+// HTTP短信接口
+type httpProvider struct {
+	perm *valueobject.SmsApiPerm
+}
+
+func (h *httpProvider) Send(key string, phone string, content string,
+	param map[string]interface{}) (string, error) {
+	p := h.perm
+	return "", sendPhoneMsgByHttpApi(p.ApiUrl, p.ApiKey, p.ApiSecret, phone,
+		compile(content, param), p.Encoding, p.SuccessChar)
+}
+
+var _ IProvider = new(aliProvider)
+
+// 阿里大鱼,须使用服务商的模板发送
+type aliProvider struct {
+	perm *valueobject.SmsApiPerm
+}
+
+func (a *aliProvider) Send(key string, phone string, content string,
+	param map[string]interface{}) (string, error) {
+	// 复制参数,避免修改调用方的数据及影响其他服务商
+	data := make(map[string]interface{}, len(param)+2)
+	for k, v := range param {
+		data[k] = v
+	}
+	if s, _ := data[aliyu.ParamKeyTplId].(string); s == "" {
+		tplId := a.perm.Templates[key]
+		if tplId == "" {
+			return "", ErrNoTemplate
+		}
+		data[aliyu.ParamKeyTplId] = tplId
+	}
+	if s, _ := data[aliyu.ParamKeyTplName].(string); s == "" {
+		data[aliyu.ParamKeyTplName] = a.perm.Signature
+	}
+	return "", aliyu.SendSms(a.perm.ApiKey, a.perm.ApiSecret, phone, content, data)
+}
+
+var _ IProvider = new(cl253Provider)
+var _ IReceiptParser = new(cl253Provider)
+
+// 创蓝253
+type cl253Provider struct {
+	perm *valueobject.SmsApiPerm
+}
+
+func (c *cl253Provider) Send(key string, phone string, content string,
+	param map[string]interface{}) (string, error) {
+	return cl253.SendMsg(c.perm.ApiKey, c.perm.ApiSecret, phone, compile(content, param))
+}
+
+// 状态报告参数:msgid,mobile,status,reportTime(如:1607061230),
+// status为DELIVRD时表示已送达
+func (c *cl253Provider) ParseReceipt(values url.Values) ([]*Receipt, error) {
+	r := &Receipt{
+		MsgId:  values.Get("msgid"),
+		Phone:  values.Get("mobile"),
+		Status: values.Get("status"),
+	}
+	if r.MsgId == "" || r.Status == "" {
+		return nil, ErrReceipt
+	}
+	r.Delivered = r.Status == "DELIVRD"
+	r.ReportTime = time.Now().Unix()
+	if t, err := time.ParseInLocation("0601021504", values.Get("reportTime"),
+		time.Local); err == nil {
+		r.ReportTime = t.Unix()
+	}
+	return []*Receipt{r}, nil
+}
+
+// 解析服务商的状态报告,未设置回执令牌、令牌不正确或服务商不支持时返回错误
+func ParseReceipt(provider int, perm *valueobject.SmsApiPerm,
+	values url.Values) ([]*Receipt, error) {
+	if perm.ReceiptToken == "" {
+		return nil, errors.New("未设置回执令牌,不接收状态报告")
+	}
+	if subtle.ConstantTimeCompare([]byte(values.Get("token")),
+		[]byte(perm.ReceiptToken)) != 1 {
+		return nil, errors.New("回执令牌不正确")
+	}
+	p, err := NewProvider(provider, perm)
+	if err != nil {
+		return nil, err
+	}
+	if rp, ok := p.(IReceiptParser); ok {
+		return rp.ParseReceipt(values)
+	}
+	return nil, errors.New("短信服务商不支持状态报告")
+}
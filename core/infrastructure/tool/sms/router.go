@@ -0,0 +1,183 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : router.go
+ * author : jarryliu
+ * date : 2026-10-21 10:40
+ * description : 短信故障转移,按顺序使用服务商发送,连续失败的服务商
+ *               暂停使用一段时间,暂停时间按失败次数增长;
+ *               健康状态仅保存在当前进程的内存中,多个进程各自统计,
+ *               进程重启后重新统计
+ * history :
+ */
+package sms
+
+import (
+	"fmt"
+	"go2o/core/domain/interface/valueobject"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 连续失败多少次后暂停使用
+	healthFailLimit = 3
+	// 首次暂停的时间
+	healthCooldown = time.Minute
+	// 最长暂停的时间
+	healthMaxCooldown = time.Minute * 10
+)
+
+// 服务商的健康状态,不在进程间共享
+type health struct {
+	// 连续失败次数
+	fails int
+	// 暂停使用至该时间
+	downUntil time.Time
+}
+
+var (
+	healthMux sync.Mutex
+	healthMap = map[int]*health{}
+)
+
+// 服务商是否可用
+func healthy(provider int, now time.Time) bool {
+	healthMux.Lock()
+	defer healthMux.Unlock()
+	h, ok := healthMap[provider]
+	return !ok || !now.Before(h.downUntil)
+}
+
+// 记录发送结果,连续失败达到次数时暂停使用,之后每次失败暂停时间加倍
+func markHealth(provider int, err error, now time.Time) {
+	healthMux.Lock()
+	defer healthMux.Unlock()
+	h, ok := healthMap[provider]
+	if !ok {
+		h = &health{}
+		healthMap[provider] = h
+	}
+	if err == nil {
+		h.fails = 0
+		h.downUntil = time.Time{}
+		return
+	}
+	if h.fails++; h.fails >= healthFailLimit {
+		d := healthCooldown
+		for i := healthFailLimit; i < h.fails && d < healthMaxCooldown; i++ {
+			d *= 2
+		}
+		if d > healthMaxCooldown {
+			d = healthMaxCooldown
+		}
+		h.downUntil = now.Add(d)
+	}
+}
+
+// 重置服务商的健康状态
+func ResetHealth() {
+	healthMux.Lock()
+	healthMap = map[int]*health{}
+	healthMux.Unlock()
+}
+
+// 发送结果
+type SendResult struct {
+	// 最终发送的服务商
+	Provider int
+	// 服务商的短信编号
+	MsgId string
+	// 各服务商的发送结果,如:"2:err;3:ok"
+	Trace string
+}
+
+// 故障转移的服务商
+type route struct {
+	id       int
+	provider IProvider
+}
+
+// 短信发送路由
+type Router struct {
+	routes []*route
+}
+
+func NewRouter() *Router {
+	return &Router{routes: []*route{}}
+}
+
+// 添加服务商,按添加的顺序使用
+func (r *Router) Add(id int, p IProvider) *Router {
+	r.routes = append(r.routes, &route{id: id, provider: p})
+	return r
+}
+
+// 根据短信接口设置创建路由,默认接口优先,
+// 其余设置了顺序的接口按顺序转移
+func NewRouterFromSet(set valueobject.SmsApiSet) *Router {
+	ids := []int{}
+	for id, v := range set {
+		if !v.Default && v.Priority > 0 {
+			ids = append(ids, id)
+		}
+	}
+	// 按顺序排列,顺序相同时按编号
+	less := func(a, b int) bool {
+		if set[a].Priority != set[b].Priority {
+			return set[a].Priority < set[b].Priority
+		}
+		return a < b
+	}
+	for i := 1; i < len(ids); i++ {
+		for j := i; j > 0 && less(ids[j], ids[j-1]); j-- {
+			ids[j], ids[j-1] = ids[j-1], ids[j]
+		}
+	}
+	for id, v := range set {
+		if v.Default {
+			ids = append([]int{id}, ids...)
+			break
+		}
+	}
+	r := NewRouter()
+	for _, id := range ids {
+		if p, err := NewProvider(id, set[id]); err == nil {
+			r.Add(id, p)
+		}
+	}
+	return r
+}
+
+// 发送短信,优先使用可用的服务商,均不可用时仍依次尝试
+func (r *Router) Send(key string, phone string, content string,
+	param map[string]interface{}) (*SendResult, error) {
+	now := time.Now()
+	list := make([]*route, 0, len(r.routes))
+	down := []*route{}
+	for _, v := range r.routes {
+		if healthy(v.id, now) {
+			list = append(list, v)
+		} else {
+			down = append(down, v)
+		}
+	}
+	list = append(list, down...)
+	ret := &SendResult{}
+	trace := []string{}
+	err := ErrNoProvider
+	for _, v := range list {
+		var msgId string
+		msgId, err = v.provider.Send(key, phone, content, param)
+		markHealth(v.id, err, time.Now())
+		ret.Provider = v.id
+		if err == nil {
+			ret.MsgId = msgId
+			trace = append(trace, fmt.Sprintf("%d:ok", v.id))
+			break
+		}
+		trace = append(trace, fmt.Sprintf("%d:%s", v.id, err.Error()))
+	}
+	ret.Trace = strings.Join(trace, ";")
+	return ret, err
+}
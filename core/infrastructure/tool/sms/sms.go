@@ -10,11 +10,11 @@ package sms
 
 import (
 	"errors"
+	"fmt"
 	"github.com/jsix/gof/util"
 	"go2o/core/domain/interface/valueobject"
 	"go2o/core/infrastructure/format"
 	"go2o/core/infrastructure/iface/aliyu"
-	"go2o/core/infrastructure/iface/cl253"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -26,22 +26,24 @@ const (
 	SmsHttp  = 1 // HTTP
 	SmsAli   = 2 //阿里大鱼
 	SmsCl253 = 3 //创蓝253
+	SmsFake  = 9 //本地短信
 )
 
 // 发送短信,tpl:短信内容模板
 func SendSms(provider int, appKey, appSecret, phoneNum string,
 	apiUrl string, enc string, successChar string, tpl string,
 	param map[string]interface{}) error {
-	switch provider {
-	case SmsHttp:
-		return sendPhoneMsgByHttpApi(apiUrl, appKey, appSecret, phoneNum,
-			compile(tpl, param), enc, successChar)
-	case SmsAli:
-		return aliyu.SendSms(appKey, appSecret, phoneNum, tpl, param)
-	case SmsCl253:
-		return cl253.SendMsgToMobile(appKey, appSecret, phoneNum, compile(tpl, param))
+	p, err := NewProvider(provider, &valueobject.SmsApiPerm{
+		ApiKey:      appKey,
+		ApiSecret:   appSecret,
+		ApiUrl:      apiUrl,
+		Encoding:    enc,
+		SuccessChar: successChar,
+	})
+	if err == nil {
+		_, err = p.Send("", phoneNum, tpl, param)
 	}
-	return errors.New("未知的短信接口服务商" + strconv.Itoa(provider))
+	return err
 }
 
 // 解析模板中的参数
@@ -52,9 +54,11 @@ func compile(tpl string, param map[string]interface{}) string {
 		case string:
 			str = v.(string)
 		case int, int32, int64:
-			str = strconv.Itoa(v.(int))
-		case float32, float64:
+			str = fmt.Sprint(v)
+		case float32:
 			str = format.FormatFloat(v.(float32))
+		case float64:
+			str = format.FormatFloat64(v.(float64))
 		case bool:
 			str = strconv.FormatBool(v.(bool))
 		default:
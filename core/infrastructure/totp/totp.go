@@ -0,0 +1,112 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : totp
+ * author : jarryliu
+ * date : 2026-10-19 19:10
+ * description : 基于时间的一次性密码(RFC 6238),兼容常见的身份验证器应用
+ * history :
+ */
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// 验证码位数
+	Digits = 6
+	// 时间步长(秒)
+	Period int64 = 30
+
+	ErrSecret = errors.New("incorrect totp secret")
+)
+
+// 生成随机密钥(Base32编码)
+func NewSecret() string {
+	b := make([]byte, 20)
+	rand.Read(b)
+	return strings.TrimRight(base32.StdEncoding.EncodeToString(b), "=")
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.Replace(secret, " ", "", -1))
+	s = strings.TrimRight(s, "=")
+	if n := len(s) % 8; n != 0 {
+		s += strings.Repeat("=", 8-n)
+	}
+	key, err := base32.StdEncoding.DecodeString(s)
+	if err != nil || len(key) == 0 {
+		return nil, ErrSecret
+	}
+	return key, nil
+}
+
+// 获取时间对应的步数
+func Step(t time.Time) int64 {
+	return t.Unix() / Period
+}
+
+// 计算一次性密码(RFC 4226)
+func hotp(key []byte, counter int64, digits int) string {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(counter))
+	m := hmac.New(sha1.New, key)
+	m.Write(b)
+	sum := m.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, v%mod)
+}
+
+// 获取指定时间的验证码
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, Step(t), Digits), nil
+}
+
+// 校验验证码,skew为允许前后偏差的步数;
+// 返回验证码对应的步数,用于防止同一验证码重复使用
+func Validate(secret string, code string, t time.Time, skew int) (int64, bool) {
+	key, err := decodeSecret(secret)
+	code = strings.TrimSpace(code)
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+	step := Step(t)
+	for i := -skew; i <= skew; i++ {
+		c := hotp(key, step+int64(i), Digits)
+		if hmac.Equal([]byte(c), []byte(code)) {
+			return step + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+// 获取验证器应用绑定使用的地址,可生成二维码供扫描,如:
+// otpauth://totp/go2o:jarry?secret=...&issuer=go2o
+func ProvisioningUri(issuer string, account string, secret string) string {
+	v := url.Values{}
+	v.Set("secret", secret)
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprintf("%d", Digits))
+	v.Set("period", fmt.Sprintf("%d", Period))
+	label := strings.Replace(url.QueryEscape(issuer+":"+account), "+", "%20", -1)
+	return "otpauth://totp/" + label + "?" + v.Encode()
+}
@@ -0,0 +1,52 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 附录B的测试数据(SHA1)
+func TestHotp(t *testing.T) {
+	key := []byte("12345678901234567890")
+	cases := map[int64]string{
+		59:          "94287082",
+		1111111109:  "07081804",
+		1111111111:  "14050471",
+		1234567890:  "89005924",
+		2000000000:  "69279037",
+		20000000000: "65353130",
+	}
+	for unix, code := range cases {
+		if c := hotp(key, unix/Period, 8); c != code {
+			t.Errorf("time %d: expect %s, got %s", unix, code, c)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+	if c, _ := Code(secret, now); c != "081804" {
+		t.Fatalf("expect 081804, got %s", c)
+	}
+	if step, ok := Validate(secret, "081804", now.Add(time.Second*30), 1); !ok || step != Step(now) {
+		t.Fatal("code should be valid in previous step")
+	}
+	if _, ok := Validate(secret, "081804", now.Add(time.Minute*2), 1); ok {
+		t.Fatal("code should be expired")
+	}
+	s := NewSecret()
+	c, _ := Code(strings.ToLower(s), now)
+	if _, ok := Validate(s, c, now, 0); !ok {
+		t.Fatal("new secret should be valid")
+	}
+	if _, err := Code("1@#", now); err == nil {
+		t.Fatal("secret should be incorrect")
+	}
+	uri := ProvisioningUri("go2o", "jarry@go2o", "ABC")
+	if !strings.HasPrefix(uri, "otpauth://totp/go2o%3Ajarry%40go2o?") {
+		t.Fatal("incorrect uri:", uri)
+	}
+}
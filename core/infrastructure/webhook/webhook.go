@@ -0,0 +1,176 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : webhook
+ * author : jarryliu
+ * date : 2026-10-20 21:30
+ * description : 向商户推送事件,请求体以HMAC-SHA256签名,
+ *               签名内容为"时间戳.请求体",接收方应校验签名及时间戳;
+ *               默认客户端在连接时校验解析后的地址,不连接内网地址
+ * history :
+ */
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// 事件类型
+	HeaderEvent = "X-Go2o-Event"
+	// 推送编号
+	HeaderDelivery = "X-Go2o-Delivery"
+	// 时间戳
+	HeaderTimestamp = "X-Go2o-Timestamp"
+	// 签名
+	HeaderSignature = "X-Go2o-Signature"
+	// 签名前缀
+	signPrefix = "sha256="
+	// 保存的响应内容最大长度
+	maxResponseBody = 1024
+)
+
+var (
+	ErrUrl       = errors.New("webhook url must start with http:// or https://")
+	ErrForbidden = errors.New("webhook address is forbidden")
+)
+
+// 默认的HTTP客户端,不使用代理,仅连接公网地址
+var DefaultClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext:           dialPublic,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost:   2,
+		IdleConnTimeout:       30 * time.Second,
+	},
+}
+
+var dialer = &net.Dialer{Timeout: 5 * time.Second}
+
+// 是否为禁止连接的地址,包括回环、内网、链路本地、未指定及组播地址
+func Forbidden(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// 解析域名后校验地址,并连接校验过的地址,避免DNS重绑定绕过校验
+func dialPublic(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range ips {
+		if Forbidden(v.IP) {
+			return nil, ErrForbidden
+		}
+	}
+	if len(ips) == 0 {
+		return nil, ErrForbidden
+	}
+	return dialer.DialContext(ctx, network,
+		net.JoinHostPort(ips[0].IP.String(), port))
+}
+
+// 校验推送地址,地址为IP时不能为内网地址,域名在推送时校验
+func CheckUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrUrl
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrForbidden
+	}
+	if ip := net.ParseIP(host); ip != nil && Forbidden(ip) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// 推送结果
+type Result struct {
+	// 响应状态码,请求失败时为0
+	Code int
+	// 响应内容,最多保存1024字节
+	Body string
+	// 耗时(毫秒)
+	Duration int64
+	// 错误
+	Err error
+}
+
+// 是否推送成功,响应状态码为2xx时成功
+func (r *Result) Success() bool {
+	return r.Err == nil && r.Code >= 200 && r.Code < 300
+}
+
+// 签名
+func Sign(secret string, timestamp int64, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return signPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// 校验签名
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, signPrefix) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// 推送事件,client为nil时使用DefaultClient
+func Post(client *http.Client, url string, secret string, eventType string,
+	deliveryId string, body []byte) *Result {
+	if client == nil {
+		client = DefaultClient
+	}
+	r := &Result{}
+	begin := time.Now()
+	defer func() {
+		r.Duration = int64(time.Since(begin) / time.Millisecond)
+	}()
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	unix := begin.Unix()
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", "go2o-webhook")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderDelivery, deliveryId)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(unix, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, unix, body))
+	rsp, err := client.Do(req)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	defer rsp.Body.Close()
+	r.Code = rsp.StatusCode
+	data, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, maxResponseBody))
+	r.Body = string(data)
+	return r
+}
@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"orderNo":"1000"}`)
+	sign := Sign("secret", 1500000000, body)
+	if !Verify("secret", 1500000000, body, sign) {
+		t.Error("verify failed")
+	}
+	if Verify("secret", 1500000001, body, sign) {
+		t.Error("timestamp not signed")
+	}
+	if Verify("other", 1500000000, body, sign) {
+		t.Error("secret not checked")
+	}
+	if Verify("secret", 1500000000, []byte(`{}`), sign) {
+		t.Error("body not signed")
+	}
+}
+
+func TestPost(t *testing.T) {
+	code := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		unix, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if r.Header.Get(HeaderEvent) != "order.paid" ||
+			r.Header.Get(HeaderDelivery) != "1" ||
+			!Verify("secret", unix, body, r.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(code)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	// 测试服务监听在回环地址,使用不校验地址的客户端
+	client := srv.Client()
+	r := Post(client, srv.URL, "secret", "order.paid", "1", []byte(`{}`))
+	if !r.Success() || r.Body != "ok" {
+		t.Errorf("post failed: %d %s %v", r.Code, r.Body, r.Err)
+	}
+	code = http.StatusInternalServerError
+	if r = Post(client, srv.URL, "secret", "order.paid", "1", []byte(`{}`)); r.Success() {
+		t.Error("5xx should be failed")
+	}
+	if r = Post(client, srv.URL, "wrong", "order.paid", "1", []byte(`{}`)); r.Code != http.StatusUnauthorized {
+		t.Errorf("expect 401, but %d", r.Code)
+	}
+	if r = Post(client, "http://127.0.0.1:1", "secret", "order.paid", "1", nil); r.Err == nil {
+		t.Error("expect connection error")
+	}
+	// 默认客户端不连接内网地址
+	if r = Post(nil, srv.URL, "secret", "order.paid", "1", []byte(`{}`)); r.Err == nil ||
+		!strings.Contains(r.Err.Error(), ErrForbidden.Error()) {
+		t.Errorf("loopback should be forbidden, got: %v", r.Err)
+	}
+}
+
+func TestCheckUrl(t *testing.T) {
+	for _, v := range []string{"https://example.com/hook", "http://8.8.8.8:8080/"} {
+		if err := CheckUrl(v); err != nil {
+			t.Error(v, err)
+		}
+	}
+	for _, v := range []string{"ftp://example.com", "http://", "http://localhost/",
+		"http://127.0.0.1/", "http://10.0.0.1/", "http://192.168.1.1/",
+		"http://169.254.169.254/latest", "http://[::1]/", "http://0.0.0.0/"} {
+		if CheckUrl(v) == nil {
+			t.Error("url should be rejected:", v)
+		}
+	}
+}
@@ -17,9 +17,11 @@ import (
 	"go2o/core/dao/model"
 	"go2o/core/domain/interface/ad"
 	"go2o/core/domain/interface/after-sales"
+	"go2o/core/domain/interface/audit"
 	"go2o/core/domain/interface/cart"
 	"go2o/core/domain/interface/content"
 	"go2o/core/domain/interface/delivery"
+	"go2o/core/domain/interface/event"
 	"go2o/core/domain/interface/express"
 	"go2o/core/domain/interface/item"
 	"go2o/core/domain/interface/member"
@@ -28,12 +30,18 @@ import (
 	"go2o/core/domain/interface/merchant/user"
 	"go2o/core/domain/interface/merchant/wholesaler"
 	"go2o/core/domain/interface/mss"
+	"go2o/core/domain/interface/mss/chat"
+	"go2o/core/domain/interface/mss/inbox"
+	"go2o/core/domain/interface/mss/notify"
+	"go2o/core/domain/interface/oauth"
 	"go2o/core/domain/interface/order"
 	"go2o/core/domain/interface/payment"
 	"go2o/core/domain/interface/personfinance"
 	"go2o/core/domain/interface/pro_model"
 	"go2o/core/domain/interface/product"
 	"go2o/core/domain/interface/promotion"
+	"go2o/core/domain/interface/risk"
+	"go2o/core/domain/interface/security"
 	"go2o/core/domain/interface/shipment"
 	"go2o/core/domain/interface/valueobject"
 	"go2o/core/dto"
@@ -52,6 +60,7 @@ func registerTypes() {
 	gob.Register(&member.Member{})
 	gob.Register(&merchant.Merchant{})
 	gob.Register(&merchant.ApiInfo{})
+	gob.Register(&merchant.ApiLimit{})
 	gob.Register(&shop.OnlineShop{})
 	gob.Register(&shop.OfflineShop{})
 	gob.Register(&shop.ComplexShop{})
@@ -95,6 +104,16 @@ func OrmMapping(conn db.Connector) {
 	orm.Mapping(mss.To{}, "msg_to")
 	orm.Mapping(mss.Content{}, "msg_content")
 	orm.Mapping(mss.Replay{}, "msg_replay")
+	orm.Mapping(notify.Template{}, "notify_template")
+	orm.Mapping(notify.Preference{}, "mm_notify_pref")
+	orm.Mapping(notify.EventPref{}, "mm_notify_event_pref")
+	orm.Mapping(notify.Delivery{}, "notify_delivery")
+	orm.Mapping(notify.SmsMessage{}, "sms_message")
+	orm.Mapping(chat.Session{}, "mss_chat_session")
+	orm.Mapping(chat.Message{}, "mss_chat_msg")
+	orm.Mapping(chat.Agent{}, "mss_chat_agent")
+	orm.Mapping(inbox.Broadcast{}, "mss_broadcast")
+	orm.Mapping(inbox.Message{}, "mss_inbox")
 
 	/* 内容 */
 	orm.Mapping(content.Page{}, "ex_page")
@@ -117,6 +136,7 @@ func OrmMapping(conn db.Connector) {
 	orm.Mapping(member.BankInfo{}, "mm_bank")
 	orm.Mapping(member.LevelUpLog{}, "mm_levelup")
 	orm.Mapping(member.BuyerGroup{}, "mm_buyer_group")
+	orm.Mapping(member.SocialIdentity{}, "mm_social_identity")
 
 	//** ORDER **//
 
@@ -171,6 +191,9 @@ func OrmMapping(conn db.Connector) {
 	orm.Mapping(merchant.Merchant{}, "mch_merchant")
 	orm.Mapping(merchant.EnterpriseInfo{}, "mch_enterprise_info")
 	orm.Mapping(merchant.ApiInfo{}, "mch_api_info")
+	orm.Mapping(merchant.ApiLimit{}, "mch_api_limit")
+	orm.Mapping(merchant.Webhook{}, "mch_webhook")
+	orm.Mapping(merchant.WebhookDelivery{}, "mch_webhook_delivery")
 	orm.Mapping(shop.Shop{}, "mch_shop")
 	orm.Mapping(shop.OnlineShop{}, "mch_online_shop")
 	orm.Mapping(shop.OfflineShop{}, "mch_offline_shop")
@@ -206,11 +229,35 @@ func OrmMapping(conn db.Connector) {
 	orm.Mapping(delivery.AreaValue{}, "dlv_area")
 	orm.Mapping(delivery.CoverageValue{}, "dlv_coverage")
 	orm.Mapping(delivery.MerchantDeliverBind{}, "dlv_merchant_bind")
+	orm.Mapping(delivery.DeliveryStaff{}, "dlv_staff")
+	orm.Mapping(delivery.DispatchOrder{}, "dlv_dispatch")
+	orm.Mapping(delivery.DispatchCheckIn{}, "dlv_dispatch_checkin")
 
 	/** 用户 **/
 	orm.Mapping(user.RoleValue{}, "usr_role")
 	orm.Mapping(user.PersonValue{}, "usr_person")
 	orm.Mapping(user.CredentialValue{}, "usr_credential")
+	orm.Mapping(user.RoleGrant{}, "usr_role_grant")
+
+	/** 安全 **/
+	orm.Mapping(security.TwoFactor{}, "sec_two_factor")
+
+	/** 授权 **/
+	orm.Mapping(oauth.Client{}, "oa_client")
+	orm.Mapping(oauth.Consent{}, "oa_consent")
+
+	/** 审计 **/
+	orm.Mapping(audit.Log{}, "sys_audit_log")
+
+	/** 风控 **/
+	orm.Mapping(risk.Record{}, "risk_record")
+	orm.Mapping(risk.BlackItem{}, "risk_blacklist")
+	orm.Mapping(risk.Review{}, "risk_review")
+
+	/** 领域事件 **/
+	orm.Mapping(event.Event{}, "sys_event_outbox")
+	orm.Mapping(event.DeadEvent{}, "sys_event_dead")
+	orm.Mapping(event.Offset{}, "sys_event_offset")
 
 	orm.Mapping(personfinance.RiseInfoValue{}, "pf_riseinfo")
 	orm.Mapping(personfinance.RiseDayInfo{}, "pf_riseday")
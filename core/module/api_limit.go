@@ -0,0 +1,143 @@
+/**
+ * Copyright 2015 @ at3.net.
+ * name : api_limit.go
+ * author : jarryliu
+ * date : 2026-10-19 17:10
+ * description : 商户接口限流及每日配额
+ * history :
+ */
+package module
+
+import (
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/jsix/gof"
+	"go2o/core/domain/interface/merchant"
+	"time"
+)
+
+var _ Module = new(ApiLimitModule)
+
+// 令牌桶及每日配额,KEYS:令牌桶,当日用量;
+// ARGV:速率(0为不限速),容量,当前毫秒,每日配额,分组,用量保存秒数;
+// 返回:是否允许,剩余令牌,需等待的毫秒数(-1为配额用尽),当日用量
+var apiLimitScript = redis.NewScript(2, `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local quota = tonumber(ARGV[4])
+local used = tonumber(redis.call('HGET', KEYS[2], ARGV[5]) or '0')
+if quota > 0 and used >= quota then
+	return {0, 0, -1, used}
+end
+if rate <= 0 then
+	used = redis.call('HINCRBY', KEYS[2], ARGV[5], 1)
+	redis.call('EXPIRE', KEYS[2], ARGV[6])
+	return {1, 0, 0, used}
+end
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(b[1])
+local ts = tonumber(b[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + (now - ts) * rate / 1000)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+	used = redis.call('HINCRBY', KEYS[2], ARGV[5], 1)
+	redis.call('EXPIRE', KEYS[2], ARGV[6])
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, math.floor(tokens), wait, used}
+`)
+
+// 限流结果
+type ApiLimitResult struct {
+	// 是否允许请求
+	Allowed bool
+	// 是否因每日配额用尽而拒绝
+	QuotaExceeded bool
+	// 每秒请求数
+	Rate int
+	// 剩余可突发的请求数
+	Remaining int
+	// 需要等待的时间
+	RetryAfter time.Duration
+	// 每日配额
+	DailyQuota int
+	// 当日已用次数
+	Used int
+}
+
+// 接口限流模块
+type ApiLimitModule struct {
+	app  gof.App
+	pool *redis.Pool
+	// 用量保存天数
+	usageDays int
+}
+
+// 模块数据
+func (a *ApiLimitModule) SetApp(app gof.App) {
+	a.app = app
+}
+
+// 初始化模块
+func (a *ApiLimitModule) Init() {
+	a.pool = a.app.Storage().Source().(*redis.Pool)
+	a.usageDays = 31
+}
+
+func (a *ApiLimitModule) getBucketKey(mchId int32, group string) string {
+	return fmt.Sprintf("go2o:module:api:bucket:%d:%s", mchId, group)
+}
+
+func (a *ApiLimitModule) getUsageKey(mchId int32, date string) string {
+	return fmt.Sprintf("go2o:module:api:usage:%d:%s", mchId, date)
+}
+
+// 获取一个令牌,并计入当日用量
+func (a *ApiLimitModule) Take(l *merchant.ApiLimit) (*ApiLimitResult, error) {
+	conn := a.pool.Get()
+	defer conn.Close()
+	now := time.Now()
+	r := &ApiLimitResult{Rate: l.Rate, DailyQuota: l.DailyQuota}
+	arr, err := redis.Ints(apiLimitScript.Do(conn,
+		a.getBucketKey(l.MchId, l.Group),
+		a.getUsageKey(l.MchId, now.Format("20060102")),
+		l.Rate, l.Burst, now.UnixNano()/1e6, l.DailyQuota, l.Group,
+		a.usageDays*24*3600))
+	if err != nil {
+		return r, err
+	}
+	r.Allowed = arr[0] == 1
+	r.Remaining = arr[1]
+	r.Used = arr[3]
+	if arr[2] == -1 {
+		// 配额用尽,次日零点恢复
+		r.QuotaExceeded = true
+		y, m, d := now.Date()
+		r.RetryAfter = time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Sub(now)
+	} else {
+		r.RetryAfter = time.Duration(arr[2]) * time.Millisecond
+	}
+	return r, nil
+}
+
+// 获取商户指定日期各接口分组的用量,date格式如:20171019
+func (a *ApiLimitModule) GetUsage(mchId int32, date string) map[string]int {
+	conn := a.pool.Get()
+	defer conn.Close()
+	m, err := redis.IntMap(conn.Do("HGETALL", a.getUsageKey(mchId, date)))
+	if err != nil {
+		return map[string]int{}
+	}
+	return m
+}
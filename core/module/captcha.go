@@ -0,0 +1,287 @@
+/**
+ * Copyright 2015 @ at3.net.
+ * name : captcha.go
+ * author : jarryliu
+ * date : 2026-10-20 17:00
+ * description : 图形验证码,验证码保存在Redis中且仅能校验一次;
+ *               登录失败次数过多时需要验证码,发送短信前需要验证码并限制发送频率
+ * history :
+ */
+package module
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/jsix/gof"
+	"go2o/core/infrastructure/tool/captcha"
+	"golang.org/x/image/font/gofont/goregular"
+	"image/png"
+	"log"
+	"strings"
+	"time"
+)
+
+var _ Module = new(CaptchaModule)
+
+var (
+	ErrCaptchaRequired = errors.New("请输入图形验证码")
+	ErrCaptchaCode     = errors.New("图形验证码不正确或已过期")
+	ErrSmsTooFrequent  = errors.New("短信发送过于频繁,请稍后再试")
+	ErrSmsPhoneLimit   = errors.New("该手机号今日接收短信已达上限")
+	ErrSmsIpLimit      = errors.New("今日发送短信已达上限")
+	ErrOpCode          = errors.New("验证码不正确或已过期")
+	ErrOpCodeLocked    = errors.New("验证失败次数过多,请稍后再试")
+)
+
+// 计数并返回计数前的值,KEYS:计数键;ARGV:上限,保存秒数;
+// 超过上限时不计数
+var captchaCountScript = redis.NewScript(1, `
+local n = tonumber(redis.call('GET', KEYS[1]) or '0')
+if tonumber(ARGV[1]) > 0 and n >= tonumber(ARGV[1]) then
+	return n
+end
+redis.call('INCR', KEYS[1])
+if n == 0 then
+	redis.call('EXPIRE', KEYS[1], ARGV[2])
+end
+return n
+`)
+
+// 校验操作验证码,KEYS:验证码键,失败次数键;ARGV:验证码,失败次数上限,锁定秒数;
+// 返回1:成功,0:验证码不正确,-1:验证码不存在或已过期,-2:已锁定;
+// 校验成功或失败次数达到上限时验证码失效
+var captchaOpCodeScript = redis.NewScript(2, `
+local max = tonumber(ARGV[2])
+if tonumber(redis.call('GET', KEYS[2]) or '0') >= max then
+	return -2
+end
+local v = redis.call('GET', KEYS[1])
+if not v then
+	return -1
+end
+if v == ARGV[1] then
+	redis.call('DEL', KEYS[1], KEYS[2])
+	return 1
+end
+local n = redis.call('INCR', KEYS[2])
+if n == 1 then
+	redis.call('EXPIRE', KEYS[2], ARGV[3])
+end
+if n >= max then
+	redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// 图形验证码
+type CaptchaChallenge struct {
+	// 验证码编号
+	Id string `json:"id"`
+	// PNG图片
+	Image []byte `json:"-"`
+}
+
+// 图形验证码模块
+type CaptchaModule struct {
+	app     gof.App
+	pool    *redis.Pool
+	captcha *captcha.Captcha
+	// 验证码有效时间(秒)
+	expires int
+	// 需要验证码的登录失败次数
+	loginFails int
+	// 登录失败次数保存时间(秒)
+	loginFailSeconds int
+	// 同一手机号发送短信的间隔(秒)
+	smsInterval int
+	// 同一手机号每日发送短信次数
+	smsPhoneDaily int
+	// 同一IP每日发送短信次数
+	smsIpDaily int
+	// 操作验证码失败次数上限
+	opCodeFails int
+}
+
+// 模块数据
+func (c *CaptchaModule) SetApp(app gof.App) {
+	c.app = app
+}
+
+// 初始化模块
+func (c *CaptchaModule) Init() {
+	c.pool = c.app.Storage().Source().(*redis.Pool)
+	cfg := c.app.Config()
+	c.expires = 300
+	c.loginFails = c.configInt(cfg, "captcha_login_fails", 3)
+	c.loginFailSeconds = 1800
+	c.smsInterval = c.configInt(cfg, "sms_phone_interval", 60)
+	c.smsPhoneDaily = c.configInt(cfg, "sms_phone_daily", 10)
+	c.smsIpDaily = c.configInt(cfg, "sms_ip_daily", 50)
+	c.opCodeFails = 5
+	c.captcha = captcha.New()
+	c.captcha.SetSize(120, 40)
+	c.captcha.SetDisturbance(captcha.MEDIUM)
+	// 未配置字体时使用内置字体,字体加载失败时不能生成验证码,拒绝启动
+	var err error
+	if font := cfg.GetString("captcha_font"); font != "" {
+		err = c.captcha.SetFont(font)
+	} else {
+		err = c.captcha.AddFontFromBytes(goregular.TTF)
+	}
+	if err != nil {
+		log.Fatalln("[ Go2o][ Captcha]: load font failed:", err.Error())
+	}
+}
+
+func (c *CaptchaModule) configInt(cfg *gof.Config, key string, def int) int {
+	if v := cfg.GetInt(key); v > 0 {
+		return v
+	}
+	return def
+}
+
+func (c *CaptchaModule) getCodeKey(id string) string {
+	return "go2o:module:captcha:code:" + id
+}
+
+func (c *CaptchaModule) getLoginFailKey(kind string, value string) string {
+	return fmt.Sprintf("go2o:module:captcha:login_fail:%s:%s", kind, value)
+}
+
+func (c *CaptchaModule) getSmsKey(kind string, value string) string {
+	return fmt.Sprintf("go2o:module:captcha:sms:%s:%s:%s", kind,
+		time.Now().Format("20060102"), value)
+}
+
+func (c *CaptchaModule) getOpCodeKey(op string, target string) string {
+	return fmt.Sprintf("go2o:module:captcha:op_code:%s:%s", op, target)
+}
+
+// 生成验证码
+func (c *CaptchaModule) NewChallenge() (*CaptchaChallenge, error) {
+	img, code := c.captcha.Create(4, captcha.NUM)
+	buf := bytes.NewBuffer(nil)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	ch := &CaptchaChallenge{Id: newToken(12), Image: buf.Bytes()}
+	conn := c.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("SET", c.getCodeKey(ch.Id), strings.ToLower(code),
+		"EX", c.expires)
+	return ch, err
+}
+
+// 校验验证码,验证码校验后即失效
+func (c *CaptchaModule) Verify(id string, code string) error {
+	id, code = strings.TrimSpace(id), strings.TrimSpace(code)
+	if id == "" || code == "" {
+		return ErrCaptchaRequired
+	}
+	conn := c.pool.Get()
+	defer conn.Close()
+	key := c.getCodeKey(id)
+	conn.Send("MULTI")
+	conn.Send("GET", key)
+	conn.Send("DEL", key)
+	arr, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return err
+	}
+	v, _ := redis.String(arr[0], nil)
+	if v == "" || v != strings.ToLower(code) {
+		return ErrCaptchaCode
+	}
+	return nil
+}
+
+func (c *CaptchaModule) getInt(conn redis.Conn, key string) int {
+	n, _ := redis.Int(conn.Do("GET", key))
+	return n
+}
+
+// 登录是否需要验证码,用户或IP登录失败次数过多时需要
+func (c *CaptchaModule) LoginCaptchaRequired(usr string, ip string) bool {
+	conn := c.pool.Get()
+	defer conn.Close()
+	return c.getInt(conn, c.getLoginFailKey("usr", usr)) >= c.loginFails ||
+		(ip != "" && c.getInt(conn, c.getLoginFailKey("ip", ip)) >= c.loginFails)
+}
+
+// 记录登录失败
+func (c *CaptchaModule) LoginFailed(usr string, ip string) {
+	conn := c.pool.Get()
+	defer conn.Close()
+	captchaCountScript.Do(conn, c.getLoginFailKey("usr", usr), 0, c.loginFailSeconds)
+	if ip != "" {
+		captchaCountScript.Do(conn, c.getLoginFailKey("ip", ip), 0, c.loginFailSeconds)
+	}
+}
+
+// 登录成功后清除用户的失败次数
+func (c *CaptchaModule) LoginSucceed(usr string) {
+	conn := c.pool.Get()
+	defer conn.Close()
+	conn.Do("DEL", c.getLoginFailKey("usr", usr))
+}
+
+// 发送短信前校验验证码并检查发送频率,通过后计入发送次数
+func (c *CaptchaModule) TakeSms(phone string, ip string, captchaId string,
+	captchaCode string) error {
+	if err := c.Verify(captchaId, captchaCode); err != nil {
+		return err
+	}
+	conn := c.pool.Get()
+	defer conn.Close()
+	// 发送间隔
+	lockKey := "go2o:module:captcha:sms_lock:" + phone
+	ok, err := redis.String(conn.Do("SET", lockKey, 1, "EX", c.smsInterval, "NX"))
+	if err != nil || ok != "OK" {
+		return ErrSmsTooFrequent
+	}
+	if ip != "" {
+		n, _ := redis.Int(captchaCountScript.Do(conn, c.getSmsKey("ip", ip),
+			c.smsIpDaily, 24*3600))
+		if n >= c.smsIpDaily {
+			conn.Do("DEL", lockKey)
+			return ErrSmsIpLimit
+		}
+	}
+	n, _ := redis.Int(captchaCountScript.Do(conn, c.getSmsKey("phone", phone),
+		c.smsPhoneDaily, 24*3600))
+	if n >= c.smsPhoneDaily {
+		conn.Do("DEL", lockKey)
+		return ErrSmsPhoneLimit
+	}
+	return nil
+}
+
+// 保存操作验证码,验证码按操作及对象保存,重新保存时覆盖之前的验证码
+func (c *CaptchaModule) SetOpCode(op string, target string, code string, seconds int) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("SET", c.getOpCodeKey(op, target), code, "EX", seconds)
+	return err
+}
+
+// 校验操作验证码,校验成功后验证码失效;同一对象连续失败次数过多时,
+// 验证码失效且在一段时间内不能再校验
+func (c *CaptchaModule) VerifyOpCode(op string, target string, code string) error {
+	conn := c.pool.Get()
+	defer conn.Close()
+	key := c.getOpCodeKey(op, target)
+	n, err := redis.Int(captchaOpCodeScript.Do(conn, key, key+":fail",
+		strings.TrimSpace(code), c.opCodeFails, c.loginFailSeconds))
+	if err != nil {
+		return err
+	}
+	switch n {
+	case 1:
+		return nil
+	case -2:
+		return ErrOpCodeLocked
+	}
+	return ErrOpCode
+}
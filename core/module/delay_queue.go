@@ -0,0 +1,353 @@
+/**
+ * Copyright 2015 @ at3.net.
+ * name : delay_queue.go
+ * author : jarryliu
+ * date : 2026-10-20 18:00
+ * description : 持久化的延迟任务队列,任务保存在Redis有序集合中并按到期时间排序;
+ *               领取的任务在可见超时内未确认将重新投递(至少投递一次),
+ *               处理失败按指数退避重试,超过最大次数后移入死信列表
+ * history :
+ */
+package module
+
+import (
+	"errors"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/jsix/gof"
+	"log"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var _ Module = new(DelayQueueModule)
+
+const (
+	// 等待执行
+	DelayPending = "pending"
+	// 执行中
+	DelayRunning = "running"
+	// 死信
+	DelayDead = "dead"
+)
+
+var (
+	ErrDelayTopic   = errors.New("任务主题不能为空")
+	ErrDelayNoJob   = errors.New("任务不存在")
+	ErrDelayState   = errors.New("任务状态不正确")
+	errDelayHandler = errors.New("no handler for topic")
+)
+
+const (
+	delayPendingKey = "go2o:module:delay:pending"
+	delayRunningKey = "go2o:module:delay:running"
+	delayDeadKey    = "go2o:module:delay:dead"
+	delayJobPrefix  = "go2o:module:delay:job:"
+)
+
+// 领取到期的任务,KEYS:等待,执行中,死信;
+// ARGV:当前时间,可见超时时间,数量,任务键前缀,最大次数;
+// 先将可见超时的任务放回等待队列(超过最大次数的移入死信),再领取到期的任务
+var delayClaimScript = redis.NewScript(3, `
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], id)
+	local n = tonumber(redis.call('HGET', ARGV[4] .. id, 'attempts') or '0')
+	if n >= tonumber(ARGV[5]) then
+		redis.call('HSET', ARGV[4] .. id, 'error', 'visibility timeout')
+		redis.call('RPUSH', KEYS[3], id)
+	elseif not redis.call('ZSCORE', KEYS[1], id) then
+		redis.call('ZADD', KEYS[1], ARGV[1], id)
+	end
+end
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[3])
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[1], id)
+	redis.call('ZADD', KEYS[2], ARGV[2], id)
+	redis.call('HINCRBY', ARGV[4] .. id, 'attempts', 1)
+end
+return ids
+`)
+
+// 确认任务,KEYS:执行中,等待;ARGV:任务编号,任务键;
+// 任务执行期间被重新安排时保留任务数据
+var delayAckScript = redis.NewScript(2, `
+redis.call('ZREM', KEYS[1], ARGV[1])
+if not redis.call('ZSCORE', KEYS[2], ARGV[1]) then
+	redis.call('DEL', ARGV[2])
+end
+return 1
+`)
+
+// 任务失败,KEYS:执行中,等待,死信;ARGV:任务编号,任务键,错误,重试时间,是否移入死信
+var delayFailScript = redis.NewScript(3, `
+if redis.call('ZREM', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', ARGV[2], 'error', ARGV[3])
+if ARGV[5] == '1' then
+	redis.call('RPUSH', KEYS[3], ARGV[1])
+elseif not redis.call('ZSCORE', KEYS[2], ARGV[1]) then
+	redis.call('ZADD', KEYS[2], ARGV[4], ARGV[1])
+end
+return 1
+`)
+
+// 重新执行死信任务,KEYS:死信,等待;ARGV:任务编号,任务键,当前时间
+var delayRetryScript = redis.NewScript(2, `
+if redis.call('LREM', KEYS[1], 0, ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HMSET', ARGV[2], 'attempts', 0, 'error', '')
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+return 1
+`)
+
+// 延迟任务
+type DelayJob struct {
+	// 任务编号,由主题和业务键组成
+	Id string `json:"id"`
+	// 主题
+	Topic string `json:"topic"`
+	// 业务键,相同主题和业务键的任务只保留一个
+	Key string `json:"key"`
+	// 任务数据
+	Payload string `json:"payload"`
+	// 已投递次数
+	Attempts int `json:"attempts"`
+	// 到期时间
+	DueTime int64 `json:"dueTime"`
+	// 最后的错误
+	Error string `json:"error"`
+	// 创建时间
+	CreateTime int64 `json:"createTime"`
+}
+
+// 任务处理函数,返回错误时将重试
+type DelayHandler func(job *DelayJob) error
+
+// 延迟任务队列模块
+type DelayQueueModule struct {
+	app      gof.App
+	pool     *redis.Pool
+	handlers map[string]DelayHandler
+	mux      sync.RWMutex
+	// 最大投递次数
+	maxAttempts int
+	// 首次重试的间隔(秒)
+	retryBase int
+	// 最大重试间隔(秒)
+	retryMax int
+	// 可见超时(秒)
+	visibility int
+}
+
+// 模块数据
+func (d *DelayQueueModule) SetApp(app gof.App) {
+	d.app = app
+}
+
+// 初始化模块
+func (d *DelayQueueModule) Init() {
+	d.pool = d.app.Storage().Source().(*redis.Pool)
+	d.handlers = map[string]DelayHandler{}
+	cfg := d.app.Config()
+	d.maxAttempts = d.configInt(cfg, "delay_max_attempts", 8)
+	d.retryBase = d.configInt(cfg, "delay_retry_base", 30)
+	d.retryMax = 3600
+	d.visibility = d.configInt(cfg, "delay_visibility", 300)
+}
+
+func (d *DelayQueueModule) configInt(cfg *gof.Config, key string, def int) int {
+	if v := cfg.GetInt(key); v > 0 {
+		return v
+	}
+	return def
+}
+
+func (d *DelayQueueModule) getJobId(topic string, key string) string {
+	return topic + ":" + key
+}
+
+// 注册主题的处理函数
+func (d *DelayQueueModule) Handle(topic string, h DelayHandler) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.handlers[topic] = h
+}
+
+// 安排任务在指定时间执行,相同主题和业务键的任务将被替换
+func (d *DelayQueueModule) Schedule(topic string, key string,
+	payload string, due time.Time) error {
+	if topic == "" {
+		return ErrDelayTopic
+	}
+	id := d.getJobId(topic, key)
+	conn := d.pool.Get()
+	defer conn.Close()
+	conn.Send("MULTI")
+	conn.Send("HMSET", delayJobPrefix+id, "topic", topic, "key", key,
+		"payload", payload, "attempts", 0, "due", due.Unix(),
+		"error", "", "create", time.Now().Unix())
+	conn.Send("LREM", delayDeadKey, 0, id)
+	conn.Send("ZADD", delayPendingKey, due.Unix(), id)
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+// 取消任务
+func (d *DelayQueueModule) Cancel(topic string, key string) error {
+	id := d.getJobId(topic, key)
+	conn := d.pool.Get()
+	defer conn.Close()
+	conn.Send("MULTI")
+	conn.Send("ZREM", delayPendingKey, id)
+	conn.Send("ZREM", delayRunningKey, id)
+	conn.Send("LREM", delayDeadKey, 0, id)
+	conn.Send("DEL", delayJobPrefix+id)
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+// 获取任务
+func (d *DelayQueueModule) GetJob(id string) *DelayJob {
+	conn := d.pool.Get()
+	defer conn.Close()
+	return d.getJob(conn, id)
+}
+
+func (d *DelayQueueModule) getJob(conn redis.Conn, id string) *DelayJob {
+	mp, err := redis.StringMap(conn.Do("HGETALL", delayJobPrefix+id))
+	if err != nil || len(mp) == 0 {
+		return nil
+	}
+	j := &DelayJob{
+		Id:      id,
+		Topic:   mp["topic"],
+		Key:     mp["key"],
+		Payload: mp["payload"],
+		Error:   mp["error"],
+	}
+	j.Attempts, _ = strconv.Atoi(mp["attempts"])
+	j.DueTime, _ = strconv.ParseInt(mp["due"], 10, 64)
+	j.CreateTime, _ = strconv.ParseInt(mp["create"], 10, 64)
+	return j
+}
+
+// 查询任务,state为:DelayPending,DelayRunning或DelayDead
+func (d *DelayQueueModule) Jobs(state string, begin, size int) (int, []*DelayJob, error) {
+	conn := d.pool.Get()
+	defer conn.Close()
+	var total int
+	var ids []string
+	var err error
+	end := begin + size - 1
+	switch state {
+	case DelayPending, DelayRunning:
+		key := delayPendingKey
+		if state == DelayRunning {
+			key = delayRunningKey
+		}
+		total, err = redis.Int(conn.Do("ZCARD", key))
+		if err == nil {
+			ids, err = redis.Strings(conn.Do("ZRANGE", key, begin, end))
+		}
+	case DelayDead:
+		total, err = redis.Int(conn.Do("LLEN", delayDeadKey))
+		if err == nil {
+			ids, err = redis.Strings(conn.Do("LRANGE", delayDeadKey, begin, end))
+		}
+	default:
+		return 0, nil, ErrDelayState
+	}
+	list := make([]*DelayJob, 0, len(ids))
+	for _, id := range ids {
+		if j := d.getJob(conn, id); j != nil {
+			list = append(list, j)
+		}
+	}
+	return total, list, err
+}
+
+// 重新执行死信任务
+func (d *DelayQueueModule) RetryDead(id string) error {
+	conn := d.pool.Get()
+	defer conn.Close()
+	n, err := redis.Int(delayRetryScript.Do(conn, delayDeadKey, delayPendingKey,
+		id, delayJobPrefix+id, time.Now().Unix()))
+	if err == nil && n == 0 {
+		err = ErrDelayNoJob
+	}
+	return err
+}
+
+// 重试间隔,按投递次数指数增长
+func (d *DelayQueueModule) backoff(attempts int) int {
+	sec := d.retryBase
+	for i := 1; i < attempts && sec < d.retryMax; i++ {
+		sec *= 2
+	}
+	if sec > d.retryMax {
+		sec = d.retryMax
+	}
+	return sec
+}
+
+// 领取并执行到期的任务,返回领取的任务数量
+func (d *DelayQueueModule) Consume(limit int) (int, error) {
+	conn := d.pool.Get()
+	defer conn.Close()
+	now := time.Now().Unix()
+	ids, err := redis.Strings(delayClaimScript.Do(conn, delayPendingKey,
+		delayRunningKey, delayDeadKey, now, now+int64(d.visibility), limit,
+		delayJobPrefix, d.maxAttempts))
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range ids {
+		j := d.getJob(conn, id)
+		if j == nil {
+			// 任务已取消
+			delayAckScript.Do(conn, delayRunningKey, delayPendingKey,
+				id, delayJobPrefix+id)
+			continue
+		}
+		if err := d.invoke(j); err != nil {
+			d.fail(conn, j, err)
+		} else {
+			delayAckScript.Do(conn, delayRunningKey, delayPendingKey,
+				id, delayJobPrefix+id)
+		}
+	}
+	return len(ids), nil
+}
+
+func (d *DelayQueueModule) invoke(j *DelayJob) (err error) {
+	d.mux.RLock()
+	h, ok := d.handlers[j.Topic]
+	d.mux.RUnlock()
+	if !ok {
+		return errDelayHandler
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			log.Println("[ Go2o][ Delay][ Recover]:", r, "\n", string(debug.Stack()))
+		}
+	}()
+	return h(j)
+}
+
+func (d *DelayQueueModule) fail(conn redis.Conn, j *DelayJob, err error) {
+	dead := 0
+	if j.Attempts >= d.maxAttempts {
+		dead = 1
+	}
+	retryAt := time.Now().Unix() + int64(d.backoff(j.Attempts))
+	delayFailScript.Do(conn, delayRunningKey, delayPendingKey, delayDeadKey,
+		j.Id, delayJobPrefix+j.Id, err.Error(), retryAt, dead)
+	log.Println("[ Go2o][ Delay][ Error]: job", j.Id, "attempts", j.Attempts,
+		"failed:", err.Error())
+}
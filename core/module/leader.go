@@ -0,0 +1,225 @@
+/**
+ * Copyright 2015 @ at3.net.
+ * name : leader.go
+ * author : jarryliu
+ * date : 2026-10-20 19:00
+ * description : 基于Redis租约的主节点选举,每次当选生成递增的令牌(fencing token),
+ *               写入共享数据时校验令牌,防止租约过期的旧主节点覆盖数据
+ * history :
+ */
+package module
+
+import (
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/jsix/gof"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ Module = new(LeaderModule)
+
+// 获取或续约租约,KEYS:租约,令牌计数;ARGV:节点编号,租约毫秒数;
+// 返回当前节点的令牌,租约被其他节点持有时返回0
+var leaderAcquireScript = redis.NewScript(2, `
+local v = redis.call('GET', KEYS[1])
+if v then
+	local sep = string.find(v, '|', 1, true)
+	if string.sub(v, 1, sep - 1) == ARGV[1] then
+		redis.call('PEXPIRE', KEYS[1], ARGV[2])
+		return tonumber(string.sub(v, sep + 1))
+	end
+	return 0
+end
+local token = redis.call('INCR', KEYS[2])
+redis.call('SET', KEYS[1], ARGV[1] .. '|' .. token, 'PX', ARGV[2])
+return token
+`)
+
+// 释放租约,KEYS:租约;ARGV:租约的值
+var leaderReleaseScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// 令牌计数小于指定值时设为该值,KEYS:令牌计数;ARGV:最小令牌
+var leaderSeedScript = redis.NewScript(1, `
+local v = tonumber(redis.call('GET', KEYS[1]) or '0')
+if v < tonumber(ARGV[1]) then
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// 校验令牌后写入,KEYS:租约,写入的键;ARGV:令牌,值
+var leaderFencedSetScript = redis.NewScript(2, `
+local v = redis.call('GET', KEYS[1])
+if not v then
+	return 0
+end
+local sep = string.find(v, '|', 1, true)
+if tonumber(string.sub(v, sep + 1)) ~= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[2])
+return 1
+`)
+
+// 租约
+type leaderLease struct {
+	token   int64
+	expires time.Time
+}
+
+// 主节点选举模块
+type LeaderModule struct {
+	app    gof.App
+	pool   *redis.Pool
+	nodeId string
+	// 租约时间
+	ttl    time.Duration
+	leases map[string]*leaderLease
+	mux    sync.RWMutex
+}
+
+// 模块数据
+func (l *LeaderModule) SetApp(app gof.App) {
+	l.app = app
+}
+
+// 初始化模块
+func (l *LeaderModule) Init() {
+	l.pool = l.app.Storage().Source().(*redis.Pool)
+	l.leases = map[string]*leaderLease{}
+	sec := l.app.Config().GetInt("leader_lease_seconds")
+	if sec <= 0 {
+		sec = 15
+	}
+	l.ttl = time.Duration(sec) * time.Second
+	host, _ := os.Hostname()
+	l.nodeId = fmt.Sprintf("%s-%d-%s", host, os.Getpid(), newToken(4))
+}
+
+func (l *LeaderModule) getLeaseKey(name string) string {
+	return "go2o:module:leader:" + name
+}
+
+// 当前节点编号
+func (l *LeaderModule) NodeId() string {
+	return l.nodeId
+}
+
+// 设置令牌的起始值,确保之后生成的令牌大于已持久化的令牌;
+// Redis数据丢失或重置后,避免新令牌小于数据库中登记的令牌
+func (l *LeaderModule) SeedToken(name string, minToken int64) error {
+	if minToken <= 0 {
+		return nil
+	}
+	conn := l.pool.Get()
+	defer conn.Close()
+	_, err := leaderSeedScript.Do(conn, l.getLeaseKey(name)+":token", minToken)
+	return err
+}
+
+// 竞选或续约,返回当选的令牌,未当选返回0
+func (l *LeaderModule) Campaign(name string) (int64, error) {
+	begin := time.Now()
+	conn := l.pool.Get()
+	defer conn.Close()
+	key := l.getLeaseKey(name)
+	token, err := redis.Int64(leaderAcquireScript.Do(conn, key, key+":token",
+		l.nodeId, int64(l.ttl/time.Millisecond)))
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	if err != nil || token == 0 {
+		delete(l.leases, name)
+		return 0, err
+	}
+	// 以请求前的时间计算到期时间,避免本地认为租约有效而实际已过期
+	ls := l.leases[name]
+	if ls == nil || ls.token != token {
+		log.Println("[ Go2o][ Leader]: node", l.nodeId, "elected as", name,
+			"leader, token", token)
+	}
+	l.leases[name] = &leaderLease{token: token, expires: begin.Add(l.ttl)}
+	return token, nil
+}
+
+// 放弃主节点
+func (l *LeaderModule) Resign(name string) error {
+	l.mux.Lock()
+	ls := l.leases[name]
+	delete(l.leases, name)
+	l.mux.Unlock()
+	if ls == nil {
+		return nil
+	}
+	conn := l.pool.Get()
+	defer conn.Close()
+	_, err := leaderReleaseScript.Do(conn, l.getLeaseKey(name),
+		fmt.Sprintf("%s|%d", l.nodeId, ls.token))
+	return err
+}
+
+// 保持竞选,每隔租约时间的三分之一续约一次,直到stop关闭
+func (l *LeaderModule) Keep(name string, stop <-chan bool) {
+	tk := time.NewTicker(l.ttl / 3)
+	defer tk.Stop()
+	for {
+		if _, err := l.Campaign(name); err != nil {
+			log.Println("[ Go2o][ Leader][ Error]:", err.Error())
+		}
+		select {
+		case <-tk.C:
+		case <-stop:
+			l.Resign(name)
+			return
+		}
+	}
+}
+
+// 当前节点的令牌,非主节点或租约已过期返回0
+func (l *LeaderModule) Token(name string) int64 {
+	l.mux.RLock()
+	defer l.mux.RUnlock()
+	if ls := l.leases[name]; ls != nil && time.Now().Before(ls.expires) {
+		return ls.token
+	}
+	return 0
+}
+
+// 当前节点是否为主节点
+func (l *LeaderModule) IsLeader(name string) bool {
+	return l.Token(name) > 0
+}
+
+// 获取主节点编号及令牌
+func (l *LeaderModule) Leader(name string) (string, int64) {
+	conn := l.pool.Get()
+	defer conn.Close()
+	v, _ := redis.String(conn.Do("GET", l.getLeaseKey(name)))
+	if i := strings.LastIndex(v, "|"); i != -1 {
+		var token int64
+		fmt.Sscanf(v[i+1:], "%d", &token)
+		return v[:i], token
+	}
+	return "", 0
+}
+
+// 令牌有效时写入键值,返回是否写入
+func (l *LeaderModule) FencedSet(name string, token int64, key string,
+	value interface{}) (bool, error) {
+	if token <= 0 {
+		return false, nil
+	}
+	conn := l.pool.Get()
+	defer conn.Close()
+	n, err := redis.Int(leaderFencedSetScript.Do(conn, l.getLeaseKey(name),
+		key, token, value))
+	return n == 1, err
+}
@@ -10,6 +10,7 @@ package module
 
 import (
 	"fmt"
+	"github.com/garyburd/redigo/redis"
 	"github.com/jsix/gof"
 	"github.com/jsix/gof/crypto"
 	"github.com/jsix/gof/storage"
@@ -22,8 +23,11 @@ var _ Module = new(SSOModule)
 type MemberModule struct {
 	app         gof.App
 	storage     storage.Interface
+	pool        *redis.Pool
 	tokenHours  int64
 	tokenOffset string
+	// 访问令牌的有效时间(秒)
+	accessSeconds int64
 }
 
 // 模块数据
@@ -36,6 +40,8 @@ func (m *MemberModule) SetApp(app gof.App) {
 func (m *MemberModule) Init() {
 	m.tokenHours = 24 * 30 //默认保存1个月
 	m.tokenOffset = "%$^&@at3.net"
+	m.accessSeconds = 7200
+	m.pool = m.storage.Source().(*redis.Pool)
 }
 
 // 获取会员Token-Key
@@ -58,12 +64,15 @@ func (m *MemberModule) GetToken(memberId int64) string {
 	return pubToken
 }
 
-// 检查会员的会话Token是否正确，
+// 检查会员的会话Token是否正确，可传入会话的访问令牌或旧版本的令牌
 func (m *MemberModule) CheckToken(memberId int64, token string) bool {
 	token = strings.TrimSpace(token)
 	if len(token) == 0 {
 		return false
 	}
+	if m.checkAccessToken(memberId, token) {
+		return true
+	}
 	pubToken, tokenBase := m.getMemberToken(memberId)
 	// 清除token
 	if pubToken == "" || tokenBase == "" {
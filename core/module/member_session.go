@@ -0,0 +1,338 @@
+/**
+ * Copyright 2015 @ at3.net.
+ * name : member_session.go
+ * author : jarryliu
+ * date : 2026-10-19 18:30
+ * description : 会员多设备会话,每个设备一个会话,会话包含短期的访问令牌及刷新令牌
+ * history :
+ */
+package module
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoSuchSession   = errors.New("no such session")
+	ErrRefreshToken    = errors.New("refresh token invalid or expired")
+	ErrSessionNotOwner = errors.New("session not belong to member")
+)
+
+// 会员会话
+type MemberSession struct {
+	// 会话编号
+	Id string `json:"id" redis:"-"`
+	// 会员编号
+	MemberId int64 `json:"memberId" redis:"member_id"`
+	// 设备编号,由客户端生成,同一设备再次登录时替换原会话
+	DeviceId string `json:"deviceId" redis:"device_id"`
+	// 设备类型,参见:app/util/device.go
+	DeviceType string `json:"deviceType" redis:"device_type"`
+	// 设备名称,如:UserAgent
+	DeviceName string `json:"deviceName" redis:"device_name"`
+	// 登录IP
+	Ip string `json:"ip" redis:"ip"`
+	// 创建时间
+	CreateTime int64 `json:"createTime" redis:"create_time"`
+	// 最后活动时间
+	LastSeen int64 `json:"lastSeen" redis:"last_seen"`
+	// 当前的访问令牌
+	AccessToken string `json:"-" redis:"access"`
+	// 当前的刷新令牌
+	RefreshToken string `json:"-" redis:"refresh"`
+	// 推送平台,如:apns,fcm
+	PushPlatform string `json:"pushPlatform" redis:"push_platform"`
+	// 推送的设备令牌
+	PushToken string `json:"-" redis:"push_token"`
+}
+
+// 会员令牌
+type MemberToken struct {
+	// 会员编号
+	MemberId int64 `json:"memberId"`
+	// 会话编号
+	SessionId string `json:"sessionId"`
+	// 访问令牌
+	AccessToken string `json:"accessToken"`
+	// 访问令牌有效时间(秒)
+	ExpiresIn int64 `json:"expiresIn"`
+	// 刷新令牌,仅能使用一次
+	RefreshToken string `json:"refreshToken"`
+}
+
+func (m *MemberModule) getSessionKey(sid string) string {
+	return "go2o:module:member:session:" + sid
+}
+
+func (m *MemberModule) getSessionSetKey(memberId int64) string {
+	return fmt.Sprintf("go2o:module:member:sessions:%d", memberId)
+}
+
+func (m *MemberModule) getAccessKey(token string) string {
+	return "go2o:module:member:access:" + token
+}
+
+func (m *MemberModule) getRefreshKey(token string) string {
+	return "go2o:module:member:refresh:" + token
+}
+
+func newToken(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// 创建会话,deviceId不为空时,替换该设备原有的会话
+func (m *MemberModule) CreateSession(memberId int64, deviceId, deviceType,
+	deviceName, ip string) (*MemberToken, error) {
+	if deviceId != "" {
+		for _, s := range m.GetSessions(memberId) {
+			if s.DeviceId == deviceId {
+				m.RevokeSession(memberId, s.Id)
+			}
+		}
+	}
+	unix := time.Now().Unix()
+	s := &MemberSession{
+		Id:         newToken(8),
+		MemberId:   memberId,
+		DeviceId:   deviceId,
+		DeviceType: deviceType,
+		DeviceName: deviceName,
+		Ip:         ip,
+		CreateTime: unix,
+		LastSeen:   unix,
+	}
+	conn := m.pool.Get()
+	defer conn.Close()
+	if _, err := conn.Do("HMSET", redis.Args{}.Add(m.getSessionKey(s.Id)).
+		AddFlat(s)...); err != nil {
+		return nil, err
+	}
+	conn.Do("SADD", m.getSessionSetKey(memberId), s.Id)
+	return m.issueToken(conn, s)
+}
+
+// 签发访问令牌及刷新令牌,并使会话原有的令牌失效
+func (m *MemberModule) issueToken(conn redis.Conn, s *MemberSession) (*MemberToken, error) {
+	t := &MemberToken{
+		MemberId:     s.MemberId,
+		SessionId:    s.Id,
+		AccessToken:  newToken(16),
+		ExpiresIn:    m.accessSeconds,
+		RefreshToken: newToken(24),
+	}
+	v := fmt.Sprintf("%d:%s", s.MemberId, s.Id)
+	refreshSeconds := m.tokenHours * 3600
+	sk := m.getSessionKey(s.Id)
+	conn.Send("MULTI")
+	if s.AccessToken != "" {
+		conn.Send("DEL", m.getAccessKey(s.AccessToken))
+	}
+	if s.RefreshToken != "" {
+		conn.Send("DEL", m.getRefreshKey(s.RefreshToken))
+	}
+	conn.Send("SET", m.getAccessKey(t.AccessToken), v, "EX", m.accessSeconds)
+	conn.Send("SET", m.getRefreshKey(t.RefreshToken), v, "EX", refreshSeconds)
+	conn.Send("HMSET", sk, "access", t.AccessToken, "refresh", t.RefreshToken)
+	conn.Send("EXPIRE", sk, refreshSeconds)
+	conn.Send("EXPIRE", m.getSessionSetKey(s.MemberId), refreshSeconds)
+	if _, err := conn.Do("EXEC"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// 解析令牌对应的会员及会话,格式:会员编号:会话编号
+func (m *MemberModule) parseTokenValue(v string) (int64, string) {
+	i := strings.Index(v, ":")
+	if i == -1 {
+		return 0, ""
+	}
+	memberId, _ := strconv.ParseInt(v[:i], 10, 64)
+	return memberId, v[i+1:]
+}
+
+// 使用刷新令牌签发新的令牌,刷新令牌仅能使用一次
+func (m *MemberModule) RefreshToken(refreshToken string) (*MemberToken, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return nil, ErrRefreshToken
+	}
+	conn := m.pool.Get()
+	defer conn.Close()
+	// 先删除刷新令牌,避免并发刷新签发多个令牌
+	key := m.getRefreshKey(refreshToken)
+	conn.Send("MULTI")
+	conn.Send("GET", key)
+	conn.Send("DEL", key)
+	arr, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return nil, err
+	}
+	v, _ := redis.String(arr[0], nil)
+	memberId, sid := m.parseTokenValue(v)
+	if memberId <= 0 {
+		return nil, ErrRefreshToken
+	}
+	s := m.getSession(conn, sid)
+	if s == nil || s.MemberId != memberId || s.RefreshToken != refreshToken {
+		return nil, ErrRefreshToken
+	}
+	return m.issueToken(conn, s)
+}
+
+// 检查访问令牌,并更新会话的最后活动时间
+func (m *MemberModule) checkAccessToken(memberId int64, token string) bool {
+	conn := m.pool.Get()
+	defer conn.Close()
+	v, err := redis.String(conn.Do("GET", m.getAccessKey(token)))
+	if err != nil {
+		return false
+	}
+	id, sid := m.parseTokenValue(v)
+	if id != memberId {
+		return false
+	}
+	sk := m.getSessionKey(sid)
+	// 会话已被删除
+	if n, _ := redis.Int(conn.Do("EXISTS", sk)); n == 0 {
+		conn.Do("DEL", m.getAccessKey(token))
+		return false
+	}
+	conn.Do("HSET", sk, "last_seen", time.Now().Unix())
+	return true
+}
+
+func (m *MemberModule) getSession(conn redis.Conn, sid string) *MemberSession {
+	values, err := redis.Values(conn.Do("HGETALL", m.getSessionKey(sid)))
+	if err != nil || len(values) == 0 {
+		return nil
+	}
+	s := &MemberSession{Id: sid}
+	if redis.ScanStruct(values, s) != nil {
+		return nil
+	}
+	return s
+}
+
+// 获取会员的所有会话,已过期的会话将被清除
+func (m *MemberModule) GetSessions(memberId int64) []*MemberSession {
+	conn := m.pool.Get()
+	defer conn.Close()
+	setKey := m.getSessionSetKey(memberId)
+	ids, _ := redis.Strings(conn.Do("SMEMBERS", setKey))
+	list := []*MemberSession{}
+	for _, sid := range ids {
+		if s := m.getSession(conn, sid); s != nil {
+			list = append(list, s)
+		} else {
+			conn.Do("SREM", setKey, sid)
+		}
+	}
+	return list
+}
+
+// 注销会话
+func (m *MemberModule) RevokeSession(memberId int64, sid string) error {
+	conn := m.pool.Get()
+	defer conn.Close()
+	s := m.getSession(conn, sid)
+	if s == nil {
+		return ErrNoSuchSession
+	}
+	if s.MemberId != memberId {
+		return ErrSessionNotOwner
+	}
+	_, err := conn.Do("DEL", m.getSessionKey(sid),
+		m.getAccessKey(s.AccessToken), m.getRefreshKey(s.RefreshToken))
+	conn.Do("SREM", m.getSessionSetKey(memberId), sid)
+	if s.PushToken != "" {
+		m.delPushTokenIndex(conn, s.PushToken, sid)
+	}
+	return err
+}
+
+// 注销会员的所有会话(在所有设备上退出)
+func (m *MemberModule) RevokeAllSessions(memberId int64) {
+	for _, s := range m.GetSessions(memberId) {
+		m.RevokeSession(memberId, s.Id)
+	}
+	m.RemoveToken(memberId)
+}
+
+func (m *MemberModule) getPushTokenKey(token string) string {
+	return "go2o:module:member:push:" + token
+}
+
+// 删除设备令牌对应的会话,仅当令牌仍属于该会话时删除
+func (m *MemberModule) delPushTokenIndex(conn redis.Conn, token string, sid string) {
+	key := m.getPushTokenKey(token)
+	if v, _ := redis.String(conn.Do("GET", key)); v == sid {
+		conn.Do("DEL", key)
+	}
+}
+
+// 注册会话的推送设备令牌,令牌已被其他会话使用时(如设备切换了会员),
+// 从原会话中移除,避免向原会员推送
+func (m *MemberModule) RegisterPushToken(memberId int64, sid string,
+	platform string, token string) error {
+	conn := m.pool.Get()
+	defer conn.Close()
+	s := m.getSession(conn, sid)
+	if s == nil {
+		return ErrNoSuchSession
+	}
+	if s.MemberId != memberId {
+		return ErrSessionNotOwner
+	}
+	key := m.getPushTokenKey(token)
+	if old, _ := redis.String(conn.Do("GET", key)); old != "" && old != sid {
+		conn.Do("HDEL", m.getSessionKey(old), "push_platform", "push_token")
+	}
+	if s.PushToken != "" && s.PushToken != token {
+		m.delPushTokenIndex(conn, s.PushToken, sid)
+	}
+	conn.Send("MULTI")
+	conn.Send("HMSET", m.getSessionKey(sid), "push_platform", platform,
+		"push_token", token)
+	conn.Send("SET", key, sid, "EX", m.tokenHours*3600)
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+// 取消会话的推送
+func (m *MemberModule) UnregisterPushToken(memberId int64, sid string) error {
+	conn := m.pool.Get()
+	defer conn.Close()
+	s := m.getSession(conn, sid)
+	if s == nil {
+		return ErrNoSuchSession
+	}
+	if s.MemberId != memberId {
+		return ErrSessionNotOwner
+	}
+	if s.PushToken != "" {
+		m.delPushTokenIndex(conn, s.PushToken, sid)
+	}
+	_, err := conn.Do("HDEL", m.getSessionKey(sid), "push_platform", "push_token")
+	return err
+}
+
+// 获取会员已注册推送的会话
+func (m *MemberModule) GetPushSessions(memberId int64) []*MemberSession {
+	list := []*MemberSession{}
+	for _, s := range m.GetSessions(memberId) {
+		if s.PushToken != "" {
+			list = append(list, s)
+		}
+	}
+	return list
+}
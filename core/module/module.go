@@ -22,6 +22,12 @@ var (
 	M_SSO     string = "sso"
 	M_MM      string = "member"
 	M_PAY     string = "payment"
+	M_API     string = "api_limit"
+	M_OAUTH   string = "oauth"
+	M_CAPTCHA string = "captcha"
+	M_DELAY   string = "delay_queue"
+	M_LEADER  string = "leader"
+	M_PUSH    string = "push"
 )
 
 // 模块实现
@@ -63,6 +69,12 @@ func registerInternal() {
 	Register(M_SSO, &SSOModule{})
 	Register(M_MM, &MemberModule{})
 	Register(M_PAY, &PaymentModule{})
+	Register(M_API, &ApiLimitModule{})
+	Register(M_OAUTH, &OAuthModule{})
+	Register(M_CAPTCHA, &CaptchaModule{})
+	Register(M_DELAY, &DelayQueueModule{})
+	Register(M_LEADER, &LeaderModule{})
+	Register(M_PUSH, &PushModule{})
 }
 
 // 获取模块
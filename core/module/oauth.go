@@ -0,0 +1,347 @@
+/**
+ * Copyright 2015 @ at3.net.
+ * name : oauth.go
+ * author : jarryliu
+ * date : 2026-10-19 22:10
+ * description : OAuth2授权服务,保存授权码、访问令牌及刷新令牌,
+ *               并使用RSA密钥签发OpenID Connect身份令牌
+ * history :
+ */
+package module
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/jsix/gof"
+	"github.com/jsix/gof/log"
+	"go2o/core/domain/interface/oauth"
+	"go2o/core/infrastructure/jwt"
+	"go2o/core/variable"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ Module = new(OAuthModule)
+
+var (
+	ErrAuthCode     = errors.New("authorization code invalid or expired")
+	ErrOAuthRefresh = errors.New("refresh token invalid or expired")
+	ErrAccessToken  = errors.New("access token invalid or expired")
+)
+
+// 授权码
+type AuthCode struct {
+	// 应用编号
+	ClientId string `json:"clientId"`
+	// 会员编号
+	MemberId int64 `json:"memberId"`
+	// 回调地址
+	RedirectUri string `json:"redirectUri"`
+	// 授权范围
+	Scope string `json:"scope"`
+	// OpenID Connect的nonce,将写入身份令牌
+	Nonce string `json:"nonce"`
+	// PKCE: code_challenge
+	Challenge string `json:"challenge"`
+	// PKCE: code_challenge_method
+	ChallengeMethod string `json:"challengeMethod"`
+	// 会员授权时间
+	AuthTime int64 `json:"authTime"`
+}
+
+// 访问令牌信息
+type AccessInfo struct {
+	// 应用编号
+	ClientId string `json:"clientId"`
+	// 会员编号
+	MemberId int64 `json:"memberId"`
+	// 授权范围
+	Scope string `json:"scope"`
+	// 会员授权时间
+	AuthTime int64 `json:"authTime"`
+	// 过期时间
+	ExpiresAt int64 `json:"expiresAt"`
+}
+
+// 令牌响应(RFC 6749 5.1)
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IdToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+// OAuth2授权模块
+type OAuthModule struct {
+	app    gof.App
+	pool   *redis.Pool
+	key    *jwt.Key
+	issuer string
+	// 授权码有效时间(秒)
+	codeSeconds int64
+	// 访问令牌有效时间(秒)
+	accessSeconds int64
+	// 刷新令牌有效时间(秒)
+	refreshSeconds int64
+}
+
+// 模块数据
+func (o *OAuthModule) SetApp(app gof.App) {
+	o.app = app
+}
+
+// 初始化模块
+func (o *OAuthModule) Init() {
+	o.pool = o.app.Storage().Source().(*redis.Pool)
+	o.codeSeconds = 300
+	o.accessSeconds = 3600
+	o.refreshSeconds = 30 * 24 * 3600
+	conf := o.app.Config()
+	o.issuer = conf.GetString("oauth_issuer")
+	if o.issuer == "" {
+		o.issuer = fmt.Sprintf("%s://%s%s", variable.DOMAIN_PASSPORT_PROTO,
+			variable.DOMAIN_PREFIX_PASSPORT, variable.Domain)
+	}
+	var err error
+	if o.key, err = o.loadKey(conf.GetString("oauth_key_file")); err != nil {
+		log.Println("[ Go2o][ OAuth]: load signing key failed:", err.Error())
+	}
+}
+
+// 加载签名密钥,未配置密钥文件时,使用保存在Redis中的密钥,
+// 多个服务实例共用同一密钥
+func (o *OAuthModule) loadKey(file string) (*jwt.Key, error) {
+	if file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return jwt.ParseKey(data)
+	}
+	conn := o.pool.Get()
+	defer conn.Close()
+	rk := "go2o:module:oauth:signing_key"
+	if data, err := redis.Bytes(conn.Do("GET", rk)); err == nil {
+		return jwt.ParseKey(data)
+	}
+	k, err := jwt.GenerateKey(2048)
+	if err != nil {
+		return nil, err
+	}
+	// 其他实例已生成时使用已有的密钥
+	if n, _ := redis.Int(conn.Do("SETNX", rk, k.Pem())); n == 0 {
+		data, err := redis.Bytes(conn.Do("GET", rk))
+		if err != nil {
+			return nil, err
+		}
+		return jwt.ParseKey(data)
+	}
+	return k, nil
+}
+
+// 签发者,如:http://passport.go2o.to
+func (o *OAuthModule) Issuer() string {
+	return o.issuer
+}
+
+// 公钥集,用于第三方应用校验身份令牌
+func (o *OAuthModule) Jwks() *jwt.JWKSet {
+	set := &jwt.JWKSet{Keys: []*jwt.JWK{}}
+	if o.key != nil {
+		set.Keys = append(set.Keys, o.key.JWK())
+	}
+	return set
+}
+
+func (o *OAuthModule) getCodeKey(code string) string {
+	return "go2o:module:oauth:code:" + code
+}
+
+func (o *OAuthModule) getAccessKey(token string) string {
+	return "go2o:module:oauth:access:" + token
+}
+
+func (o *OAuthModule) getRefreshKey(token string) string {
+	return "go2o:module:oauth:refresh:" + token
+}
+
+// 会员对应用签发的所有令牌
+func (o *OAuthModule) getGrantKey(memberId int64, clientId string) string {
+	return fmt.Sprintf("go2o:module:oauth:grant:%d:%s", memberId, clientId)
+}
+
+// 创建授权码,授权码仅能使用一次
+func (o *OAuthModule) CreateCode(c *AuthCode) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	code := newToken(24)
+	conn := o.pool.Get()
+	defer conn.Close()
+	_, err = conn.Do("SET", o.getCodeKey(code), data, "EX", o.codeSeconds)
+	return code, err
+}
+
+// 获取并删除授权码或刷新令牌
+func (o *OAuthModule) take(conn redis.Conn, key string, dst interface{}) bool {
+	conn.Send("MULTI")
+	conn.Send("GET", key)
+	conn.Send("DEL", key)
+	arr, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return false
+	}
+	data, err := redis.Bytes(arr[0], nil)
+	return err == nil && json.Unmarshal(data, dst) == nil
+}
+
+// 使用授权码,授权码使用后即失效
+func (o *OAuthModule) TakeCode(code string) (*AuthCode, error) {
+	if code = strings.TrimSpace(code); code == "" {
+		return nil, ErrAuthCode
+	}
+	conn := o.pool.Get()
+	defer conn.Close()
+	c := &AuthCode{}
+	if !o.take(conn, o.getCodeKey(code), c) {
+		return nil, ErrAuthCode
+	}
+	return c, nil
+}
+
+// 签发令牌;授权范围包含offline_access时签发刷新令牌,
+// 包含openid时签发身份令牌
+func (o *OAuthModule) IssueToken(clientId string, memberId int64, scope string,
+	nonce string, authTime int64) (*OAuthToken, error) {
+	unix := time.Now().Unix()
+	info := &AccessInfo{
+		ClientId:  clientId,
+		MemberId:  memberId,
+		Scope:     scope,
+		AuthTime:  authTime,
+		ExpiresAt: unix + o.accessSeconds,
+	}
+	t := &OAuthToken{
+		AccessToken: newToken(24),
+		TokenType:   "Bearer",
+		ExpiresIn:   o.accessSeconds,
+		Scope:       scope,
+	}
+	data, _ := json.Marshal(info)
+	gk := o.getGrantKey(memberId, clientId)
+	ak := o.getAccessKey(t.AccessToken)
+	conn := o.pool.Get()
+	defer conn.Close()
+	conn.Send("MULTI")
+	conn.Send("SET", ak, data, "EX", o.accessSeconds)
+	conn.Send("SADD", gk, ak)
+	if oauth.HasScope(scope, oauth.ScopeOffline) {
+		t.RefreshToken = newToken(32)
+		rk := o.getRefreshKey(t.RefreshToken)
+		conn.Send("SET", rk, data, "EX", o.refreshSeconds)
+		conn.Send("SADD", gk, rk)
+	}
+	conn.Send("EXPIRE", gk, o.refreshSeconds)
+	if _, err := conn.Do("EXEC"); err != nil {
+		return nil, err
+	}
+	if oauth.HasScope(scope, oauth.ScopeOpenId) {
+		var err error
+		t.IdToken, err = o.signIdToken(clientId, memberId, nonce, authTime, unix)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// 签发身份令牌
+func (o *OAuthModule) signIdToken(clientId string, memberId int64,
+	nonce string, authTime int64, unix int64) (string, error) {
+	if o.key == nil {
+		return "", errors.New("oauth signing key not loaded")
+	}
+	claims := jwt.Claims{
+		"iss":       o.issuer,
+		"sub":       strconv.FormatInt(memberId, 10),
+		"aud":       clientId,
+		"iat":       unix,
+		"exp":       unix + o.accessSeconds,
+		"auth_time": authTime,
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	return o.key.Sign(claims)
+}
+
+// 使用刷新令牌签发新的令牌,原刷新令牌失效;
+// 先校验令牌所属的应用再使用令牌,避免其他应用的请求使令牌失效
+func (o *OAuthModule) RefreshToken(clientId string, refreshToken string) (*OAuthToken, error) {
+	if refreshToken = strings.TrimSpace(refreshToken); refreshToken == "" {
+		return nil, ErrOAuthRefresh
+	}
+	conn := o.pool.Get()
+	defer conn.Close()
+	info := &AccessInfo{}
+	rk := o.getRefreshKey(refreshToken)
+	data, err := redis.Bytes(conn.Do("GET", rk))
+	if err != nil || json.Unmarshal(data, info) != nil || info.ClientId != clientId {
+		return nil, ErrOAuthRefresh
+	}
+	if !o.take(conn, rk, info) || info.ClientId != clientId {
+		return nil, ErrOAuthRefresh
+	}
+	conn.Do("SREM", o.getGrantKey(info.MemberId, info.ClientId), rk)
+	return o.IssueToken(info.ClientId, info.MemberId, info.Scope, "", info.AuthTime)
+}
+
+// 检查访问令牌
+func (o *OAuthModule) CheckAccessToken(token string) (*AccessInfo, error) {
+	if token = strings.TrimSpace(token); token == "" {
+		return nil, ErrAccessToken
+	}
+	conn := o.pool.Get()
+	defer conn.Close()
+	data, err := redis.Bytes(conn.Do("GET", o.getAccessKey(token)))
+	if err != nil {
+		return nil, ErrAccessToken
+	}
+	info := &AccessInfo{}
+	if json.Unmarshal(data, info) != nil {
+		return nil, ErrAccessToken
+	}
+	return info, nil
+}
+
+// 注销访问令牌或刷新令牌(RFC 7009),令牌不存在时不返回错误
+func (o *OAuthModule) RevokeToken(clientId string, token string) {
+	conn := o.pool.Get()
+	defer conn.Close()
+	for _, k := range []string{o.getAccessKey(token), o.getRefreshKey(token)} {
+		data, err := redis.Bytes(conn.Do("GET", k))
+		info := &AccessInfo{}
+		if err == nil && json.Unmarshal(data, info) == nil &&
+			info.ClientId == clientId {
+			conn.Do("DEL", k)
+			conn.Do("SREM", o.getGrantKey(info.MemberId, clientId), k)
+		}
+	}
+}
+
+// 注销会员对应用签发的所有令牌,用于会员取消授权
+func (o *OAuthModule) RevokeGrant(memberId int64, clientId string) {
+	conn := o.pool.Get()
+	defer conn.Close()
+	gk := o.getGrantKey(memberId, clientId)
+	keys, _ := redis.Strings(conn.Do("SMEMBERS", gk))
+	args := redis.Args{}.Add(gk).AddFlat(keys)
+	conn.Do("DEL", args...)
+}
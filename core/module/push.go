@@ -0,0 +1,127 @@
+/**
+ * Copyright 2015 @ at3.net.
+ * name : push.go
+ * author : jarryliu
+ * date : 2026-10-21 15:40
+ * description : 移动设备推送,向会员已注册推送的设备会话推送消息,
+ *               推送平台根据配置创建,其他厂商通道可通过RegisterProvider添加
+ * history :
+ */
+package module
+
+import (
+	"errors"
+	"github.com/jsix/gof"
+	"go2o/core/infrastructure/push"
+	"go2o/core/variable"
+	"io/ioutil"
+	"log"
+	"sync"
+)
+
+var _ Module = new(PushModule)
+
+var ErrNoPushDevice = errors.New("member has no push device")
+
+// 推送模块
+type PushModule struct {
+	app       gof.App
+	mux       sync.RWMutex
+	providers map[string]push.IProvider
+}
+
+// 模块数据
+func (p *PushModule) SetApp(app gof.App) {
+	p.app = app
+}
+
+// 初始化模块,根据配置创建苹果及Firebase推送
+func (p *PushModule) Init() {
+	p.providers = map[string]push.IProvider{}
+	cfg := p.app.Config()
+	if file := cfg.GetString(variable.PushApnsKeyFile); file != "" {
+		if err := p.loadApns(cfg, file); err != nil {
+			log.Println("[ Go2o][ Push]: load apns key failed:", err.Error())
+		}
+	}
+	if file := cfg.GetString(variable.PushFcmCredentials); file != "" {
+		if err := p.loadFcm(file); err != nil {
+			log.Println("[ Go2o][ Push]: load fcm credentials failed:", err.Error())
+		}
+	}
+}
+
+func (p *PushModule) loadApns(cfg *gof.Config, file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	key, err := push.ParseApnsKey(data)
+	if err != nil {
+		return err
+	}
+	p.RegisterProvider(push.PlatformApns, push.NewApnsProvider(
+		cfg.GetString(variable.PushApnsKeyId),
+		cfg.GetString(variable.PushApnsTeamId),
+		cfg.GetString(variable.PushApnsTopic), key,
+		cfg.GetString(variable.PushApnsSandbox) == "true", nil))
+	return nil
+}
+
+func (p *PushModule) loadFcm(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	fp, err := push.NewFcmProvider(data, nil)
+	if err == nil {
+		p.RegisterProvider(push.PlatformFcm, fp)
+	}
+	return err
+}
+
+// 注册推送平台,如厂商通道或测试使用的模拟平台
+func (p *PushModule) RegisterProvider(platform string, pv push.IProvider) {
+	p.mux.Lock()
+	p.providers[platform] = pv
+	p.mux.Unlock()
+}
+
+// 获取推送平台
+func (p *PushModule) GetProvider(platform string) push.IProvider {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	return p.providers[platform]
+}
+
+// 向会员的所有设备推送消息,返回推送成功的设备数量;
+// 设备令牌失效时取消该会话的推送,所有设备均推送失败时返回最后的错误
+func (p *PushModule) PushToMember(memberId int64, m *push.Message) (int, error) {
+	mm := Get(M_MM).(*MemberModule)
+	list := mm.GetPushSessions(memberId)
+	if len(list) == 0 {
+		return 0, ErrNoPushDevice
+	}
+	n := 0
+	var err error
+	for _, s := range list {
+		pv := p.GetProvider(s.PushPlatform)
+		if pv == nil {
+			err = push.ErrNoPlatform
+			continue
+		}
+		e := pv.Push(s.PushToken, m)
+		if e == push.ErrInvalidToken {
+			mm.UnregisterPushToken(memberId, s.Id)
+		}
+		if e != nil {
+			err = e
+			continue
+		}
+		n++
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, err
+}
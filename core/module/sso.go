@@ -5,6 +5,7 @@
  * date : 2016-11-25 13:02
  * description :
  * history :
+ *   2026-10-19 22:30 jarryliu 已由OAuth2授权(oauth.go)替代,仅保留兼容
  */
 package module
 
@@ -20,6 +21,8 @@ import (
 
 var _ Module = new(SSOModule)
 
+// 单点登录同步模块,通过调用各应用的sync_m.p同步登录状态。
+// Deprecated: 第三方应用应使用OAuth2授权码模式(参见:OAuthModule)
 type SSOModule struct {
 	app         gof.App
 	appMap      map[string]*define.SsoApp
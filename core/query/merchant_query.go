@@ -63,9 +63,9 @@ func (m *MerchantQuery) QueryMerchantIdByHost(host string) int32 {
 	return mchId
 }
 
-// 验证用户密码并返回编号
-func (m *MerchantQuery) Verify(usr, pwd string) int32 {
+// 根据用户名获取商户编号,密码由商户的资料管理器验证
+func (m *MerchantQuery) GetMerchantIdByUsr(usr string) int32 {
 	var id int32
-	m.Connector.ExecScalar("SELECT id FROM mch_merchant WHERE usr=? AND pwd=?", &id, usr, pwd)
+	m.Connector.ExecScalar("SELECT id FROM mch_merchant WHERE usr=?", &id, usr)
 	return id
 }
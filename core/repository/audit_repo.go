@@ -0,0 +1,86 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : audit_repo
+ * author : jarryliu
+ * date : 2026-10-20 11:40
+ * description :
+ * history :
+ */
+package repository
+
+import (
+	"github.com/jsix/gof/db"
+	"github.com/jsix/gof/db/orm"
+	"go2o/core/domain/interface/audit"
+)
+
+var _ audit.IAuditRepo = new(auditRepo)
+
+type auditRepo struct {
+	db.Connector
+}
+
+func NewAuditRepo(c db.Connector) audit.IAuditRepo {
+	return &auditRepo{
+		Connector: c,
+	}
+}
+
+// 获取最新的日志
+func (a *auditRepo) GetLatestLog() *audit.Log {
+	e := audit.Log{}
+	if a.GetOrm().GetBy(&e, "1=1 ORDER BY id DESC LIMIT 1") == nil {
+		return &e
+	}
+	return nil
+}
+
+// 根据散列值获取日志
+func (a *auditRepo) GetLogByHash(hash string) *audit.Log {
+	e := audit.Log{}
+	if a.GetOrm().GetBy(&e, "hash=?", hash) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存日志,日志仅能新增
+func (a *auditRepo) SaveLog(v *audit.Log) (int64, error) {
+	id, err := orm.Save(a.GetOrm(), v, 0)
+	return int64(id), err
+}
+
+// 获取从编号fromId(包含)开始的日志
+func (a *auditRepo) GetLogs(fromId int64, size int) []*audit.Log {
+	list := []*audit.Log{}
+	a.GetOrm().Select(&list, "id>=? ORDER BY id LIMIT ?", fromId, size)
+	return list
+}
+
+// 查询目标的日志
+func (a *auditRepo) QueryByTarget(targetType string, targetId string,
+	begin, size int) (int, []*audit.Log) {
+	total := 0
+	list := []*audit.Log{}
+	a.ExecScalar("SELECT COUNT(0) FROM sys_audit_log WHERE target_type=? AND target_id=?",
+		&total, targetType, targetId)
+	if total > 0 {
+		a.GetOrm().Select(&list, "target_type=? AND target_id=? ORDER BY id DESC LIMIT ?,?",
+			targetType, targetId, begin, size)
+	}
+	return total, list
+}
+
+// 查询操作人的日志
+func (a *auditRepo) QueryByActor(actorType string, actorId int64,
+	begin, size int) (int, []*audit.Log) {
+	total := 0
+	list := []*audit.Log{}
+	a.ExecScalar("SELECT COUNT(0) FROM sys_audit_log WHERE actor_type=? AND actor_id=?",
+		&total, actorType, actorId)
+	if total > 0 {
+		a.GetOrm().Select(&list, "actor_type=? AND actor_id=? ORDER BY id DESC LIMIT ?,?",
+			actorType, actorId, begin, size)
+	}
+	return total, list
+}
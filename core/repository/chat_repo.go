@@ -0,0 +1,151 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : chat_repo.go
+ * author : jarryliu
+ * date : 2026-10-22 10:50
+ * description :
+ * history :
+ */
+package repository
+
+import (
+	"database/sql"
+	"github.com/jsix/gof/db"
+	"github.com/jsix/gof/db/orm"
+	"go2o/core/domain/interface/mss/chat"
+	"go2o/core/variable"
+)
+
+var _ chat.IChatRepo = new(chatRepo)
+
+type chatRepo struct {
+	_conn db.Connector
+}
+
+func NewChatRepo(conn db.Connector) chat.IChatRepo {
+	return &chatRepo{
+		_conn: conn,
+	}
+}
+
+// 保存会话
+func (c *chatRepo) SaveSession(v *chat.Session) (int32, error) {
+	return orm.I32(orm.Save(c._conn.GetOrm(), v, int(v.Id)))
+}
+
+// 获取会话
+func (c *chatRepo) GetSession(id int32) *chat.Session {
+	e := chat.Session{}
+	if c._conn.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取会员与商户未结束的会话
+func (c *chatRepo) GetActiveSession(memberId int64, mchId int32) *chat.Session {
+	e := chat.Session{}
+	if c._conn.GetOrm().GetBy(&e, "member_id=? AND mch_id=? AND state<>?",
+		memberId, mchId, chat.SessionClosed) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取会员最近的会话
+func (c *chatRepo) GetMemberSessions(memberId int64) []*chat.Session {
+	list := []*chat.Session{}
+	c._conn.GetOrm().Select(&list, "member_id=? ORDER BY update_time DESC LIMIT 50",
+		memberId)
+	return list
+}
+
+// 获取商户指定状态的会话
+func (c *chatRepo) GetSessions(mchId int32, agentId int32, state int) []*chat.Session {
+	list := []*chat.Session{}
+	if agentId > 0 {
+		c._conn.GetOrm().Select(&list, "mch_id=? AND agent_id=? AND state=? ORDER BY id",
+			mchId, agentId, state)
+	} else {
+		c._conn.GetOrm().Select(&list, "mch_id=? AND state=? ORDER BY id",
+			mchId, state)
+	}
+	return list
+}
+
+// 获取商户各客服接待中的会话数量
+func (c *chatRepo) GetAgentLoad(mchId int32) map[int32]int {
+	load := map[int32]int{}
+	c._conn.Query(`SELECT agent_id,COUNT(0) FROM mss_chat_session
+		WHERE mch_id=? AND state=? GROUP BY agent_id`, func(rs *sql.Rows) {
+		for rs.Next() {
+			var agentId int32
+			var n int
+			rs.Scan(&agentId, &n)
+			load[agentId] = n
+		}
+	}, mchId, chat.SessionServing)
+	return load
+}
+
+// 保存消息
+func (c *chatRepo) SaveMessage(v *chat.Message) (int64, error) {
+	id, err := orm.Save(c._conn.GetOrm(), v, int(v.Id))
+	return int64(id), err
+}
+
+// 获取消息
+func (c *chatRepo) GetMessage(id int64) *chat.Message {
+	e := chat.Message{}
+	if c._conn.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取会话中编号小于beforeId的消息
+func (c *chatRepo) GetMessages(sessionId int32, beforeId int64, size int) []*chat.Message {
+	list := []*chat.Message{}
+	if beforeId > 0 {
+		c._conn.GetOrm().Select(&list, "session_id=? AND id<? ORDER BY id DESC LIMIT ?",
+			sessionId, beforeId, size)
+	} else {
+		c._conn.GetOrm().Select(&list, "session_id=? ORDER BY id DESC LIMIT ?",
+			sessionId, size)
+	}
+	return list
+}
+
+// 统计未读的消息数量
+func (c *chatRepo) CountUnread(sessionId int32, afterId int64, readerRole int) int {
+	total := 0
+	c._conn.ExecScalar(`SELECT COUNT(0) FROM mss_chat_msg WHERE session_id=?
+		AND id>? AND sender_role<>?`, &total, sessionId, afterId, readerRole)
+	return total
+}
+
+// 保存客服
+func (c *chatRepo) SaveAgent(v *chat.Agent) (int32, error) {
+	return orm.I32(orm.Save(c._conn.GetOrm(), v, int(v.Id)))
+}
+
+// 获取客服
+func (c *chatRepo) GetAgent(mchId int32, personId int32) *chat.Agent {
+	e := chat.Agent{}
+	if c._conn.GetOrm().GetBy(&e, "mch_id=? AND person_id=?", mchId, personId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取商户的客服
+func (c *chatRepo) GetAgents(mchId int32) []*chat.Agent {
+	list := []*chat.Agent{}
+	c._conn.GetOrm().Select(&list, "mch_id=? ORDER BY person_id", mchId)
+	return list
+}
+
+// 加入到通知队列,以推送给在线的会员及客服
+func (c *chatRepo) PushNotify(sessionId int32, msgId int64) {
+	pushSocketNotify(variable.KvChatTcpNotifyQueue, int64(sessionId), msgId)
+}
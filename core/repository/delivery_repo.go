@@ -75,3 +75,73 @@ func (this *deliveryRepo) GetDeliveryBind(mchId, coverageId int32) *delivery.Mer
 	}
 	return e
 }
+
+// 保存配送员
+func (this *deliveryRepo) SaveStaff(v *delivery.DeliveryStaff) (int32, error) {
+	return orm.I32(orm.Save(this.GetOrm(), v, int(v.Id)))
+}
+
+// 获取配送员
+func (this *deliveryRepo) GetStaff(mchId, personId int32) *delivery.DeliveryStaff {
+	e := delivery.DeliveryStaff{}
+	if this.GetOrm().GetBy(&e, "mch_id=? AND person_id=?", mchId, personId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取商户的配送员,按人员编号排序
+func (this *deliveryRepo) GetStaffList(mchId int32) []*delivery.DeliveryStaff {
+	list := []*delivery.DeliveryStaff{}
+	this.GetOrm().Select(&list, "mch_id=? ORDER BY person_id", mchId)
+	return list
+}
+
+// 创建配送单
+func (this *deliveryRepo) CreateDispatchOrder(v *delivery.DispatchOrder) delivery.IDispatchOrder {
+	return deliverImpl.NewDispatchOrder(v, this)
+}
+
+// 获取配送单
+func (this *deliveryRepo) GetDispatchOrder(id int32) *delivery.DispatchOrder {
+	e := delivery.DispatchOrder{}
+	if this.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取子订单的配送单
+func (this *deliveryRepo) GetDispatchOrderBySubOrder(subOrderId int64) *delivery.DispatchOrder {
+	e := delivery.DispatchOrder{}
+	if this.GetOrm().GetBy(&e, "sub_order_id=?", subOrderId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取商户最近的一个配送单
+func (this *deliveryRepo) GetLatestDispatchOrder(mchId int32) *delivery.DispatchOrder {
+	e := delivery.DispatchOrder{}
+	if this.GetOrm().GetBy(&e, "mch_id=? ORDER BY assign_time DESC,id DESC LIMIT 1", mchId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存配送单
+func (this *deliveryRepo) SaveDispatchOrder(v *delivery.DispatchOrder) (int32, error) {
+	return orm.I32(orm.Save(this.GetOrm(), v, int(v.Id)))
+}
+
+// 保存配送签到记录
+func (this *deliveryRepo) SaveDispatchCheckIn(v *delivery.DispatchCheckIn) (int32, error) {
+	return orm.I32(orm.Save(this.GetOrm(), v, int(v.Id)))
+}
+
+// 获取配送签到记录
+func (this *deliveryRepo) GetDispatchCheckIns(dispatchId int32) []*delivery.DispatchCheckIn {
+	list := []*delivery.DispatchCheckIn{}
+	this.GetOrm().Select(&list, "dispatch_id=? ORDER BY create_time", dispatchId)
+	return list
+}
@@ -0,0 +1,188 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : event_repo
+ * author : jarryliu
+ * date : 2026-10-20 20:40
+ * description :
+ * history :
+ */
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"github.com/jsix/gof/db"
+	"github.com/jsix/gof/db/orm"
+	eventImpl "go2o/core/domain/event"
+	"go2o/core/domain/interface/event"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var _ event.IEventRepo = new(eventRepo)
+
+type eventRepo struct {
+	db.Connector
+	bus event.IEventBus
+}
+
+func NewEventRepo(c db.Connector) event.IEventRepo {
+	return &eventRepo{
+		Connector: c,
+	}
+}
+
+// 获取事件总线
+func (e *eventRepo) GetBus() event.IEventBus {
+	if e.bus == nil {
+		e.bus = eventImpl.NewEventBus(e)
+	}
+	return e.bus
+}
+
+// 保存事件
+func (e *eventRepo) SaveEvent(v *event.Event) (int64, error) {
+	id, err := orm.Save(e.GetOrm(), v, 0)
+	return int64(id), err
+}
+
+// 获取编号大于offset的事件
+func (e *eventRepo) GetEvents(offset int64, size int) []*event.Event {
+	list := []*event.Event{}
+	e.GetOrm().Select(&list, "id>? ORDER BY id LIMIT ?", offset, size)
+	return list
+}
+
+// 获取最新的事件编号
+func (e *eventRepo) GetLatestId() int64 {
+	var id int64
+	e.ExecScalar("SELECT IFNULL(MAX(id),0) FROM sys_event_outbox", &id)
+	return id
+}
+
+// 查询聚合的事件
+func (e *eventRepo) QueryByAggregate(aggregateType string, aggregateId string,
+	begin, size int) (int, []*event.Event) {
+	total := 0
+	list := []*event.Event{}
+	e.ExecScalar("SELECT COUNT(0) FROM sys_event_outbox WHERE aggregate_type=? AND aggregate_id=?",
+		&total, aggregateType, aggregateId)
+	if total > 0 {
+		e.GetOrm().Select(&list, "aggregate_type=? AND aggregate_id=? ORDER BY id DESC LIMIT ?,?",
+			aggregateType, aggregateId, begin, size)
+	}
+	return total, list
+}
+
+// 获取订阅者的位置
+func (e *eventRepo) GetOffset(consumer string) *event.Offset {
+	v := event.Offset{}
+	if e.GetOrm().Get(consumer, &v) == nil {
+		return &v
+	}
+	return nil
+}
+
+// 保存订阅者的位置
+func (e *eventRepo) SaveOffset(v *event.Offset) error {
+	var err error
+	if e.GetOffset(v.Consumer) != nil {
+		_, _, err = e.GetOrm().Save(v.Consumer, v)
+	} else {
+		_, _, err = e.GetOrm().Save(nil, v)
+	}
+	return err
+}
+
+// 保存死信
+func (e *eventRepo) SaveDeadEvent(v *event.DeadEvent) (int64, error) {
+	id, err := orm.Save(e.GetOrm(), v, int(v.Id))
+	return int64(id), err
+}
+
+// 查询订阅者的死信
+func (e *eventRepo) QueryDeadEvents(consumer string, begin, size int) (int, []*event.DeadEvent) {
+	total := 0
+	list := []*event.DeadEvent{}
+	e.ExecScalar("SELECT COUNT(0) FROM sys_event_dead WHERE consumer=?", &total, consumer)
+	if total > 0 {
+		e.GetOrm().Select(&list, "consumer=? ORDER BY id DESC LIMIT ?,?",
+			consumer, begin, size)
+	}
+	return total, list
+}
+
+// 创建发件箱中的事件
+func newOutboxEvent(eventType string, aggregateType string,
+	aggregateId string, data interface{}) *event.Event {
+	bytes, _ := json.Marshal(data)
+	return &event.Event{
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateId:   aggregateId,
+		Data:          string(bytes),
+		CreateTime:    time.Now().Unix(),
+	}
+}
+
+// 在同一事务中保存聚合并将事件写入发件箱,聚合与事件同时成功或失败。
+// ORM不支持事务,聚合按db标签生成SQL保存;primary为0时新增,否则按主键更新;
+// ev根据保存后的编号返回要写入的事件,不需要写入时返回nil
+func saveWithOutbox(c db.Connector, table string, entity interface{},
+	primary int64, ev func(id int64) *event.Event) (int64, error) {
+	tx, err := c.Raw().Begin()
+	if err != nil {
+		return 0, err
+	}
+	id, err := txSaveEntity(tx, table, entity, primary)
+	if err == nil {
+		if e := ev(id); e != nil {
+			_, err = tx.Exec(`INSERT INTO sys_event_outbox(type,aggregate_type,
+				aggregate_id,data,create_time) VALUES(?,?,?,?,?)`, e.Type,
+				e.AggregateType, e.AggregateId, e.Data, e.CreateTime)
+		}
+	}
+	if err != nil {
+		tx.Rollback()
+		log.Println("[ Go2o][ Event][ Error]: save", table, "with outbox failed:", err.Error())
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+// 在事务中保存实体,字段按db标签映射,返回主键编号
+func txSaveEntity(tx *sql.Tx, table string, entity interface{}, primary int64) (int64, error) {
+	v := reflect.Indirect(reflect.ValueOf(entity))
+	t := v.Type()
+	pk := ""
+	cols := []string{}
+	args := []interface{}{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := f.Tag.Get("db")
+		if name == "" || name == "-" {
+			continue
+		}
+		if f.Tag.Get("pk") == "yes" {
+			pk = name
+			if primary > 0 || f.Tag.Get("auto") == "yes" {
+				continue
+			}
+		}
+		cols = append(cols, name)
+		args = append(args, v.Field(i).Interface())
+	}
+	if primary > 0 {
+		_, err := tx.Exec("UPDATE "+table+" SET "+strings.Join(cols, "=?,")+
+			"=? WHERE "+pk+"=?", append(args, primary)...)
+		return primary, err
+	}
+	r, err := tx.Exec("INSERT INTO "+table+"("+strings.Join(cols, ",")+
+		") VALUES(?"+strings.Repeat(",?", len(cols)-1)+")", args...)
+	if err != nil {
+		return 0, err
+	}
+	return r.LastInsertId()
+}
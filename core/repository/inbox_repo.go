@@ -0,0 +1,229 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : inbox_repo.go
+ * author : jarryliu
+ * date : 2026-10-23 11:10
+ * description :
+ * history :
+ */
+package repository
+
+import (
+	"database/sql"
+	"github.com/jsix/gof/db"
+	"github.com/jsix/gof/db/orm"
+	"go2o/core/domain/interface/mss/inbox"
+	"go2o/core/infrastructure/format"
+	"time"
+)
+
+var _ inbox.IInboxRepo = new(inboxRepo)
+
+type inboxRepo struct {
+	_conn db.Connector
+}
+
+func NewInboxRepo(conn db.Connector) inbox.IInboxRepo {
+	return &inboxRepo{
+		_conn: conn,
+	}
+}
+
+// 保存广播消息
+func (i *inboxRepo) SaveBroadcast(v *inbox.Broadcast) (int32, error) {
+	return orm.I32(orm.Save(i._conn.GetOrm(), v, int(v.Id)))
+}
+
+// 获取广播消息
+func (i *inboxRepo) GetBroadcast(id int32) *inbox.Broadcast {
+	e := inbox.Broadcast{}
+	if i._conn.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取广播消息
+func (i *inboxRepo) GetBroadcasts(begin, size int) (int, []*inbox.Broadcast) {
+	total := 0
+	list := []*inbox.Broadcast{}
+	i._conn.ExecScalar("SELECT COUNT(0) FROM mss_broadcast", &total)
+	if total > 0 {
+		i._conn.GetOrm().Select(&list, "1=1 ORDER BY id DESC LIMIT ?,?",
+			begin, size)
+	}
+	return total, list
+}
+
+// 获取编号大于lastId且未过期的广播消息
+func (i *inboxRepo) GetBroadcastsAfter(lastId int32, unix int64, size int) []*inbox.Broadcast {
+	list := []*inbox.Broadcast{}
+	i._conn.GetOrm().Select(&list, `id>? AND (expires_time=0 OR expires_time>?)
+		ORDER BY id LIMIT ?`, lastId, unix, size)
+	return list
+}
+
+// 获取会员已同步的广播消息编号
+func (i *inboxRepo) GetCursor(memberId int64) int32 {
+	var lastId int32
+	i._conn.ExecScalar("SELECT last_id FROM mss_inbox_cursor WHERE member_id=?",
+		&lastId, memberId)
+	return lastId
+}
+
+// 更新会员已同步的广播消息编号
+func (i *inboxRepo) ClaimCursor(memberId int64, from int32, to int32) bool {
+	unix := time.Now().Unix()
+	if from == 0 {
+		n, err := i._conn.ExecNonQuery(`INSERT IGNORE INTO mss_inbox_cursor
+			(member_id,last_id,update_time) VALUES(?,?,?)`, memberId, to, unix)
+		if err == nil && n == 1 {
+			return true
+		}
+	}
+	n, err := i._conn.ExecNonQuery(`UPDATE mss_inbox_cursor SET last_id=?,update_time=?
+		WHERE member_id=? AND last_id=?`, to, unix, memberId, from)
+	return err == nil && n == 1
+}
+
+// 获取会员用于匹配目标分群的信息
+func (i *inboxRepo) GetTarget(memberId int64) *inbox.Target {
+	t := &inbox.Target{MemberId: memberId}
+	err := i._conn.QueryRow(`SELECT m.level,m.reg_time,IFNULL(p.province,0),
+		IFNULL(p.city,0),IFNULL(p.district,0),IFNULL(g.group_id,0) FROM mm_member m
+		LEFT JOIN mm_profile p ON p.member_id=m.id
+		LEFT JOIN mm_member_group g ON g.member_id=m.id WHERE m.id=?`,
+		func(rs *sql.Row) error {
+			return rs.Scan(&t.Level, &t.RegTime, &t.Province, &t.City,
+				&t.District, &t.GroupId)
+		}, memberId)
+	if err != nil {
+		return nil
+	}
+	if t.GroupId <= 0 {
+		// 未设置分组的会员属于默认分组
+		i._conn.ExecScalar("SELECT id FROM mm_buyer_group WHERE is_default=1 LIMIT 1",
+			&t.GroupId)
+	}
+	t.Tags = i.GetMemberTags(memberId)
+	return t
+}
+
+// 保存消息
+func (i *inboxRepo) SaveMessage(v *inbox.Message) (int64, error) {
+	id, err := orm.Save(i._conn.GetOrm(), v, int(v.Id))
+	return int64(id), err
+}
+
+// 保存广播同步到收件箱的消息,依赖会员及广播编号的唯一索引忽略重复的消息
+func (i *inboxRepo) SaveBroadcastMessage(v *inbox.Message) error {
+	_, err := i._conn.ExecNonQuery(`INSERT IGNORE INTO mss_inbox(member_id,
+		broadcast_id,subject,content,has_read,read_time,deleted,create_time,
+		expires_time) VALUES(?,?,?,?,0,0,0,?,?)`, v.MemberId, v.BroadcastId,
+		v.Subject, v.Content, v.CreateTime, v.ExpiresTime)
+	return err
+}
+
+// 获取消息
+func (i *inboxRepo) GetMessage(id int64) *inbox.Message {
+	e := inbox.Message{}
+	if i._conn.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取会员未删除且未过期的消息
+func (i *inboxRepo) GetMessages(memberId int64, unreadOnly bool, unix int64,
+	begin, size int) (int, []*inbox.Message) {
+	total := 0
+	list := []*inbox.Message{}
+	where := "member_id=? AND deleted=0 AND (expires_time=0 OR expires_time>?)"
+	if unreadOnly {
+		where += " AND has_read=0"
+	}
+	i._conn.ExecScalar("SELECT COUNT(0) FROM mss_inbox WHERE "+where,
+		&total, memberId, unix)
+	if total > 0 {
+		i._conn.GetOrm().Select(&list, where+" ORDER BY create_time DESC,id DESC LIMIT ?,?",
+			memberId, unix, begin, size)
+	}
+	return total, list
+}
+
+// 统计未读的消息数量
+func (i *inboxRepo) CountUnread(memberId int64, unix int64) int {
+	total := 0
+	i._conn.ExecScalar(`SELECT COUNT(0) FROM mss_inbox WHERE member_id=? AND has_read=0
+		AND deleted=0 AND (expires_time=0 OR expires_time>?)`, &total, memberId, unix)
+	return total
+}
+
+// 标记消息已读
+func (i *inboxRepo) MarkRead(memberId int64, ids []int64, unix int64) error {
+	s := `UPDATE mss_inbox SET has_read=1,read_time=? WHERE member_id=?
+		AND has_read=0 AND deleted=0`
+	if len(ids) > 0 {
+		s += " AND id IN (" + format.IdArrJoinStr64(ids) + ")"
+	}
+	_, err := i._conn.ExecNonQuery(s, unix, memberId)
+	return err
+}
+
+// 删除消息
+func (i *inboxRepo) DeleteMessages(memberId int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := i._conn.ExecNonQuery(`UPDATE mss_inbox SET deleted=1 WHERE member_id=?
+		AND id IN (`+format.IdArrJoinStr64(ids)+")", memberId)
+	return err
+}
+
+// 删除广播同步到收件箱的消息
+func (i *inboxRepo) DeleteBroadcastMessages(broadcastId int32) error {
+	_, err := i._conn.ExecNonQuery("DELETE FROM mss_inbox WHERE broadcast_id=?",
+		broadcastId)
+	return err
+}
+
+// 删除已过期的消息
+func (i *inboxRepo) DeleteExpired(unix int64) (int, error) {
+	return i._conn.ExecNonQuery(`DELETE FROM mss_inbox WHERE expires_time>0
+		AND expires_time<=?`, unix)
+}
+
+// 保存会员的买家分组
+func (i *inboxRepo) SaveMemberGroup(memberId int64, groupId int32) error {
+	_, err := i._conn.ExecNonQuery(`INSERT INTO mm_member_group(member_id,group_id)
+		VALUES(?,?) ON DUPLICATE KEY UPDATE group_id=?`, memberId, groupId, groupId)
+	return err
+}
+
+// 保存会员的标签
+func (i *inboxRepo) SaveMemberTags(memberId int64, tags []string) error {
+	_, err := i._conn.ExecNonQuery("DELETE FROM mm_member_tag WHERE member_id=?",
+		memberId)
+	for _, v := range tags {
+		if err != nil {
+			break
+		}
+		_, err = i._conn.ExecNonQuery(`INSERT INTO mm_member_tag(member_id,tag)
+			VALUES(?,?)`, memberId, v)
+	}
+	return err
+}
+
+// 获取会员的标签
+func (i *inboxRepo) GetMemberTags(memberId int64) []string {
+	list := []string{}
+	i._conn.Query("SELECT tag FROM mm_member_tag WHERE member_id=? ORDER BY id",
+		func(rs *sql.Rows) {
+			for rs.Next() {
+				var tag string
+				rs.Scan(&tag)
+				list = append(list, tag)
+			}
+		}, memberId)
+	return list
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/jsix/gof/db"
 	"github.com/jsix/gof/db/orm"
 	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/event"
 	"go2o/core/domain/interface/express"
 	"go2o/core/domain/interface/item"
 	"go2o/core/domain/interface/pro_model"
@@ -22,6 +23,7 @@ import (
 	itemImpl "go2o/core/domain/item"
 	"go2o/core/infrastructure/format"
 	"log"
+	"strconv"
 )
 
 var _ item.IGoodsItemRepo = new(goodsRepo)
@@ -153,9 +155,25 @@ func (g *goodsRepo) RemoveGoodsLevelPrice(id int32) error {
 	return g.Connector.GetOrm().DeleteByPk(item.MemberPrice{}, id)
 }
 
-// 保存商品
+// 保存商品,库存变更时写入事件
 func (g *goodsRepo) SaveValueGoods(v *item.GoodsItem) (int32, error) {
-	return orm.I32(orm.Save(g.GetOrm(), v, int(v.Id)))
+	var stock int32 = -1
+	if v.Id > 0 {
+		g.ExecScalar("SELECT stock_num FROM item_info WHERE id=?", &stock, v.Id)
+	}
+	id, err := saveWithOutbox(g.Connector, "item_info", v, int64(v.Id),
+		func(id int64) *event.Event {
+			if stock == v.StockNum {
+				return nil
+			}
+			return newOutboxEvent(event.ItemStockChanged, event.AggregateItem,
+				strconv.Itoa(int(id)), &event.ItemData{
+					ItemId:   int32(id),
+					VendorId: v.VendorId,
+					StockNum: v.StockNum,
+				})
+		})
+	return int32(id), err
 }
 
 // 获取已上架的商品
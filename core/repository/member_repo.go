@@ -17,6 +17,8 @@ import (
 	"github.com/jsix/gof/db/orm"
 	"github.com/jsix/gof/storage"
 	"go2o/core"
+	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/event"
 	"go2o/core/domain/interface/member"
 	"go2o/core/domain/interface/mss"
 	"go2o/core/domain/interface/valueobject"
@@ -26,6 +28,7 @@ import (
 	"go2o/core/infrastructure/tool"
 	"go2o/core/variable"
 	"log"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -242,13 +245,17 @@ func (m *MemberRepo) SaveMember(v *member.Member) (int64, error) {
 		rc.Do("RPUSH", variable.KvMemberUpdateTcpNotifyQueue, v.Id) // push to tcp notify queue
 
 		// 保存会员信息
-		_, _, err := m.Connector.GetOrm().Save(v.Id, v)
-
+		_, err := saveWithOutbox(m.Connector, "mm_member", v, v.Id,
+			func(id int64) *event.Event {
+				return newOutboxEvent(event.MemberUpdated, event.AggregateMember,
+					strconv.FormatInt(id, 10), &event.MemberData{
+						MemberId:   id,
+						UpdateTime: v.UpdateTime,
+					})
+			})
 		if err == nil {
 			// 存储到缓存中
 			err = m.Storage.Set(m.getMemberCk(v.Id), *v)
-			// 存储到队列
-			rc.Do("RPUSH", variable.KvMemberUpdateQueue, fmt.Sprintf("%d-update", v.Id))
 		}
 		return v.Id, err
 	}
@@ -257,19 +264,20 @@ func (m *MemberRepo) SaveMember(v *member.Member) (int64, error) {
 }
 
 func (m *MemberRepo) createMember(v *member.Member) (int64, error) {
-	var id int64
-	_, id, err := m.Connector.GetOrm().Save(nil, v)
+	id, err := saveWithOutbox(m.Connector, "mm_member", v, 0,
+		func(id int64) *event.Event {
+			return newOutboxEvent(event.MemberRegistered, event.AggregateMember,
+				strconv.FormatInt(id, 10), &event.MemberData{
+					MemberId:   id,
+					UpdateTime: v.UpdateTime,
+				})
+		})
 	if err != nil {
 		return -1, err
 	}
 	v.Id = id
 	m.initMember(v)
 
-	rc := core.GetRedisConn()
-	defer rc.Close()
-	rc.Do("RPUSH", variable.KvMemberUpdateQueue,
-		fmt.Sprintf("%d-create", v.Id)) // push to queue
-
 	// 更新会员数 todo: 考虑去掉
 	var total = 0
 	m.Connector.ExecScalar("SELECT COUNT(0) FROM mm_member", &total)
@@ -300,6 +308,10 @@ func (m *MemberRepo) initMember(v *member.Member) {
 		InviterId: 0,
 		RegMchId:  0,
 	})
+
+	// 收件箱从注册时最新的广播消息之后开始同步
+	m.ExecNonQuery(`INSERT IGNORE INTO mss_inbox_cursor(member_id,last_id,update_time)
+		SELECT ?,IFNULL(MAX(id),0),? FROM mss_broadcast`, v.Id, v.RegTime)
 }
 
 // 删除会员
@@ -376,7 +388,14 @@ func (m *MemberRepo) GetAccount(memberId int64) *member.Account {
 
 // 保存账户，传入会员编号
 func (m *MemberRepo) SaveAccount(v *member.Account) (int64, error) {
-	_, _, err := m.Connector.GetOrm().Save(v.MemberId, v)
+	_, err := saveWithOutbox(m.Connector, "mm_account", v, v.MemberId,
+		func(id int64) *event.Event {
+			return newOutboxEvent(event.AccountChanged, event.AggregateAccount,
+				strconv.FormatInt(id, 10), &event.MemberData{
+					MemberId:   id,
+					UpdateTime: v.UpdateTime,
+				})
+		})
 	if err == nil {
 		m.pushToAccountUpdateQueue(v.MemberId, v.UpdateTime)
 		m.Storage.Set(m.getAccountCk(v.MemberId), *v)
@@ -621,6 +640,22 @@ func (m *MemberRepo) GetInvitationMeMember(memberId int64) *member.Member {
 	return d
 }
 
+// 获取指定时间后注册的受邀会员数量
+func (m *MemberRepo) GetInvitationNumSince(memberId int64, unix int64) int {
+	total := 0
+	m.Connector.ExecScalar(`SELECT COUNT(0) FROM mm_member WHERE reg_time >= ? AND id IN
+	 (SELECT member_id FROM mm_relation WHERE inviter_id=?)`, &total, unix, memberId)
+	return total
+}
+
+// 获取指定IP注册的受邀会员数量
+func (m *MemberRepo) GetInvitationNumByIp(memberId int64, ip string) int {
+	total := 0
+	m.Connector.ExecScalar(`SELECT COUNT(0) FROM mm_member WHERE reg_ip = ? AND id IN
+	 (SELECT member_id FROM mm_relation WHERE inviter_id=?)`, &total, ip, memberId)
+	return total
+}
+
 // 根据编号获取余额变动信息
 func (m *MemberRepo) GetBalanceInfo(id int32) *member.BalanceInfo {
 	var e member.BalanceInfo
@@ -694,3 +729,56 @@ func (m *MemberRepo) SaveMmBuyerGroup(v *member.BuyerGroup) (int, error) {
 	}
 	return id, err
 }
+
+// 获取等待人工审核的实名认证,按风险分数从高到低排列
+func (m *MemberRepo) GetAwaitingTrustedInfos(begin, size int) (int, []*member.TrustedInfo) {
+	total := 0
+	list := []*member.TrustedInfo{}
+	m.ExecScalar("SELECT COUNT(0) FROM mm_trusted_info WHERE reviewed=?",
+		&total, enum.ReviewAwaiting)
+	if total > 0 {
+		m._orm.Select(&list, "reviewed=? ORDER BY risk_score DESC,update_time LIMIT ?,?",
+			enum.ReviewAwaiting, begin, size)
+	}
+	return total, list
+}
+
+// 根据平台及账号标识获取第三方账号
+func (m *MemberRepo) GetSocialIdentity(provider string, openId string) *member.SocialIdentity {
+	e := member.SocialIdentity{}
+	if m._orm.GetBy(&e, "provider=? AND open_id=?", provider, openId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 根据平台及UnionID获取第三方账号
+func (m *MemberRepo) GetSocialIdentityByUnionId(provider string, unionId string) *member.SocialIdentity {
+	if unionId == "" {
+		return nil
+	}
+	e := member.SocialIdentity{}
+	if m._orm.GetBy(&e, "provider=? AND union_id=?", provider, unionId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取会员绑定的第三方账号
+func (m *MemberRepo) GetSocialIdentities(memberId int64) []*member.SocialIdentity {
+	list := []*member.SocialIdentity{}
+	m._orm.Select(&list, "member_id=? ORDER BY id", memberId)
+	return list
+}
+
+// 保存第三方账号
+func (m *MemberRepo) SaveSocialIdentity(v *member.SocialIdentity) (int32, error) {
+	return orm.I32(orm.Save(m._orm, v, int(v.Id)))
+}
+
+// 删除第三方账号
+func (m *MemberRepo) DeleteSocialIdentity(memberId int64, provider string) error {
+	_, err := m._orm.Delete(member.SocialIdentity{}, "member_id=? AND provider=?",
+		memberId, provider)
+	return err
+}
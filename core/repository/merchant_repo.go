@@ -203,9 +203,12 @@ func (m *merchantRepo) SaveMerchantSaleConf(v *merchant.SaleConf) error {
 	return err
 }
 
-// 保存API信息
+// 保存API信息,并清除接口认证使用的缓存,使轮换的密钥或停用立即生效
 func (m *merchantRepo) SaveApiInfo(v *merchant.ApiInfo) error {
 	_, err := orm.Save(m.GetOrm(), v, int(v.MerchantId))
+	if err == nil {
+		m.storage.Del(fmt.Sprintf("%s%d", variable.KvMerchantApiInfo, v.MerchantId))
+	}
 	return err
 }
 
@@ -225,6 +228,78 @@ func (m *merchantRepo) GetMerchantIdByApiId(apiId string) int32 {
 	return mchId
 }
 
+// 获取接口限流配置
+func (m *merchantRepo) GetApiLimits(mchId int32) []*merchant.ApiLimit {
+	list := []*merchant.ApiLimit{}
+	m.GetOrm().Select(&list, "mch_id=?", mchId)
+	return list
+}
+
+// 保存接口限流配置,并清除缓存
+func (m *merchantRepo) SaveApiLimit(v *merchant.ApiLimit) (int32, error) {
+	id, err := orm.I32(orm.Save(m.GetOrm(), v, int(v.Id)))
+	if err == nil {
+		m.storage.Del(fmt.Sprintf("%s%d-%s", variable.KvMerchantApiLimit, v.MchId, v.Group))
+	}
+	return id, err
+}
+
+// 获取商户的推送地址
+func (m *merchantRepo) GetWebhooks(mchId int32) []*merchant.Webhook {
+	list := []*merchant.Webhook{}
+	m.GetOrm().Select(&list, "mch_id=? ORDER BY id", mchId)
+	return list
+}
+
+// 保存推送地址
+func (m *merchantRepo) SaveWebhook(v *merchant.Webhook) (int32, error) {
+	return orm.I32(orm.Save(m.GetOrm(), v, int(v.Id)))
+}
+
+// 删除推送地址
+func (m *merchantRepo) DeleteWebhook(mchId int32, id int32) error {
+	_, err := m.GetOrm().Delete(merchant.Webhook{}, "mch_id=? AND id=?", mchId, id)
+	return err
+}
+
+// 获取推送记录
+func (m *merchantRepo) GetWebhookDelivery(id int64) *merchant.WebhookDelivery {
+	e := merchant.WebhookDelivery{}
+	if m.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存推送记录
+func (m *merchantRepo) SaveWebhookDelivery(v *merchant.WebhookDelivery) (int64, error) {
+	id, err := orm.Save(m.GetOrm(), v, int(v.Id))
+	return int64(id), err
+}
+
+// 查询推送记录,webhookId及state为0时不作为条件
+func (m *merchantRepo) QueryWebhookDeliveries(mchId int32, webhookId int32, state int,
+	begin, size int) (int, []*merchant.WebhookDelivery) {
+	total := 0
+	list := []*merchant.WebhookDelivery{}
+	where := "mch_id=?"
+	args := []interface{}{mchId}
+	if webhookId > 0 {
+		where += " AND webhook_id=?"
+		args = append(args, webhookId)
+	}
+	if state > 0 {
+		where += " AND state=?"
+		args = append(args, state)
+	}
+	m.ExecScalar("SELECT COUNT(0) FROM mch_webhook_delivery WHERE "+where, &total, args...)
+	if total > 0 {
+		m.GetOrm().Select(&list, where+" ORDER BY id DESC LIMIT ?,?",
+			append(args, begin, size)...)
+	}
+	return total, list
+}
+
 // 获取键值
 func (m *merchantRepo) GetKeyValue(mchId int32, indent string, k string) string {
 	var v string
@@ -16,9 +16,13 @@ import (
 	"github.com/jsix/gof/util"
 	"go2o/core"
 	"go2o/core/domain/interface/mss"
+	"go2o/core/domain/interface/mss/chat"
+	"go2o/core/domain/interface/mss/inbox"
 	"go2o/core/domain/interface/mss/notify"
 	"go2o/core/domain/interface/valueobject"
 	mssImpl "go2o/core/domain/mss"
+	chatImpl "go2o/core/domain/mss/chat"
+	inboxImpl "go2o/core/domain/mss/inbox"
 	notifyImpl "go2o/core/domain/mss/notify"
 	"go2o/core/variable"
 )
@@ -29,6 +33,8 @@ type mssRepo struct {
 	_conn         db.Connector
 	_sysManger    mss.IMessageManager
 	_notifyManger notify.INotifyManager
+	_chatManager  chat.IChatManager
+	_inboxManager inbox.IInboxManager
 	_notifyRepo   notify.INotifyRepo
 	_valRepo      valueobject.IValueRepo
 	_globMss      mss.IUserMessageManager
@@ -54,12 +60,30 @@ func (m *mssRepo) MessageManager() mss.IMessageManager {
 // 通知服务
 func (m *mssRepo) NotifyManager() notify.INotifyManager {
 	if m._notifyManger == nil {
-		m._notifyManger = notifyImpl.NewNotifyManager(
-			m._notifyRepo, m._valRepo)
+		mgr := notifyImpl.NewNotifyManager(m._notifyRepo, m._valRepo)
+		mgr.RegisterSender(notify.TypeSiteMessage, mssImpl.NewSiteSender(m))
+		mgr.RegisterSender(notify.TypeEmailMessage, mssImpl.NewMailSender(m))
+		m._notifyManger = mgr
 	}
 	return m._notifyManger
 }
 
+// 客服会话
+func (m *mssRepo) ChatManager() chat.IChatManager {
+	if m._chatManager == nil {
+		m._chatManager = chatImpl.NewChatManager(NewChatRepo(m._conn))
+	}
+	return m._chatManager
+}
+
+// 站内信
+func (m *mssRepo) InboxManager() inbox.IInboxManager {
+	if m._inboxManager == nil {
+		m._inboxManager = inboxImpl.NewInboxManager(NewInboxRepo(m._conn))
+	}
+	return m._inboxManager
+}
+
 func (m *mssRepo) GetProvider() mss.IUserMessageManager {
 	if m._globMss == nil {
 		m._globMss = mssImpl.NewMssManager(0, m)
@@ -118,17 +142,9 @@ func (m *mssRepo) DeleteMailTemplate(mchId, id int32) error {
 
 // 加入到发送对列
 func (m *mssRepo) JoinMailTaskToQueen(v *mss.MailTask) error {
-	var err error
-	if v.Id > 0 {
-		_, _, err = m._conn.GetOrm().Save(v.Id, v)
-	} else {
-		_, _, err = m._conn.GetOrm().Save(nil, v)
-		if err == nil {
-			err = m._conn.ExecScalar("SELECT max(id) FROM pt_mail_queue", &v.Id)
-		}
-	}
-
+	id, err := orm.I32(orm.Save(m._conn.GetOrm(), v, int(v.Id)))
 	if err == nil {
+		v.Id = id
 		rc := core.GetRedisConn()
 		defer rc.Close()
 		rc.Do("RPUSH", variable.KvNewMailTask, v.Id) // push to queue
@@ -160,7 +176,14 @@ func (m *mssRepo) GetMessage(id int32) *mss.Message {
 
 // 保存用户消息关联
 func (m *mssRepo) SaveUserMsg(v *mss.To) (int32, error) {
-	return orm.I32(orm.Save(m._conn.GetOrm(), v, int(v.Id)))
+	isNew := v.Id <= 0
+	id, err := orm.I32(orm.Save(m._conn.GetOrm(), v, int(v.Id)))
+	// 新的会员消息加入到通知队列,以推送给在线的会员
+	if err == nil && isNew && v.ToRole == mss.RoleMember {
+		pushSocketNotify(variable.KvMessageTcpNotifyQueue,
+			int64(v.ToId), int64(v.MsgId))
+	}
+	return id, err
 }
 
 // 保存消息内容
@@ -10,6 +10,7 @@ package repository
 
 import (
 	"github.com/jsix/gof/db"
+	"github.com/jsix/gof/db/orm"
 	"github.com/jsix/gof/util"
 	"go2o/core/domain/interface/mss/notify"
 )
@@ -39,6 +40,20 @@ func (this *notifyRepImpl) getNotifyItemMap() map[string]*notify.NotifyItem {
 				vv := *v
 				this._notifyItems[v.Key] = &vv
 			}
+		} else {
+			// 合并新增的通知项,及不允许修改的属性
+			for _, v := range notify.DefaultNotifyItems {
+				if v2, ok := this._notifyItems[v.Key]; ok {
+					v2.ReadonlyBy = v.ReadonlyBy
+					v2.Urgent = v.Urgent
+					if v2.Subject == "" {
+						v2.Subject = v.Subject
+					}
+				} else {
+					vv := *v
+					this._notifyItems[v.Key] = &vv
+				}
+			}
 		}
 	}
 	return this._notifyItems
@@ -66,3 +81,124 @@ func (this *notifyRepImpl) SaveNotifyItem(v *notify.NotifyItem) error {
 	this._notifyItems[v.Key] = v
 	return this._itemGob.Save(this._notifyItems)
 }
+
+// 获取通知项的模板
+func (this *notifyRepImpl) GetTemplates(key string) []*notify.Template {
+	list := []*notify.Template{}
+	this._conn.GetOrm().Select(&list, "notify_key=? ORDER BY id", key)
+	return list
+}
+
+// 获取模板
+func (this *notifyRepImpl) GetTemplate(id int32) *notify.Template {
+	e := notify.Template{}
+	if this._conn.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存模板
+func (this *notifyRepImpl) SaveTemplate(v *notify.Template) (int32, error) {
+	return orm.I32(orm.Save(this._conn.GetOrm(), v, int(v.Id)))
+}
+
+// 删除模板
+func (this *notifyRepImpl) DeleteTemplate(id int32) error {
+	return this._conn.GetOrm().DeleteByPk(notify.Template{}, id)
+}
+
+// 获取会员的通知偏好
+func (this *notifyRepImpl) GetPreference(memberId int64) *notify.Preference {
+	e := notify.Preference{}
+	if this._conn.GetOrm().Get(memberId, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存会员的通知偏好
+func (this *notifyRepImpl) SavePreference(v *notify.Preference) error {
+	var err error
+	if this.GetPreference(v.MemberId) != nil {
+		_, _, err = this._conn.GetOrm().Save(v.MemberId, v)
+	} else {
+		_, _, err = this._conn.GetOrm().Save(nil, v)
+	}
+	return err
+}
+
+// 获取会员对通知项设置的渠道
+func (this *notifyRepImpl) GetEventPrefs(memberId int64) []*notify.EventPref {
+	list := []*notify.EventPref{}
+	this._conn.GetOrm().Select(&list, "member_id=?", memberId)
+	return list
+}
+
+// 获取会员对通知项设置的渠道
+func (this *notifyRepImpl) GetEventPref(memberId int64, key string) *notify.EventPref {
+	e := notify.EventPref{}
+	if this._conn.GetOrm().GetBy(&e, "member_id=? AND notify_key=?", memberId, key) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存会员对通知项设置的渠道
+func (this *notifyRepImpl) SaveEventPref(v *notify.EventPref) (int32, error) {
+	return orm.I32(orm.Save(this._conn.GetOrm(), v, int(v.Id)))
+}
+
+// 保存发送记录
+func (this *notifyRepImpl) SaveDelivery(v *notify.Delivery) (int64, error) {
+	id, err := orm.Save(this._conn.GetOrm(), v, int(v.Id))
+	return int64(id), err
+}
+
+// 查询会员的通知发送记录
+func (this *notifyRepImpl) QueryDeliveries(memberId int64, begin, size int) (int, []*notify.Delivery) {
+	total := 0
+	list := []*notify.Delivery{}
+	this._conn.ExecScalar("SELECT COUNT(0) FROM notify_delivery WHERE member_id=?",
+		&total, memberId)
+	if total > 0 {
+		this._conn.GetOrm().Select(&list, "member_id=? ORDER BY id DESC LIMIT ?,?",
+			memberId, begin, size)
+	}
+	return total, list
+}
+
+// 保存短信发送记录
+func (this *notifyRepImpl) SaveSmsMessage(v *notify.SmsMessage) (int64, error) {
+	id, err := orm.Save(this._conn.GetOrm(), v, int(v.Id))
+	return int64(id), err
+}
+
+// 根据服务商的短信编号获取短信发送记录
+func (this *notifyRepImpl) GetSmsMessage(provider int, msgId string) *notify.SmsMessage {
+	if msgId == "" {
+		return nil
+	}
+	e := notify.SmsMessage{}
+	if this._conn.GetOrm().GetBy(&e, "provider=? AND msg_id=?", provider, msgId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 查询短信发送记录
+func (this *notifyRepImpl) QuerySmsMessages(phone string, begin, size int) (int, []*notify.SmsMessage) {
+	total := 0
+	list := []*notify.SmsMessage{}
+	where, args := "1=1", []interface{}{}
+	if phone != "" {
+		where, args = "phone=?", append(args, phone)
+	}
+	this._conn.ExecScalar("SELECT COUNT(0) FROM sms_message WHERE "+where,
+		&total, args...)
+	if total > 0 {
+		this._conn.GetOrm().Select(&list, where+" ORDER BY id DESC LIMIT ?,?",
+			append(args, begin, size)...)
+	}
+	return total, list
+}
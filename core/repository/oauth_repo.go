@@ -0,0 +1,82 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : oauth_repo
+ * author : jarryliu
+ * date : 2026-10-19 22:00
+ * description :
+ * history :
+ */
+package repository
+
+import (
+	"github.com/jsix/gof/db"
+	"github.com/jsix/gof/db/orm"
+	"go2o/core/domain/interface/oauth"
+	oaImpl "go2o/core/domain/oauth"
+)
+
+var _ oauth.IOAuthRepo = new(oauthRepo)
+
+type oauthRepo struct {
+	db.Connector
+}
+
+func NewOAuthRepo(c db.Connector) oauth.IOAuthRepo {
+	return &oauthRepo{
+		Connector: c,
+	}
+}
+
+// 创建应用
+func (o *oauthRepo) CreateClient(v *oauth.Client) oauth.IClient {
+	return oaImpl.NewClient(v, o)
+}
+
+// 获取应用
+func (o *oauthRepo) GetClient(clientId string) oauth.IClient {
+	e := oauth.Client{}
+	if o.GetOrm().GetBy(&e, "client_id=?", clientId) == nil {
+		return o.CreateClient(&e)
+	}
+	return nil
+}
+
+// 获取商户的应用,mchId为0时返回平台的应用
+func (o *oauthRepo) GetClients(mchId int32) []*oauth.Client {
+	list := []*oauth.Client{}
+	o.GetOrm().Select(&list, "mch_id=? ORDER BY id", mchId)
+	return list
+}
+
+// 保存应用
+func (o *oauthRepo) SaveClient(v *oauth.Client) (int32, error) {
+	return orm.I32(orm.Save(o.GetOrm(), v, int(v.Id)))
+}
+
+// 获取会员对应用的授权
+func (o *oauthRepo) GetConsent(memberId int64, clientId string) *oauth.Consent {
+	e := oauth.Consent{}
+	if o.GetOrm().GetBy(&e, "member_id=? AND client_id=?", memberId, clientId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取会员授权的所有应用
+func (o *oauthRepo) GetConsents(memberId int64) []*oauth.Consent {
+	list := []*oauth.Consent{}
+	o.GetOrm().Select(&list, "member_id=? ORDER BY update_time DESC", memberId)
+	return list
+}
+
+// 保存授权
+func (o *oauthRepo) SaveConsent(v *oauth.Consent) (int32, error) {
+	return orm.I32(orm.Save(o.GetOrm(), v, int(v.Id)))
+}
+
+// 删除授权
+func (o *oauthRepo) DeleteConsent(memberId int64, clientId string) error {
+	_, err := o.GetOrm().Delete(oauth.Consent{}, "member_id=? AND client_id=?",
+		memberId, clientId)
+	return err
+}
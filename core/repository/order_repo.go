@@ -15,9 +15,9 @@ import (
 	"github.com/jsix/gof/db"
 	"github.com/jsix/gof/db/orm"
 	"github.com/jsix/gof/storage"
-	"go2o/core"
 	"go2o/core/domain/interface/cart"
 	"go2o/core/domain/interface/delivery"
+	"go2o/core/domain/interface/event"
 	"go2o/core/domain/interface/express"
 	"go2o/core/domain/interface/item"
 	"go2o/core/domain/interface/member"
@@ -310,16 +310,14 @@ func (o *orderRepImpl) GetOrder(where string, arg ...interface{}) *order.Order {
 	return nil
 }
 
-// 加入到订单通知队列,如果为子订单,则带上sub
-func (o *orderRepImpl) pushOrderQueue(orderNo string, sub bool) {
-	rc := core.GetRedisConn()
-	if sub {
-		rc.Do("RPUSH", variable.KvOrderBusinessQueue, fmt.Sprintf("sub!%d", orderNo))
-	} else {
-		rc.Do("RPUSH", variable.KvOrderBusinessQueue, orderNo)
+// 订单状态改变时的事件
+func (o *orderRepImpl) newOrderEvent(data *event.OrderData) *event.Event {
+	aggType := event.AggregateOrder
+	if data.Sub {
+		aggType = event.AggregateSubOrder
 	}
-	rc.Close()
-	//log.Println("-----order ",v.ID,v.Status,statusIsChanged,err)
+	return newOutboxEvent(event.OrderEventType(data.State), aggType,
+		data.OrderNo, data)
 }
 
 // Save OrderList
@@ -348,24 +346,30 @@ func (o *orderRepImpl) SaveOrder(v *order.Order) (int, error) {
 	}
 	// log.Println("--- save order:", v.ID, "; state:",
 	// v.State, ";", statusIsChanged)
-	id, err := o.saveOrder(v)
+	//如果业务状态已经发生改变,则在同一事务中写入事件
+	id, err := saveWithOutbox(o.Connector, "order_list", v, v.ID,
+		func(id int64) *event.Event {
+			if !statusIsChanged {
+				return nil
+			}
+			return o.newOrderEvent(&event.OrderData{
+				OrderNo: v.OrderNo,
+				BuyerId: v.BuyerId,
+				State:   v.State,
+			})
+		})
 	if err == nil {
-		v.ID = int64(id)
-		//如果业务状态已经发生改变,则提交到队列
-		if statusIsChanged {
-			o.pushOrderQueue(v.OrderNo, false)
-		}
+		v.ID = id
 	}
-	return id, err
+	return int(id), err
 }
 
-func (o *orderRepImpl) saveSubOrder(v *order.NormalSubOrder) (int, error) {
-	id, err := orm.Save(o._orm, v, int(v.ID))
-	if err != nil && err != sql.ErrNoRows {
-		log.Println("[ Orm][ Error]:", err.Error(), "; Entity:SaleSubOrder")
-	}
+// 保存子订单,ev返回需在同一事务中写入的事件
+func (o *orderRepImpl) saveSubOrder(v *order.NormalSubOrder,
+	ev func(id int64) *event.Event) (int, error) {
+	id, err := saveWithOutbox(o.Connector, "sale_sub_order", v, v.ID, ev)
 	if err == nil {
-		v.ID = int64(id)
+		v.ID = id
 		// 缓存订单号
 		o.Storage.Set(o.getOrderCkByNo(v.OrderNo, true), v.ID)
 		// 缓存订单
@@ -384,17 +388,30 @@ func (o *orderRepImpl) SaveSubOrder(v *order.NormalSubOrder) (int, error) {
 		origin := o.GetSubOrder(v.ID)
 		statusIsChanged = origin.State != v.State
 	}
-	id, err := o.saveSubOrder(v)
-	if err == nil {
-		v.ID = int64(id)
-		//如果业务状态已经发生改变,则提交到队列
-		if statusIsChanged {
-			o.pushOrderQueue(v.OrderNo, true)
+	//如果业务状态已经发生改变,则在同一事务中写入事件
+	id, err := o.saveSubOrder(v, func(id int64) *event.Event {
+		if !statusIsChanged {
+			return nil
 		}
+		return o.newOrderEvent(&event.OrderData{
+			OrderNo:  v.OrderNo,
+			Sub:      true,
+			BuyerId:  v.BuyerId,
+			VendorId: v.VendorId,
+			State:    v.State,
+		})
+	})
+	if err == nil && statusIsChanged {
+		o.pushOrderStateNotify(v.BuyerId, v.ID)
 	}
 	return id, err
 }
 
+// 加入到订单状态通知队列,以推送给在线的会员
+func (o *orderRepImpl) pushOrderStateNotify(buyerId int64, subOrderId int64) {
+	pushSocketNotify(variable.KvOrderStateTcpNotifyQueue, buyerId, subOrderId)
+}
+
 // Get WholesaleOrder
 func (o *orderRepImpl) GetWholesaleOrder(where string, v ...interface{}) *order.WholesaleOrder {
 	e := order.WholesaleOrder{}
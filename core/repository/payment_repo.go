@@ -11,16 +11,15 @@ package repository
 import (
 	"fmt"
 	"github.com/jsix/gof/db"
-	"github.com/jsix/gof/db/orm"
 	"github.com/jsix/gof/storage"
 	"github.com/jsix/gof/util"
-	"go2o/core"
+	"go2o/core/domain/interface/event"
 	"go2o/core/domain/interface/member"
 	"go2o/core/domain/interface/order"
 	"go2o/core/domain/interface/payment"
 	"go2o/core/domain/interface/valueobject"
 	payImpl "go2o/core/domain/payment"
-	"go2o/core/variable"
+	"strconv"
 )
 
 var _ payment.IPaymentRepo = new(paymentRepo)
@@ -104,26 +103,25 @@ func (p *paymentRepo) SavePaymentOrder(v *payment.PaymentOrder) (int32, error) {
 	if v.Id > 0 {
 		stat = p.GetPaymentOrderById(v.Id).GetValue().State
 	}
-	id, err := orm.I32(orm.Save(p.GetOrm(), v, int(v.Id)))
+	// 已经更改过状态,且为已成功,则写入支付完成事件
+	id64, err := saveWithOutbox(p.Connector, "pay_order", v, int64(v.Id),
+		func(id int64) *event.Event {
+			if stat != v.State && v.State == payment.StateFinishPayment {
+				return newOutboxEvent(event.PaymentFinished, event.AggregatePaymentOrder,
+					strconv.Itoa(int(id)), &event.PaymentData{
+						PaymentOrderId: int32(id),
+						TradeNo:        v.TradeNo,
+					})
+			}
+			return nil
+		})
+	id := int32(id64)
 	if err == nil {
 		v.Id = id
 		// 缓存订单
 		p.Storage.SetExpire(p.getPaymentOrderCk(id), *v, DefaultCacheSeconds)
 		// 缓存订单号与订单的关系
 		p.Storage.SetExpire(p.getPaymentOrderCkByNo(v.TradeNo), v.Id, DefaultCacheSeconds*10)
-		// 已经更改过状态,且为已成功,则推送到队列中
-		if stat != v.State && v.State == payment.StateFinishPayment {
-			p.notifyPaymentFinish(v.Id)
-		}
 	}
 	return id, err
 }
-
-// 通知支付单完成
-func (p *paymentRepo) notifyPaymentFinish(paymentOrderId int32) error {
-	rc := core.GetRedisConn()
-	defer rc.Close()
-	_, err := rc.Do("RPUSH", variable.KvPaymentOrderFinishQueue, paymentOrderId)
-	//log.Println("--  推送支付单成功", paymentOrderId)
-	return err
-}
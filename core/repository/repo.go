@@ -9,17 +9,34 @@
 package repository
 
 import (
+	"fmt"
+	"github.com/jsix/gof"
 	"github.com/jsix/gof/log"
 	"github.com/jsix/gof/storage"
+	"go2o/core"
 	"go2o/core/infrastructure/domain"
+	"go2o/core/variable"
 	"sync"
 )
 
 var (
 	mux                 sync.Mutex
 	DefaultCacheSeconds int64 = 3600
+	// 推送队列的最大长度,Socket服务停止时丢弃较早的通知
+	socketNotifyQueueMax = 10000
 )
 
+// 加入到Socket服务的推送队列,格式如:1!1001;未启用Socket服务时不推送
+func pushSocketNotify(queue string, id int64, objId int64) {
+	if gof.CurrentApp.Config().GetString(variable.SocketServeOn) != "1" {
+		return
+	}
+	rc := core.GetRedisConn()
+	defer rc.Close()
+	rc.Do("RPUSH", queue, fmt.Sprintf("%d!%d", id, objId))
+	rc.Do("LTRIM", queue, -socketNotifyQueueMax, -1)
+}
+
 // 处理错误
 func handleError(err error) error {
 	return domain.HandleError(err, "rep")
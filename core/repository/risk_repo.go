@@ -0,0 +1,188 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : risk_repo
+ * author : jarryliu
+ * date : 2026-10-20 16:00
+ * description :
+ * history :
+ */
+package repository
+
+import (
+	"github.com/jsix/gof/db"
+	"github.com/jsix/gof/db/orm"
+	"github.com/jsix/gof/util"
+	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/member"
+	"go2o/core/domain/interface/risk"
+	riskImpl "go2o/core/domain/risk"
+	"sync"
+)
+
+var _ risk.IRiskRepo = new(riskRepo)
+
+// 默认的风控设置
+var DefaultRiskSettings = risk.Settings{
+	VelocityWindow:    3600,
+	MemberVelocity:    5,
+	IpVelocity:        20,
+	DeviceVelocity:    10,
+	CoolingPeriod:     3 * 24 * 3600,
+	CoolingAmount:     100,
+	InviteWindow:      24 * 3600,
+	InviteLimit:       20,
+	SameIpInviteLimit: 3,
+}
+
+type riskRepo struct {
+	db.Connector
+	memberRepo member.IMemberRepo
+	manager    risk.IRiskManager
+	settings   *risk.Settings
+	gob        *util.GobFile
+	mux        sync.RWMutex
+}
+
+func NewRiskRepo(c db.Connector, memberRepo member.IMemberRepo) risk.IRiskRepo {
+	return &riskRepo{
+		Connector:  c,
+		memberRepo: memberRepo,
+		gob:        util.NewGobFile("conf/core/risk_conf"),
+	}
+}
+
+// 获取风控管理
+func (r *riskRepo) GetManager() risk.IRiskManager {
+	if r.manager == nil {
+		r.manager = riskImpl.NewRiskManager(r, r.memberRepo)
+	}
+	return r.manager
+}
+
+// 获取设置
+func (r *riskRepo) GetSettings() risk.Settings {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.settings == nil {
+		v := DefaultRiskSettings
+		r.settings = &v
+		r.gob.Unmarshal(r.settings)
+	}
+	return *r.settings
+}
+
+// 保存设置
+func (r *riskRepo) SaveSettings(v *risk.Settings) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.settings = v
+	return r.gob.Save(r.settings)
+}
+
+// 获取记录
+func (r *riskRepo) GetRecord(id int64) *risk.Record {
+	e := risk.Record{}
+	if r.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存记录
+func (r *riskRepo) SaveRecord(v *risk.Record) (int64, error) {
+	id, err := orm.Save(r.GetOrm(), v, int(v.Id))
+	return int64(id), err
+}
+
+// 统计指定时间后的操作次数,field为统计的字段,如:FieldIp
+func (r *riskRepo) CountRecords(operation string, field string,
+	value interface{}, since int64) int {
+	switch field {
+	case risk.FieldMember, risk.FieldIp, risk.FieldDevice:
+	default:
+		return 0
+	}
+	total := 0
+	r.ExecScalar("SELECT COUNT(0) FROM risk_record WHERE operation=? AND "+
+		field+"=? AND create_time >= ?", &total, operation, value, since)
+	return total
+}
+
+// 查询记录,decision为0时查询所有
+func (r *riskRepo) QueryRecords(decision int, begin, size int) (int, []*risk.Record) {
+	total := 0
+	list := []*risk.Record{}
+	where := "1=1"
+	args := []interface{}{}
+	if decision > 0 {
+		where = "decision=?"
+		args = append(args, decision)
+	}
+	r.ExecScalar("SELECT COUNT(0) FROM risk_record WHERE "+where, &total, args...)
+	if total > 0 {
+		r.GetOrm().Select(&list, where+" ORDER BY id DESC LIMIT ?,?",
+			append(args, begin, size)...)
+	}
+	return total, list
+}
+
+// 获取审核
+func (r *riskRepo) GetReview(id int64) *risk.Review {
+	e := risk.Review{}
+	if r.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存审核
+func (r *riskRepo) SaveReview(v *risk.Review) (int64, error) {
+	id, err := orm.Save(r.GetOrm(), v, int(v.Id))
+	return int64(id), err
+}
+
+// 更新等待审核的操作的状态,返回是否更新
+func (r *riskRepo) UpdateReviewState(id int64, state int32, remark string,
+	reviewTime int64) (bool, error) {
+	n, err := r.ExecNonQuery(`UPDATE risk_review SET state=?,remark=?,review_time=?
+		WHERE id=? AND state=?`, state, remark, reviewTime, id, enum.ReviewAwaiting)
+	return n > 0, err
+}
+
+// 查询审核,state为0时查询所有
+func (r *riskRepo) QueryReviews(state int32, begin, size int) (int, []*risk.Review) {
+	total := 0
+	list := []*risk.Review{}
+	where := "1=1"
+	args := []interface{}{}
+	if state > 0 {
+		where = "state=?"
+		args = append(args, state)
+	}
+	r.ExecScalar("SELECT COUNT(0) FROM risk_review WHERE "+where, &total, args...)
+	if total > 0 {
+		r.GetOrm().Select(&list, where+" ORDER BY id DESC LIMIT ?,?",
+			append(args, begin, size)...)
+	}
+	return total, list
+}
+
+// 获取黑名单
+func (r *riskRepo) GetBlackItem(kind string, value string) *risk.BlackItem {
+	e := risk.BlackItem{}
+	if r.GetOrm().GetBy(&e, "kind=? AND value=?", kind, value) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存黑名单
+func (r *riskRepo) SaveBlackItem(v *risk.BlackItem) (int32, error) {
+	return orm.I32(orm.Save(r.GetOrm(), v, int(v.Id)))
+}
+
+// 删除黑名单
+func (r *riskRepo) DeleteBlackItem(kind string, value string) error {
+	_, err := r.GetOrm().Delete(risk.BlackItem{}, "kind=? AND value=?", kind, value)
+	return err
+}
@@ -0,0 +1,55 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : security_repo
+ * author : jarryliu
+ * date : 2026-10-19 20:00
+ * description :
+ * history :
+ */
+package repository
+
+import (
+	"github.com/jsix/gof/db"
+	"github.com/jsix/gof/db/orm"
+	"go2o/core/domain/interface/security"
+	secImpl "go2o/core/domain/security"
+)
+
+var _ security.ITwoFactorRepo = new(securityRepo)
+
+type securityRepo struct {
+	db.Connector
+}
+
+func NewSecurityRepo(c db.Connector) security.ITwoFactorRepo {
+	return &securityRepo{
+		Connector: c,
+	}
+}
+
+// 创建两步验证,如已存在则返回已有的
+func (s *securityRepo) CreateTwoFactor(userType int, userId int64) security.ITwoFactor {
+	v := s.GetTwoFactor(userType, userId)
+	if v == nil {
+		v = &security.TwoFactor{
+			UserType: userType,
+			UserId:   userId,
+			State:    security.TwoFactorDisabled,
+		}
+	}
+	return secImpl.NewTwoFactor(v, s)
+}
+
+// 获取两步验证
+func (s *securityRepo) GetTwoFactor(userType int, userId int64) *security.TwoFactor {
+	e := security.TwoFactor{}
+	if s.GetOrm().GetBy(&e, "user_type=? AND user_id=?", userType, userId) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 保存两步验证
+func (s *securityRepo) SaveTwoFactor(v *security.TwoFactor) (int32, error) {
+	return orm.I32(orm.Save(s.GetOrm(), v, int(v.Id)))
+}
@@ -62,6 +62,17 @@ func (s *shipmentRepo) GetShipOrders(orderId int64) []shipment.IShipmentOrder {
 	return orders
 }
 
+// 获取子订单对应的发货单
+func (s *shipmentRepo) GetSubOrderShipOrders(subOrderId int64) []shipment.IShipmentOrder {
+	list := []*shipment.ShipmentOrder{}
+	s.GetOrm().Select(&list, "sub_orderid=?", subOrderId)
+	orders := make([]shipment.IShipmentOrder, len(list))
+	for i, v := range list {
+		orders[i] = s.CreateShipmentOrder(v)
+	}
+	return orders
+}
+
 // 保存发货单
 func (s *shipmentRepo) SaveShipmentOrder(o *shipment.ShipmentOrder) (int, error) {
 	return orm.Save(s.GetOrm(), o, int(o.ID))
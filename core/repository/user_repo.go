@@ -12,17 +12,20 @@ import (
 	"github.com/jsix/gof/db"
 	"github.com/jsix/gof/db/orm"
 	"go2o/core/domain/interface/merchant/user"
+	"go2o/core/domain/interface/security"
 )
 
 var _ user.IUserRepo = new(userRepo)
 
 type userRepo struct {
 	db.Connector
+	secRepo security.ITwoFactorRepo
 }
 
-func NewUserRepo(c db.Connector) user.IUserRepo {
+func NewUserRepo(c db.Connector, secRepo security.ITwoFactorRepo) user.IUserRepo {
 	return &userRepo{
 		Connector: c,
+		secRepo:   secRepo,
 	}
 }
 
@@ -44,7 +47,7 @@ func (this *userRepo) SaveCredential(v *user.CredentialValue) (int32, error) {
 // 获取人员
 func (this *userRepo) GetPersonValue(id int32) *user.PersonValue {
 	e := new(user.PersonValue)
-	err := this.Connector.GetOrm().Get(e, id)
+	err := this.Connector.GetOrm().Get(id, e)
 	if err != nil {
 		return nil
 	}
@@ -54,9 +57,77 @@ func (this *userRepo) GetPersonValue(id int32) *user.PersonValue {
 // 获取配送人员
 func (this *userRepo) GetDeliveryStaffPersons(mchId int32) []*user.PersonValue {
 	e := make([]*user.PersonValue, 0)
-	err := this.Connector.GetOrm().Select(e, "select * from usr_person")
+	err := this.Connector.GetOrm().SelectByQuery(&e, `SELECT p.* FROM usr_person p
+		INNER JOIN dlv_staff s ON s.person_id = p.id WHERE s.mch_id=? ORDER BY p.id`, mchId)
 	if err != nil {
 		return nil
 	}
 	return e
 }
+
+// 获取所有角色
+func (this *userRepo) GetRoles() []*user.RoleValue {
+	list := make([]*user.RoleValue, 0)
+	this.Connector.GetOrm().Select(&list, "1=1 ORDER BY id")
+	return list
+}
+
+// 获取角色
+func (this *userRepo) GetRole(id int32) *user.RoleValue {
+	e := user.RoleValue{}
+	if this.Connector.GetOrm().Get(id, &e) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取商户的角色
+func (this *userRepo) GetMchRoles(mchId int32) []*user.RoleValue {
+	list := make([]*user.RoleValue, 0)
+	this.Connector.GetOrm().Select(&list, "mch_id=? ORDER BY id", mchId)
+	return list
+}
+
+// 删除角色
+func (this *userRepo) DeleteRole(id int32) error {
+	return this.Connector.GetOrm().DeleteByPk(user.RoleValue{}, id)
+}
+
+// 获取人员授予的角色
+func (this *userRepo) GetRoleGrants(personId int32) []*user.RoleGrant {
+	list := make([]*user.RoleGrant, 0)
+	this.Connector.GetOrm().Select(&list, "person_id=? ORDER BY id", personId)
+	return list
+}
+
+// 保存角色授予
+func (this *userRepo) SaveRoleGrant(v *user.RoleGrant) (int32, error) {
+	return orm.I32(orm.Save(this.GetOrm(), v, int(v.Id)))
+}
+
+// 删除角色授予
+func (this *userRepo) DeleteRoleGrant(personId int32, roleId int32, shopId int32) error {
+	_, err := this.Connector.GetOrm().Delete(user.RoleGrant{},
+		"person_id=? AND role_id=? AND shop_id=?", personId, roleId, shopId)
+	return err
+}
+
+// 删除角色的所有授予
+func (this *userRepo) DeleteRoleGrants(roleId int32) error {
+	_, err := this.Connector.GetOrm().Delete(user.RoleGrant{}, "role_id=?", roleId)
+	return err
+}
+
+// 根据用户名获取凭据
+func (this *userRepo) GetCredentialByUsr(usr string) *user.CredentialValue {
+	e := user.CredentialValue{}
+	if this.Connector.GetOrm().GetBy(&e, "usr=?", usr) == nil {
+		return &e
+	}
+	return nil
+}
+
+// 获取人员的两步验证
+func (this *userRepo) GetTwoFactor(personId int32) security.ITwoFactor {
+	return this.secRepo.CreateTwoFactor(security.UserMerchantStaff, int64(personId))
+}
@@ -60,6 +60,11 @@ var (
 			valueobject.RKMemberTransferAccountsOn: "true",
 			// 会员转账提示信息
 			valueobject.RKMemberTransferAccountsMessage: "平台仅提供转账功能，请尽量当面交易以保证安全！",
+			// 提现及转账超过该金额时必须实名认证
+			valueobject.RKMemberTrustRequiredAmount: "-1",
+			// 实名认证自动通过及拒绝的风险分数
+			valueobject.RKKycAutoPassRisk:   "30",
+			valueobject.RKKycAutoRejectRisk: "80",
 		},
 	}
 	systemIncorrectWords = `系统|官方|shop|www|政府|mall|mch|商户|客服|system|`
@@ -188,5 +193,6 @@ var (
 		valueobject.SmsHttp:   {Default: true},
 		valueobject.SmsAli:    {},
 		valueobject.Sms253Com: {},
+		valueobject.SmsFake:   {},
 	}
 )
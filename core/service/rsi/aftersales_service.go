@@ -12,6 +12,7 @@ import (
 	"github.com/jsix/gof/db"
 	"github.com/labstack/gommon/log"
 	"go2o/core/domain/interface/after-sales"
+	"go2o/core/domain/interface/merchant/user"
 	"go2o/core/domain/interface/order"
 	"go2o/core/dto"
 	"go2o/core/infrastructure/format"
@@ -89,9 +90,26 @@ func (a *afterSalesService) GetAfterSaleOrder(id int32) *afterSales.AfterSalesOr
 	return nil
 }
 
-// 同意售后
-func (a *afterSalesService) AgreeAfterSales(id int32, remark string) error {
+// 同意售后,退款及退货需要审核退款的权限;
+// personId为操作的员工,为0表示商户本身操作
+func (a *afterSalesService) AgreeAfterSales(mchId int32, personId int32,
+	id int32, remark string) error {
 	as := a._rep.GetAfterSalesOrder(id)
+	if as == nil || as.Value().VendorId != mchId {
+		return afterSales.ErrNoSuchOrder
+	}
+	v := as.Value()
+	if v.Type == afterSales.TypeRefund || v.Type == afterSales.TypeReturn {
+		var shopId int32
+		if o := a._orderRepo.GetSubOrder(v.OrderId); o != nil {
+			shopId = o.ShopId
+		}
+		err := MerchantService.CheckStaffPermission(mchId, personId,
+			shopId, user.PermRefundApprove)
+		if err != nil {
+			return err
+		}
+	}
 	return as.Agree()
 }
 
@@ -0,0 +1,72 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : audit_service.go
+ * author : jarryliu
+ * date : 2026-10-20 11:50
+ * description : 审计服务,记录管理及资金相关的操作
+ * history :
+ */
+package rsi
+
+import (
+	"fmt"
+	auditImpl "go2o/core/domain/audit"
+	"go2o/core/domain/interface/audit"
+	"log"
+)
+
+type auditService struct {
+	_rep     audit.IAuditRepo
+	_auditor audit.IAuditor
+}
+
+// 创建审计服务,key为计算日志散列值的密钥
+func NewAuditService(rep audit.IAuditRepo, key string) *auditService {
+	return &auditService{
+		_rep:     rep,
+		_auditor: auditImpl.NewAuditor(rep, []byte(key)),
+	}
+}
+
+// 记录操作,actor为空时视为系统操作
+func (a *auditService) Record(actor *audit.Actor, action string, targetType string,
+	targetId string, before interface{}, after interface{}, remark string) error {
+	_, err := a._auditor.Record(actor, action, targetType, targetId,
+		before, after, remark)
+	return err
+}
+
+// 查询目标的日志
+func (a *auditService) QueryByTarget(targetType string, targetId string,
+	begin, size int) (int, []*audit.Log) {
+	return a._rep.QueryByTarget(targetType, targetId, begin, size)
+}
+
+// 查询操作人的日志
+func (a *auditService) QueryByActor(actorType string, actorId int64,
+	begin, size int) (int, []*audit.Log) {
+	return a._rep.QueryByActor(actorType, actorId, begin, size)
+}
+
+// 校验日志是否被篡改,返回被篡改的日志编号
+func (a *auditService) Verify(fromId int64, size int) (int64, error) {
+	return a._auditor.Verify(fromId, size)
+}
+
+// 记录审计日志,记录失败不影响操作结果
+func auditLog(actor *audit.Actor, action string, targetType string,
+	targetId interface{}, before interface{}, after interface{}, remark string) {
+	id := fmt.Sprint(targetId)
+	err := AuditService.Record(actor, action, targetType, id, before, after, remark)
+	if err != nil {
+		log.Println("[ Go2o][ Audit]: record failed", action, targetType, id, err)
+	}
+}
+
+// 根据关联的操作人编号获取操作人,未关联时为系统操作
+func relateActor(relateUser int64) *audit.Actor {
+	if relateUser > 0 {
+		return &audit.Actor{Type: audit.ActorAdmin, Id: relateUser}
+	}
+	return audit.SystemActor
+}
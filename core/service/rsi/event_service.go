@@ -0,0 +1,52 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : event_service.go
+ * author : jarryliu
+ * date : 2026-10-20 21:00
+ * description : 领域事件服务,订阅事件及发布发件箱中的事件
+ * history :
+ */
+package rsi
+
+import (
+	"go2o/core/domain/interface/event"
+)
+
+type eventService struct {
+	_rep event.IEventRepo
+	_bus event.IEventBus
+}
+
+func NewEventService(rep event.IEventRepo) *eventService {
+	return &eventService{
+		_rep: rep,
+		_bus: rep.GetBus(),
+	}
+}
+
+// 订阅事件,types为空时订阅所有事件
+func (e *eventService) Subscribe(consumer string, types []string, h event.Handler) error {
+	return e._bus.Subscribe(consumer, types, h)
+}
+
+// 将发件箱中的事件发布给订阅者,返回发布的事件数量;
+// 为保证事件的顺序,同一时间只应有一个进程发布
+func (e *eventService) Relay(size int) (int, error) {
+	return e._bus.Relay(size)
+}
+
+// 从指定位置重放事件
+func (e *eventService) Replay(consumer string, offset int64) error {
+	return e._bus.Replay(consumer, offset)
+}
+
+// 获取订阅者的位置
+func (e *eventService) GetOffset(consumer string) int64 {
+	return e._bus.GetOffset(consumer)
+}
+
+// 查询聚合的事件
+func (e *eventService) QueryByAggregate(aggregateType string, aggregateId string,
+	begin, size int) (int, []*event.Event) {
+	return e._rep.QueryByAggregate(aggregateType, aggregateId, begin, size)
+}
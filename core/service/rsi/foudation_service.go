@@ -94,6 +94,7 @@ func (s *foundationService) FlushSuperPwd(user string, pwd string) (err error) {
 //   -  1. 成功，并返回token
 //   - -1. 接口地址不正确
 //   - -2. 已经注册
+// Deprecated: 第三方应用请使用OAuthService.RegisterClient注册
 func (s *foundationService) RegisterApp(app *define.SsoApp) (r string, err error) {
 	sso := module.Get(module.M_SSO).(*module.SSOModule)
 	token, err := sso.Register(app)
@@ -110,6 +111,7 @@ func (s *foundationService) GetApp(name string) (r *define.SsoApp, err error) {
 }
 
 // 获取单点登录应用
+// Deprecated: 第三方应用请使用OAuth2授权码模式登录
 func (s *foundationService) GetAllSsoApp() (r []string, err error) {
 	sso := module.Get(module.M_SSO).(*module.SSOModule)
 	return sso.Array(), nil
@@ -16,9 +16,11 @@ import (
 	"go2o/core/domain/interface/enum"
 	"go2o/core/domain/interface/item"
 	"go2o/core/domain/interface/merchant"
+	"go2o/core/domain/interface/merchant/user"
 	"go2o/core/domain/interface/pro_model"
 	"go2o/core/domain/interface/product"
 	"go2o/core/domain/interface/valueobject"
+	itemImpl "go2o/core/domain/item"
 	"go2o/core/infrastructure/format"
 	"go2o/core/query"
 	"go2o/core/service/thrift/idl/gen-go/define"
@@ -110,8 +112,10 @@ func (s *itemService) GetItemSkuJson(itemId int32) (r string, err error) {
 	return string(skuBytes), nil
 }
 
-// 保存商品
-func (s *itemService) SaveItem(di *define.Item, vendorId int32) (_ *define.Result_, err error) {
+// 保存商品,personId为操作的员工,为0表示商户本身操作;
+// 按已保存商品的店铺检查权限,修改商品或SKU的价格及新建带价格的商品时需要改价权限
+func (s *itemService) SaveItem(di *define.Item, vendorId int32,
+	personId int32) (_ *define.Result_, err error) {
 	var gi item.IGoodsItem
 	it := parser.Item(di)
 	if it.Id > 0 {
@@ -120,7 +124,19 @@ func (s *itemService) SaveItem(di *define.Item, vendorId int32) (_ *define.Resul
 			err = item.ErrNoSuchItem
 			goto R
 		}
+		if err = s.checkItemPermission(gi, it, personId); err != nil {
+			goto R
+		}
 	} else {
+		err = MerchantService.CheckStaffPermission(vendorId, personId,
+			it.ShopId, user.PermItemEdit)
+		if err == nil && itemImpl.PriceChanged(nil, nil, it) {
+			err = MerchantService.CheckStaffPermission(vendorId, personId,
+				it.ShopId, user.PermPriceChange)
+		}
+		if err != nil {
+			goto R
+		}
 		gi = s.itemRepo.CreateItem(it)
 	}
 	err = gi.SetValue(it)
@@ -134,6 +150,23 @@ R:
 	return parser.Result(it.Id, err), nil
 }
 
+// 检查员工修改商品的权限,转移店铺时需要拥有新店铺的权限
+func (s *itemService) checkItemPermission(gi item.IGoodsItem, it *item.GoodsItem,
+	personId int32) error {
+	v := gi.GetValue()
+	err := MerchantService.CheckStaffPermission(v.VendorId, personId,
+		v.ShopId, user.PermItemEdit)
+	if err == nil && it.ShopId != v.ShopId {
+		err = MerchantService.CheckStaffPermission(v.VendorId, personId,
+			it.ShopId, user.PermItemEdit)
+	}
+	if err == nil && itemImpl.PriceChanged(v, gi.SkuArray(), it) {
+		err = MerchantService.CheckStaffPermission(v.VendorId, personId,
+			v.ShopId, user.PermPriceChange)
+	}
+	return err
+}
+
 // 获取上架商品数据（分页）
 func (s *itemService) GetPagedOnShelvesItem(itemType int32, catId int32, start,
 	end int32, where, sortBy string) (int32, []*define.Item) {
@@ -462,15 +495,21 @@ func (s *itemService) GetGoodsLevelPrices(itemId int32) []*item.MemberPrice {
 	return make([]*item.MemberPrice, 0)
 }
 
-// 保存商品的会员价
-func (s *itemService) SaveMemberPrices(mchId int32, itemId int32,
-	priceSet []*item.MemberPrice) (err error) {
+// 保存商品的会员价,personId为操作的员工,为0表示商户本身操作
+func (s *itemService) SaveMemberPrices(mchId int32, personId int32,
+	itemId int32, priceSet []*item.MemberPrice) (err error) {
 	gi := s.itemRepo.GetItem(itemId)
-	if gi != nil {
-		for _, v := range priceSet {
-			if _, err = gi.SaveLevelPrice(v); err != nil {
-				return err
-			}
+	if gi == nil || gi.GetValue().VendorId != mchId {
+		return item.ErrNoSuchItem
+	}
+	err = MerchantService.CheckStaffPermission(mchId, personId,
+		gi.GetValue().ShopId, user.PermPriceChange)
+	if err != nil {
+		return err
+	}
+	for _, v := range priceSet {
+		if _, err = gi.SaveLevelPrice(v); err != nil {
+			return err
 		}
 	}
 	return err
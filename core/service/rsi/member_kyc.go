@@ -0,0 +1,43 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : member_kyc
+ * author : jarryliu
+ * date : 2026-10-20 14:20
+ * description : 会员实名认证自动审核
+ * history :
+ */
+package rsi
+
+import (
+	"go2o/core/domain/interface/audit"
+	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/member"
+	"go2o/core/infrastructure/kyc"
+	"strings"
+	"time"
+)
+
+// 自动认证实名信息,无法判定或未设置认证接口时等待人工审核
+func (ms *memberService) verifyTrustedInfo(m member.IMember) error {
+	pf := m.Profile()
+	before := pf.GetTrustedInfo()
+	r, err := kyc.Verify(before.RealName, before.CardId, before.TrustImage, time.Now())
+	if err == kyc.ErrNoVerifier {
+		return nil
+	}
+	if err != nil {
+		return member.ErrTrustCardId
+	}
+	reviewed, err := pf.AutoReviewTrustedInfo(int32(r.RiskScore), r.Factors)
+	if err == nil && reviewed != enum.ReviewAwaiting {
+		auditLog(audit.SystemActor, "member.kyc_auto_review", audit.TargetMember,
+			m.GetAggregateRootId(), before, pf.GetTrustedInfo(),
+			strings.Join(r.Factors, ","))
+	}
+	return err
+}
+
+// 获取等待人工审核的实名认证,按风险分数从高到低排列
+func (ms *memberService) GetTrustReviewQueue(begin, size int) (int, []*member.TrustedInfo) {
+	return ms._repo.GetAwaitingTrustedInfos(begin, size)
+}
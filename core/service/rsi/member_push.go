@@ -0,0 +1,126 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : member_push
+ * author : jarryliu
+ * date : 2026-10-21 16:10
+ * description : 移动设备推送,会员会话注册设备令牌后,通知及站内信可推送到设备;
+ *               订阅订单及账户事件,推送订单状态变更及账户变动
+ * history :
+ */
+package rsi
+
+import (
+	"fmt"
+	"github.com/jsix/gof"
+	"go2o/core/domain/interface/event"
+	"go2o/core/domain/interface/mss/notify"
+	"go2o/core/domain/interface/order"
+	"go2o/core/infrastructure/format"
+	"go2o/core/infrastructure/push"
+	"go2o/core/module"
+	"strconv"
+	"strings"
+)
+
+var _ notify.ISender = new(pushSender)
+
+// 推送发送器,推送到会员已注册推送的所有设备
+type pushSender struct {
+}
+
+func (p *pushSender) Send(key string, to *notify.Recipient, subject string,
+	body string, data map[string]interface{}) error {
+	if to.MemberId <= 0 {
+		return notify.ErrNoAddress
+	}
+	m := &push.Message{
+		Title: subject,
+		Body:  body,
+		Data:  map[string]string{"notifyKey": key},
+	}
+	// 附加数据中的文本及数字由客户端处理,如订单号
+	for k, v := range data {
+		switch v.(type) {
+		case string, int, int32, int64, float32, float64, bool:
+			m.Data[k] = fmt.Sprint(v)
+		}
+	}
+	md := module.Get(module.M_PUSH).(*module.PushModule)
+	_, err := md.PushToMember(to.MemberId, m)
+	if err == module.ErrNoPushDevice {
+		return notify.ErrNoAddress
+	}
+	return err
+}
+
+// 注册会话的推送设备令牌
+func (ms *memberService) RegisterPushToken(memberId int64, sessionId string,
+	platform string, token string) error {
+	platform = strings.ToLower(strings.TrimSpace(platform))
+	token = strings.TrimSpace(token)
+	md := module.Get(module.M_PUSH).(*module.PushModule)
+	if token == "" || md.GetProvider(platform) == nil {
+		return push.ErrNoPlatform
+	}
+	mm := module.Get(module.M_MM).(*module.MemberModule)
+	return mm.RegisterPushToken(memberId, sessionId, platform, token)
+}
+
+// 取消会话的推送
+func (ms *memberService) UnregisterPushToken(memberId int64, sessionId string) error {
+	mm := module.Get(module.M_MM).(*module.MemberModule)
+	return mm.UnregisterPushToken(memberId, sessionId)
+}
+
+// 订阅可推送给会员的领域事件
+func (m *mssService) SubscribePushEvents() error {
+	return EventService.Subscribe("member_push", []string{
+		event.OrderSubmitted, event.OrderPaid, event.OrderShipped,
+		event.OrderCompleted, event.OrderCancelled, event.OrderRefunded,
+		event.OrderStateChanged, event.AccountChanged,
+	}, m.handlePushEvent)
+}
+
+// 推送失败不影响事件处理,仅记录在通知发送记录中
+func (m *mssService) handlePushEvent(e *event.Event) error {
+	if e.Type == event.AccountChanged {
+		return m.pushAccountChanged(e)
+	}
+	d := event.OrderData{}
+	if err := e.Unmarshal(&d); err != nil {
+		return err
+	}
+	// 父订单拆分后由子订单推送
+	if d.BuyerId <= 0 || d.State == order.StatBreak {
+		return nil
+	}
+	m.DispatchNotify("订单状态变更", d.BuyerId, map[string]interface{}{
+		"orderNo": d.OrderNo,
+		"state":   order.OrderState(d.State).String(),
+	})
+	return nil
+}
+
+// 推送账户变动,余额及积分未变化时不推送
+func (m *mssService) pushAccountChanged(e *event.Event) error {
+	d := event.MemberData{}
+	if err := e.Unmarshal(&d); err != nil {
+		return err
+	}
+	acc := m._mmRepo.GetAccount(d.MemberId)
+	if acc == nil {
+		return nil
+	}
+	sto := gof.CurrentApp.Storage()
+	key := "go2o:rsi:push:account:" + strconv.FormatInt(d.MemberId, 10)
+	v := fmt.Sprintf("%s|%d", format.FormatFloat(acc.Balance), acc.Integral)
+	if last, _ := sto.GetString(key); last == v {
+		return nil
+	}
+	sto.SetExpire(key, v, 3600*24*30)
+	m.DispatchNotify("账户变动", d.MemberId, map[string]interface{}{
+		"balance":  format.FormatFloat(acc.Balance),
+		"integral": acc.Integral,
+	})
+	return nil
+}
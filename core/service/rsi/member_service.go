@@ -11,12 +11,16 @@ package rsi
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/jsix/gof"
+	"go2o/core/domain/interface/audit"
 	"go2o/core/domain/interface/enum"
 	"go2o/core/domain/interface/member"
 	"go2o/core/domain/interface/mss/notify"
+	"go2o/core/domain/interface/risk"
+	"go2o/core/domain/interface/security"
 	"go2o/core/domain/interface/valueobject"
 	"go2o/core/dto"
 	"go2o/core/infrastructure/domain"
@@ -27,6 +31,7 @@ import (
 	"go2o/core/service/thrift/parser"
 	"go2o/core/variable"
 	"log"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -40,21 +45,29 @@ type memberService struct {
 	_query          *query.MemberQuery
 	_orderQuery     *query.OrderQuery
 	valRepo         valueobject.IValueRepo
+	secRepo         security.ITwoFactorRepo
 }
 
 func NewMemberService(mchService *merchantService, repo member.IMemberRepo,
-	q *query.MemberQuery, oq *query.OrderQuery, valRepo valueobject.IValueRepo) *memberService {
+	q *query.MemberQuery, oq *query.OrderQuery, valRepo valueobject.IValueRepo,
+	secRepo security.ITwoFactorRepo) *memberService {
 	ms := &memberService{
 		_repo:           repo,
 		_query:          q,
 		_partnerService: mchService,
 		_orderQuery:     oq,
 		valRepo:         valRepo,
+		secRepo:         secRepo,
 	}
 	return ms
 	//return m.init()
 }
 
+// 敏感操作时校验会员两步验证的验证码,code为验证码或恢复码;未启用两步验证时不需要验证
+func (ms *memberService) checkTwoFactor(memberId int64, code string) error {
+	return ms.secRepo.CreateTwoFactor(security.UserMember, memberId).CheckCode(code)
+}
+
 func (ms *memberService) init() *memberService {
 	db := gof.CurrentApp.Db()
 	list := []*member.Relation{}
@@ -150,13 +163,41 @@ func (ms *memberService) GetToken(memberId int64, reset bool) (r string, err err
 	return pubToken, nil
 }
 
-// 移除会员的Token
+// 移除会员的Token,并注销会员在所有设备上的会话
 func (ms *memberService) RemoveToken(memberId int64) (err error) {
 	md := module.Get(module.M_MM).(*module.MemberModule)
-	md.RemoveToken(memberId)
+	md.RevokeAllSessions(memberId)
 	return nil
 }
 
+// 创建设备会话,返回访问令牌及刷新令牌;deviceId不为空时替换该设备原有的会话
+func (ms *memberService) CreateSession(memberId int64, deviceId, deviceType,
+	deviceName, ip string) (*module.MemberToken, error) {
+	if ms._repo.GetMember(memberId) == nil {
+		return nil, member.ErrNoSuchMember
+	}
+	md := module.Get(module.M_MM).(*module.MemberModule)
+	return md.CreateSession(memberId, deviceId, deviceType, deviceName, ip)
+}
+
+// 使用刷新令牌签发新的令牌
+func (ms *memberService) RefreshToken(refreshToken string) (*module.MemberToken, error) {
+	md := module.Get(module.M_MM).(*module.MemberModule)
+	return md.RefreshToken(refreshToken)
+}
+
+// 获取会员的设备会话
+func (ms *memberService) GetSessions(memberId int64) []*module.MemberSession {
+	md := module.Get(module.M_MM).(*module.MemberModule)
+	return md.GetSessions(memberId)
+}
+
+// 注销会员的设备会话
+func (ms *memberService) RevokeSession(memberId int64, sessionId string) error {
+	md := module.Get(module.M_MM).(*module.MemberModule)
+	return md.RevokeSession(memberId, sessionId)
+}
+
 // 更改手机号码，不验证手机格式
 func (ms *memberService) ChangePhone(memberId int64, phone string) error {
 	m := ms._repo.GetMember(memberId)
@@ -301,22 +342,52 @@ func (ms *memberService) GetMemberIdByBasis(str string, basic int) int64 {
 	return -1
 }
 
-// 发送验证码
-func (ms *memberService) SendCode(memberId int64, operation string, msgType int) (string, error) {
+// 会员验证码的操作
+const checkCodeOp = "check"
+
+// 发送短信前校验图形验证码,并检查发送频率
+func (ms *memberService) checkSmsSend(phone string, ip string,
+	captchaId string, captchaCode string) error {
+	md := module.Get(module.M_CAPTCHA).(*module.CaptchaModule)
+	return md.TakeSms(phone, ip, captchaId, captchaCode)
+}
+
+// 发送验证码,发送短信时需传入图形验证码及客户端IP
+func (ms *memberService) SendCode(memberId int64, operation string, msgType int,
+	ip string, captchaId string, captchaCode string) (string, error) {
 	m := ms._repo.GetMember(memberId)
 	if m == nil {
 		return "", member.ErrNoSuchMember
 	}
-	return m.SendCheckCode(operation, msgType)
+	if msgType == notify.TypePhoneMessage {
+		phone := m.Profile().GetProfile().Phone
+		if err := ms.checkSmsSend(phone, ip, captchaId, captchaCode); err != nil {
+			return "", err
+		}
+	}
+	// 验证码保存在验证码模块中,以限制校验失败次数
+	code := domain.NewCheckCode()
+	cm := module.Get(module.M_CAPTCHA).(*module.CaptchaModule)
+	err := cm.SetOpCode(checkCodeOp, strconv.Itoa(int(memberId)), code,
+		member.CheckCodeMinutes*60)
+	if err == nil {
+		err = m.NotifyCheckCode(operation, code, msgType)
+	}
+	return code, err
 }
 
-// 对比验证码
+// 对比验证码,校验成功后验证码失效;连续失败次数过多时,验证码失效并锁定一段时间
 func (ms *memberService) CompareCode(memberId int64, code string) error {
 	m := ms._repo.GetMember(memberId)
 	if m == nil {
 		return member.ErrNoSuchMember
 	}
-	return m.CompareCode(code)
+	cm := module.Get(module.M_CAPTCHA).(*module.CaptchaModule)
+	err := cm.VerifyOpCode(checkCodeOp, strconv.Itoa(int(memberId)), code)
+	if err == module.ErrOpCode {
+		return member.ErrCheckCodeError
+	}
+	return err
 }
 
 // 更改会员用户名
@@ -367,9 +438,10 @@ func (ms *memberService) updateMember(v *define.Member) (int64, error) {
 	return m.Save()
 }
 
-// 注册会员
+// 注册会员,client为接口层获取的客户端信息,可为空
 func (ms *memberService) RegisterMember(mchId int32, v1 *define.Member,
-	pro1 *define.Profile, cardId string, invitationCode string) (int64, error) {
+	pro1 *define.Profile, cardId string, invitationCode string,
+	client *risk.Client) (int64, error) {
 	if v1 == nil || pro1 == nil {
 		return 0, errors.New("missing data")
 	}
@@ -378,6 +450,19 @@ func (ms *memberService) RegisterMember(mchId int32, v1 *define.Member,
 	invitationId, err := ms._repo.GetManager().PrepareRegister(
 		v, pro, invitationCode)
 	if err == nil {
+		// 风控评估,需人工审核时锁定会员
+		c := &risk.Context{
+			Operation: risk.OpRegister,
+			Phone:     pro.Phone,
+			InviterId: invitationId,
+		}
+		if client != nil {
+			c.Client = *client
+		}
+		rc, riskErr := RiskService.Evaluate(c)
+		if riskErr == risk.ErrRiskDenied {
+			return -1, riskErr
+		}
 		m := ms._repo.CreateMember(v) //创建会员
 		id, err := m.Save()
 		if err == nil {
@@ -395,6 +480,12 @@ func (ms *memberService) RegisterMember(mchId int32, v1 *define.Member,
 				err = m.SaveRelation(rl)
 			}
 		}
+		if err == nil && riskErr == risk.ErrRiskReview {
+			RiskService.BindRecord(rc.Id, strconv.Itoa(int(id)))
+			if err = m.Lock(); err == nil {
+				err = riskErr
+			}
+		}
 		return id, err
 	}
 	return -1, err
@@ -413,18 +504,29 @@ func (ms *memberService) GetRelation(memberId int64) *member.Relation {
 	return ms._repo.GetRelation(memberId)
 }
 
-// 锁定/解锁会员
-func (ms *memberService) LockMember(memberId int64) (bool, error) {
+// 锁定/解锁会员,actor为操作人
+func (ms *memberService) LockMember(memberId int64, actor *audit.Actor) (bool, error) {
 	m := ms._repo.GetMember(memberId)
 	if m == nil {
 		return false, member.ErrNoSuchMember
 	}
 
 	state := m.GetValue().State
+	before := map[string]int32{"state": state}
 	if state == 1 {
-		return false, m.Lock()
+		err := m.Lock()
+		if err == nil {
+			auditLog(actor, "member.lock", audit.TargetMember, memberId,
+				before, map[string]int32{"state": m.GetValue().State}, "")
+		}
+		return false, err
 	}
-	return true, m.Unlock()
+	err := m.Unlock()
+	if err == nil {
+		auditLog(actor, "member.unlock", audit.TargetMember, memberId,
+			before, map[string]int32{"state": m.GetValue().State}, "")
+	}
+	return true, err
 }
 
 // 判断资料是否完善
@@ -475,13 +577,16 @@ func (ms *memberService) ModifyPassword(memberId int64, newPwd, oldPwd string) e
 	return m.Profile().ModifyPassword(newPwd, oldPwd)
 }
 
-//修改密码,传入密文密码
+//修改密码,传入密文密码,tfCode为两步验证的验证码
 func (ms *memberService) ModifyTradePassword(memberId int64,
-	oldPwd, newPwd string) error {
+	oldPwd, newPwd string, tfCode string) error {
 	m := ms._repo.GetMember(memberId)
 	if m == nil {
 		return member.ErrNoSuchMember
 	}
+	if err := ms.checkTwoFactor(memberId, tfCode); err != nil {
+		return err
+	}
 	return m.Profile().ModifyTradePassword(newPwd, oldPwd)
 }
 
@@ -497,11 +602,9 @@ func (ms *memberService) testLogin(usr string, pwd string) (id int64, err error)
 	if val == nil {
 		return 0, member.ErrNoSuchMember
 	}
-	if val.Pwd != pwd {
-		//todo: 兼容旧密码
-		if val.Pwd != domain.Sha1(pwd) {
-			return 0, member.ErrCredential
-		}
+	// 验证密码,旧版本的密码将重新散列保存
+	if err := ms._repo.CreateMember(val).Profile().CheckPassword(pwd); err != nil {
+		return 0, err
 	}
 	if val.State == member.StateStopped {
 		return 0, member.ErrMemberDisabled
@@ -526,12 +629,8 @@ func (ms *memberService) CheckTradePwd(id int64, tradePwd string) (r *define.Res
 	if m == nil {
 		return parser.Result(0, member.ErrNoSuchMember), nil
 	}
-	mv := m.GetValue()
-	if mv.TradePwd == "" {
-		return parser.Result(0, member.ErrNotSetTradePwd), nil
-	}
-	if mv.TradePwd != tradePwd {
-		return parser.Result(0, member.ErrIncorrectTradePwd), nil
+	if err := m.Profile().CheckTradePassword(tradePwd); err != nil {
+		return parser.Result(0, err), nil
 	}
 	return &define.Result_{Result_: true}, nil
 }
@@ -629,7 +728,11 @@ func (ms *memberService) GetBank(memberId int64) *member.BankInfo {
 	return &b
 }
 
-func (ms *memberService) SaveBankInfo(v *member.BankInfo) error {
+// 保存银行卡信息,tfCode为两步验证的验证码
+func (ms *memberService) SaveBankInfo(v *member.BankInfo, tfCode string) error {
+	if err := ms.checkTwoFactor(v.MemberId, tfCode); err != nil {
+		return err
+	}
 	m := ms._repo.CreateMember(&member.Member{Id: v.MemberId})
 	return m.Profile().SaveBank(v)
 }
@@ -650,19 +753,32 @@ func (ms *memberService) GetTrustInfo(memberId int64) (*define.TrustedInfo, erro
 	return parser.TrustedInfoDto(&t), nil
 }
 
-// 保存实名认证信息
+// 保存实名认证信息,保存后自动认证
 func (ms *memberService) SaveTrustedInfo(memberId int64, v *member.TrustedInfo) error {
 	m := ms._repo.GetMember(memberId)
 	if m == nil {
 		return member.ErrNoSuchMember
 	}
-	return m.Profile().SaveTrustedInfo(v)
+	if err := m.Profile().SaveTrustedInfo(v); err != nil {
+		return err
+	}
+	return ms.verifyTrustedInfo(m)
 }
 
 // 审核实名认证,若重复审核将返回错误
-func (ms *memberService) ReviewTrustedInfo(memberId int64, pass bool, remark string) error {
+func (ms *memberService) ReviewTrustedInfo(memberId int64, pass bool,
+	remark string, actor *audit.Actor) error {
 	m := ms._repo.GetMember(memberId)
-	return m.Profile().ReviewTrustedInfo(pass, remark)
+	if m == nil {
+		return member.ErrNoSuchMember
+	}
+	before := m.Profile().GetTrustedInfo()
+	err := m.Profile().ReviewTrustedInfo(pass, remark)
+	if err == nil {
+		auditLog(actor, "member.review_trusted", audit.TargetMember, memberId,
+			before, m.Profile().GetTrustedInfo(), remark)
+	}
+	return err
 }
 
 // 获取返现记录
@@ -853,11 +969,17 @@ func (ms *memberService) ChargeAccount(memberId int64, account int32,
 	if acc == nil {
 		err = member.ErrNoSuchMember
 	} else {
+		before := *acc.GetValue()
 		if account == member.AccountIntegral {
 			err = acc.AddIntegral(int(kind), outerNo, int64(amount), title)
 		} else {
 			err = acc.Charge(account, kind, title, outerNo, float32(amount), relateUser)
 		}
+		if err == nil {
+			auditLog(relateActor(relateUser), "member_account.charge",
+				audit.TargetMemberAccount, memberId, before, acc.GetValue(),
+				fmt.Sprintf("%s(%s)", title, outerNo))
+		}
 	}
 	return parser.Result(0, err), nil
 }
@@ -888,6 +1010,7 @@ func (ms *memberService) DiscountAccount(memberId int64, account int32, title st
 	m, err := ms.getMember(memberId)
 	if err == nil {
 		acc := m.GetAccount()
+		before := *acc.GetValue()
 		switch int(account) {
 		case member.AccountBalance:
 			err = acc.DiscountBalance(title, outerNo, float32(amount),
@@ -896,6 +1019,11 @@ func (ms *memberService) DiscountAccount(memberId int64, account int32, title st
 			err = acc.DiscountWallet(title, outerNo, float32(amount),
 				member.DefaultRelateUser, mustLargeZero)
 		}
+		if err == nil {
+			auditLog(relateActor(relateUser), "member_account.discount",
+				audit.TargetMemberAccount, memberId, before, acc.GetValue(),
+				fmt.Sprintf("%s(%s)", title, outerNo))
+		}
 	}
 	return parser.I64Result(memberId, err), nil
 }
@@ -904,27 +1032,48 @@ func (ms *memberService) DiscountAccount(memberId int64, account int32, title st
 func (ms *memberService) VerifyTradePwd(memberId int64, tradePwd string) (bool, error) {
 	im, err := ms.getMember(memberId)
 	if err == nil {
-		m := im.GetValue()
-		if len(m.TradePwd) == 0 {
-			return false, member.ErrNotSetTradePwd
-		}
-		if m.TradePwd != tradePwd {
-			return false, member.ErrIncorrectTradePwd
+		if err = im.Profile().CheckTradePassword(tradePwd); err != nil {
+			return false, err
 		}
 		return true, err
 	}
 	return false, err
 }
 
-// 提现并返回提现编号,交易号以及错误信息
+// 提现并返回提现编号,交易号以及错误信息,tfCode为两步验证的验证码,
+// client为客户端信息,可为空;风控评估需人工审核时,提现将等待审核
 func (ms *memberService) SubmitTakeOutRequest(memberId int64, takeKind int32,
-	applyAmount float32, commission float32) (int32, string, error) {
+	applyAmount float32, commission float32, tfCode string,
+	client *risk.Client) (int32, string, error) {
 	m, err := ms.getMember(memberId)
 	if err != nil {
 		return 0, "", err
 	}
-
+	if err = ms.checkTwoFactor(memberId, tfCode); err != nil {
+		return 0, "", err
+	}
+	// 先检查提现条件,不满足条件的请求不计入风控频次
 	acc := m.GetAccount()
+	if err = acc.CheckTakeOut(takeKind, applyAmount); err != nil {
+		return 0, "", err
+	}
+	c := &risk.Context{
+		Operation: risk.OpTakeOut,
+		MemberId:  memberId,
+		Amount:    applyAmount,
+		Phone:     m.Profile().GetProfile().Phone,
+	}
+	if client != nil {
+		c.Client = *client
+	}
+	if takeKind == member.KindWalletTakeOutToBankCard {
+		c.BankCard = m.Profile().GetBank().Account
+	}
+	rc, riskErr := RiskService.Evaluate(c)
+	if riskErr == risk.ErrRiskDenied {
+		return 0, "", riskErr
+	}
+
 	var title string
 	switch takeKind {
 	case member.KindWalletTakeOutToBankCard:
@@ -934,7 +1083,12 @@ func (ms *memberService) SubmitTakeOutRequest(memberId int64, takeKind int32,
 	case member.KindWalletTakeOutToThirdPart:
 		title = "充值到第三方账户"
 	}
-	return acc.RequestTakeOut(takeKind, title, applyAmount, commission)
+	id, tradeNo, err := acc.RequestTakeOut(takeKind, title, applyAmount,
+		commission, riskErr == risk.ErrRiskReview)
+	if err == nil {
+		RiskService.BindRecord(rc.Id, tradeNo)
+	}
+	return id, tradeNo, err
 }
 
 // 获取最近的提现
@@ -972,10 +1126,19 @@ func (ms *memberService) GetLatestApplyCashText(memberId int64) string {
 
 // 确认提现
 func (a *memberService) ConfirmTakeOutRequest(memberId int64,
-	infoId int32, pass bool, remark string) error {
+	infoId int32, pass bool, remark string, actor *audit.Actor) error {
 	m, err := a.getMember(memberId)
 	if err == nil {
-		err = m.GetAccount().ConfirmTakeOut(infoId, pass, remark)
+		acc := m.GetAccount()
+		var before member.BalanceInfo
+		if v := acc.GetBalanceInfo(infoId); v != nil {
+			before = *v
+		}
+		if err = acc.ConfirmTakeOut(infoId, pass, remark); err == nil {
+			auditLog(actor, "member_account.confirm_take_out",
+				audit.TargetMemberAccount, memberId, before,
+				acc.GetBalanceInfo(infoId), remark)
+		}
 	}
 	return err
 }
@@ -1039,13 +1202,109 @@ func (ms *memberService) FreezeExpired(memberId int64, accountKind int, amount f
 	return m.GetAccount().FreezeExpired(accountKind, amount, remark)
 }
 
-// 转账余额到其他账户
+const (
+	// 转账到其他会员
+	transferAccount = "account"
+	// 将活动金转给其他会员
+	transferFlow = "flow"
+)
+
+// 转账请求,需人工审核时作为审核通过后执行的参数
+type transferRequest struct {
+	Kind        string  `json:"kind"`
+	MemberId    int64   `json:"memberId"`
+	ToMemberId  int64   `json:"toMemberId"`
+	AccountKind int     `json:"accountKind,omitempty"`
+	FlowKind    int32   `json:"flowKind,omitempty"`
+	Amount      float32 `json:"amount"`
+	Commission  float32 `json:"commission"`
+	TradeNo     string  `json:"tradeNo,omitempty"`
+	ToTitle     string  `json:"toTitle,omitempty"`
+	FromTitle   string  `json:"fromTitle,omitempty"`
+	Remark      string  `json:"remark,omitempty"`
+}
+
+// 检查转账参数,不正确的请求不计入风控频次
+func (ms *memberService) checkTransfer(r *transferRequest) error {
+	if r.Amount <= 0 || math.IsNaN(float64(r.Amount)) {
+		return member.ErrIncorrectAmount
+	}
+	if r.ToMemberId == r.MemberId || ms._repo.GetMember(r.ToMemberId) == nil {
+		return member.ErrNoSuchMember
+	}
+	return nil
+}
+
+// 转账前进行风控评估,需人工审核时加入审核队列并返回risk.ErrReviewSubmitted
+func (ms *memberService) evaluateTransfer(m member.IMember, r *transferRequest,
+	client *risk.Client) error {
+	c := &risk.Context{
+		Operation: risk.OpTransfer,
+		MemberId:  m.GetAggregateRootId(),
+		Amount:    r.Amount,
+		Phone:     m.Profile().GetProfile().Phone,
+	}
+	if client != nil {
+		c.Client = *client
+	}
+	rc, err := RiskService.Evaluate(c)
+	if err == risk.ErrRiskReview {
+		data, _ := json.Marshal(r)
+		if _, err = RiskService.SubmitReview(rc, string(data)); err == nil {
+			err = risk.ErrReviewSubmitted
+		}
+	}
+	return err
+}
+
+// 执行审核通过的转账
+func (ms *memberService) executeTransfer(data string) error {
+	r := transferRequest{}
+	if err := json.Unmarshal([]byte(data), &r); err != nil {
+		return err
+	}
+	m := ms._repo.GetMember(r.MemberId)
+	if m == nil {
+		return member.ErrNoSuchMember
+	}
+	switch r.Kind {
+	case transferAccount:
+		return m.GetAccount().TransferAccount(r.AccountKind, r.ToMemberId,
+			r.Amount, r.Commission, r.Remark)
+	case transferFlow:
+		return m.GetAccount().TransferFlowTo(r.ToMemberId, r.FlowKind, r.Amount,
+			r.Commission, r.TradeNo, r.ToTitle, r.FromTitle)
+	}
+	return errors.New("unknown transfer kind: " + r.Kind)
+}
+
+// 转账余额到其他账户,tfCode为两步验证的验证码,client为客户端信息,可为空;
+// 需人工审核时返回risk.ErrReviewSubmitted,审核通过后完成转账
 func (ms *memberService) TransferAccount(accountKind int, fromMember int64,
-	toMember int64, amount float32, csnRate float32, remark string) error {
+	toMember int64, amount float32, csnRate float32, remark string,
+	tfCode string, client *risk.Client) error {
 	m := ms._repo.GetMember(fromMember)
 	if m == nil {
 		return member.ErrNoSuchMember
 	}
+	if err := ms.checkTwoFactor(fromMember, tfCode); err != nil {
+		return err
+	}
+	r := &transferRequest{
+		Kind:        transferAccount,
+		MemberId:    fromMember,
+		ToMemberId:  toMember,
+		AccountKind: accountKind,
+		Amount:      amount,
+		Commission:  csnRate,
+		Remark:      remark,
+	}
+	if err := ms.checkTransfer(r); err != nil {
+		return err
+	}
+	if err := ms.evaluateTransfer(m, r, client); err != nil {
+		return err
+	}
 	return m.GetAccount().TransferAccount(accountKind, toMember,
 		amount, csnRate, remark)
 }
@@ -1084,14 +1343,32 @@ func (ms *memberService) TransferFlow(memberId int64, kind int32, amount float32
 		toTitle, fromTitle)
 }
 
-// 将活动金转给其他人
+// 将活动金转给其他人,client为客户端信息,可为空;
+// 需人工审核时返回risk.ErrReviewSubmitted,审核通过后完成转账
 func (ms *memberService) TransferFlowTo(memberId int64, toMemberId int64, kind int32,
 	amount float32, commission float32, tradeNo string, toTitle string,
-	fromTitle string) error {
+	fromTitle string, client *risk.Client) error {
 	m := ms._repo.GetMember(memberId)
 	if m == nil {
 		return member.ErrNoSuchMember
 	}
+	r := &transferRequest{
+		Kind:       transferFlow,
+		MemberId:   memberId,
+		ToMemberId: toMemberId,
+		FlowKind:   kind,
+		Amount:     amount,
+		Commission: commission,
+		TradeNo:    tradeNo,
+		ToTitle:    toTitle,
+		FromTitle:  fromTitle,
+	}
+	if err := ms.checkTransfer(r); err != nil {
+		return err
+	}
+	if err := ms.evaluateTransfer(m, r, client); err != nil {
+		return err
+	}
 	return m.GetAccount().TransferFlowTo(toMemberId, kind, amount,
 		commission, tradeNo, toTitle, fromTitle)
 }
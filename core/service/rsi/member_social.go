@@ -0,0 +1,209 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : member_social
+ * author : jarryliu
+ * date : 2026-10-20 00:40
+ * description : 第三方账号登录及绑定
+ * history :
+ */
+package rsi
+
+import (
+	"go2o/core/domain/interface/member"
+	"go2o/core/domain/interface/mss/notify"
+	"go2o/core/infrastructure/apisign"
+	"go2o/core/infrastructure/domain"
+	"go2o/core/infrastructure/social"
+	"go2o/core/module"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 合并账号验证码的操作名称
+const socialMergeOp = "merge"
+
+// 合并账号验证码按目标会员保存
+func socialMergeTarget(target member.IMember) string {
+	return strconv.Itoa(int(target.GetAggregateRootId()))
+}
+
+// 验证码与发起合并的会员绑定
+func socialMergeCode(memberId int64, code string) string {
+	return strconv.Itoa(int(memberId)) + ":" + strings.TrimSpace(code)
+}
+
+// 查找第三方账号绑定的会员,优先使用OpenID,其次使用UnionID
+func (ms *memberService) findSocialIdentity(id *social.Identity) *member.SocialIdentity {
+	v := ms._repo.GetSocialIdentity(id.Provider, id.OpenId)
+	if v == nil && id.UnionId != "" {
+		v = ms._repo.GetSocialIdentityByUnionId(id.Provider, id.UnionId)
+	}
+	return v
+}
+
+func socialIdentity(id *social.Identity) *member.SocialIdentity {
+	return &member.SocialIdentity{
+		Provider: id.Provider,
+		OpenId:   id.OpenId,
+		UnionId:  id.UnionId,
+		Nickname: id.Nickname,
+		Avatar:   id.Avatar,
+	}
+}
+
+// 通过第三方账号注册会员,用户名及密码随机生成
+func (ms *memberService) registerSocialMember(id *social.Identity, regIp string) (member.IMember, error) {
+	perm := ms.valRepo.GetRegisterPerm()
+	if perm.RegisterMode == member.RegisterModeClosed {
+		return nil, member.ErrRegOff
+	}
+	var usr string
+	for {
+		usr = id.Provider + "_" + apisign.NewNonce()[:10]
+		if !ms._repo.CheckUsrExist(usr, 0) {
+			break
+		}
+	}
+	v := &member.Member{
+		Usr:     usr,
+		Pwd:     domain.MemberSha1Pwd(apisign.NewNonce()),
+		RegFrom: member.SocialRegFromPrefix + id.Provider,
+		RegIp:   regIp,
+	}
+	m := ms._repo.CreateMember(v)
+	memberId, err := m.Save()
+	if err != nil {
+		return nil, err
+	}
+	pro := &member.Profile{
+		MemberId: memberId,
+		Name:     id.Nickname,
+		Avatar:   id.Avatar,
+		Sex:      1,
+	}
+	if pro.Name == "" {
+		pro.Name = usr
+	}
+	if pro.Avatar == "" {
+		pro.Avatar = "res/no_avatar.gif"
+	}
+	if err = m.Profile().SaveProfile(pro); err != nil {
+		ms._repo.DeleteMember(memberId)
+		return nil, err
+	}
+	return m, nil
+}
+
+// 使用第三方账号登录,账号未绑定会员时自动注册,返回会员编号
+func (ms *memberService) SocialLogin(provider, code, regIp string) (int64, error) {
+	id, err := social.Exchange(provider, code)
+	if err != nil {
+		return 0, err
+	}
+	var m member.IMember
+	si := ms.findSocialIdentity(id)
+	if si != nil {
+		if m = ms._repo.GetMember(si.MemberId); m == nil {
+			return 0, member.ErrNoSuchMember
+		}
+		if m.GetValue().State == member.StateStopped {
+			return 0, member.ErrMemberDisabled
+		}
+	} else {
+		if m, err = ms.registerSocialMember(id, regIp); err != nil {
+			return 0, err
+		}
+		if err = m.Social().Bind(socialIdentity(id)); err != nil {
+			return 0, err
+		}
+		si = ms._repo.GetSocialIdentity(id.Provider, id.OpenId)
+	}
+	if si != nil {
+		si.LoginTime = time.Now().Unix()
+		ms._repo.SaveSocialIdentity(si)
+	}
+	return m.GetAggregateRootId(), m.UpdateLoginTime()
+}
+
+// 获取会员绑定的第三方账号
+func (ms *memberService) GetSocialIdentities(memberId int64) []*member.SocialIdentity {
+	return ms._repo.GetSocialIdentities(memberId)
+}
+
+// 绑定第三方账号
+func (ms *memberService) BindSocial(memberId int64, provider, code string) error {
+	m := ms._repo.GetMember(memberId)
+	if m == nil {
+		return member.ErrNoSuchMember
+	}
+	id, err := social.Exchange(provider, code)
+	if err != nil {
+		return err
+	}
+	// 通过UnionID绑定其他会员的账号,同样视为已绑定
+	if si := ms.findSocialIdentity(id); si != nil && si.MemberId != memberId {
+		return member.ErrSocialIdentityBound
+	}
+	return m.Social().Bind(socialIdentity(id))
+}
+
+// 解绑第三方账号
+func (ms *memberService) UnbindSocial(memberId int64, provider string) error {
+	m := ms._repo.GetMember(memberId)
+	if m == nil {
+		return member.ErrNoSuchMember
+	}
+	return m.Social().Unbind(provider)
+}
+
+// 发送合并账号的验证码到已注册会员的手机,需传入图形验证码;
+// 手机号未注册时同样返回成功,避免被用于探测手机号
+func (ms *memberService) SendSocialMergeCode(memberId int64, phone string,
+	ip string, captchaId string, captchaCode string) error {
+	if ms._repo.GetMember(memberId) == nil {
+		return member.ErrNoSuchMember
+	}
+	if err := ms.checkSmsSend(phone, ip, captchaId, captchaCode); err != nil {
+		return err
+	}
+	target := ms._repo.GetMember(ms._repo.GetMemberIdByPhone(phone))
+	if target == nil || target.GetAggregateRootId() == memberId {
+		return nil
+	}
+	code := domain.NewCheckCode()
+	cm := module.Get(module.M_CAPTCHA).(*module.CaptchaModule)
+	err := cm.SetOpCode(socialMergeOp, socialMergeTarget(target),
+		socialMergeCode(memberId, code), member.CheckCodeMinutes*60)
+	if err == nil {
+		err = target.NotifyCheckCode("合并账号", code, notify.TypePhoneMessage)
+	}
+	return err
+}
+
+// 将通过第三方账号注册的会员合并到手机号对应的会员,
+// 合并后原会员的会话将被注销,返回目标会员编号;
+// 验证码仅能使用一次,同一目标会员连续验证失败后将被锁定
+func (ms *memberService) MergeSocialMember(memberId int64, phone string,
+	checkCode string) (int64, error) {
+	m := ms._repo.GetMember(memberId)
+	if m == nil {
+		return 0, member.ErrNoSuchMember
+	}
+	target := ms._repo.GetMember(ms._repo.GetMemberIdByPhone(phone))
+	if target == nil {
+		return 0, member.ErrCheckCodeError
+	}
+	cm := module.Get(module.M_CAPTCHA).(*module.CaptchaModule)
+	err := cm.VerifyOpCode(socialMergeOp, socialMergeTarget(target),
+		socialMergeCode(memberId, checkCode))
+	if err == nil {
+		err = m.Social().MergeInto(target)
+	}
+	if err != nil {
+		return 0, err
+	}
+	md := module.Get(module.M_MM).(*module.MemberModule)
+	md.RevokeAllSessions(memberId)
+	return target.GetAggregateRootId(), nil
+}
@@ -10,12 +10,15 @@
 package rsi
 
 import (
+	"go2o/core/domain/interface/audit"
 	"go2o/core/domain/interface/member"
 	"go2o/core/domain/interface/merchant"
 	"go2o/core/domain/interface/merchant/shop"
+	"go2o/core/domain/interface/merchant/user"
 	"go2o/core/domain/interface/merchant/wholesaler"
 	"go2o/core/dto"
 	"go2o/core/infrastructure/domain"
+	"go2o/core/module"
 	"go2o/core/query"
 	"go2o/core/service/thrift/idl/gen-go/define"
 	"go2o/core/service/thrift/parser"
@@ -60,9 +63,20 @@ func (m *merchantService) GetSignUp(id int32) *merchant.MchSignUp {
 	return m._mchRepo.GetManager().GetSignUpInfo(id)
 }
 
-// 审核商户申请信息
-func (m *merchantService) ReviewSignUp(id int32, pass bool, remark string) error {
-	return m._mchRepo.GetManager().ReviewMchSignUp(id, pass, remark)
+// 审核商户申请信息,actor为操作人
+func (m *merchantService) ReviewSignUp(id int32, pass bool, remark string,
+	actor *audit.Actor) error {
+	mgr := m._mchRepo.GetManager()
+	var before merchant.MchSignUp
+	if v := mgr.GetSignUpInfo(id); v != nil {
+		before = *v
+	}
+	err := mgr.ReviewMchSignUp(id, pass, remark)
+	if err == nil {
+		auditLog(actor, "mch_signup.review", audit.TargetMchSignUp, id,
+			before, mgr.GetSignUpInfo(id), remark)
+	}
+	return err
 }
 
 // 商户注册
@@ -141,11 +155,9 @@ func (ms *merchantService) testMemberLogin(usr string, pwd string) (id int64, er
 	if val == nil {
 		return 0, member.ErrNoSuchMember
 	}
-	if val.Pwd != pwd {
-		//todo: 兼容旧密码
-		if val.Pwd != domain.Sha1(pwd) {
-			return 0, member.ErrCredential
-		}
+	// 验证密码,旧版本的密码将重新散列保存
+	if err := ms._memberRepo.CreateMember(val).Profile().CheckPassword(pwd); err != nil {
+		return 0, err
 	}
 	if val.State == member.StateStopped {
 		return 0, member.ErrMemberDisabled
@@ -153,6 +165,22 @@ func (ms *merchantService) testMemberLogin(usr string, pwd string) (id int64, er
 	return val.Id, nil
 }
 
+// 商户员工登录,返回人员编号;启用两步验证时需传入验证码,
+// 角色要求两步验证但未绑定时返回security.ErrTwoFactorNotEnrolled
+func (m *merchantService) StaffLogin(mchId int32, usr, oriPwd, code string) (int32, error) {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return 0, merchant.ErrNoSuchMerchant
+	}
+	usr = strings.ToLower(strings.TrimSpace(usr))
+	encPwd := domain.MerchantSha1Pwd(usr, strings.TrimSpace(oriPwd))
+	u, err := mch.UserManager().Login(usr, encPwd, code)
+	if u != nil {
+		return u.GetPerson().GetDomainId(), err
+	}
+	return 0, err
+}
+
 // 验证用户密码,并返回编号。可传入商户或会员的账号密码
 func (m *merchantService) CheckLogin(usr, oriPwd string) (r *define.Result_, err error) {
 	usr = strings.ToLower(strings.TrimSpace(usr))
@@ -163,7 +191,12 @@ func (m *merchantService) CheckLogin(usr, oriPwd string) (r *define.Result_, err
 	}
 	//尝试作为独立的商户账号登陆
 	encPwd := domain.MerchantSha1Pwd(usr, oriPwd)
-	mchId = m._query.Verify(usr, encPwd)
+	if id := m._query.GetMerchantIdByUsr(usr); id > 0 {
+		mch := m._mchRepo.GetMerchant(id)
+		if mch != nil && mch.ProfileManager().CheckPassword(encPwd) == nil {
+			mchId = id
+		}
+	}
 	if mchId <= 0 {
 		// 使用会员身份登录
 		var id int64
@@ -227,8 +260,12 @@ func (m *merchantService) GetMerchant(mchId int32) *merchant.Merchant {
 	return nil
 }
 
-func (m *merchantService) GetAccount(mchId int32) *merchant.Account {
-	return m._mchRepo.GetAccount(mchId)
+// 获取商户账户,personId为操作的员工,为0表示商户本身操作
+func (m *merchantService) GetAccount(mchId, personId int32) (*merchant.Account, error) {
+	if err := m.CheckStaffPermission(mchId, personId, 0, user.PermFinanceView); err != nil {
+		return nil, err
+	}
+	return m._mchRepo.GetAccount(mchId), nil
 }
 
 func (m *merchantService) SaveMerchant(mchId int32, v *merchant.Merchant) (int32, error) {
@@ -281,13 +318,19 @@ func (m *merchantService) Stat(mchId int32) (r *define.Result_, err error) {
 	return parser.Result(mchId, err), nil
 }
 
-// 设置商户启用或停用
-func (m *merchantService) SetEnabled(mchId int32, enabled bool) error {
+// 设置商户启用或停用,actor为操作人
+func (m *merchantService) SetEnabled(mchId int32, enabled bool, actor *audit.Actor) error {
 	mch := m._mchRepo.GetMerchant(mchId)
 	if mch == nil {
 		return merchant.ErrNoSuchMerchant
 	}
-	return mch.SetEnabled(enabled)
+	before := map[string]int32{"enabled": mch.GetValue().Enabled}
+	err := mch.SetEnabled(enabled)
+	if err == nil {
+		auditLog(actor, "merchant.set_enabled", audit.TargetMerchant, mchId,
+			before, map[string]int32{"enabled": mch.GetValue().Enabled}, "")
+	}
+	return err
 }
 
 // 根据主机查询商户编号
@@ -345,8 +388,11 @@ func (m *merchantService) GetMerchantsId() []int32 {
 	return m._mchRepo.GetMerchantsId()
 }
 
-// 保存API信息
-func (m *merchantService) SaveApiInfo(mchId int32, d *merchant.ApiInfo) error {
+// 保存API信息,personId为操作的员工,为0表示商户本身操作
+func (m *merchantService) SaveApiInfo(mchId, personId int32, d *merchant.ApiInfo) error {
+	if err := m.CheckStaffPermission(mchId, personId, 0, user.PermApiKey); err != nil {
+		return err
+	}
 	mch := m._mchRepo.GetMerchant(mchId)
 	if mch != nil {
 		return mch.ApiManager().SaveApiInfo(d)
@@ -370,6 +416,78 @@ func (m *merchantService) ApiPerm(mchId int32, enabled bool) error {
 	return mch.ApiManager().DisableApiPerm()
 }
 
+// 轮换接口密钥,旧密钥在overlap秒内仍然可用;
+// personId为操作的员工,为0表示商户本身操作
+func (m *merchantService) RotateApiSecret(mchId, personId int32, overlap int64) (string, error) {
+	if err := m.CheckStaffPermission(mchId, personId, 0, user.PermApiKey); err != nil {
+		return "", err
+	}
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return "", merchant.ErrNoSuchMerchant
+	}
+	return mch.ApiManager().RotateSecret(overlap)
+}
+
+// 获取接口分组的限流配置
+func (m *merchantService) GetApiLimit(mchId int32, group string) *merchant.ApiLimit {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return nil
+	}
+	v := mch.ApiManager().GetApiLimit(group)
+	return &v
+}
+
+// 获取所有接口分组的限流配置
+func (m *merchantService) GetApiLimits(mchId int32) []merchant.ApiLimit {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return []merchant.ApiLimit{}
+	}
+	return mch.ApiManager().GetApiLimits()
+}
+
+// 保存接口分组的限流配置
+func (m *merchantService) SaveApiLimit(mchId int32, v *merchant.ApiLimit) error {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return merchant.ErrNoSuchMerchant
+	}
+	return mch.ApiManager().SaveApiLimit(v)
+}
+
+// 获取一个接口令牌并计入当日用量,l为接口分组的限流配置
+func (m *merchantService) TakeApiToken(l *merchant.ApiLimit) (*module.ApiLimitResult, error) {
+	md := module.Get(module.M_API).(*module.ApiLimitModule)
+	return md.Take(l)
+}
+
+// 获取接口最近days天的用量
+func (m *merchantService) GetApiUsage(mchId int32, days int) []*merchant.ApiUsage {
+	list := []*merchant.ApiUsage{}
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return list
+	}
+	md := module.Get(module.M_API).(*module.ApiLimitModule)
+	limits := mch.ApiManager().GetApiLimits()
+	now := time.Now()
+	for i := 0; i < days; i++ {
+		date := now.AddDate(0, 0, -i).Format("20060102")
+		usage := md.GetUsage(mchId, date)
+		for _, l := range limits {
+			list = append(list, &merchant.ApiUsage{
+				Group:      l.Group,
+				Date:       date,
+				Count:      usage[l.Group],
+				DailyQuota: l.DailyQuota,
+			})
+		}
+	}
+	return list
+}
+
 // 根据API ID获取MerchantId
 func (m *merchantService) GetMerchantIdByApiId(apiId string) int32 {
 	return m._mchRepo.GetMerchantIdByApiId(apiId)
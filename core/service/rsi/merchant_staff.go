@@ -0,0 +1,128 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : merchant_staff
+ * author : jarryliu
+ * date : 2026-10-20 09:40
+ * description : 商户员工角色及权限
+ * history :
+ */
+package rsi
+
+import (
+	"go2o/core/domain/interface/audit"
+	"go2o/core/domain/interface/merchant"
+	"go2o/core/domain/interface/merchant/user"
+)
+
+// 记录员工越权操作
+func logPermissionDenied(mchId, personId, shopId int32, perm string) {
+	actor := &audit.Actor{Type: audit.ActorStaff, Id: int64(personId)}
+	auditLog(actor, "staff.permission_denied", audit.TargetMerchant, mchId,
+		nil, map[string]interface{}{"shop_id": shopId, "permission": perm}, "")
+}
+
+// 检查员工权限,personId为0表示商户本身操作,拥有所有权限;
+// shopId为0表示商户级别的操作。无权限的操作将被记录
+func (m *merchantService) CheckStaffPermission(mchId, personId, shopId int32,
+	perm string) error {
+	if personId <= 0 {
+		return nil
+	}
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return merchant.ErrNoSuchMerchant
+	}
+	u := mch.UserManager().GetUser(personId)
+	if u == nil || u.GetPerson().GetValue().MchId != mchId {
+		logPermissionDenied(mchId, personId, shopId, perm)
+		return user.ErrPermissionDenied
+	}
+	err := u.CheckPermission(perm, shopId)
+	if err == user.ErrPermissionDenied {
+		logPermissionDenied(mchId, personId, shopId, perm)
+	}
+	return err
+}
+
+// 获取商户的角色
+func (m *merchantService) GetStaffRoles(mchId int32) []*user.RoleValue {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return []*user.RoleValue{}
+	}
+	list := mch.UserManager().GetRoles()
+	arr := make([]*user.RoleValue, len(list))
+	for i, v := range list {
+		vv := v.GetValue()
+		arr[i] = &vv
+	}
+	return arr
+}
+
+// 保存商户的角色,perms为角色包含的权限
+func (m *merchantService) SaveStaffRole(mchId int32, v *user.RoleValue,
+	perms []string) (int32, error) {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return 0, merchant.ErrNoSuchMerchant
+	}
+	um := mch.UserManager()
+	var r user.IRole
+	if v.Id > 0 {
+		if r = um.GetRole(v.Id); r == nil {
+			return 0, user.ErrNoSuchRole
+		}
+		v.MchId = mchId
+		if err := r.SetValue(v); err != nil {
+			return 0, err
+		}
+	} else {
+		r = um.CreateRole(v)
+	}
+	if err := r.SetPermissions(perms); err != nil {
+		return 0, err
+	}
+	return r.Save()
+}
+
+// 删除商户的角色
+func (m *merchantService) DeleteStaffRole(mchId, roleId int32) error {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return merchant.ErrNoSuchMerchant
+	}
+	return mch.UserManager().DeleteRole(roleId)
+}
+
+// 授予员工角色,shopId为0表示在所有店铺拥有该角色
+func (m *merchantService) GrantStaffRole(mchId, personId, roleId, shopId int32) error {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return merchant.ErrNoSuchMerchant
+	}
+	if shopId > 0 && mch.ShopManager().GetShop(shopId) == nil {
+		return merchant.ErrNoSuchShop
+	}
+	return mch.UserManager().GrantRole(personId, roleId, shopId)
+}
+
+// 移除员工的角色
+func (m *merchantService) RevokeStaffRole(mchId, personId, roleId, shopId int32) error {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return merchant.ErrNoSuchMerchant
+	}
+	return mch.UserManager().RevokeRole(personId, roleId, shopId)
+}
+
+// 获取员工授予的角色
+func (m *merchantService) GetStaffGrants(mchId, personId int32) []*user.RoleGrant {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch != nil {
+		if u := mch.UserManager().GetUser(personId); u != nil &&
+			u.GetPerson().GetValue().MchId == mchId {
+			return u.Grants()
+		}
+	}
+	return []*user.RoleGrant{}
+}
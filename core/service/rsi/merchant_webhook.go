@@ -0,0 +1,144 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : merchant_webhook.go
+ * author : jarryliu
+ * date : 2026-10-20 22:20
+ * description : 商户事件推送,订阅领域事件后创建推送记录,由延迟队列推送及重试
+ * history :
+ */
+package rsi
+
+import (
+	"fmt"
+	"go2o/core/domain/interface/event"
+	"go2o/core/domain/interface/merchant"
+	"go2o/core/module"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 商户事件推送的延迟任务主题
+const WebhookTopic = "merchant.webhook"
+
+// 获取推送地址
+func (m *merchantService) GetWebhooks(mchId int32) []*merchant.Webhook {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return []*merchant.Webhook{}
+	}
+	return mch.ApiManager().GetWebhooks()
+}
+
+// 保存推送地址
+func (m *merchantService) SaveWebhook(mchId int32, v *merchant.Webhook) (int32, error) {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return 0, merchant.ErrNoSuchMerchant
+	}
+	return mch.ApiManager().SaveWebhook(v)
+}
+
+// 删除推送地址
+func (m *merchantService) DeleteWebhook(mchId int32, id int32) error {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return merchant.ErrNoSuchMerchant
+	}
+	return mch.ApiManager().DeleteWebhook(id)
+}
+
+// 查询推送记录,webhookId及state为0时不作为条件
+func (m *merchantService) QueryWebhookDeliveries(mchId int32, webhookId int32,
+	state int, begin, size int) (int, []*merchant.WebhookDelivery) {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return 0, []*merchant.WebhookDelivery{}
+	}
+	return mch.ApiManager().QueryDeliveries(webhookId, state, begin, size)
+}
+
+// 重新推送
+func (m *merchantService) RedeliverWebhook(mchId int32, deliveryId int64) (int64, error) {
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return 0, merchant.ErrNoSuchMerchant
+	}
+	d, err := mch.ApiManager().Redeliver(deliveryId)
+	if err == nil {
+		err = scheduleWebhook(d)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return d.Id, nil
+}
+
+// 订阅商户可推送的领域事件
+func (m *merchantService) SubscribeWebhookEvents() error {
+	return EventService.Subscribe("merchant_webhook", merchant.WebhookEvents,
+		m.handleWebhookEvent)
+}
+
+// 获取事件所属的商户
+func (m *merchantService) getEventVendorId(e *event.Event) (int32, error) {
+	if e.AggregateType == event.AggregateItem {
+		d := event.ItemData{}
+		err := e.Unmarshal(&d)
+		return d.VendorId, err
+	}
+	// 仅推送子订单的事件,父订单不属于商户
+	if e.AggregateType == event.AggregateSubOrder {
+		d := event.OrderData{}
+		err := e.Unmarshal(&d)
+		return d.VendorId, err
+	}
+	return 0, nil
+}
+
+func (m *merchantService) handleWebhookEvent(e *event.Event) error {
+	mchId, err := m.getEventVendorId(e)
+	if err != nil || mchId <= 0 {
+		return err
+	}
+	mch := m._mchRepo.GetMerchant(mchId)
+	if mch == nil {
+		return nil
+	}
+	list, err := mch.ApiManager().CreateDeliveries(e)
+	for _, d := range list {
+		if err := scheduleWebhook(d); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// 执行推送任务,失败时按推送记录的下次推送时间重新安排
+func (m *merchantService) HandleWebhookJob(j *module.DelayJob) error {
+	arr := strings.Split(j.Key, ":")
+	if len(arr) != 2 {
+		return nil
+	}
+	mchId, _ := strconv.Atoi(arr[0])
+	id, _ := strconv.ParseInt(arr[1], 10, 64)
+	mch := m._mchRepo.GetMerchant(int32(mchId))
+	if mch == nil {
+		return nil
+	}
+	d, err := mch.ApiManager().Deliver(id)
+	if err == merchant.ErrNoSuchDelivery {
+		return nil
+	}
+	if err == nil && d.State == merchant.DeliveryRetrying {
+		err = scheduleWebhook(d)
+	}
+	return err
+}
+
+// 安排推送任务
+func scheduleWebhook(d *merchant.WebhookDelivery) error {
+	q := module.Get(module.M_DELAY).(*module.DelayQueueModule)
+	return q.Schedule(WebhookTopic, fmt.Sprintf("%d:%d", d.MchId, d.Id),
+		"", time.Unix(d.NextTime, 0))
+}
@@ -0,0 +1,212 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : mss_chat
+ * author : jarryliu
+ * date : 2026-10-22 11:20
+ * description : 会员与商户客服的在线会话,客服须拥有客服接待权限;
+ *               客服通过令牌连接推送服务,令牌由商户在员工登录后获取
+ * history :
+ */
+package rsi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/jsix/gof"
+	"go2o/core/domain/interface/merchant"
+	"go2o/core/domain/interface/merchant/user"
+	"go2o/core/domain/interface/mss"
+	"go2o/core/domain/interface/mss/chat"
+)
+
+// 客服令牌的有效时间(秒)
+const chatAgentTokenExpires = 3600 * 12
+
+var ErrChatAgentToken = errors.New("chat agent token expired")
+
+// 会员发起会话
+func (m *mssService) OpenChat(memberId int64, mchId int32) (*chat.Session, error) {
+	if MerchantService.GetMerchant(mchId) == nil {
+		return nil, merchant.ErrNoSuchMerchant
+	}
+	return m._rep.ChatManager().OpenSession(memberId, mchId)
+}
+
+// 获取会员的会话
+func (m *mssService) GetMemberChats(memberId int64) []*chat.Session {
+	return m._rep.ChatManager().GetMemberSessions(memberId)
+}
+
+// 获取会话
+func (m *mssService) GetChatSession(id int32) *chat.Session {
+	return m._rep.ChatManager().GetSession(id)
+}
+
+// 获取消息
+func (m *mssService) GetChatMessage(id int64) *chat.Message {
+	return m._rep.ChatManager().GetMessage(id)
+}
+
+// 会员获取会话的历史消息
+func (m *mssService) GetMemberChatMessages(memberId int64, sessionId int32,
+	beforeId int64, size int) ([]*chat.Message, error) {
+	mgr := m._rep.ChatManager()
+	s := mgr.GetSession(sessionId)
+	if s == nil || s.MemberId != memberId {
+		return nil, chat.ErrNotSessionUser
+	}
+	return mgr.GetMessages(sessionId, beforeId, size), nil
+}
+
+// 会员发送消息
+func (m *mssService) MemberSendChat(memberId int64, sessionId int32,
+	msgType int, content string) (*chat.Message, error) {
+	return m._rep.ChatManager().Send(sessionId, mss.RoleMember,
+		memberId, msgType, content)
+}
+
+// 会员标记已读
+func (m *mssService) MemberReadChat(memberId int64, sessionId int32, msgId int64) error {
+	return m._rep.ChatManager().MarkRead(sessionId, mss.RoleMember, memberId, msgId)
+}
+
+// 会员结束会话
+func (m *mssService) MemberCloseChat(memberId int64, sessionId int32) error {
+	return m._rep.ChatManager().Close(sessionId, mss.RoleMember, memberId)
+}
+
+// 设置客服状态,员工须拥有客服接待权限
+func (m *mssService) SetChatAgentState(mchId, personId int32, state int,
+	maxSessions int) error {
+	if personId <= 0 {
+		return chat.ErrNoSuchAgent
+	}
+	if state != chat.AgentOffline {
+		err := MerchantService.CheckStaffPermission(mchId, personId, 0,
+			user.PermCustomerService)
+		if err != nil {
+			return err
+		}
+	}
+	return m._rep.ChatManager().SetAgentState(mchId, personId, state, maxSessions)
+}
+
+// 获取商户的客服
+func (m *mssService) GetChatAgents(mchId int32) []*chat.Agent {
+	return m._rep.ChatManager().GetAgents(mchId)
+}
+
+// 检查客服,员工须为商户的客服且仍拥有客服接待权限,
+// 权限被收回后客服的所有操作均被拒绝
+func (m *mssService) checkChatAgent(mchId, personId int32) error {
+	if personId <= 0 || m._rep.ChatManager().GetAgent(mchId, personId) == nil {
+		return chat.ErrNoSuchAgent
+	}
+	return MerchantService.CheckStaffPermission(mchId, personId, 0,
+		user.PermCustomerService)
+}
+
+// 获取客服接待中及等待接入的会话
+func (m *mssService) GetAgentChats(mchId, personId int32) (serving []*chat.Session,
+	waiting []*chat.Session) {
+	if m.checkChatAgent(mchId, personId) != nil {
+		return []*chat.Session{}, []*chat.Session{}
+	}
+	mgr := m._rep.ChatManager()
+	return mgr.GetAgentSessions(mchId, personId), mgr.GetWaitingSessions(mchId)
+}
+
+// 检查客服及会话是否属于商户
+func (m *mssService) checkMchChat(mchId, personId int32, sessionId int32) (*chat.Session, error) {
+	if err := m.checkChatAgent(mchId, personId); err != nil {
+		return nil, err
+	}
+	s := m._rep.ChatManager().GetSession(sessionId)
+	if s == nil || s.MchId != mchId {
+		return nil, chat.ErrNoSuchSession
+	}
+	return s, nil
+}
+
+// 客服获取会话的历史消息,可查看商户的所有会话
+func (m *mssService) GetAgentChatMessages(mchId, personId int32, sessionId int32,
+	beforeId int64, size int) ([]*chat.Message, error) {
+	if _, err := m.checkMchChat(mchId, personId, sessionId); err != nil {
+		return nil, err
+	}
+	return m._rep.ChatManager().GetMessages(sessionId, beforeId, size), nil
+}
+
+// 客服发送消息
+func (m *mssService) AgentSendChat(mchId, personId int32, sessionId int32,
+	msgType int, content string) (*chat.Message, error) {
+	if _, err := m.checkMchChat(mchId, personId, sessionId); err != nil {
+		return nil, err
+	}
+	return m._rep.ChatManager().Send(sessionId, mss.RoleMerchant,
+		int64(personId), msgType, content)
+}
+
+// 客服标记已读
+func (m *mssService) AgentReadChat(mchId, personId int32, sessionId int32, msgId int64) error {
+	if _, err := m.checkMchChat(mchId, personId, sessionId); err != nil {
+		return err
+	}
+	return m._rep.ChatManager().MarkRead(sessionId, mss.RoleMerchant,
+		int64(personId), msgId)
+}
+
+// 客服结束会话
+func (m *mssService) AgentCloseChat(mchId, personId int32, sessionId int32) error {
+	if _, err := m.checkMchChat(mchId, personId, sessionId); err != nil {
+		return err
+	}
+	return m._rep.ChatManager().Close(sessionId, mss.RoleMerchant, int64(personId))
+}
+
+// 转接会话,接收的客服同样须拥有客服接待权限
+func (m *mssService) TransferChat(mchId, personId int32, sessionId int32, toAgent int32) error {
+	if _, err := m.checkMchChat(mchId, personId, sessionId); err != nil {
+		return err
+	}
+	if err := m.checkChatAgent(mchId, toAgent); err != nil {
+		return err
+	}
+	return m._rep.ChatManager().Transfer(sessionId, personId, toAgent)
+}
+
+func getChatAgentTokenKey(token string) string {
+	return "go2o:rsi:chat:agent:" + token
+}
+
+// 创建客服连接推送服务的令牌
+func (m *mssService) CreateChatAgentToken(mchId, personId int32) (string, error) {
+	if err := m.checkChatAgent(mchId, personId); err != nil {
+		return "", err
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+	err := gof.CurrentApp.Storage().SetExpire(getChatAgentTokenKey(token),
+		fmt.Sprintf("%d:%d", mchId, personId), chatAgentTokenExpires)
+	return token, err
+}
+
+// 校验客服令牌,返回商户编号及人员编号;客服权限被收回时令牌失效
+func (m *mssService) CheckChatAgentToken(token string) (int32, int32, error) {
+	var mchId, personId int32
+	v, err := gof.CurrentApp.Storage().GetString(getChatAgentTokenKey(token))
+	if err == nil && token != "" {
+		_, err = fmt.Sscanf(v, "%d:%d", &mchId, &personId)
+	}
+	if err != nil || personId <= 0 {
+		return 0, 0, ErrChatAgentToken
+	}
+	if err = m.checkChatAgent(mchId, personId); err != nil {
+		gof.CurrentApp.Storage().Del(getChatAgentTokenKey(token))
+		return 0, 0, err
+	}
+	return mchId, personId, nil
+}
@@ -0,0 +1,126 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : mss_inbox
+ * author : jarryliu
+ * date : 2026-10-23 13:30
+ * description : 会员站内信,广播消息按等级,买家分组,地区及标签定向发送
+ * history :
+ */
+package rsi
+
+import (
+	"encoding/json"
+	"go2o/core/domain/interface/mss/inbox"
+	"go2o/core/service/thrift/idl/gen-go/define"
+	"go2o/core/service/thrift/parser"
+)
+
+var _ define.MessageService = new(mssService)
+
+// 获取会员收件箱的消息
+func (m *mssService) GetInboxMessages(memberId int64, unreadOnly bool,
+	begin, size int) (int, []*inbox.Message) {
+	return m._rep.InboxManager().GetMessages(memberId, unreadOnly, begin, size)
+}
+
+// 获取会员的消息
+func (m *mssService) GetInboxMessage(memberId int64, id int64) *inbox.Message {
+	return m._rep.InboxManager().GetMessage(memberId, id)
+}
+
+// 标记消息已读,ids为空时标记全部
+func (m *mssService) MarkInboxRead(memberId int64, ids []int64) error {
+	return m._rep.InboxManager().MarkRead(memberId, ids)
+}
+
+// 删除消息
+func (m *mssService) DeleteInboxMessages(memberId int64, ids []int64) error {
+	return m._rep.InboxManager().Delete(memberId, ids)
+}
+
+// 发送消息给会员
+func (m *mssService) SendInboxMessage(memberId int64, subject string,
+	content string, expiresTime int64) (*inbox.Message, error) {
+	return m._rep.InboxManager().Send(memberId, subject, content, expiresTime)
+}
+
+// 广播消息,seg为空时发送给所有会员
+func (m *mssService) BroadcastMessage(subject string, content string,
+	seg *inbox.Segment, expiresTime int64) (*inbox.Broadcast, error) {
+	return m._rep.InboxManager().Broadcast(subject, content, seg, expiresTime)
+}
+
+// 获取广播消息
+func (m *mssService) GetBroadcasts(begin, size int) (int, []*inbox.Broadcast) {
+	return m._rep.InboxManager().GetBroadcasts(begin, size)
+}
+
+// 撤回广播消息
+func (m *mssService) RevokeBroadcast(id int32) error {
+	return m._rep.InboxManager().Revoke(id)
+}
+
+// 清理已过期的站内信
+func (m *mssService) ClearExpiredInbox() (int, error) {
+	return m._rep.InboxManager().ClearExpired()
+}
+
+// 设置会员的买家分组
+func (m *mssService) SetMemberBuyerGroup(memberId int64, groupId int32) error {
+	return m._rep.InboxManager().SetMemberGroup(memberId, groupId)
+}
+
+// 设置会员的标签
+func (m *mssService) SetMemberTags(memberId int64, tags []string) error {
+	return m._rep.InboxManager().SetMemberTags(memberId, tags)
+}
+
+// 获取会员的标签
+func (m *mssService) GetMemberTags(memberId int64) []string {
+	return m._rep.InboxManager().GetMemberTags(memberId)
+}
+
+// 获取会员的站内信
+func (m *mssService) GetInbox(memberId int64, unreadOnly bool, begin int32,
+	size int32) (r []*define.InboxMessage, err error) {
+	_, list := m.GetInboxMessages(memberId, unreadOnly, int(begin), int(size))
+	r = make([]*define.InboxMessage, len(list))
+	for i, v := range list {
+		r[i] = parser.InboxMessageDto(v)
+	}
+	return r, nil
+}
+
+// 获取未读站内信数量
+func (m *mssService) InboxUnread(memberId int64) (r int32, err error) {
+	return int32(m._rep.InboxManager().UnreadCount(memberId)), nil
+}
+
+// 标记站内信已读
+func (m *mssService) InboxRead(memberId int64, ids []int64) (r *define.Result_, err error) {
+	err = m.MarkInboxRead(memberId, ids)
+	return parser.Result(0, err), nil
+}
+
+// 删除站内信
+func (m *mssService) InboxDelete(memberId int64, ids []int64) (r *define.Result_, err error) {
+	err = m.DeleteInboxMessages(memberId, ids)
+	return parser.Result(0, err), nil
+}
+
+// 发送广播站内信,segment为JSON格式的目标分群
+func (m *mssService) Broadcast(subject string, content string, segment string,
+	expiresTime int64) (r *define.Result_, err error) {
+	var seg *inbox.Segment
+	if segment != "" {
+		seg = &inbox.Segment{}
+		if json.Unmarshal([]byte(segment), seg) != nil {
+			return parser.Result(0, inbox.ErrIncorrectSegment), nil
+		}
+	}
+	b, err := m.BroadcastMessage(subject, content, seg, expiresTime)
+	if err != nil {
+		return parser.Result(0, err), nil
+	}
+	return parser.Result(b.Id, nil), nil
+}
@@ -9,18 +9,22 @@
 package rsi
 
 import (
+	"go2o/core/domain/interface/member"
 	"go2o/core/domain/interface/mss"
 	"go2o/core/domain/interface/mss/notify"
 	"go2o/core/dto"
+	"net/url"
 )
 
 type mssService struct {
-	_rep mss.IMssRepo
+	_rep    mss.IMssRepo
+	_mmRepo member.IMemberRepo
 }
 
-func NewMssService(rep mss.IMssRepo) *mssService {
+func NewMssService(rep mss.IMssRepo, mmRepo member.IMemberRepo) *mssService {
 	return &mssService{
-		_rep: rep,
+		_rep:    rep,
+		_mmRepo: mmRepo,
 	}
 }
 
@@ -195,3 +199,64 @@ func (m *mssService) GetChatSessionId(senderRole int, senderId int32, toRole int
 func (m *mssService) CreateChatSession(senderRole int, senderId int32, toRole int, toId int32) (mss.Message, error) {
 	return m._rep.MessageManager().CreateChatSession(senderRole, senderId, toRole, toId)
 }
+
+// 向会员发送通知,按会员的偏好选择渠道
+func (m *mssService) DispatchNotify(key string, memberId int64,
+	data map[string]interface{}) (*notify.Delivery, error) {
+	to := &notify.Recipient{MemberId: memberId}
+	if pro := m._mmRepo.GetProfile(memberId); pro != nil {
+		to.Phone = pro.Phone
+		to.Email = pro.Email
+	}
+	return m._rep.NotifyManager().Dispatch(key, to, data)
+}
+
+// 获取通知项的模板
+func (m *mssService) GetNotifyTemplates(key string) []*notify.Template {
+	return m._rep.NotifyManager().GetTemplates(key)
+}
+
+// 保存通知模板
+func (m *mssService) SaveNotifyTemplate(v *notify.Template) (int32, error) {
+	return m._rep.NotifyManager().SaveTemplate(v)
+}
+
+// 删除通知模板
+func (m *mssService) DeleteNotifyTemplate(id int32) error {
+	return m._rep.NotifyManager().DeleteTemplate(id)
+}
+
+// 获取会员的通知偏好
+func (m *mssService) GetNotifyPreference(memberId int64) *notify.Preference {
+	return m._rep.NotifyManager().GetPreference(memberId)
+}
+
+// 保存会员的通知偏好
+func (m *mssService) SaveNotifyPreference(v *notify.Preference) error {
+	return m._rep.NotifyManager().SavePreference(v)
+}
+
+// 获取会员对通知项设置的渠道
+func (m *mssService) GetNotifyEventPrefs(memberId int64) []*notify.EventPref {
+	return m._rep.NotifyManager().GetEventPrefs(memberId)
+}
+
+// 设置会员接收通知项的渠道,channels为空时不接收
+func (m *mssService) SaveNotifyEventPref(memberId int64, key string, channels []int) error {
+	return m._rep.NotifyManager().SaveEventPref(memberId, key, channels)
+}
+
+// 查询会员的通知发送记录
+func (m *mssService) QueryNotifyDeliveries(memberId int64, begin, size int) (int, []*notify.Delivery) {
+	return m._rep.NotifyManager().QueryDeliveries(memberId, begin, size)
+}
+
+// 处理短信服务商的状态报告,返回更新的短信数量
+func (m *mssService) HandleSmsReceipt(provider int, values url.Values) (int, error) {
+	return m._rep.NotifyManager().HandleSmsReceipt(provider, values)
+}
+
+// 查询短信发送记录
+func (m *mssService) QuerySmsMessages(phone string, begin, size int) (int, []*notify.SmsMessage) {
+	return m._rep.NotifyManager().QuerySmsMessages(phone, begin, size)
+}
@@ -0,0 +1,390 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : oauth_service.go
+ * author : jarryliu
+ * date : 2026-10-19 22:30
+ * description : OAuth2 / OpenID Connect授权服务
+ * history :
+ */
+package rsi
+
+import (
+	"go2o/core/domain/interface/oauth"
+	"go2o/core/dto"
+	"go2o/core/module"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 授权请求
+type AuthorizeRequest struct {
+	// 应用编号
+	ClientId string
+	// 回调地址
+	RedirectUri string
+	// 授权范围
+	Scope string
+	// 原样返回给应用的状态
+	State string
+	// OpenID Connect的nonce
+	Nonce string
+	// PKCE: code_challenge
+	CodeChallenge string
+	// PKCE: code_challenge_method
+	CodeChallengeMethod string
+}
+
+// 授权结果
+type AuthorizeResult struct {
+	// 是否需要会员确认授权
+	ConsentRequired bool `json:"consentRequired"`
+	// 应用名称
+	ClientName string `json:"clientName"`
+	// 应用图标
+	ClientLogo string `json:"clientLogo"`
+	// 申请的授权范围及说明
+	Scopes map[string]string `json:"scopes"`
+	// 回调地址,已授权时包含授权码
+	RedirectUrl string `json:"redirectUrl"`
+}
+
+type oauthService struct {
+	_rep           oauth.IOAuthRepo
+	_memberService *memberService
+}
+
+func NewOAuthService(rep oauth.IOAuthRepo, ms *memberService) *oauthService {
+	return &oauthService{
+		_rep:           rep,
+		_memberService: ms,
+	}
+}
+
+func (o *oauthService) module() *module.OAuthModule {
+	return module.Get(module.M_OAUTH).(*module.OAuthModule)
+}
+
+// 获取商户的应用,应用不属于该商户时返回nil
+func (o *oauthService) getMchClient(mchId int32, clientId string) oauth.IClient {
+	c := o._rep.GetClient(clientId)
+	if c == nil || c.GetValue().MchId != mchId {
+		return nil
+	}
+	return c
+}
+
+// 注册应用,返回应用编号及密钥,公开应用无密钥;
+// mchId为0时注册平台的应用,商户的应用不能设为受信任
+func (o *oauthService) RegisterClient(mchId int32, v *oauth.Client) (string, string, error) {
+	c := o._rep.CreateClient(&oauth.Client{})
+	v.MchId = mchId
+	v.State = oauth.ClientEnabled
+	if mchId > 0 {
+		v.Trusted = 0
+	}
+	err := c.SetValue(v)
+	if err != nil {
+		return "", "", err
+	}
+	secret := ""
+	if v.Public != 1 {
+		if secret, err = c.ResetSecret(); err != nil {
+			return "", "", err
+		}
+	}
+	if _, err = c.Save(); err != nil {
+		return "", "", err
+	}
+	return c.GetValue().ClientId, secret, nil
+}
+
+// 保存商户的应用
+func (o *oauthService) SaveClient(mchId int32, v *oauth.Client) error {
+	c := o.getMchClient(mchId, v.ClientId)
+	if c == nil {
+		return oauth.ErrNoSuchClient
+	}
+	if mchId > 0 {
+		v.Trusted = c.GetValue().Trusted
+	}
+	err := c.SetValue(v)
+	if err == nil {
+		_, err = c.Save()
+	}
+	return err
+}
+
+// 重置商户的应用密钥
+func (o *oauthService) ResetClientSecret(mchId int32, clientId string) (string, error) {
+	c := o.getMchClient(mchId, clientId)
+	if c == nil {
+		return "", oauth.ErrNoSuchClient
+	}
+	secret, err := c.ResetSecret()
+	if err == nil {
+		_, err = c.Save()
+	}
+	return secret, err
+}
+
+// 获取应用
+func (o *oauthService) GetClient(clientId string) *oauth.Client {
+	if c := o._rep.GetClient(clientId); c != nil {
+		v := c.GetValue()
+		v.Secret = ""
+		return &v
+	}
+	return nil
+}
+
+// 获取商户的应用,mchId为0时返回平台的应用
+func (o *oauthService) GetClients(mchId int32) []*oauth.Client {
+	list := o._rep.GetClients(mchId)
+	for _, v := range list {
+		v.Secret = ""
+	}
+	return list
+}
+
+// 校验授权请求
+func (o *oauthService) checkAuthorize(r *AuthorizeRequest) (oauth.IClient, string, error) {
+	c := o._rep.GetClient(r.ClientId)
+	if c == nil {
+		return nil, "", oauth.ErrNoSuchClient
+	}
+	if !c.Enabled() {
+		return nil, "", oauth.ErrClientDisabled
+	}
+	if err := c.CheckRedirectUri(r.RedirectUri); err != nil {
+		return nil, "", err
+	}
+	scope, err := c.CheckScope(r.Scope)
+	if err == nil {
+		err = c.CheckPkce(r.CodeChallenge, r.CodeChallengeMethod)
+	}
+	return c, scope, err
+}
+
+// 授权,会员已授权或应用受信任时,返回包含授权码的回调地址,
+// 否则返回应用信息,由会员确认后调用Consent
+func (o *oauthService) Authorize(memberId int64, r *AuthorizeRequest) (*AuthorizeResult, error) {
+	c, scope, err := o.checkAuthorize(r)
+	if err != nil {
+		return nil, err
+	}
+	v := c.GetValue()
+	result := &AuthorizeResult{
+		ClientName: v.Name,
+		ClientLogo: v.Logo,
+		Scopes:     map[string]string{},
+	}
+	for _, s := range strings.Fields(scope) {
+		result.Scopes[s] = oauth.ScopeNames[s]
+	}
+	cs := o._rep.GetConsent(memberId, r.ClientId)
+	if v.Trusted != 1 && (cs == nil || !oauth.ScopeCovered(cs.Scope, scope)) {
+		result.ConsentRequired = true
+		return result, nil
+	}
+	result.RedirectUrl, err = o.redirectWithCode(memberId, scope, r)
+	return result, err
+}
+
+// 会员确认授权,返回回调地址;拒绝授权时回调地址包含错误
+func (o *oauthService) Consent(memberId int64, r *AuthorizeRequest, allow bool) (string, error) {
+	_, scope, err := o.checkAuthorize(r)
+	if err != nil {
+		return "", err
+	}
+	if !allow {
+		return o.redirectUrl(r.RedirectUri, url.Values{
+			"error": {"access_denied"},
+			"state": {r.State},
+		}), nil
+	}
+	unix := time.Now().Unix()
+	cs := o._rep.GetConsent(memberId, r.ClientId)
+	if cs == nil {
+		cs = &oauth.Consent{
+			MemberId:   memberId,
+			ClientId:   r.ClientId,
+			CreateTime: unix,
+		}
+	}
+	cs.Scope = oauth.MergeScope(cs.Scope, scope)
+	cs.UpdateTime = unix
+	if _, err = o._rep.SaveConsent(cs); err != nil {
+		return "", err
+	}
+	return o.redirectWithCode(memberId, scope, r)
+}
+
+// 生成授权码,并返回回调地址
+func (o *oauthService) redirectWithCode(memberId int64, scope string,
+	r *AuthorizeRequest) (string, error) {
+	code, err := o.module().CreateCode(&module.AuthCode{
+		ClientId:        r.ClientId,
+		MemberId:        memberId,
+		RedirectUri:     r.RedirectUri,
+		Scope:           scope,
+		Nonce:           r.Nonce,
+		Challenge:       r.CodeChallenge,
+		ChallengeMethod: r.CodeChallengeMethod,
+		AuthTime:        time.Now().Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return o.redirectUrl(r.RedirectUri, url.Values{
+		"code":  {code},
+		"state": {r.State},
+	}), nil
+}
+
+func (o *oauthService) redirectUrl(uri string, v url.Values) string {
+	if v.Get("state") == "" {
+		v.Del("state")
+	}
+	if strings.Index(uri, "?") == -1 {
+		return uri + "?" + v.Encode()
+	}
+	return uri + "&" + v.Encode()
+}
+
+// 校验应用身份,公开应用无需密钥
+func (o *oauthService) authenticateClient(clientId, secret string) (oauth.IClient, error) {
+	c := o._rep.GetClient(clientId)
+	if c == nil {
+		return nil, oauth.ErrNoSuchClient
+	}
+	if !c.Enabled() {
+		return nil, oauth.ErrClientDisabled
+	}
+	if c.GetValue().Public != 1 && !c.VerifySecret(secret) {
+		return nil, oauth.ErrClientSecret
+	}
+	return c, nil
+}
+
+// 使用授权码换取令牌
+func (o *oauthService) ExchangeCode(clientId, secret, code, redirectUri,
+	codeVerifier string) (*module.OAuthToken, error) {
+	c, err := o.authenticateClient(clientId, secret)
+	if err != nil {
+		return nil, err
+	}
+	m := o.module()
+	ac, err := m.TakeCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if ac.ClientId != clientId {
+		return nil, module.ErrAuthCode
+	}
+	if ac.RedirectUri != redirectUri {
+		return nil, oauth.ErrRedirectUri
+	}
+	if ac.Challenge != "" {
+		if !oauth.VerifyCodeChallenge(ac.Challenge, ac.ChallengeMethod, codeVerifier) {
+			return nil, oauth.ErrCodeVerifier
+		}
+	} else if c.GetValue().Public == 1 {
+		return nil, oauth.ErrPkceRequired
+	}
+	return m.IssueToken(clientId, ac.MemberId, ac.Scope, ac.Nonce, ac.AuthTime)
+}
+
+// 使用刷新令牌换取新的令牌
+func (o *oauthService) RefreshToken(clientId, secret,
+	refreshToken string) (*module.OAuthToken, error) {
+	if _, err := o.authenticateClient(clientId, secret); err != nil {
+		return nil, err
+	}
+	return o.module().RefreshToken(clientId, refreshToken)
+}
+
+// 注销令牌
+func (o *oauthService) RevokeToken(clientId, secret, token string) error {
+	if _, err := o.authenticateClient(clientId, secret); err != nil {
+		return err
+	}
+	o.module().RevokeToken(clientId, token)
+	return nil
+}
+
+// 检查访问令牌,scope不为空时,检查是否包含该授权范围;
+// 应用已停用或删除时,已签发的令牌失效
+func (o *oauthService) CheckAccessToken(token string, scope string) (*module.AccessInfo, error) {
+	info, err := o.module().CheckAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if c := o._rep.GetClient(info.ClientId); c == nil || !c.Enabled() {
+		return nil, module.ErrAccessToken
+	}
+	if scope != "" && !oauth.HasScope(info.Scope, scope) {
+		return info, oauth.ErrInsufficientScope
+	}
+	return info, nil
+}
+
+// 获取会员信息(OpenID Connect UserInfo),根据授权范围返回资料及账户
+func (o *oauthService) UserInfo(info *module.AccessInfo) map[string]interface{} {
+	mp := map[string]interface{}{
+		"sub": strconv.FormatInt(info.MemberId, 10),
+	}
+	if oauth.HasScope(info.Scope, oauth.ScopeProfile) {
+		if p, _ := o._memberService.GetProfile(info.MemberId); p != nil {
+			mp["name"] = p.Name
+			mp["picture"] = p.Avatar
+			mp["gender"] = p.Sex
+			mp["birthdate"] = p.BirthDay
+			mp["updated_at"] = p.UpdateTime
+		}
+		if m, _ := o._memberService.GetMember(info.MemberId); m != nil {
+			mp["preferred_username"] = m.Usr
+		}
+	}
+	if oauth.HasScope(info.Scope, oauth.ScopeAccount) {
+		if a, _ := o._memberService.GetAccount(info.MemberId); a != nil {
+			mp["account"] = map[string]interface{}{
+				"balance":        a.Balance,
+				"wallet_balance": a.WalletBalance,
+				"integral":       a.Integral,
+			}
+		}
+	}
+	return mp
+}
+
+// 获取会员的订单,须包含orders授权范围
+func (o *oauthService) QueryOrders(info *module.AccessInfo, begin,
+	size int) (int, []*dto.PagedMemberSubOrder, error) {
+	if !oauth.HasScope(info.Scope, oauth.ScopeOrders) {
+		return 0, nil, oauth.ErrInsufficientScope
+	}
+	n, rows := o._memberService.QueryNormalOrder(info.MemberId,
+		begin, size, true, "", "")
+	return n, rows, nil
+}
+
+// 获取会员已授权的应用
+func (o *oauthService) GetConsents(memberId int64) []*oauth.Consent {
+	return o._rep.GetConsents(memberId)
+}
+
+// 取消对应用的授权,并注销已签发的令牌;
+// 受信任的应用无授权记录,也须注销其令牌
+func (o *oauthService) RevokeConsent(memberId int64, clientId string) error {
+	if o._rep.GetConsent(memberId, clientId) != nil {
+		if err := o._rep.DeleteConsent(memberId, clientId); err != nil {
+			return err
+		}
+	} else if o._rep.GetClient(clientId) == nil {
+		return oauth.ErrNoSuchClient
+	}
+	o.module().RevokeGrant(memberId, clientId)
+	return nil
+}
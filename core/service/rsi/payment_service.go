@@ -9,6 +9,8 @@
 package rsi
 
 import (
+	"fmt"
+	"go2o/core/domain/interface/audit"
 	"go2o/core/domain/interface/order"
 	"go2o/core/domain/interface/payment"
 	"go2o/core/service/thrift/idl/gen-go/define"
@@ -69,7 +71,12 @@ func (p *paymentService) AdjustOrder(paymentNo string, amount float64) (*define.
 	if o == nil {
 		err = payment.ErrNoSuchPaymentOrder
 	} else {
-		err = o.Adjust(float32(amount))
+		before := o.GetValue()
+		if err = o.Adjust(float32(amount)); err == nil {
+			auditLog(audit.SystemActor, "payment_order.adjust",
+				audit.TargetPaymentOrder, paymentNo, before, o.GetValue(),
+				fmt.Sprintf("adjust %.2f", amount))
+		}
 	}
 	return parser.Result(0, err), nil
 }
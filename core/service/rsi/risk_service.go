@@ -0,0 +1,122 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : risk_service.go
+ * author : jarryliu
+ * date : 2026-10-20 16:20
+ * description : 风控服务
+ * history :
+ */
+package rsi
+
+import (
+	"go2o/core/domain/interface/audit"
+	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/risk"
+	"log"
+)
+
+type riskService struct {
+	_rep risk.IRiskRepo
+}
+
+func NewRiskService(rep risk.IRiskRepo) *riskService {
+	return &riskService{
+		_rep: rep,
+	}
+}
+
+// 评估操作,返回记录及决策对应的错误。评估出错时不阻止操作
+func (r *riskService) Evaluate(c *risk.Context) (*risk.Record, error) {
+	v, err := r._rep.GetManager().Evaluate(c)
+	if err != nil {
+		log.Println("[ Go2o][ Risk]: save record failed:", err.Error())
+	}
+	switch v.Decision {
+	case risk.DecisionDeny:
+		return v, risk.ErrRiskDenied
+	case risk.DecisionReview:
+		return v, risk.ErrRiskReview
+	}
+	return v, nil
+}
+
+// 关联业务编号
+func (r *riskService) BindRecord(id int64, refId string) error {
+	return r._rep.GetManager().BindRecord(id, refId)
+}
+
+// 将需人工审核的操作加入审核队列,data为审核通过后执行操作的参数
+func (r *riskService) SubmitReview(rc *risk.Record, data string) (int64, error) {
+	return r._rep.GetManager().SubmitReview(rc, data)
+}
+
+// 获取等待人工审核的操作
+func (r *riskService) GetReviewQueue(begin, size int) (int, []*risk.Review) {
+	return r._rep.QueryReviews(enum.ReviewAwaiting, begin, size)
+}
+
+// 审核操作,审核通过后执行操作;执行失败时标记为审核不通过
+func (r *riskService) Review(id int64, pass bool, remark string,
+	actor *audit.Actor) error {
+	v, err := r._rep.GetManager().Review(id, pass, remark)
+	if err != nil {
+		return err
+	}
+	if pass {
+		var execErr error
+		switch v.Operation {
+		case risk.OpTransfer:
+			execErr = MemberService.executeTransfer(v.Data)
+		}
+		if execErr != nil {
+			v.State = enum.ReviewReject
+			v.Remark = "执行失败:" + execErr.Error()
+			r._rep.SaveReview(v)
+			err = execErr
+		}
+	}
+	auditLog(actor, "risk.review", audit.TargetRisk, v.Id, nil,
+		map[string]interface{}{"memberId": v.MemberId, "state": v.State}, v.Remark)
+	return err
+}
+
+// 获取设置
+func (r *riskService) GetSettings() risk.Settings {
+	return r._rep.GetManager().GetSettings()
+}
+
+// 保存设置
+func (r *riskService) SaveSettings(v *risk.Settings, actor *audit.Actor) error {
+	before := r.GetSettings()
+	err := r._rep.GetManager().SaveSettings(v)
+	if err == nil {
+		auditLog(actor, "risk.save_settings", audit.TargetRisk, 0, before, v, "")
+	}
+	return err
+}
+
+// 查询记录,decision为0时查询所有
+func (r *riskService) QueryRecords(decision int, begin, size int) (int, []*risk.Record) {
+	return r._rep.QueryRecords(decision, begin, size)
+}
+
+// 加入黑名单
+func (r *riskService) AddBlackItem(kind string, value string, remark string,
+	actor *audit.Actor) error {
+	err := r._rep.GetManager().AddBlackItem(kind, value, remark)
+	if err == nil {
+		auditLog(actor, "risk.add_black_item", audit.TargetRisk, 0, nil,
+			map[string]string{"kind": kind, "value": value}, remark)
+	}
+	return err
+}
+
+// 移出黑名单
+func (r *riskService) RemoveBlackItem(kind string, value string, actor *audit.Actor) error {
+	err := r._rep.GetManager().RemoveBlackItem(kind, value)
+	if err == nil {
+		auditLog(actor, "risk.remove_black_item", audit.TargetRisk, 0,
+			map[string]string{"kind": kind, "value": value}, nil, "")
+	}
+	return err
+}
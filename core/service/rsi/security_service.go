@@ -0,0 +1,63 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : security_service.go
+ * author : jarryliu
+ * date : 2026-10-19 20:20
+ * description : 安全服务,两步验证
+ * history :
+ */
+package rsi
+
+import (
+	"go2o/core/domain/interface/security"
+)
+
+type securityService struct {
+	_rep security.ITwoFactorRepo
+}
+
+func NewSecurityService(rep security.ITwoFactorRepo) *securityService {
+	return &securityService{
+		_rep: rep,
+	}
+}
+
+// 是否已启用两步验证,userType如:security.UserMember
+func (s *securityService) TwoFactorEnabled(userType int, userId int64) bool {
+	return s._rep.CreateTwoFactor(userType, userId).Enabled()
+}
+
+// 绑定两步验证,返回验证器应用绑定的地址及二维码(PNG);
+// 绑定后需调用ActivateTwoFactor启用
+func (s *securityService) EnrollTwoFactor(userType int, userId int64,
+	issuer string, account string) (string, []byte, error) {
+	tf := s._rep.CreateTwoFactor(userType, userId)
+	uri, err := tf.Enroll(issuer, account)
+	if err != nil {
+		return "", nil, err
+	}
+	qr, err := tf.QrCode(issuer, account)
+	return uri, qr, err
+}
+
+// 使用验证码启用两步验证,返回恢复码
+func (s *securityService) ActivateTwoFactor(userType int, userId int64,
+	code string) ([]string, error) {
+	return s._rep.CreateTwoFactor(userType, userId).Activate(code)
+}
+
+// 校验验证码或恢复码,通过后一段时间内可进行敏感操作
+func (s *securityService) VerifyTwoFactor(userType int, userId int64, code string) error {
+	return s._rep.CreateTwoFactor(userType, userId).Verify(code)
+}
+
+// 重新生成恢复码
+func (s *securityService) ResetRecoveryCodes(userType int, userId int64,
+	code string) ([]string, error) {
+	return s._rep.CreateTwoFactor(userType, userId).ResetRecoveryCodes(code)
+}
+
+// 停用两步验证
+func (s *securityService) DisableTwoFactor(userType int, userId int64, code string) error {
+	return s._rep.CreateTwoFactor(userType, userId).Disable(code)
+}
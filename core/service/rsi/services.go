@@ -17,11 +17,18 @@ import (
 	"github.com/jsix/gof/storage"
 	"go2o/app"
 	"go2o/core/dao"
+	"go2o/core/domain/interface/merchant/user"
+	"go2o/core/domain/interface/mss/notify"
 	"go2o/core/domain/interface/valueobject"
 	"go2o/core/infrastructure/domain"
+	"go2o/core/infrastructure/kyc"
+	"go2o/core/infrastructure/printer"
+	"go2o/core/infrastructure/social"
 	"go2o/core/query"
 	"go2o/core/repository"
 	"go2o/core/variable"
+	"io/ioutil"
+	"log"
 	"strconv"
 	"strings"
 	"time"
@@ -49,6 +56,16 @@ var (
 	PaymentService *paymentService
 	// 消息服务
 	MssService *mssService
+	// 安全服务
+	SecurityService *securityService
+	// 授权服务
+	OAuthService *oauthService
+	// 审计服务
+	AuditService *auditService
+	// 风控服务
+	RiskService *riskService
+	// 领域事件服务
+	EventService *eventService
 	// 快递服务
 	ExpressService *expressService
 	// 配送服务
@@ -100,12 +117,17 @@ func initService(ctx gof.App, db db.Connector, orm orm.Orm, sto storage.Interfac
 	/** Repository **/
 	proMRepo := repository.NewProModelRepo(db, orm)
 	valueRepo = repository.NewValueRepo(db, sto)
-	userRepo := repository.NewUserRepo(db)
+	secRepo := repository.NewSecurityRepo(db)
+	oauthRepo := repository.NewOAuthRepo(db)
+	auditRepo := repository.NewAuditRepo(db)
+	eventRepo := repository.NewEventRepo(db)
+	userRepo := repository.NewUserRepo(db, secRepo)
 	notifyRepo := repository.NewNotifyRepo(db)
 	mssRepo := repository.NewMssRepo(db, notifyRepo, valueRepo)
 	expressRepo := repository.NewExpressRepo(db, valueRepo)
 	shipRepo := repository.NewShipmentRepo(db, expressRepo)
 	memberRepo := repository.NewMemberRepo(sto, db, mssRepo, valueRepo)
+	riskRepo := repository.NewRiskRepo(db, memberRepo)
 	productRepo := repository.NewProductRepo(db, proMRepo, valueRepo)
 	itemWsRepo := repository.NewItemWholesaleRepo(db)
 	catRepo := repository.NewCategoryRepo(db, valueRepo, sto)
@@ -133,6 +155,9 @@ func initService(ctx gof.App, db db.Connector, orm orm.Orm, sto storage.Interfac
 
 	/* 初始化数据 */
 	memberRepo.GetManager().GetAllBuyerGroups()
+	initSocialProviders(ctx, valueRepo)
+	initKycVerifiers(ctx)
+	printer.SetZplFont(ctx.Config().GetString("waybill_zpl_font"))
 
 	/** Query **/
 	memberQue := query.NewMemberQuery(db)
@@ -152,12 +177,28 @@ func initService(ctx gof.App, db db.Connector, orm orm.Orm, sto storage.Interfac
 	AfterSalesService = NewAfterSalesService(asRepo, afterSalesQuery, orderRepo)
 	MerchantService = NewMerchantService(mchRepo, memberRepo, mchQuery, orderQuery)
 	ShopService = NewShopService(shopRepo, mchRepo, shopQuery)
-	MemberService = NewMemberService(MerchantService, memberRepo, memberQue, orderQuery, valueRepo)
+	MemberService = NewMemberService(MerchantService, memberRepo, memberQue, orderQuery, valueRepo, secRepo)
 	ItemService = NewSaleService(rds, catRepo, itemRepo, goodsQuery, tagSaleRepo, proMRepo, mchRepo, valueRepo)
 	PaymentService = NewPaymentService(paymentRepo, orderRepo)
-	MssService = NewMssService(mssRepo)
+	MssService = NewMssService(mssRepo, memberRepo)
+	// 仅分配会话给有客服权限的员工,权限被收回后不再接入新的会话
+	mssRepo.ChatManager().SetAgentChecker(func(mchId int32, personId int32) bool {
+		return MerchantService.CheckStaffPermission(mchId, personId, 0,
+			user.PermCustomerService) == nil
+	})
+	mssRepo.NotifyManager().RegisterSender(notify.TypePushMessage, &pushSender{})
+	SecurityService = NewSecurityService(secRepo)
+	OAuthService = NewOAuthService(oauthRepo, MemberService)
+	auditKey := ctx.Config().GetString(variable.AuditHmacKey)
+	if auditKey == "" || auditKey == variable.AuditHmacKeySample {
+		log.Fatalln("[ Go2o][ Audit]: audit_hmac_key not configured")
+	}
+	AuditService = NewAuditService(auditRepo, auditKey)
+	RiskService = NewRiskService(riskRepo)
+	EventService = NewEventService(eventRepo)
 	ExpressService = NewExpressService(expressRepo)
-	ShipmentService = NewShipmentService(shipRepo, deliveryRepo)
+	ShipmentService = NewShipmentService(shipRepo, deliveryRepo, orderRepo,
+		shopRepo, expressRepo, valueRepo, orderQuery)
 	ContentService = NewContentService(contentRepo, contentQue)
 	AdService = NewAdvertisementService(adRepo, sto)
 	PersonFinanceService = NewPersonFinanceService(personFinanceRepo, memberRepo)
@@ -166,6 +207,53 @@ func initService(ctx gof.App, db db.Connector, orm orm.Orm, sto storage.Interfac
 	PortalService = NewPortalService(CommonDao)
 }
 
+// 注册第三方登录平台,未配置的平台不注册
+func initSocialProviders(ctx gof.App, valRepo valueobject.IValueRepo) {
+	gf := ctx.Config().GetString
+	if wx := valRepo.GetWxApiConfig(); wx.AppId != "" {
+		social.Register(&social.WeChatProvider{
+			AppId:     wx.AppId,
+			AppSecret: wx.AppSecret,
+		})
+	}
+	if appId := gf("alipay_app_id"); appId != "" {
+		data, err := ioutil.ReadFile(gf("alipay_private_key_file"))
+		if err == nil {
+			pk, err := social.ParseAlipayKey(data)
+			if err == nil {
+				social.Register(&social.AlipayProvider{
+					AppId:      appId,
+					PrivateKey: pk,
+				})
+			}
+		}
+		if err != nil {
+			log.Println("[ Go2o][ Social]: alipay provider not registered:", err)
+		}
+	}
+	if clientId := gf("apple_client_id"); clientId != "" {
+		social.Register(&social.AppleProvider{ClientId: clientId})
+	}
+}
+
+// 初始化实名认证接口,未配置时实名认证均须人工审核
+func initKycVerifiers(ctx gof.App) {
+	gf := ctx.Config().GetString
+	if appCode := gf("kyc_aliyun_app_code"); appCode != "" {
+		v := &kyc.AliyunVerifier{
+			AppCode:     appCode,
+			IdentityUrl: gf("kyc_aliyun_identity_url"),
+			FaceUrl:     gf("kyc_aliyun_face_url"),
+		}
+		if v.IdentityUrl != "" {
+			kyc.SetIdentityVerifier(v)
+		}
+		if v.FaceUrl != "" {
+			kyc.SetFaceVerifier(v)
+		}
+	}
+}
+
 // RPC服务初始化
 func initRpcServe(ctx gof.App) {
 	gf := ctx.Config().GetString
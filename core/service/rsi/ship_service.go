@@ -10,20 +10,40 @@ package rsi
 
 import (
 	"go2o/core/domain/interface/delivery"
+	"go2o/core/domain/interface/express"
+	"go2o/core/domain/interface/merchant/shop"
+	"go2o/core/domain/interface/order"
 	"go2o/core/domain/interface/shipment"
+	"go2o/core/domain/interface/valueobject"
+	shipImpl "go2o/core/domain/shipment"
+	"go2o/core/infrastructure/domain"
+	"go2o/core/infrastructure/lbs"
+	"go2o/core/query"
 )
 
 type shipmentService struct {
 	_rep          shipment.IShipmentRepo
 	_deliveryRepo delivery.IDeliveryRepo
+	_orderRepo    order.IOrderRepo
+	_shopRepo     shop.IShopRepo
+	_expressRepo  express.IExpressRepo
+	_valueRepo    valueobject.IValueRepo
+	_orderQuery   *query.OrderQuery
 }
 
 // 获取快递服务
 func NewShipmentService(rep shipment.IShipmentRepo,
-	deliveryRepo delivery.IDeliveryRepo) *shipmentService {
+	deliveryRepo delivery.IDeliveryRepo, orderRepo order.IOrderRepo,
+	shopRepo shop.IShopRepo, expressRepo express.IExpressRepo,
+	valueRepo valueobject.IValueRepo, orderQuery *query.OrderQuery) *shipmentService {
 	return &shipmentService{
 		_rep:          rep,
 		_deliveryRepo: deliveryRepo,
+		_orderRepo:    orderRepo,
+		_shopRepo:     shopRepo,
+		_expressRepo:  expressRepo,
+		_valueRepo:    valueRepo,
+		_orderQuery:   orderQuery,
 	}
 }
 
@@ -41,3 +61,294 @@ func (s *shipmentService) GetShipOrderOfOrder(orderId int64) *shipment.ShipmentO
 	}
 	return nil
 }
+
+// 获取商户的寄件人信息,取第一个营业中的门店
+func (s *shipmentService) getSender(vendorId int32) *shipment.WaybillContact {
+	for _, v := range s._shopRepo.GetShopsOfMerchant(vendorId) {
+		if v.ShopType != shop.TypeOfflineShop || v.State != shop.StateNormal {
+			continue
+		}
+		if os := s._shopRepo.GetOfflineShop(v.Id); os != nil {
+			return &shipment.WaybillContact{
+				Name:    v.Name,
+				Phone:   os.Tel,
+				Area:    s._valueRepo.GetAreaString(os.Province, os.City, os.District),
+				Address: os.Address,
+			}
+		}
+	}
+	return nil
+}
+
+// 获取子订单的电子面单,vendorId为商户编号,大于0时校验订单是否属于该商户
+func (s *shipmentService) GetWaybill(vendorId int32, subOrderId int64) (*shipment.Waybill, error) {
+	so := s._orderRepo.Manager().GetSubOrder(subOrderId)
+	if so == nil || (vendorId > 0 && so.GetValue().VendorId != vendorId) {
+		return nil, order.ErrNoSuchOrder
+	}
+	list := s._rep.GetSubOrderShipOrders(subOrderId)
+	if len(list) == 0 {
+		return nil, shipment.ErrWaybillNotShipped
+	}
+	sv := list[0].Value()
+	sp := s._expressRepo.GetExpressProvider(sv.SpId)
+	if sp == nil {
+		return nil, express.ErrNotSupportProvider
+	}
+	co := so.Complex()
+	sender := s.getSender(co.VendorId)
+	if sender == nil {
+		return nil, shipment.ErrWaybillNoSender
+	}
+	w := &shipment.Waybill{
+		ShipOrderId: sv.ID,
+		OrderNo:     co.OrderNo,
+		SpId:        sp.Id,
+		SpName:      sp.Name,
+		SpCode:      sp.Code,
+		SpOrder:     sv.SpOrder,
+		Sender:      sender,
+		Receiver: &shipment.WaybillContact{
+			Name:    co.ConsigneePerson,
+			Phone:   co.ConsigneePhone,
+			Address: co.ShippingAddress,
+		},
+		Items:  []*shipment.WaybillItem{},
+		Remark: co.BuyerRemark,
+	}
+	for _, v := range s._orderQuery.QueryOrderItems(subOrderId) {
+		w.Items = append(w.Items, &shipment.WaybillItem{
+			Title:    v.GoodsTitle,
+			Quantity: int32(v.Quantity),
+		})
+	}
+	return w, nil
+}
+
+// 批量打印子订单的电子面单,format为面单格式,如:pdf,zpl
+func (s *shipmentService) PrintWaybills(vendorId int32, subOrderIds []int64, format string) ([]byte, error) {
+	list := make([]*shipment.Waybill, len(subOrderIds))
+	for i, id := range subOrderIds {
+		w, err := s.GetWaybill(vendorId, id)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = w
+	}
+	return shipImpl.RenderWaybills(format, list)
+}
+
+// 保存商户的配送员
+func (s *shipmentService) SaveDeliveryStaff(mchId int32, v *delivery.DeliveryStaff) (int32, error) {
+	return s._deliveryRepo.GetDelivery(mchId).SaveStaff(v)
+}
+
+// 获取商户的配送员
+func (s *shipmentService) GetDeliveryStaffList(mchId int32) []*delivery.DeliveryStaff {
+	return s._deliveryRepo.GetDelivery(mchId).GetStaffList()
+}
+
+// 配送员上线或离线
+func (s *shipmentService) SetDeliveryStaffState(mchId, staffId int32, online bool) error {
+	state := delivery.StaffOffline
+	if online {
+		state = delivery.StaffOnline
+	}
+	return s._deliveryRepo.GetDelivery(mchId).SetStaffState(staffId, state)
+}
+
+// 上报配送员位置
+func (s *shipmentService) ReportStaffLocation(mchId, staffId int32, lng, lat float64) error {
+	return s._deliveryRepo.GetDelivery(mchId).ReportLocation(staffId, lng, lat)
+}
+
+// 获取商户待派单的子订单,并解析收货地址的坐标
+func (s *shipmentService) getDispatchSubOrder(mchId int32, subOrderId int64) (
+	order.ISubOrder, float64, float64, error) {
+	so := s._orderRepo.Manager().GetSubOrder(subOrderId)
+	if so == nil || so.GetValue().VendorId != mchId {
+		return nil, 0, 0, order.ErrNoSuchOrder
+	}
+	v := so.GetValue()
+	if v.State < order.StatAwaitingShipment {
+		return nil, 0, 0, order.ErrOrderNotPickUp
+	}
+	if v.State != order.StatAwaitingShipment {
+		return nil, 0, 0, order.ErrUnusualOrderStat
+	}
+	// 无法解析坐标时,不校验送达位置且不能就近派单
+	lng, lat, err := lbs.GetLocation(so.Complex().ShippingAddress)
+	if err != nil {
+		lng, lat = 0, 0
+	}
+	return so, lng, lat, nil
+}
+
+// 派单给配送员
+func (s *shipmentService) DispatchSubOrder(mchId int32, subOrderId int64,
+	staffId int32) (*delivery.DispatchOrder, error) {
+	_, lng, lat, err := s.getDispatchSubOrder(mchId, subOrderId)
+	if err != nil {
+		return nil, err
+	}
+	dlv := s._deliveryRepo.GetDelivery(mchId)
+	d, err := dlv.Dispatch(subOrderId, staffId, lng, lat)
+	if err != nil {
+		return nil, err
+	}
+	return s.staffDispatchValue(d), nil
+}
+
+// 自动派单,mode为派单方式,如:delivery.AssignNearest
+func (s *shipmentService) AutoDispatchSubOrder(mchId int32, subOrderId int64,
+	mode int) (*delivery.DispatchOrder, error) {
+	_, lng, lat, err := s.getDispatchSubOrder(mchId, subOrderId)
+	if err != nil {
+		return nil, err
+	}
+	dlv := s._deliveryRepo.GetDelivery(mchId)
+	d, err := dlv.AutoDispatch(subOrderId, lng, lat, mode)
+	if err != nil {
+		return nil, err
+	}
+	return s.staffDispatchValue(d), nil
+}
+
+// 商户及配送员查看的配送单,不包含签收码.签收码仅由买家出示给配送员
+func (s *shipmentService) staffDispatchValue(d delivery.IDispatchOrder) *delivery.DispatchOrder {
+	v := d.GetValue()
+	v.DeliverCode = ""
+	return &v
+}
+
+// 获取子订单的配送单
+func (s *shipmentService) GetDispatchOfSubOrder(mchId int32, subOrderId int64) *delivery.DispatchOrder {
+	if d := s._deliveryRepo.GetDelivery(mchId).GetDispatchBySubOrder(subOrderId); d != nil {
+		return s.staffDispatchValue(d)
+	}
+	return nil
+}
+
+// 获取买家子订单的签收码,送达时出示给配送员
+func (s *shipmentService) GetDeliverCode(buyerId int64, subOrderId int64) (string, error) {
+	so := s._orderRepo.Manager().GetSubOrder(subOrderId)
+	if so == nil || so.GetValue().BuyerId != buyerId {
+		return "", order.ErrNoSuchOrder
+	}
+	d := s._deliveryRepo.GetDelivery(so.GetValue().VendorId).GetDispatchBySubOrder(subOrderId)
+	if d == nil {
+		return "", delivery.ErrNoSuchDispatch
+	}
+	v := d.GetValue()
+	if v.State == delivery.DispatchCancelled || v.State == delivery.DispatchDelivered {
+		return "", delivery.ErrDispatchState
+	}
+	return v.DeliverCode, nil
+}
+
+// 获取配送单的签到记录
+func (s *shipmentService) GetDispatchCheckIns(mchId, dispatchId int32) []*delivery.DispatchCheckIn {
+	if d := s._deliveryRepo.GetDelivery(mchId).GetDispatch(dispatchId); d != nil {
+		return d.GetCheckIns()
+	}
+	return []*delivery.DispatchCheckIn{}
+}
+
+func (s *shipmentService) getDispatch(mchId, dispatchId int32) (delivery.IDispatchOrder, error) {
+	d := s._deliveryRepo.GetDelivery(mchId).GetDispatch(dispatchId)
+	if d == nil {
+		return nil, delivery.ErrNoSuchDispatch
+	}
+	return d, nil
+}
+
+// 记录子订单的配送日志
+func (s *shipmentService) appendDispatchLog(d delivery.IDispatchOrder, message string) {
+	if so := s._orderRepo.Manager().GetSubOrder(d.GetValue().SubOrderId); so != nil {
+		so.AppendLog(order.LogSetup, true, message)
+	}
+}
+
+// 配送员接单
+func (s *shipmentService) AcceptDispatch(mchId, dispatchId, staffId int32, lng, lat float64) error {
+	d, err := s.getDispatch(mchId, dispatchId)
+	if err == nil {
+		if err = d.Accept(staffId, lng, lat); err == nil {
+			s.appendDispatchLog(d, "配送员已接单")
+		}
+	}
+	return err
+}
+
+// 配送员拒单
+func (s *shipmentService) RejectDispatch(mchId, dispatchId, staffId int32, remark string) error {
+	d, err := s.getDispatch(mchId, dispatchId)
+	if err == nil {
+		err = d.Reject(staffId, remark)
+	}
+	return err
+}
+
+// 配送员取货
+func (s *shipmentService) PickupDispatch(mchId, dispatchId, staffId int32, lng, lat float64) error {
+	d, err := s.getDispatch(mchId, dispatchId)
+	if err == nil {
+		if err = d.Pickup(staffId, lng, lat); err == nil {
+			s.appendDispatchLog(d, "配送员已取货,正在配送")
+		}
+	}
+	return err
+}
+
+// 配送员送达,image为签收照片,code为签收码.送达后子订单变为已发货,
+// 使用买家的签收码签收时,视为买家已收货.
+// 先校验子订单状态,子订单更新失败时恢复配送单,以便重新送达
+func (s *shipmentService) DeliverDispatch(mchId, dispatchId, staffId int32,
+	lng, lat float64, image, code string) error {
+	d, err := s.getDispatch(mchId, dispatchId)
+	if err != nil {
+		return err
+	}
+	origin := d.GetValue()
+	so := s._orderRepo.Manager().GetSubOrder(origin.SubOrderId)
+	if so == nil {
+		return order.ErrNoSuchOrder
+	}
+	if so.GetValue().State != order.StatAwaitingShipment {
+		return order.ErrUnusualOrderStat
+	}
+	proof := &delivery.DeliveryProof{Image: image, Code: code}
+	if err = d.Deliver(staffId, lng, lat, proof); err != nil {
+		return err
+	}
+	if err = so.LocalDelivered(); err != nil {
+		if _, err2 := s._deliveryRepo.SaveDispatchOrder(&origin); err2 != nil {
+			domain.HandleError(err2, "service")
+		}
+		return err
+	}
+	s.appendDispatchLog(d, "订单已送达")
+	// 子订单已发货,确认收货失败时由买家或自动确认收货完成
+	if code != "" {
+		err = so.BuyerReceived()
+	}
+	return err
+}
+
+// 配送途中签到
+func (s *shipmentService) CheckInDispatch(mchId, dispatchId, staffId int32, lng, lat float64) error {
+	d, err := s.getDispatch(mchId, dispatchId)
+	if err == nil {
+		err = d.CheckIn(staffId, lng, lat)
+	}
+	return err
+}
+
+// 取消配送
+func (s *shipmentService) CancelDispatch(mchId, dispatchId int32, remark string) error {
+	d, err := s.getDispatch(mchId, dispatchId)
+	if err == nil {
+		err = d.Cancel(remark)
+	}
+	return err
+}
@@ -13,10 +13,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"github.com/jsix/gof/util"
+	"go2o/core/domain/interface/audit"
 	"go2o/core/domain/interface/cart"
 	proItem "go2o/core/domain/interface/item"
 	"go2o/core/domain/interface/merchant"
 	"go2o/core/domain/interface/merchant/shop"
+	"go2o/core/domain/interface/merchant/user"
 	"go2o/core/domain/interface/order"
 	"go2o/core/domain/interface/product"
 	"go2o/core/dto"
@@ -499,10 +501,12 @@ func (s *shoppingService) GetOrderByNo(orderNo string) *order.ComplexOrder {
 	return nil
 }
 
-// 人工付款
-func (s *shoppingService) PayForOrderByManager(orderNo string) error {
+// 人工付款,actor为操作人
+func (s *shoppingService) PayForOrderByManager(orderNo string, actor *audit.Actor) error {
 	//todo: 对支付单进行人工付款
-	panic("应使用支付单进行人工付款")
+	auditLog(actor, "order.pay_by_manager", audit.TargetOrder, orderNo,
+		nil, nil, "rejected")
+	return order.ErrPayByManager
 	//o := s._manager.GetOrderByNo(orderNo)
 	//if o == nil {
 	//	return order.ErrNoSuchOrder
@@ -633,10 +637,21 @@ func (s *shoppingService) PickUp(orderNo string, sub bool) error {
 	return c.PickUp()
 }
 
-// 订单发货,并记录配送服务商编号及单号
-func (s *shoppingService) Ship(orderNo string, sub bool, spId int32, spOrder string) error {
-	c := s._manager.Unified(orderNo, sub)
-	return c.Ship(spId, spOrder)
+// 订单发货,并记录配送服务商编号及单号;
+// personId为操作的员工,为0表示商户本身操作
+func (s *shoppingService) Ship(mchId int32, personId int32, orderNo string, sub bool,
+	spId int32, spOrder string) error {
+	u := s._manager.Unified(orderNo, sub)
+	c := u.Complex()
+	if c == nil || c.VendorId != mchId {
+		return order.ErrNoSuchOrder
+	}
+	err := MerchantService.CheckStaffPermission(mchId, personId,
+		c.ShopId, user.PermOrderShip)
+	if err != nil {
+		return err
+	}
+	return u.Ship(spId, spOrder)
 }
 
 // 消费者收货
@@ -148,3 +148,17 @@ func ShopServeClient() (*define.ShopServiceClient, error) {
 	}
 	return nil, err
 }
+
+// 消息服务
+func MessageServeClient() (*define.MessageServiceClient, error) {
+	transport, protocol, err := getTransportAndProtocol()
+	if err == nil {
+		err = transport.Open()
+		if err == nil {
+			proto := protocol.GetProtocol(transport)
+			opProto := thrift.NewTMultiplexedProtocol(proto, "message")
+			return define.NewMessageServiceClientProtocol(transport, proto, opProto), err
+		}
+	}
+	return nil, err
+}
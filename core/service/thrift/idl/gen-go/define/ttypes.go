@@ -12466,3 +12466,374 @@ func (p *SsoApp) String() string {
 	}
 	return fmt.Sprintf("SsoApp(%+v)", *p)
 }
+
+// Attributes:
+//  - ID
+//  - MemberId
+//  - BroadcastId
+//  - Subject
+//  - Content
+//  - HasRead
+//  - ReadTime
+//  - CreateTime
+//  - ExpiresTime
+type InboxMessage struct {
+	ID          int64  `thrift:"ID,1" json:"ID"`
+	MemberId    int64  `thrift:"MemberId,2" json:"MemberId"`
+	BroadcastId int32  `thrift:"BroadcastId,3" json:"BroadcastId"`
+	Subject     string `thrift:"Subject,4" json:"Subject"`
+	Content     string `thrift:"Content,5" json:"Content"`
+	HasRead     int32  `thrift:"HasRead,6" json:"HasRead"`
+	ReadTime    int64  `thrift:"ReadTime,7" json:"ReadTime"`
+	CreateTime  int64  `thrift:"CreateTime,8" json:"CreateTime"`
+	ExpiresTime int64  `thrift:"ExpiresTime,9" json:"ExpiresTime"`
+}
+
+func NewInboxMessage() *InboxMessage {
+	return &InboxMessage{}
+}
+
+func (p *InboxMessage) GetID() int64 {
+	return p.ID
+}
+
+func (p *InboxMessage) GetMemberId() int64 {
+	return p.MemberId
+}
+
+func (p *InboxMessage) GetBroadcastId() int32 {
+	return p.BroadcastId
+}
+
+func (p *InboxMessage) GetSubject() string {
+	return p.Subject
+}
+
+func (p *InboxMessage) GetContent() string {
+	return p.Content
+}
+
+func (p *InboxMessage) GetHasRead() int32 {
+	return p.HasRead
+}
+
+func (p *InboxMessage) GetReadTime() int64 {
+	return p.ReadTime
+}
+
+func (p *InboxMessage) GetCreateTime() int64 {
+	return p.CreateTime
+}
+
+func (p *InboxMessage) GetExpiresTime() int64 {
+	return p.ExpiresTime
+}
+func (p *InboxMessage) Read(iprot thrift.TProtocol) error {
+	if _, err := iprot.ReadStructBegin(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read error: ", p), err)
+	}
+
+	for {
+		_, fieldTypeId, fieldId, err := iprot.ReadFieldBegin()
+		if err != nil {
+			return thrift.PrependError(fmt.Sprintf("%T field %d read error: ", p, fieldId), err)
+		}
+		if fieldTypeId == thrift.STOP {
+			break
+		}
+		switch fieldId {
+		case 1:
+			if err := p.readField1(iprot); err != nil {
+				return err
+			}
+		case 2:
+			if err := p.readField2(iprot); err != nil {
+				return err
+			}
+		case 3:
+			if err := p.readField3(iprot); err != nil {
+				return err
+			}
+		case 4:
+			if err := p.readField4(iprot); err != nil {
+				return err
+			}
+		case 5:
+			if err := p.readField5(iprot); err != nil {
+				return err
+			}
+		case 6:
+			if err := p.readField6(iprot); err != nil {
+				return err
+			}
+		case 7:
+			if err := p.readField7(iprot); err != nil {
+				return err
+			}
+		case 8:
+			if err := p.readField8(iprot); err != nil {
+				return err
+			}
+		case 9:
+			if err := p.readField9(iprot); err != nil {
+				return err
+			}
+		default:
+			if err := iprot.Skip(fieldTypeId); err != nil {
+				return err
+			}
+		}
+		if err := iprot.ReadFieldEnd(); err != nil {
+			return err
+		}
+	}
+	if err := iprot.ReadStructEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T read struct end error: ", p), err)
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField1(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 1: ", err)
+	} else {
+		p.ID = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField2(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 2: ", err)
+	} else {
+		p.MemberId = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField3(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 3: ", err)
+	} else {
+		p.BroadcastId = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField4(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 4: ", err)
+	} else {
+		p.Subject = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField5(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadString(); err != nil {
+		return thrift.PrependError("error reading field 5: ", err)
+	} else {
+		p.Content = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField6(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI32(); err != nil {
+		return thrift.PrependError("error reading field 6: ", err)
+	} else {
+		p.HasRead = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField7(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 7: ", err)
+	} else {
+		p.ReadTime = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField8(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 8: ", err)
+	} else {
+		p.CreateTime = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) readField9(iprot thrift.TProtocol) error {
+	if v, err := iprot.ReadI64(); err != nil {
+		return thrift.PrependError("error reading field 9: ", err)
+	} else {
+		p.ExpiresTime = v
+	}
+	return nil
+}
+
+func (p *InboxMessage) Write(oprot thrift.TProtocol) error {
+	if err := oprot.WriteStructBegin("InboxMessage"); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write struct begin error: ", p), err)
+	}
+	if err := p.writeField1(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField2(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField3(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField4(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField5(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField6(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField7(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField8(oprot); err != nil {
+		return err
+	}
+	if err := p.writeField9(oprot); err != nil {
+		return err
+	}
+	if err := oprot.WriteFieldStop(); err != nil {
+		return thrift.PrependError("write field stop error: ", err)
+	}
+	if err := oprot.WriteStructEnd(); err != nil {
+		return thrift.PrependError("write struct stop error: ", err)
+	}
+	return nil
+}
+
+func (p *InboxMessage) writeField1(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("ID", thrift.I64, 1); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 1:ID: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.ID)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.ID (1) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 1:ID: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) writeField2(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("MemberId", thrift.I64, 2); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 2:MemberId: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.MemberId)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.MemberId (2) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 2:MemberId: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) writeField3(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("BroadcastId", thrift.I32, 3); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 3:BroadcastId: ", p), err)
+	}
+	if err := oprot.WriteI32(int32(p.BroadcastId)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.BroadcastId (3) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 3:BroadcastId: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) writeField4(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("Subject", thrift.STRING, 4); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 4:Subject: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Subject)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.Subject (4) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 4:Subject: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) writeField5(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("Content", thrift.STRING, 5); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 5:Content: ", p), err)
+	}
+	if err := oprot.WriteString(string(p.Content)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.Content (5) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 5:Content: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) writeField6(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("HasRead", thrift.I32, 6); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 6:HasRead: ", p), err)
+	}
+	if err := oprot.WriteI32(int32(p.HasRead)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.HasRead (6) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 6:HasRead: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) writeField7(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("ReadTime", thrift.I64, 7); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 7:ReadTime: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.ReadTime)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.ReadTime (7) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 7:ReadTime: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) writeField8(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("CreateTime", thrift.I64, 8); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 8:CreateTime: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.CreateTime)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.CreateTime (8) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 8:CreateTime: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) writeField9(oprot thrift.TProtocol) (err error) {
+	if err := oprot.WriteFieldBegin("ExpiresTime", thrift.I64, 9); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field begin error 9:ExpiresTime: ", p), err)
+	}
+	if err := oprot.WriteI64(int64(p.ExpiresTime)); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T.ExpiresTime (9) field write error: ", p), err)
+	}
+	if err := oprot.WriteFieldEnd(); err != nil {
+		return thrift.PrependError(fmt.Sprintf("%T write field end error 9:ExpiresTime: ", p), err)
+	}
+	return err
+}
+
+func (p *InboxMessage) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("InboxMessage(%+v)", *p)
+}
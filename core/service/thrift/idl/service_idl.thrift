@@ -435,6 +435,21 @@ struct SsoApp{
     4: string Token
 }
 
+// 站内信
+struct InboxMessage{
+    1: i64 ID
+    2: i64 MemberId
+    // 广播消息编号,为0时为单独发送的消息
+    3: i32 BroadcastId
+    4: string Subject
+    5: string Content
+    6: i32 HasRead
+    7: i64 ReadTime
+    8: i64 CreateTime
+    // 过期时间,为0时不过期
+    9: i64 ExpiresTime
+}
+
 // 基础服务
 service FoundationService{
    // 格式化资源地址并返回
@@ -603,4 +618,19 @@ service ShopService{
     Result TurnShop(1:i32 shopId,2:bool on,3:string reason)
     // 设置商店是否营业
     Result OpenShop(1:i32 shopId,2:bool opening,3:string reason)
+}
+
+// 消息服务
+service MessageService{
+    // 获取会员的站内信,begin和size用于分页
+    list<InboxMessage> GetInbox(1:i64 memberId,2:bool unreadOnly,3:i32 begin,4:i32 size)
+    // 获取未读站内信数量
+    i32 InboxUnread(1:i64 memberId)
+    // 标记站内信已读,ids为空时标记全部
+    Result InboxRead(1:i64 memberId,2:list<i64> ids)
+    // 删除站内信
+    Result InboxDelete(1:i64 memberId,2:list<i64> ids)
+    // 发送广播站内信,segment为JSON格式的目标会员,如:{"levels":[1,2],"regions":[310000]};
+    // expiresTime为过期时间,为0时不过期
+    Result Broadcast(1:string subject,2:string content,3:string segment,4:i64 expiresTime)
 }
\ No newline at end of file
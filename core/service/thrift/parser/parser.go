@@ -15,6 +15,7 @@ import (
 	"go2o/core/domain/interface/item"
 	"go2o/core/domain/interface/member"
 	"go2o/core/domain/interface/merchant"
+	"go2o/core/domain/interface/mss/inbox"
 	"go2o/core/domain/interface/order"
 	"go2o/core/domain/interface/payment"
 	"go2o/core/domain/interface/valueobject"
@@ -635,3 +636,17 @@ func OrderItem(src *define.ComplexItem) *order.ComplexItem {
 		Data:           src.Data,
 	}
 }
+
+func InboxMessageDto(src *inbox.Message) *define.InboxMessage {
+	return &define.InboxMessage{
+		ID:          src.Id,
+		MemberId:    src.MemberId,
+		BroadcastId: src.BroadcastId,
+		Subject:     src.Subject,
+		Content:     src.Content,
+		HasRead:     src.HasRead,
+		ReadTime:    src.ReadTime,
+		CreateTime:  src.CreateTime,
+		ExpiresTime: src.ExpiresTime,
+	}
+}
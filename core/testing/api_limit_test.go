@@ -0,0 +1,72 @@
+package testing
+
+import (
+	"go2o/core/domain/interface/merchant"
+	"go2o/core/module"
+	"go2o/core/testing/ti"
+	"testing"
+	"time"
+)
+
+func newApiLimitModule() *module.ApiLimitModule {
+	m := &module.ApiLimitModule{}
+	m.SetApp(ti.GetApp())
+	m.Init()
+	return m
+}
+
+// 测试令牌桶限流
+func TestApiLimitRate(t *testing.T) {
+	m := newApiLimitModule()
+	mchId := int32(time.Now().Unix() % 1000000)
+	l := &merchant.ApiLimit{MchId: mchId, Group: merchant.ApiGroupDefault,
+		Rate: 1, Burst: 2}
+	for i := 0; i < 2; i++ {
+		r, err := m.Take(l)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if !r.Allowed {
+			t.Error("burst request should be allowed:", i)
+		}
+	}
+	r, _ := m.Take(l)
+	if r.Allowed || r.QuotaExceeded || r.RetryAfter <= 0 {
+		t.Errorf("request over burst should be limited: %#v", r)
+	}
+	time.Sleep(r.RetryAfter)
+	if r, _ = m.Take(l); !r.Allowed {
+		t.Error("token should be refilled after retry duration")
+	}
+}
+
+// 测试每日配额及未设置限流
+func TestApiLimitQuota(t *testing.T) {
+	m := newApiLimitModule()
+	mchId := int32(time.Now().Unix()%1000000) + 1000000
+	l := &merchant.ApiLimit{MchId: mchId, Group: merchant.ApiGroupMember,
+		Rate: merchant.DefaultApiRate, Burst: merchant.DefaultApiBurst,
+		DailyQuota: merchant.DefaultApiDailyQuota}
+	// 默认不限制请求
+	for i := 0; i < 50; i++ {
+		if r, _ := m.Take(l); !r.Allowed {
+			t.Error("default limit should not throttle requests")
+			t.FailNow()
+		}
+	}
+	l.Group = merchant.ApiGroupGet
+	l.DailyQuota = 3
+	for i := 0; i < 3; i++ {
+		if r, _ := m.Take(l); !r.Allowed {
+			t.Error("request in quota should be allowed:", i)
+		}
+	}
+	r, _ := m.Take(l)
+	if r.Allowed || !r.QuotaExceeded || r.Used != 3 {
+		t.Errorf("request over quota should be rejected: %#v", r)
+	}
+	if u := m.GetUsage(mchId, time.Now().Format("20060102")); u[merchant.ApiGroupMember] != 50 {
+		t.Error("usage should be counted, got ", u)
+	}
+}
@@ -0,0 +1,54 @@
+package testing
+
+import (
+	auditImpl "go2o/core/domain/audit"
+	"go2o/core/domain/interface/audit"
+	"go2o/core/domain/interface/member"
+	"go2o/core/testing/ti"
+	"testing"
+)
+
+// 测试记录审计日志,并检测被篡改的日志
+func TestAuditLogChain(t *testing.T) {
+	a := auditImpl.NewAuditor(ti.AuditRepo, []byte("test-audit-key"))
+	actor := &audit.Actor{Type: audit.ActorAdmin, Id: 1, Role: "finance", Ip: "127.0.0.1"}
+	before := member.Account{Balance: 10}
+	after := member.Account{Balance: 20}
+	l1, err := a.Record(actor, "member_account.charge", audit.TargetMemberAccount,
+		"1", before, after, "test")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	l2, err := a.Record(nil, "member.lock", audit.TargetMember, "1",
+		map[string]int{"state": 1}, map[string]int{"state": 0}, "")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if l2.PrevHash != l1.Hash || l2.ActorType != audit.ActorSystem {
+		t.Error("log not chained")
+		t.FailNow()
+	}
+	if _, err = a.Verify(l1.Id, 2); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	// 不知道密钥时无法伪造日志链
+	if _, err = auditImpl.NewAuditor(ti.AuditRepo, []byte("other")).Verify(l1.Id, 2); err != audit.ErrChainBroken {
+		t.Error("log should not be verified with other key")
+	}
+	total, list := ti.AuditRepo.QueryByTarget(audit.TargetMemberAccount, "1", 0, 10)
+	if total == 0 || len(list) == 0 {
+		t.Error("query by target failed")
+		t.FailNow()
+	}
+	// 修改日志后校验失败,日志仅能新增,故直接修改数据
+	db := ti.GetApp().Db()
+	db.ExecNonQuery("UPDATE sys_audit_log SET diff=? WHERE id=?",
+		`{"Balance":[10,100]}`, l1.Id)
+	if id, err := a.Verify(l1.Id, 2); err != audit.ErrChainBroken || id != l1.Id {
+		t.Error("tampered log not detected")
+	}
+	db.ExecNonQuery("UPDATE sys_audit_log SET diff=? WHERE id=?", l1.Diff, l1.Id)
+}
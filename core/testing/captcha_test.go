@@ -0,0 +1,28 @@
+package testing
+
+import (
+	"go2o/core/module"
+	"go2o/core/testing/ti"
+	"testing"
+)
+
+// 测试未配置字体时使用内置字体生成验证码
+func TestCaptchaChallenge(t *testing.T) {
+	m := &module.CaptchaModule{}
+	m.SetApp(ti.GetApp())
+	m.Init()
+	ch, err := m.NewChallenge()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if len(ch.Image) == 0 {
+		t.Error("captcha image is empty")
+	}
+	if err = m.Verify(ch.Id, "0000x"); err != module.ErrCaptchaCode {
+		t.Error("wrong code should be rejected, got:", err)
+	}
+	if err = m.Verify(ch.Id, ""); err != module.ErrCaptchaRequired {
+		t.Error("empty code should be required, got:", err)
+	}
+}
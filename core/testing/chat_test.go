@@ -0,0 +1,154 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : chat_test.go
+ * author : jarryliu
+ * date : 2026-10-22 15:00
+ * description :
+ * history :
+ */
+package testing
+
+import (
+	"go2o/core/domain/interface/mss"
+	"go2o/core/domain/interface/mss/chat"
+	chatImpl "go2o/core/domain/mss/chat"
+	"go2o/core/repository"
+	"go2o/core/testing/ti"
+	"testing"
+)
+
+// 测试会话分配给空闲的客服,客服有空闲后接入等待中的会话
+func TestChatRouting(t *testing.T) {
+	var mchId int32 = 1
+	var agent1, agent2 int32 = 9001, 9002
+	mgr := chatImpl.NewChatManager(repository.NewChatRepo(ti.GetApp().Db()))
+	mgr.SetAgentState(mchId, agent1, chat.AgentOnline, 1)
+	mgr.SetAgentState(mchId, agent2, chat.AgentOnline, 1)
+	defer mgr.SetAgentState(mchId, agent1, chat.AgentOffline, 0)
+	defer mgr.SetAgentState(mchId, agent2, chat.AgentOffline, 0)
+
+	members := []int64{8001, 8002, 8003}
+	sessions := make([]*chat.Session, len(members))
+	for i, v := range members {
+		s, err := mgr.OpenSession(v, mchId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer mgr.Close(s.Id, mss.RoleMember, v)
+		sessions[i] = s
+	}
+	if sessions[0].AgentId == sessions[1].AgentId || sessions[0].AgentId <= 0 {
+		t.Fatal("sessions should be assigned to different agents")
+	}
+	if sessions[2].State != chat.SessionWaiting {
+		t.Fatal("no agent available, session should be waiting")
+	}
+	// 重复发起时返回未结束的会话
+	if s, _ := mgr.OpenSession(members[0], mchId); s.Id != sessions[0].Id {
+		t.Fatal("expect active session", sessions[0].Id, "but", s.Id)
+	}
+
+	// 客服结束会话后接入等待中的会话
+	s := sessions[0]
+	if err := mgr.Close(s.Id, mss.RoleMerchant, int64(s.AgentId)); err != nil {
+		t.Fatal(err)
+	}
+	if v := mgr.GetSession(sessions[2].Id); v.State != chat.SessionServing ||
+		v.AgentId != s.AgentId {
+		t.Fatalf("waiting session should be assigned to agent %d, %#v", s.AgentId, v)
+	}
+}
+
+// 测试消息发送,历史消息及已读回执
+func TestChatMessages(t *testing.T) {
+	var mchId int32 = 1
+	var memberId int64 = 8011
+	var agentId int32 = 9011
+	mgr := chatImpl.NewChatManager(repository.NewChatRepo(ti.GetApp().Db()))
+	mgr.SetAgentState(mchId, agentId, chat.AgentOnline, 100)
+	defer mgr.SetAgentState(mchId, agentId, chat.AgentOffline, 0)
+	s, err := mgr.OpenSession(memberId, mchId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Close(s.Id, mss.RoleMember, memberId)
+
+	if _, err = mgr.Send(s.Id, mss.RoleMember, memberId+1, chat.MsgText, "hi"); err != chat.ErrNotSessionUser {
+		t.Fatal("other member can't send to the session")
+	}
+	if _, err = mgr.Send(s.Id, mss.RoleMember, memberId, chat.MsgProduct, `{"itemId":0}`); err != chat.ErrIncorrectCard {
+		t.Fatal("expect incorrect card, but", err)
+	}
+	if _, err = mgr.Send(s.Id, mss.RoleMember, memberId, chat.MsgOrder,
+		`{"orderNo":"100001","amount":12.5}`); err != nil {
+		t.Fatal(err)
+	}
+	m, err := mgr.Send(s.Id, mss.RoleMember, memberId, chat.MsgText, " 请问什么时候发货? ")
+	if err != nil || m.Content != "请问什么时候发货?" {
+		t.Fatal(m, err)
+	}
+	if v := mgr.GetSession(s.Id); v.AgentUnread != 2 {
+		t.Fatal("agent unread should be 2, but", v.AgentUnread)
+	}
+	// 客服已读到第一条消息
+	list := mgr.GetMessages(s.Id, m.Id, 10)
+	if len(list) == 0 || list[0].MsgType != chat.MsgOrder {
+		t.Fatal("expect order card before message", m.Id)
+	}
+	mgr.MarkRead(s.Id, mss.RoleMerchant, int64(agentId), list[0].Id)
+	if v := mgr.GetSession(s.Id); v.AgentUnread != 1 || v.AgentReadId != list[0].Id {
+		t.Fatalf("read receipt: %#v", v)
+	}
+	if _, err = mgr.Send(s.Id, mss.RoleMerchant, int64(agentId), chat.MsgText, "今天发货"); err != nil {
+		t.Fatal(err)
+	}
+	mgr.MarkRead(s.Id, mss.RoleMember, memberId, 0)
+	if v := mgr.GetSession(s.Id); v.MemberUnread != 0 || v.MemberReadId != v.LastMsgId {
+		t.Fatalf("member should read all messages: %#v", v)
+	}
+}
+
+// 测试未通过检查的客服不分配会话,转接时检查客服的接待数量
+func TestChatAgentCheckAndTransfer(t *testing.T) {
+	var mchId int32 = 1
+	var agent1, agent2 int32 = 9011, 9012
+	mgr := chatImpl.NewChatManager(repository.NewChatRepo(ti.GetApp().Db()))
+	// 模拟客服2的权限已被收回
+	revoked := true
+	mgr.SetAgentChecker(func(mchId int32, personId int32) bool {
+		return !(revoked && personId == agent2)
+	})
+	mgr.SetAgentState(mchId, agent1, chat.AgentOnline, 1)
+	mgr.SetAgentState(mchId, agent2, chat.AgentOnline, 1)
+	defer mgr.SetAgentState(mchId, agent1, chat.AgentOffline, 0)
+	defer mgr.SetAgentState(mchId, agent2, chat.AgentOffline, 0)
+
+	members := []int64{8011, 8012}
+	sessions := make([]*chat.Session, len(members))
+	for i, v := range members {
+		s, err := mgr.OpenSession(v, mchId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer mgr.Close(s.Id, mss.RoleMember, v)
+		sessions[i] = s
+	}
+	if sessions[0].AgentId != agent1 {
+		t.Fatal("session should be assigned to agent", agent1)
+	}
+	if sessions[1].State != chat.SessionWaiting {
+		t.Fatal("revoked agent should not be assigned")
+	}
+	if err := mgr.Transfer(sessions[0].Id, agent1, agent2); err != chat.ErrAgentOffline {
+		t.Fatal("transfer to revoked agent should fail, got:", err)
+	}
+	// 恢复权限后接入等待中的会话,客服2已满时不能转接
+	revoked = false
+	mgr.SetAgentState(mchId, agent2, chat.AgentOnline, 1)
+	if v := mgr.GetSession(sessions[1].Id); v.AgentId != agent2 {
+		t.Fatalf("waiting session should be assigned to agent %d, %#v", agent2, v)
+	}
+	if err := mgr.Transfer(sessions[0].Id, agent1, agent2); err != chat.ErrAgentFull {
+		t.Fatal("transfer to full agent should fail, got:", err)
+	}
+}
@@ -0,0 +1,130 @@
+package testing
+
+import (
+	"errors"
+	"go2o/core/module"
+	"go2o/core/testing/ti"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 创建延迟队列,最大投递2次,首次重试间隔2秒,可见超时1秒
+func newTestDelayQueue() *module.DelayQueueModule {
+	app := ti.GetApp()
+	app.Config().Set("delay_max_attempts", "2")
+	app.Config().Set("delay_retry_base", "2")
+	app.Config().Set("delay_visibility", "1")
+	q := &module.DelayQueueModule{}
+	q.SetApp(app)
+	q.Init()
+	app.Config().Set("delay_max_attempts", "")
+	app.Config().Set("delay_retry_base", "")
+	app.Config().Set("delay_visibility", "")
+	return q
+}
+
+func newTestDelayTopic(name string) string {
+	return "test." + name + "." + strconv.Itoa(int(time.Now().UnixNano()%1000000))
+}
+
+// 测试任务失败后按退避间隔重试,超过最大次数后移入死信
+func TestDelayQueueRetryAndDead(t *testing.T) {
+	q := newTestDelayQueue()
+	topic := newTestDelayTopic("retry")
+	var calls int32
+	q.Handle(topic, func(j *module.DelayJob) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("handle failed")
+	})
+	if err := q.Schedule(topic, "1", "", time.Now()); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	id := topic + ":1"
+	defer q.Cancel(topic, "1")
+	q.Consume(100)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Error("job should be delivered once, got:", n)
+		t.FailNow()
+	}
+	// 退避时间内不重试
+	q.Consume(100)
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Error("job should not retry before backoff, got:", n)
+	}
+	if j := q.GetJob(id); j == nil || j.Error != "handle failed" {
+		t.Error("failed job should keep last error, got:", j)
+		t.FailNow()
+	}
+	time.Sleep(time.Millisecond * 2100)
+	q.Consume(100)
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Error("job should retry after backoff, got:", n)
+		t.FailNow()
+	}
+	_, list, _ := q.Jobs(module.DelayDead, 0, 1000)
+	dead := false
+	for _, j := range list {
+		if j.Id == id {
+			dead = j.Attempts == 2
+		}
+	}
+	if !dead {
+		t.Error("job should be dead lettered after max attempts")
+		t.FailNow()
+	}
+	// 重新执行死信任务
+	q.Handle(topic, func(j *module.DelayJob) error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	})
+	if err := q.RetryDead(id); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	q.Consume(100)
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Error("retried dead job should be delivered, got:", n)
+	}
+	if q.GetJob(id) != nil {
+		t.Error("acked job should be removed")
+	}
+}
+
+// 测试领取后未确认的任务在可见超时后重新投递
+func TestDelayQueueVisibilityTimeout(t *testing.T) {
+	q := newTestDelayQueue()
+	topic := newTestDelayTopic("visibility")
+	var calls int32
+	release := make(chan bool)
+	q.Handle(topic, func(j *module.DelayJob) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// 模拟处理超时的节点
+			<-release
+		}
+		return nil
+	})
+	if err := q.Schedule(topic, "1", "", time.Now()); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer q.Cancel(topic, "1")
+	done := make(chan bool)
+	go func() {
+		q.Consume(100)
+		done <- true
+	}()
+	time.Sleep(time.Millisecond * 2100)
+	q.Consume(100)
+	close(release)
+	<-done
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Error("job should be redelivered after visibility timeout, got:", n)
+		t.FailNow()
+	}
+	if q.GetJob(topic+":1") != nil {
+		t.Error("redelivered job should be removed after ack")
+	}
+}
@@ -0,0 +1,73 @@
+package testing
+
+import (
+	"go2o/core/domain/interface/delivery"
+	"go2o/core/testing/ti"
+	"testing"
+)
+
+// 测试同城配送派单及配送流程
+func TestDispatchSubOrder(t *testing.T) {
+	var mchId int32 = 1
+	var subOrderId int64 = 10
+	dlv := ti.DeliveryRepo.GetDelivery(mchId)
+	for _, v := range dlv.GetStaffList() {
+		dlv.SetStaffState(v.PersonId, delivery.StaffOnline)
+		dlv.ReportLocation(v.PersonId, 113.3246, 23.1291)
+	}
+	d, err := dlv.AutoDispatch(subOrderId, 113.3245, 23.1292, delivery.AssignNearest)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	v := d.GetValue()
+	t.Logf("dispatch to staff %d, code:%s", v.StaffId, v.DeliverCode)
+	if err = d.Accept(v.StaffId, 113.3246, 23.1291); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if err = d.Pickup(v.StaffId, 113.3246, 23.1291); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	// 签收码错误
+	err = d.Deliver(v.StaffId, 113.3245, 23.1292,
+		&delivery.DeliveryProof{Code: "x"})
+	if err != delivery.ErrDeliveryCode {
+		t.Error("deliver code not checked")
+		t.FailNow()
+	}
+	err = d.Deliver(v.StaffId, 113.3245, 23.1292,
+		&delivery.DeliveryProof{Code: v.DeliverCode})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	t.Logf("check ins: %d", len(d.GetCheckIns()))
+}
+
+// 测试取消配送后重新派单
+func TestRedispatchCancelled(t *testing.T) {
+	var mchId int32 = 1
+	var subOrderId int64 = 11
+	dlv := ti.DeliveryRepo.GetDelivery(mchId)
+	d, err := dlv.AutoDispatch(subOrderId, 0, 0, delivery.AssignRoundRobin)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	code := d.GetValue().DeliverCode
+	if err = d.Cancel("顾客改约"); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	d, err = dlv.AutoDispatch(subOrderId, 0, 0, delivery.AssignRoundRobin)
+	if err != nil {
+		t.Error("cancelled dispatch should be re-dispatched:", err)
+		t.FailNow()
+	}
+	v := d.GetValue()
+	if v.State != delivery.DispatchAssigned || v.DeliverCode == code {
+		t.Error("re-dispatch should reset state and deliver code")
+	}
+}
@@ -0,0 +1,145 @@
+package testing
+
+import (
+	"errors"
+	"fmt"
+	eventImpl "go2o/core/domain/event"
+	"go2o/core/domain/interface/event"
+	"go2o/core/testing/ti"
+	"testing"
+	"time"
+)
+
+func saveTestEvent(t *testing.T, orderNo string, state int32) int64 {
+	id, err := ti.EventRepo.SaveEvent(&event.Event{
+		Type:          event.OrderEventType(state),
+		AggregateType: event.AggregateOrder,
+		AggregateId:   orderNo,
+		Data:          fmt.Sprintf(`{"orderNo":"%s","state":%d}`, orderNo, state),
+		CreateTime:    time.Now().Unix(),
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	return id
+}
+
+// 测试按顺序发布事件,处理失败后重新发布及从指定位置重放
+func TestEventBusRelayAndReplay(t *testing.T) {
+	bus := eventImpl.NewEventBus(ti.EventRepo)
+	consumer := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	received := []int64{}
+	fail := true
+	err := bus.Subscribe(consumer, []string{event.OrderPaid, event.OrderShipped},
+		func(e *event.Event) error {
+			if e.Type == event.OrderShipped && fail {
+				fail = false
+				return errors.New("shipped failed")
+			}
+			received = append(received, e.Id)
+			return nil
+		})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if bus.Subscribe(consumer, nil, nil) != event.ErrConsumerExists {
+		t.Error("duplicate consumer subscribed")
+	}
+	orderNo := fmt.Sprintf("T%d", time.Now().Unix())
+	id1 := saveTestEvent(t, orderNo, 1) // 已提交,未订阅
+	id2 := saveTestEvent(t, orderNo, 2) // 已支付
+	id3 := saveTestEvent(t, orderNo, 6) // 已发货
+	// 首次发布时发货事件处理失败,位置停留在支付事件
+	bus.Relay(100)
+	if len(received) != 1 || received[0] != id2 || bus.GetOffset(consumer) != id2 {
+		t.Errorf("relay failed, received %v, offset %d", received, bus.GetOffset(consumer))
+		t.FailNow()
+	}
+	bus.Relay(100)
+	if len(received) != 2 || received[1] != id3 {
+		t.Errorf("retry failed, received %v", received)
+		t.FailNow()
+	}
+	// 重放
+	if err = bus.Replay(consumer, id1); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	received = received[:0]
+	bus.Relay(100)
+	if len(received) != 2 || received[0] != id2 || received[1] != id3 {
+		t.Errorf("replay failed, received %v", received)
+	}
+	total, _ := ti.EventRepo.QueryByAggregate(event.AggregateOrder, orderNo, 0, 10)
+	if total != 3 {
+		t.Errorf("expect 3 events, but %d", total)
+	}
+}
+
+// 测试事件处理失败达到上限后移入死信,并继续处理后续事件
+func TestEventBusDeadLetter(t *testing.T) {
+	bus := eventImpl.NewEventBus(ti.EventRepo)
+	consumer := fmt.Sprintf("test_dead_%d", time.Now().UnixNano())
+	received := []int64{}
+	err := bus.Subscribe(consumer, nil, func(e *event.Event) error {
+		if e.Type == event.OrderPaid {
+			return errors.New("paid failed")
+		}
+		received = append(received, e.Id)
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	orderNo := fmt.Sprintf("D%d", time.Now().Unix())
+	id1 := saveTestEvent(t, orderNo, 2) // 已支付,始终失败
+	id2 := saveTestEvent(t, orderNo, 6) // 已发货
+	for i := 1; i < 5; i++ {
+		bus.Relay(100)
+		if len(received) != 0 || bus.GetOffset(consumer) == id1 {
+			t.Errorf("event should retry before dead letter, received %v", received)
+			t.FailNow()
+		}
+	}
+	bus.Relay(100)
+	if len(received) != 1 || received[0] != id2 || bus.GetOffset(consumer) != id2 {
+		t.Errorf("dead event should be skipped, received %v", received)
+		t.FailNow()
+	}
+	total, list := ti.EventRepo.QueryDeadEvents(consumer, 0, 10)
+	if total != 1 || list[0].EventId != id1 || list[0].Attempts != 5 {
+		t.Errorf("dead event not saved, total %d", total)
+	}
+}
+
+// 测试编号不连续时等待较小编号的事务提交
+func TestEventBusWaitGap(t *testing.T) {
+	bus := eventImpl.NewEventBus(ti.EventRepo)
+	consumer := fmt.Sprintf("test_gap_%d", time.Now().UnixNano())
+	received := []int64{}
+	bus.Subscribe(consumer, nil, func(e *event.Event) error {
+		received = append(received, e.Id)
+		return nil
+	})
+	orderNo := fmt.Sprintf("G%d", time.Now().Unix())
+	id1 := saveTestEvent(t, orderNo, 2)
+	id2 := saveTestEvent(t, orderNo, 6)
+	// 模拟尚未提交的事务
+	db := ti.GetApp().Db()
+	db.ExecNonQuery("DELETE FROM sys_event_outbox WHERE id=?", id1)
+	bus.Relay(100)
+	if len(received) != 0 {
+		t.Errorf("event after gap should wait, received %v", received)
+		t.FailNow()
+	}
+	// 超过等待时间视为事务已回滚
+	db.ExecNonQuery("UPDATE sys_event_outbox SET create_time=? WHERE id=?",
+		time.Now().Unix()-60, id2)
+	bus.Relay(100)
+	if len(received) != 1 || received[0] != id2 {
+		t.Errorf("event should be relayed after gap timeout, received %v", received)
+	}
+}
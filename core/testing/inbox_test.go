@@ -0,0 +1,129 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : inbox_test.go
+ * author : jarryliu
+ * date : 2026-10-23 15:00
+ * description :
+ * history :
+ */
+package testing
+
+import (
+	"go2o/core/domain/interface/mss/inbox"
+	inboxImpl "go2o/core/domain/mss/inbox"
+	"go2o/core/repository"
+	"go2o/core/testing/ti"
+	"testing"
+	"time"
+)
+
+// 测试广播消息按标签定向同步到收件箱,以及已读和撤回
+func TestInboxBroadcast(t *testing.T) {
+	var memberId int64 = 1
+	mgr := inboxImpl.NewInboxManager(repository.NewInboxRepo(ti.GetApp().Db()))
+	if err := mgr.SetMemberTags(memberId, []string{" inbox_test ", "inbox_test"}); err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.SetMemberTags(memberId, nil)
+	if tags := mgr.GetMemberTags(memberId); len(tags) != 1 {
+		t.Fatal("tags should be trimmed and distinct", tags)
+	}
+	unread := mgr.UnreadCount(memberId)
+
+	expires := time.Now().Unix() + 3600
+	b, err := mgr.Broadcast("测试广播", "广播内容",
+		&inbox.Segment{Tags: []string{"inbox_test"}}, expires)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Revoke(b.Id)
+	other, _ := mgr.Broadcast("其他会员", "广播内容",
+		&inbox.Segment{Tags: []string{"inbox_other"}}, expires)
+	defer mgr.Revoke(other.Id)
+
+	if n := mgr.UnreadCount(memberId); n != unread+1 {
+		t.Fatalf("expect unread %d, but %d", unread+1, n)
+	}
+	_, list := mgr.GetMessages(memberId, true, 0, inbox.MaxPageSize)
+	var msg *inbox.Message
+	for _, v := range list {
+		if v.BroadcastId == other.Id {
+			t.Fatal("broadcast should not be synced to member", other.Id)
+		}
+		if v.BroadcastId == b.Id {
+			msg = v
+		}
+	}
+	if msg == nil {
+		t.Fatal("broadcast not synced to inbox", b.Id)
+	}
+	mgr.MarkRead(memberId, []int64{msg.Id})
+	if n := mgr.UnreadCount(memberId); n != unread {
+		t.Fatalf("expect unread %d, but %d", unread, n)
+	}
+	if err = mgr.Revoke(b.Id); err != nil {
+		t.Fatal(err)
+	}
+	if mgr.GetMessage(memberId, msg.Id) != nil {
+		t.Fatal("revoked broadcast should be removed from inbox")
+	}
+}
+
+// 测试单独发送的消息,过期时间及删除
+func TestInboxSend(t *testing.T) {
+	var memberId int64 = 1
+	mgr := inboxImpl.NewInboxManager(repository.NewInboxRepo(ti.GetApp().Db()))
+	unix := time.Now().Unix()
+	if _, err := mgr.Send(memberId, "过期消息", "内容", unix-1); err != inbox.ErrExpiresTime {
+		t.Fatal("expect expires time error, but", err)
+	}
+	if _, err := mgr.Send(memberId, " ", "内容", 0); err != inbox.ErrEmptySubject {
+		t.Fatal("expect empty subject error, but", err)
+	}
+	m, err := mgr.Send(memberId, "订单已发货", "您的订单已发货", unix+60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := mgr.GetMessage(memberId+1, m.Id); v != nil {
+		t.Fatal("message should not be accessed by other member")
+	}
+	mgr.Delete(memberId, []int64{m.Id})
+	if v := mgr.GetMessage(memberId, m.Id); v != nil {
+		t.Fatal("message should be deleted")
+	}
+}
+
+// 测试广播消息重复同步时只保存一份
+func TestInboxBroadcastDistinct(t *testing.T) {
+	var memberId int64 = 1
+	rep := repository.NewInboxRepo(ti.GetApp().Db())
+	mgr := inboxImpl.NewInboxManager(rep)
+	b, err := mgr.Broadcast("重复同步", "广播内容",
+		&inbox.Segment{Tags: []string{"inbox_distinct"}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.Revoke(b.Id)
+	for i := 0; i < 2; i++ {
+		err = rep.SaveBroadcastMessage(&inbox.Message{
+			MemberId:    memberId,
+			BroadcastId: b.Id,
+			Subject:     b.Subject,
+			Content:     b.Content,
+			CreateTime:  b.CreateTime,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, list := mgr.GetMessages(memberId, false, 0, inbox.MaxPageSize)
+	n := 0
+	for _, v := range list {
+		if v.BroadcastId == b.Id {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("broadcast should be saved once, but %d", n)
+	}
+}
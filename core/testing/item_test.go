@@ -2,6 +2,7 @@ package testing
 
 import (
 	"go2o/core/domain/interface/item"
+	itemImpl "go2o/core/domain/item"
 	"go2o/core/testing/ti"
 	"testing"
 )
@@ -89,3 +90,68 @@ func TestItemWholesaleSkuPrice(t *testing.T) {
 		t.Fail()
 	}
 }
+
+// 测试修改SKU价格需要改价权限
+func TestItemSkuPriceChanged(t *testing.T) {
+	var itemId int32 = 6 //商品编号
+	it := ti.ItemRepo.GetItem(itemId)
+	origin := it.GetValue()
+	skuArr := it.SkuArray()
+	if len(skuArr) == 0 {
+		t.Log("商品没有SKU")
+		t.FailNow()
+	}
+	v := *origin
+	v.SkuArray = make([]*item.Sku, len(skuArr))
+	for i, s := range skuArr {
+		sku := *s
+		v.SkuArray[i] = &sku
+	}
+	if itemImpl.PriceChanged(origin, skuArr, &v) {
+		t.Error("价格未变更,不应要求改价权限")
+		t.FailNow()
+	}
+	v.SkuArray[0].Price += 1
+	if !itemImpl.PriceChanged(origin, skuArr, &v) {
+		t.Error("SKU价格已变更,应要求改价权限")
+		t.FailNow()
+	}
+	// 按规格匹配未带编号的SKU
+	v.SkuArray[0].Price = skuArr[0].Price
+	v.SkuArray[0].ID = 0
+	if itemImpl.PriceChanged(origin, skuArr, &v) {
+		t.Error("按规格匹配的SKU价格未变更,不应要求改价权限")
+		t.FailNow()
+	}
+	// 新增带价格的SKU
+	v.SkuArray = append(v.SkuArray, &item.Sku{
+		SpecData: "-1:-1",
+		Price:    1,
+	})
+	if !itemImpl.PriceChanged(origin, skuArr, &v) {
+		t.Error("新增带价格的SKU,应要求改价权限")
+		t.FailNow()
+	}
+}
+
+// 测试新建商品设置价格需要改价权限
+func TestItemCreatePriceChanged(t *testing.T) {
+	v := &item.GoodsItem{
+		SkuArray: []*item.Sku{{SpecData: "1:1"}},
+	}
+	if itemImpl.PriceChanged(nil, nil, v) {
+		t.Error("未设置价格,不应要求改价权限")
+		t.FailNow()
+	}
+	v.SkuArray[0].RetailPrice = 10
+	if !itemImpl.PriceChanged(nil, nil, v) {
+		t.Error("新建商品设置了SKU价格,应要求改价权限")
+		t.FailNow()
+	}
+	v.SkuArray[0].RetailPrice = 0
+	v.Price = 10
+	if !itemImpl.PriceChanged(nil, nil, v) {
+		t.Error("新建商品设置了价格,应要求改价权限")
+		t.FailNow()
+	}
+}
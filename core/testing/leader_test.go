@@ -0,0 +1,90 @@
+package testing
+
+import (
+	"go2o/core/module"
+	"go2o/core/testing/ti"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestLeaderNode() *module.LeaderModule {
+	l := &module.LeaderModule{}
+	l.SetApp(ti.GetApp())
+	l.Init()
+	return l
+}
+
+// 测试同一时间仅一个节点当选,放弃后其他节点当选并获得更大的令牌
+func TestLeaderCampaign(t *testing.T) {
+	name := "test-" + strconv.Itoa(int(time.Now().UnixNano()%1000000))
+	a, b := newTestLeaderNode(), newTestLeaderNode()
+	ta, err := a.Campaign(name)
+	if err != nil || ta == 0 {
+		t.Error("first node should be elected, got:", ta, err)
+		t.FailNow()
+	}
+	if tb, _ := b.Campaign(name); tb != 0 || b.IsLeader(name) {
+		t.Error("second node should not be elected while lease held")
+		t.FailNow()
+	}
+	// 续约保持相同令牌
+	if ta2, _ := a.Campaign(name); ta2 != ta {
+		t.Error("renew should keep token, got:", ta2, "expect:", ta)
+	}
+	if node, token := a.Leader(name); node != a.NodeId() || token != ta {
+		t.Error("leader info not match, got:", node, token)
+	}
+	a.Resign(name)
+	if a.IsLeader(name) {
+		t.Error("resigned node should not be leader")
+	}
+	tb, _ := b.Campaign(name)
+	if tb <= ta {
+		t.Error("new leader should get larger token, got:", tb, "old:", ta)
+		t.FailNow()
+	}
+	b.Resign(name)
+}
+
+// 测试旧主节点的令牌不能写入
+func TestLeaderFencedSet(t *testing.T) {
+	name := "test-" + strconv.Itoa(int(time.Now().UnixNano()%1000000))
+	key := "go2o:test:leader:" + name
+	a, b := newTestLeaderNode(), newTestLeaderNode()
+	ta, _ := a.Campaign(name)
+	if ok, err := a.FencedSet(name, ta, key, 1); !ok || err != nil {
+		t.Error("leader should write with its token, got:", ok, err)
+		t.FailNow()
+	}
+	// 旧主节点租约被释放后,新节点当选
+	a.Resign(name)
+	tb, _ := b.Campaign(name)
+	defer b.Resign(name)
+	if ok, _ := a.FencedSet(name, ta, key, 2); ok {
+		t.Error("stale token should be fenced")
+	}
+	if ok, _ := b.FencedSet(name, tb, key, 3); !ok {
+		t.Error("new leader should write with its token")
+	}
+	if ok, _ := b.FencedSet(name, 0, key, 4); ok {
+		t.Error("empty token should not write")
+	}
+}
+
+// 测试设置令牌起始值后,当选的令牌大于已登记的令牌,且不会回退
+func TestLeaderSeedToken(t *testing.T) {
+	name := "test-" + strconv.Itoa(int(time.Now().UnixNano()%1000000))
+	a := newTestLeaderNode()
+	if err := a.SeedToken(name, 1000); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	a.SeedToken(name, 10)
+	token, err := a.Campaign(name)
+	if err != nil || token != 1001 {
+		t.Error("token should continue from seed, got:", token, err)
+		t.FailNow()
+	}
+	a.Resign(name)
+}
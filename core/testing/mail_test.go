@@ -0,0 +1,135 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : mail_test.go
+ * author : jarryliu
+ * date : 2026-10-21 01:30
+ * description :
+ * history :
+ */
+package testing
+
+import (
+	"bufio"
+	"bytes"
+	"go2o/core/infrastructure/mss"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMailMultipart(t *testing.T) {
+	m := &mss.Mail{
+		From:    "go2o <noreply@go2o.cc>",
+		To:      []string{"user@go2o.cc"},
+		Subject: "订单通知",
+		Text:    "您的订单已发货",
+		Html:    "<p>您的订单已发货</p>",
+		Attachments: []*mss.Attachment{
+			{Name: "invoice.pdf", Data: []byte("%PDF")},
+		},
+	}
+	data, err := m.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mt != "multipart/mixed" {
+		t.Fatal("content type:", mt)
+	}
+	r := multipart.NewReader(msg.Body, params["boundary"])
+	types := []string{}
+	for {
+		p, err := r.NextPart()
+		if err != nil {
+			break
+		}
+		ct, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		types = append(types, ct)
+	}
+	if strings.Join(types, ",") != "multipart/alternative,application/pdf" {
+		t.Fatal("parts:", types)
+	}
+}
+
+// 简单的SMTP服务,返回连接数及接收的邮件数
+func startTestSmtpServer(t *testing.T) (addr string, conns *int32, mails *int32) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns, mails = new(int32), new(int32)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(conns, 1)
+			go func(c net.Conn) {
+				defer c.Close()
+				r := bufio.NewReader(c)
+				c.Write([]byte("220 go2o\r\n"))
+				data := false
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if data {
+						if line == ".\r\n" {
+							data = false
+							atomic.AddInt32(mails, 1)
+							c.Write([]byte("250 ok\r\n"))
+						}
+						continue
+					}
+					switch strings.ToUpper(strings.Fields(line + " _")[0]) {
+					case "DATA":
+						data = true
+						c.Write([]byte("354 go ahead\r\n"))
+					case "QUIT":
+						c.Write([]byte("221 bye\r\n"))
+						return
+					default:
+						c.Write([]byte("250 ok\r\n"))
+					}
+				}
+			}(c)
+		}
+	}()
+	return l.Addr().String(), conns, mails
+}
+
+func TestSmtpPoolReuse(t *testing.T) {
+	addr, conns, mails := startTestSmtpServer(t)
+	p := mss.NewSmtpPool(mss.SmtpConfig{
+		Server: addr,
+		Host:   "127.0.0.1",
+		From:   "noreply@go2o.cc",
+	}, 2)
+	defer p.Close()
+	for i := 0; i < 5; i++ {
+		err := p.Send(&mss.Mail{
+			To:      []string{"user@go2o.cc"},
+			Subject: "test",
+			Html:    "<p>test</p>",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := atomic.LoadInt32(mails); n != 5 {
+		t.Fatal("expect 5 mails, but", n)
+	}
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Fatal("connection not reused, connections:", n)
+	}
+}
@@ -0,0 +1,59 @@
+package testing
+
+import (
+	"go2o/core/domain/interface/merchant/user"
+	"go2o/core/testing/ti"
+	"testing"
+)
+
+// 测试员工通过授予的角色获得权限,并限定在指定的店铺
+func TestStaffPermission(t *testing.T) {
+	var mchId, shopId int32 = 1, 1
+	um := ti.MchRepo.GetMerchant(mchId).UserManager()
+	personId, err := ti.UserRepo.SavePerson(&user.PersonValue{
+		MchId:   mchId,
+		Name:    "staff",
+		Enabled: 1,
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	r := um.CreateRole(&user.RoleValue{Name: "发货员", Enabled: 1})
+	if err = r.SetPermissions([]string{"order.none"}); err != user.ErrNoSuchPermission {
+		t.Error("permission not in catalog, got:", err)
+		t.FailNow()
+	}
+	r.SetPermissions([]string{user.PermOrderShip, user.PermItemEdit})
+	roleId, err := r.Save()
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	defer um.DeleteRole(roleId)
+	if err = um.GrantRole(personId, roleId, shopId); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	u := um.GetUser(personId)
+	if err = u.CheckPermission(user.PermOrderShip, shopId); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if u.HasPermission(user.PermOrderShip, shopId+1) {
+		t.Error("permission should be scoped to shop", shopId)
+		t.FailNow()
+	}
+	if u.HasPermission(user.PermOrderShip, 0) {
+		t.Error("shop scoped role can't do merchant operation")
+		t.FailNow()
+	}
+	if u.CheckPermission(user.PermRefundApprove, shopId) != user.ErrPermissionDenied {
+		t.Error("staff can't approve refund")
+		t.FailNow()
+	}
+	um.RevokeRole(personId, roleId, shopId)
+	if u.HasPermission(user.PermOrderShip, shopId) {
+		t.Error("permission not revoked")
+	}
+}
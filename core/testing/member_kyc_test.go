@@ -0,0 +1,98 @@
+package testing
+
+import (
+	"fmt"
+	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/member"
+	"go2o/core/infrastructure/domain"
+	"go2o/core/infrastructure/kyc"
+	"go2o/core/testing/ti"
+	"testing"
+	"time"
+)
+
+// 生成随机的身份证号
+func randomCardId() string {
+	n := time.Now().UnixNano()
+	birth := time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local).AddDate(0, 0, int(n/1000%10000))
+	base := fmt.Sprintf("110105%s%03d", birth.Format("20060102"), n%1000)
+	for _, c := range "0123456789X" {
+		if id := base + string(c); kyc.CheckCardId(id) {
+			return id
+		}
+	}
+	return ""
+}
+
+// 创建已提交实名信息的会员
+func createTrustedMember(t *testing.T, cardId string) member.IMember {
+	m := ti.MemberRepo.CreateMember(&member.Member{
+		Usr: "kyc_" + domain.NewSecret(int(time.Now().UnixNano()))[:10],
+		Pwd: domain.MemberSha1Pwd("123456"),
+	})
+	if _, err := m.Save(); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	err := m.Profile().SaveTrustedInfo(&member.TrustedInfo{
+		RealName:   "张三",
+		CardId:     cardId,
+		TrustImage: "upload/trust/kyc.jpg",
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	return m
+}
+
+// 测试根据风险评分自动审核实名认证
+func TestAutoReviewTrustedInfo(t *testing.T) {
+	// 测试使用本地校验
+	stub := &kyc.StubVerifier{}
+	kyc.SetIdentityVerifier(stub)
+	kyc.SetFaceVerifier(stub)
+	cardId := randomCardId()
+	r, err := kyc.Verify("张三", cardId, "upload/trust/kyc.jpg", time.Now())
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	m := createTrustedMember(t, cardId)
+	reviewed, err := m.Profile().AutoReviewTrustedInfo(int32(r.RiskScore), r.Factors)
+	if err != nil || reviewed != enum.ReviewPass {
+		t.Error("should pass automatically, factors:", r.Factors, err)
+		t.FailNow()
+	}
+	// 已审核的不能再次自动审核
+	if _, err = m.Profile().AutoReviewTrustedInfo(0, nil); err != member.ErrNotAwaitingTrustReview {
+		t.Error("reviewed twice, got:", err)
+	}
+
+	// 风险中等时等待人工审核
+	m = createTrustedMember(t, randomCardId())
+	factors := []string{kyc.FactorFaceLowScore, kyc.FactorElder}
+	reviewed, _ = m.Profile().AutoReviewTrustedInfo(int32(kyc.Score(factors)), factors)
+	if reviewed != enum.ReviewAwaiting {
+		t.Error("should be reviewed manually")
+		t.FailNow()
+	}
+	_, list := ti.MemberRepo.GetAwaitingTrustedInfos(0, 1000)
+	found := false
+	for _, v := range list {
+		if v.MemberId == m.GetAggregateRootId() {
+			found = v.RiskScore == 50
+		}
+	}
+	if !found {
+		t.Error("not in manual review queue")
+	}
+
+	// 姓名与身份证号不一致时自动拒绝
+	m = createTrustedMember(t, randomCardId())
+	factors = []string{kyc.FactorIdentityMismatch}
+	reviewed, _ = m.Profile().AutoReviewTrustedInfo(int32(kyc.Score(factors)), factors)
+	if reviewed != enum.ReviewReject {
+		t.Error("should reject automatically")
+	}
+}
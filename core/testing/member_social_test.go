@@ -0,0 +1,129 @@
+package testing
+
+import (
+	"go2o/core/domain/interface/member"
+	"go2o/core/infrastructure/domain"
+	"go2o/core/infrastructure/social"
+	"go2o/core/module"
+	"go2o/core/testing/ti"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// 创建通过第三方账号注册的会员
+func createSocialMember(t *testing.T, provider string) member.IMember {
+	repo := ti.MemberRepo
+	m := repo.CreateMember(&member.Member{
+		Usr:     provider + "_" + domain.NewSecret(int(time.Now().UnixNano()))[:10],
+		Pwd:     domain.MemberSha1Pwd("123456"),
+		RegFrom: member.SocialRegFromPrefix + provider,
+	})
+	if _, err := m.Save(); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	return m
+}
+
+// 测试绑定及解绑第三方账号
+func TestBindSocialIdentity(t *testing.T) {
+	social.Register(&social.MockProvider{
+		ProviderName: "mock",
+		Identities: map[string]*social.Identity{
+			"code1": {OpenId: "mock_open_1", Nickname: "mock"},
+		},
+	})
+	id, _ := social.Exchange("mock", "code1")
+	m := createSocialMember(t, "mock")
+	sm := m.Social()
+	v := &member.SocialIdentity{Provider: id.Provider, OpenId: id.OpenId}
+	if err := sm.Bind(v); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if sm.GetIdentity("mock") == nil {
+		t.Error("identity not bound")
+		t.FailNow()
+	}
+	// 已绑定的账号不能绑定到其他会员
+	m2 := createSocialMember(t, "mock")
+	v = &member.SocialIdentity{Provider: id.Provider, OpenId: id.OpenId}
+	if err := m2.Social().Bind(v); err != member.ErrSocialIdentityBound {
+		t.Error("identity bound by other member, got:", err)
+		t.FailNow()
+	}
+	// 同一平台仅能绑定一个账号
+	v = &member.SocialIdentity{Provider: "mock", OpenId: "mock_open_2"}
+	if err := sm.Bind(v); err != member.ErrSocialProviderBound {
+		t.Error("provider already bound, got:", err)
+		t.FailNow()
+	}
+	// 未绑定手机时不能解绑唯一的账号
+	if err := sm.Unbind("mock"); err != member.ErrSocialLastIdentity {
+		t.Error("can't unbind last identity, got:", err)
+		t.FailNow()
+	}
+}
+
+// 测试合并第三方账号注册的会员到手机会员
+func TestMergeSocialMember(t *testing.T) {
+	m := createSocialMember(t, "mock")
+	v := &member.SocialIdentity{Provider: "mock",
+		OpenId: "merge_" + domain.NewSecret(int(time.Now().UnixNano()))}
+	if err := m.Social().Bind(v); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	target := ti.MemberRepo.GetMember(1)
+	if target.Social().GetIdentity("mock") != nil {
+		target.Social().Unbind("mock")
+	}
+	if err := m.Social().MergeInto(m); err != member.ErrSocialMergeSelf {
+		t.Error("can't merge into self, got:", err)
+		t.FailNow()
+	}
+	if err := target.Social().MergeInto(m); err != member.ErrSocialMergeSource {
+		t.Error("only social member can be merged, got:", err)
+		t.FailNow()
+	}
+	if err := m.Social().MergeInto(target); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if e := ti.MemberRepo.GetSocialIdentity("mock", v.OpenId); e == nil ||
+		e.MemberId != target.GetAggregateRootId() {
+		t.Error("identity not moved to target member")
+		t.FailNow()
+	}
+	if ti.MemberRepo.GetMember(m.GetAggregateRootId()).GetValue().State != 0 {
+		t.Error("source member not locked")
+	}
+	target.Social().Unbind("mock")
+}
+
+// 测试合并账号验证码仅能使用一次,连续失败后锁定
+func TestSocialMergeOpCode(t *testing.T) {
+	m := &module.CaptchaModule{}
+	m.SetApp(ti.GetApp())
+	m.Init()
+	target := strconv.Itoa(int(time.Now().UnixNano() % 1000000))
+	m.SetOpCode("merge", target, "1:123456", 600)
+	if err := m.VerifyOpCode("merge", target, "2:123456"); err != module.ErrOpCode {
+		t.Error("code bound to other member should be rejected, got:", err)
+	}
+	if err := m.VerifyOpCode("merge", target, "1:123456"); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if err := m.VerifyOpCode("merge", target, "1:123456"); err != module.ErrOpCode {
+		t.Error("code should not be reused, got:", err)
+	}
+	m.SetOpCode("merge", target, "1:654321", 600)
+	for i := 0; i < 5; i++ {
+		m.VerifyOpCode("merge", target, "1:000000")
+	}
+	if err := m.VerifyOpCode("merge", target, "1:654321"); err != module.ErrOpCodeLocked {
+		t.Error("target should be locked after too many failures, got:", err)
+	}
+}
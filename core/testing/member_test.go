@@ -2,7 +2,9 @@ package testing
 
 import (
 	"go2o/core/domain/interface/member"
+	"go2o/core/domain/interface/valueobject"
 	"go2o/core/infrastructure/domain"
+	"go2o/core/repository"
 	"go2o/core/testing/ti"
 	"testing"
 	"time"
@@ -55,8 +57,72 @@ func TestModifyPwd(t *testing.T) {
 		t.Error(err)
 		t.FailNow()
 	}
-	if o := m.GetValue().Pwd; o != newPwd {
+	if err := m.Profile().CheckPassword(newPwd); err != nil {
 		t.Logf("登陆密码不正确")
 		t.FailNow()
 	}
 }
+
+// 测试旧版本的密码登录后重新散列
+func TestUpgradeLegacyPwd(t *testing.T) {
+	repo := ti.MemberRepo
+	m := repo.GetMember(2)
+	v := m.GetValue()
+	legacyPwd := domain.MemberSha1Pwd("13268240456")
+	v.Pwd = legacyPwd
+	repo.SaveMember(&v)
+	m = repo.GetMember(2)
+	if err := m.Profile().CheckPassword(legacyPwd); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	v = repo.GetMember(2).GetValue()
+	if domain.IsLegacyPwd(v.Pwd) {
+		t.Error("密码未重新散列")
+		t.FailNow()
+	}
+	if err := m.Profile().CheckPassword(legacyPwd); err != nil {
+		t.Error(err)
+	}
+}
+
+// 测试默认设置下未实名会员提现
+func TestTakeOutNotTrusted(t *testing.T) {
+	origin := ti.ValueRepo.GetRegistry()
+	defer ti.ValueRepo.SaveRegistry(&origin)
+	// 使用默认设置,不按金额限制实名
+	conf := repository.DefaultRegistry
+	conf.RegistryData = make(map[string]string)
+	for k, v := range repository.DefaultRegistry.RegistryData {
+		conf.RegistryData[k] = v
+	}
+	ti.ValueRepo.SaveRegistry(&conf)
+
+	m := ti.MemberRepo.CreateMember(&member.Member{
+		Usr: "take_" + domain.NewSecret(int(time.Now().UnixNano()))[:10],
+		Pwd: domain.MemberSha1Pwd("123456"),
+	})
+	if _, err := m.Save(); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	acc := m.GetAccount()
+	kind := member.KindWalletTakeOutToBankCard
+	if err := acc.CheckTakeOut(kind, 1); err != member.ErrTakeOutNotTrust {
+		t.Error("提现必须实名时,未实名会员可以提现:", err)
+		t.FailNow()
+	}
+	// 关闭提现实名后,仅超过设定金额时需要实名
+	conf.TakeOutMustTrust = false
+	ti.ValueRepo.SaveRegistry(&conf)
+	if err := acc.CheckTakeOut(kind, 1); err == member.ErrTakeOutNotTrust {
+		t.Error("未设定实名金额时,不应要求实名")
+		t.FailNow()
+	}
+	conf.RegistryData[valueobject.RKMemberTrustRequiredAmount] = "100"
+	ti.ValueRepo.SaveRegistry(&conf)
+	if err := acc.CheckTakeOut(kind, 200); err != member.ErrTakeOutNotTrust {
+		t.Error("超过实名金额时,未实名会员可以提现:", err)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,174 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : notify_test.go
+ * author : jarryliu
+ * date : 2026-10-21 00:10
+ * description :
+ * history :
+ */
+package testing
+
+import (
+	"errors"
+	"go2o/core/domain/interface/mss/notify"
+	notifyImpl "go2o/core/domain/mss/notify"
+	"go2o/core/repository"
+	"go2o/core/testing/ti"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testNotifyKey    = "测试通知"
+	testSensitiveKey = "测试验证码"
+)
+
+type testSender struct {
+	err  error
+	sent []string
+}
+
+func (t *testSender) Send(key string, to *notify.Recipient, subject string,
+	body string, data map[string]interface{}) error {
+	if t.err != nil {
+		return t.err
+	}
+	t.sent = append(t.sent, body)
+	return nil
+}
+
+func registerTestNotifyItem() {
+	defer func() { recover() }() // 通知项已注册
+	notify.RegisterNotifyItem(testNotifyKey, &notify.NotifyItem{
+		Key:      testNotifyKey,
+		NotifyBy: notify.TypePushMessage,
+		Fallback: []int{notify.TypeSiteMessage},
+		Content:  "您好{name}",
+	})
+	notify.RegisterNotifyItem(testSensitiveKey, &notify.NotifyItem{
+		Key:      testSensitiveKey,
+		NotifyBy: notify.TypeSiteMessage,
+		Urgent:   true,
+		Content:  "验证码{code}",
+	})
+}
+
+// 测试敏感通知的发送记录不保存内容
+func TestNotifySensitiveMasked(t *testing.T) {
+	registerTestNotifyItem()
+	db := ti.GetApp().Db()
+	mgr := notifyImpl.NewNotifyManager(repository.NewNotifyRepo(db), ti.ValueRepo)
+	site := &testSender{}
+	mgr.RegisterSender(notify.TypeSiteMessage, site)
+	d, err := mgr.Dispatch(testSensitiveKey, &notify.Recipient{MemberId: 1},
+		map[string]interface{}{"code": "123456"})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if len(site.sent) != 1 || site.sent[0] != "验证码123456" {
+		t.Error("sensitive notify should be sent with content, got:", site.sent)
+	}
+	if d.Body != notify.MaskedContent {
+		t.Error("sensitive notify body should be masked, got:", d.Body)
+	}
+}
+
+func TestNotifyRender(t *testing.T) {
+	s, err := notifyImpl.Render(`{{if .vip}}尊敬的会员{{end}}{name},`+
+		`{{range .items}}[{{.}}]{{end}}`, map[string]interface{}{
+		"vip":   true,
+		"name":  "张三",
+		"items": []string{"a", "b"},
+	})
+	if err != nil || s != "尊敬的会员张三,[a][b]" {
+		t.Fatal(s, err)
+	}
+	if _, err = notifyImpl.Render("{{if .vip}", nil); err != notify.ErrTemplateSyntax {
+		t.Fatal("expect syntax error")
+	}
+}
+
+func TestNotifyDispatch(t *testing.T) {
+	registerTestNotifyItem()
+	db := ti.GetApp().Db()
+	mgr := notifyImpl.NewNotifyManager(repository.NewNotifyRepo(db), ti.ValueRepo)
+	push := &testSender{err: errors.New("device offline")}
+	site := &testSender{}
+	mgr.RegisterSender(notify.TypePushMessage, push)
+	mgr.RegisterSender(notify.TypeSiteMessage, site)
+
+	var memberId int64 = 1
+	mgr.SavePreference(&notify.Preference{MemberId: memberId})
+	mgr.SaveEventPref(memberId, testNotifyKey, []int{
+		notify.TypePushMessage, notify.TypeSiteMessage})
+	to := &notify.Recipient{MemberId: memberId}
+	data := map[string]interface{}{"name": "张三"}
+
+	// 推送失败后使用站内信
+	d, err := mgr.Dispatch(testNotifyKey, to, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Channel != notify.TypeSiteMessage || d.Body != "您好张三" {
+		t.Fatalf("expect site message, but %#v", d)
+	}
+	if !strings.HasPrefix(d.Trace, "4:device offline;") {
+		t.Fatal("trace:", d.Trace)
+	}
+
+	// 免打扰时段内不使用推送
+	now := time.Now()
+	m := now.Hour()*60 + now.Minute()
+	mgr.SavePreference(&notify.Preference{MemberId: memberId,
+		QuietStart: m, QuietEnd: (m + 60) % 1440})
+	push.err = nil
+	if d, _ = mgr.Dispatch(testNotifyKey, to, data); d.Channel != notify.TypeSiteMessage ||
+		len(push.sent) != 0 {
+		t.Fatal("push should be skipped in quiet hours")
+	}
+	mgr.SavePreference(&notify.Preference{MemberId: memberId, Locale: "en-US"})
+
+	// 按语言选择模板
+	id, err := mgr.SaveTemplate(&notify.Template{Key: testNotifyKey,
+		Locale: "en", Body: "Hello {{.name}}", Enabled: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mgr.DeleteTemplate(id)
+	if d, _ = mgr.Dispatch(testNotifyKey, to, data); d.Body != "Hello 张三" {
+		t.Fatal("expect english template, but", d.Body)
+	}
+
+	// 关闭通知
+	mgr.SaveEventPref(memberId, testNotifyKey, []int{})
+	if d, _ = mgr.Dispatch(testNotifyKey, to, data); d.State != notify.DeliveryMuted {
+		t.Fatal("expect muted")
+	}
+}
+
+func TestNotifyPushSiteMessage(t *testing.T) {
+	db := ti.GetApp().Db()
+	mgr := notifyImpl.NewNotifyManager(repository.NewNotifyRepo(db), ti.ValueRepo)
+	push := &testSender{}
+	mgr.RegisterSender(notify.TypePushMessage, push)
+
+	var memberId int64 = 1
+	mgr.SavePreference(&notify.Preference{MemberId: memberId})
+	mgr.SaveEventPref(memberId, "站内信", []int{notify.TypePushMessage})
+	msg := &notify.SiteMessage{Subject: "系统通知", Message: "您的账户已实名认证"}
+	if err := mgr.PushSiteMessage(memberId, msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(push.sent) != 1 || push.sent[0] != msg.Message {
+		t.Fatal("site message should be pushed, sent:", push.sent)
+	}
+
+	// 关闭推送后不推送,也不使用其他渠道
+	mgr.SaveEventPref(memberId, "站内信", []int{})
+	mgr.PushSiteMessage(memberId, msg)
+	if len(push.sent) != 1 {
+		t.Fatal("push should be skipped when muted")
+	}
+}
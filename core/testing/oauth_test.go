@@ -0,0 +1,87 @@
+package testing
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"go2o/core/domain/interface/oauth"
+	"go2o/core/module"
+	"go2o/core/testing/ti"
+	"testing"
+)
+
+// 测试注册应用并校验回调地址、授权范围及密钥
+func TestRegisterOAuthClient(t *testing.T) {
+	c := ti.OAuthRepo.CreateClient(&oauth.Client{})
+	err := c.SetValue(&oauth.Client{
+		Name:         "TestApp",
+		RedirectUris: "https://app.go2o.to/callback\ncom.go2o.app:/oauth",
+		Scopes:       "openid profile orders",
+		State:        oauth.ClientEnabled,
+	})
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	secret, _ := c.ResetSecret()
+	if _, err = c.Save(); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	c = ti.OAuthRepo.GetClient(c.GetValue().ClientId)
+	if !c.VerifySecret(secret) || c.VerifySecret(secret+"1") {
+		t.Error("client secret verify failed")
+		t.FailNow()
+	}
+	if c.CheckRedirectUri("https://app.go2o.to/callback?a=1") == nil {
+		t.Error("redirect uri must be exactly matched")
+		t.FailNow()
+	}
+	if _, err = c.CheckScope("openid account"); err != oauth.ErrScope {
+		t.Error("scope account is not allowed")
+		t.FailNow()
+	}
+	scope, _ := c.CheckScope("openid profile openid")
+	if scope != "openid profile" {
+		t.Error("scope not distinct:", scope)
+		t.FailNow()
+	}
+}
+
+// 测试公开应用必须使用PKCE
+func TestOAuthPkce(t *testing.T) {
+	c := ti.OAuthRepo.CreateClient(&oauth.Client{Public: 1})
+	if c.CheckPkce("", "") != oauth.ErrPkceRequired ||
+		c.CheckPkce("challenge", oauth.PkcePlain) != oauth.ErrPkceRequired {
+		t.Error("public client must use S256")
+		t.FailNow()
+	}
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	if !oauth.VerifyCodeChallenge(challenge, oauth.PkceS256, verifier) {
+		t.Error("code verifier not match")
+		t.FailNow()
+	}
+	if oauth.VerifyCodeChallenge(challenge, oauth.PkceS256, verifier+"x") {
+		t.Error("wrong code verifier passed")
+	}
+}
+
+// 测试其他应用使用刷新令牌时,令牌不失效
+func TestOAuthRefreshWrongClient(t *testing.T) {
+	m := &module.OAuthModule{}
+	m.SetApp(ti.GetApp())
+	m.Init()
+	tk, err := m.IssueToken("client_a", 1, oauth.ScopeOffline, "", 0)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if _, err = m.RefreshToken("client_b", tk.RefreshToken); err != module.ErrOAuthRefresh {
+		t.Error("refresh token of other client should be rejected, got:", err)
+		t.FailNow()
+	}
+	if _, err = m.RefreshToken("client_a", tk.RefreshToken); err != nil {
+		t.Error("refresh token should be kept after wrong client request:", err)
+	}
+}
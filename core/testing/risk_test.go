@@ -0,0 +1,97 @@
+package testing
+
+import (
+	"go2o/core/domain/interface/enum"
+	"go2o/core/domain/interface/risk"
+	"go2o/core/testing/ti"
+	"testing"
+	"time"
+)
+
+// 测试黑名单
+func TestRiskBlacklist(t *testing.T) {
+	rm := ti.RiskRepo.GetManager()
+	device := "dev_" + time.Now().Format("20060102150405.000")
+	if err := rm.AddBlackItem(risk.ListDevice, device, "test"); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	c := &risk.Context{
+		Client:    risk.Client{DeviceId: device},
+		Operation: risk.OpRegister,
+	}
+	if v, _ := rm.Evaluate(c); v.Decision != risk.DecisionDeny || v.Rules != "blacklist" {
+		t.Error("blacklisted device should be denied, got:", v.Decision, v.Rules)
+		t.FailNow()
+	}
+	rm.RemoveBlackItem(risk.ListDevice, device)
+	if v, _ := rm.Evaluate(c); v.Decision != risk.DecisionAllow {
+		t.Error("removed device should be allowed, got:", v.Rules)
+	}
+	if err := rm.AddBlackItem("email", "a@b.com", ""); err != risk.ErrListKind {
+		t.Error("should validate list kind")
+	}
+}
+
+// 测试操作频率
+func TestRiskVelocity(t *testing.T) {
+	rm := ti.RiskRepo.GetManager()
+	s := rm.GetSettings()
+	defer rm.SaveSettings(&s)
+	s2 := s
+	s2.MemberVelocity = 2
+	rm.SaveSettings(&s2)
+	// 使用不存在的会员,仅验证频率
+	c := &risk.Context{
+		Operation: risk.OpTakeOut,
+		MemberId:  time.Now().UnixNano() / 1000,
+		Amount:    10,
+	}
+	expects := []int{risk.DecisionAllow, risk.DecisionAllow,
+		risk.DecisionReview, risk.DecisionReview, risk.DecisionDeny}
+	for i, d := range expects {
+		v, err := rm.Evaluate(c)
+		if err != nil {
+			t.Error(err)
+			t.FailNow()
+		}
+		if v.Decision != d {
+			t.Errorf("attempt %d expect decision %d, got %d(%s)",
+				i+1, d, v.Decision, v.Rules)
+			t.FailNow()
+		}
+	}
+	_, list := ti.RiskRepo.QueryRecords(risk.DecisionDeny, 0, 1)
+	if len(list) == 0 || list[0].MemberId != c.MemberId {
+		t.Error("deny decision not recorded")
+	}
+}
+
+// 测试人工审核不能重复审核
+func TestRiskReview(t *testing.T) {
+	rm := ti.RiskRepo.GetManager()
+	rc := &risk.Record{
+		Operation: risk.OpTransfer,
+		MemberId:  time.Now().UnixNano() / 1000,
+		Amount:    10,
+	}
+	id, err := rm.SubmitReview(rc, "{}")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	v, err := rm.Review(id, true, "test")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if v.State != enum.ReviewPass {
+		t.Error("review state should be pass, got:", v.State)
+	}
+	if _, err = rm.Review(id, false, "again"); err != risk.ErrReviewed {
+		t.Error("should not review twice, got:", err)
+	}
+	if _, err = rm.Review(-1, true, ""); err != risk.ErrNoSuchReview {
+		t.Error("should return no such review, got:", err)
+	}
+}
@@ -0,0 +1,87 @@
+package testing
+
+import (
+	"go2o/core/domain/interface/security"
+	"go2o/core/infrastructure/totp"
+	"go2o/core/testing/ti"
+	"testing"
+	"time"
+)
+
+// 测试会员绑定及使用两步验证
+func TestMemberTwoFactor(t *testing.T) {
+	var memberId int64 = 1
+	tf := ti.SecurityRepo.CreateTwoFactor(security.UserMember, memberId)
+	if tf.Enabled() {
+		t.Log("two factor has been enabled")
+		return
+	}
+	uri, err := tf.Enroll("go2o", "jarry")
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	t.Log("provisioning uri:", uri)
+	code, _ := totp.Code(tf.GetValue().Secret, time.Now())
+	codes, err := tf.Activate(code)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	// 同一验证码不能重复使用
+	if err = tf.Verify(code); err != security.ErrTwoFactorCode {
+		t.Error("code should not be reused")
+		t.FailNow()
+	}
+	// 恢复码仅能使用一次
+	if err = tf.Verify(codes[0]); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if err = tf.Verify(codes[0]); err == nil {
+		t.Error("recovery code should not be reused")
+		t.FailNow()
+	}
+	// 敏感操作每次均需验证码,之前的验证不能放行
+	tf = ti.SecurityRepo.CreateTwoFactor(security.UserMember, memberId)
+	if err = tf.CheckCode(""); err != security.ErrTwoFactorRequired {
+		t.Error("sensitive operation should require code, got ", err)
+		t.FailNow()
+	}
+	if err = tf.CheckCode(codes[2]); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if err = tf.Disable(codes[1]); err != nil {
+		t.Error(err)
+	}
+}
+
+// 测试两步验证连续失败后锁定
+func TestTwoFactorLock(t *testing.T) {
+	userId := time.Now().Unix()
+	tf := ti.SecurityRepo.CreateTwoFactor(security.UserMerchantStaff, userId)
+	if _, err := tf.Enroll("go2o", "staff"); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	code, _ := totp.Code(tf.GetValue().Secret, time.Now())
+	if _, err := tf.Activate(code); err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	for i := 0; i < security.TwoFactorMaxFails; i++ {
+		if err := tf.Verify("000000"); err != security.ErrTwoFactorCode {
+			t.Error("wrong code should be rejected, got ", err)
+		}
+	}
+	code, _ = totp.Code(tf.GetValue().Secret, time.Now().Add(30*time.Second))
+	if err := tf.Verify(code); err != security.ErrTwoFactorLocked {
+		t.Error("two factor should be locked after too many failures, got ", err)
+	}
+	// 锁定状态已保存
+	tf = ti.SecurityRepo.CreateTwoFactor(security.UserMerchantStaff, userId)
+	if tf.GetValue().LockTime <= time.Now().Unix() {
+		t.Error("lock time not saved")
+	}
+}
@@ -1,7 +1,11 @@
 package testing
 
 import (
+	"bytes"
+	"go2o/core/domain/interface/shipment"
+	shipImpl "go2o/core/domain/shipment"
 	"go2o/core/testing/ti"
+	"strings"
 	"testing"
 )
 
@@ -12,3 +16,57 @@ func TestGetShipmentOrderByOrderId(t *testing.T) {
 		t.Logf("%#v", v.Value())
 	}
 }
+
+// 测试批量生成电子面单
+func TestRenderWaybills(t *testing.T) {
+	w := &shipment.Waybill{
+		OrderNo: "100000021289",
+		SpName:  "顺丰快递",
+		SpCode:  "SF",
+		SpOrder: "SF1001928374",
+		Sender: &shipment.WaybillContact{
+			Name:    "天河门店",
+			Phone:   "020-88888888",
+			Area:    "广东省 广州市 天河区",
+			Address: "天河路1号",
+		},
+		Receiver: &shipment.WaybillContact{
+			Name:    "张三",
+			Phone:   "13800138000",
+			Address: "广东省 佛山市 顺德区 大良街道100号",
+		},
+		Items: []*shipment.WaybillItem{
+			{Title: "商品A", Quantity: 1},
+			{Title: "商品B", Quantity: 2},
+		},
+	}
+	w2 := *w
+	w2.SpOrder = "SF^1001~928"
+	list := []*shipment.Waybill{w, &w2}
+	data, err := shipImpl.RenderWaybills(shipment.WaybillFormatPdf, list)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	if !bytes.HasPrefix(data, []byte("%PDF")) {
+		t.Error("pdf waybill should start with %PDF")
+	}
+	data, err = shipImpl.RenderWaybills(shipment.WaybillFormatZpl, list)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	zpl := string(data)
+	if n := strings.Count(zpl, "^XA"); n != 2 || strings.Count(zpl, "^XZ") != 2 {
+		t.Error("expect 2 labels, but got ", n)
+	}
+	if !strings.Contains(zpl, "^CI28") || !strings.Contains(zpl, ",E:ANMDS.TTF^FH^FD张三") {
+		t.Error("chinese text should use utf-8 and cjk font")
+	}
+	if !strings.Contains(zpl, "^FH^FDSF_5E1001_7E928^FS") || strings.Contains(zpl, "SF^1001") {
+		t.Error("barcode content not escaped")
+	}
+	if _, err := shipImpl.RenderWaybills("png", list); err != shipment.ErrNotSupportWaybillFormat {
+		t.Error("expect format error, but got ", err)
+	}
+}
@@ -0,0 +1,100 @@
+/**
+ * Copyright 2015 @ z3q.net.
+ * name : sms_test.go
+ * author : jarryliu
+ * date : 2026-10-21 12:00
+ * description :
+ * history :
+ */
+package testing
+
+import (
+	"errors"
+	"go2o/core/domain/interface/valueobject"
+	"go2o/core/infrastructure/tool/sms"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSmsFailover(t *testing.T) {
+	sms.ResetHealth()
+	defer sms.ResetHealth()
+	p1, p2 := sms.NewFakeProvider(), sms.NewFakeProvider()
+	p1.SetError(errors.New("gateway timeout"))
+	r := sms.NewRouter().Add(1, p1).Add(2, p2)
+	data := map[string]interface{}{"code": "1234"}
+
+	// 第一个服务商失败后转移到第二个
+	ret, err := r.Send("验证手机", "13800000000", "验证码{code}", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ret.Provider != 2 || ret.Trace != "1:gateway timeout;2:ok" {
+		t.Fatalf("failover error: %#v", ret)
+	}
+	if m := p2.Messages(); len(m) != 1 || m[0].Content != "验证码1234" ||
+		m[0].MsgId != ret.MsgId {
+		t.Fatal("message not recorded")
+	}
+
+	// 连续失败后暂停使用
+	r.Send("验证手机", "13800000000", "验证码{code}", data)
+	r.Send("验证手机", "13800000000", "验证码{code}", data)
+	if ret, _ = r.Send("验证手机", "13800000000", "验证码{code}", data); ret.Trace != "2:ok" {
+		t.Fatal("unhealthy provider should be skipped:", ret.Trace)
+	}
+
+	// 所有服务商都失败
+	p2.SetError(errors.New("no balance"))
+	if ret, err = r.Send("验证手机", "13800000000", "", data); err == nil ||
+		!strings.HasPrefix(ret.Trace, "2:no balance;1:") {
+		t.Fatal("expect error:", err, ret.Trace)
+	}
+}
+
+func TestSmsRouterFromSet(t *testing.T) {
+	sms.ResetHealth()
+	defer sms.ResetHealth()
+	sms.Fake.Reset()
+	defer sms.Fake.Reset()
+	set := valueobject.SmsApiSet{
+		valueobject.SmsAli:  {Priority: 1},
+		valueobject.SmsFake: {Default: true},
+		valueobject.SmsHttp: {},
+	}
+	// 阿里大鱼未设置模板时转移到本地短信
+	sms.Fake.SetError(errors.New("fake down"))
+	ret, _ := sms.NewRouterFromSet(set).Send("验证手机", "13800000000", "hi", nil)
+	if ret.Trace != "9:fake down;2:"+sms.ErrNoTemplate.Error() {
+		t.Fatal("route order:", ret.Trace)
+	}
+}
+
+func TestSmsFakeReceipt(t *testing.T) {
+	perm := &valueobject.SmsApiPerm{ReceiptToken: "secret"}
+	values := url.Values{
+		"msgid":  {"fake-1"},
+		"phone":  {"13800000000"},
+		"status": {"DELIVRD"},
+	}
+	if _, err := sms.ParseReceipt(valueobject.SmsFake, perm, values); err == nil {
+		t.Fatal("expect token error")
+	}
+	// 未设置回执令牌时不接收回执
+	empty := &valueobject.SmsApiPerm{}
+	if _, err := sms.ParseReceipt(valueobject.SmsFake, empty, values); err == nil {
+		t.Fatal("receipt without token should be rejected")
+	}
+	values.Set("token", "secret")
+	list, err := sms.ParseReceipt(valueobject.SmsFake, perm, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || !list[0].Delivered || list[0].MsgId != "fake-1" {
+		t.Fatalf("receipt error: %#v", list)
+	}
+	if _, err = sms.ParseReceipt(valueobject.SmsAli, perm, values); err == nil {
+		t.Fatal("ali should not support receipt")
+	}
+}
@@ -17,14 +17,21 @@ import (
 	"github.com/jsix/gof/storage"
 	"go2o/core"
 	"go2o/core/domain/interface/after-sales"
+	"go2o/core/domain/interface/audit"
 	"go2o/core/domain/interface/cart"
+	"go2o/core/domain/interface/delivery"
+	"go2o/core/domain/interface/event"
 	"go2o/core/domain/interface/express"
 	"go2o/core/domain/interface/item"
 	"go2o/core/domain/interface/member"
 	"go2o/core/domain/interface/merchant"
+	"go2o/core/domain/interface/merchant/user"
+	"go2o/core/domain/interface/oauth"
 	"go2o/core/domain/interface/order"
 	"go2o/core/domain/interface/pro_model"
 	"go2o/core/domain/interface/product"
+	"go2o/core/domain/interface/risk"
+	"go2o/core/domain/interface/security"
 	"go2o/core/domain/interface/shipment"
 	"go2o/core/domain/interface/valueobject"
 	"go2o/core/repository"
@@ -161,6 +168,13 @@ var (
 	MchRepo        merchant.IMerchantRepo
 	CartRepo       cart.ICartRepo
 	ShipmentRepo   shipment.IShipmentRepo
+	DeliveryRepo   delivery.IDeliveryRepo
+	SecurityRepo   security.ITwoFactorRepo
+	OAuthRepo      oauth.IOAuthRepo
+	UserRepo       user.IUserRepo
+	AuditRepo      audit.IAuditRepo
+	RiskRepo       risk.IRiskRepo
+	EventRepo      event.IEventRepo
 )
 
 func init() {
@@ -170,7 +184,8 @@ func init() {
 	sto := app.Storage()
 	proMRepo := repository.NewProModelRepo(db, orm)
 	valueRepo := repository.NewValueRepo(db, sto)
-	userRepo := repository.NewUserRepo(db)
+	secRepo := repository.NewSecurityRepo(db)
+	userRepo := repository.NewUserRepo(db, secRepo)
 	notifyRepo := repository.NewNotifyRepo(db)
 	mssRepo := repository.NewMssRepo(db, notifyRepo, valueRepo)
 	expressRepo := repository.NewExpressRepo(db, valueRepo)
@@ -211,4 +226,11 @@ func init() {
 	MchRepo = mchRepo
 	CartRepo = cartRepo
 	ShipmentRepo = shipRepo
+	DeliveryRepo = deliveryRepo
+	SecurityRepo = secRepo
+	OAuthRepo = repository.NewOAuthRepo(db)
+	UserRepo = userRepo
+	AuditRepo = repository.NewAuditRepo(db)
+	RiskRepo = repository.NewRiskRepo(db, MemberRepo)
+	EventRepo = repository.NewEventRepo(db)
 }
@@ -14,6 +14,12 @@ const (
 	//域名
 	ServerDomain = "domain"
 	ApiDomain    = "api_domain"
+	// 受信任的代理服务器,以逗号分隔的地址或网段,如:127.0.0.1,10.0.0.0/8
+	TrustedProxies = "trusted_proxies"
+	// 审计日志散列密钥,修改后已有日志将无法校验
+	AuditHmacKey = "audit_hmac_key"
+	// 审计日志散列密钥的示例值,不允许使用
+	AuditHmacKeySample = "change-me-audit-key"
 
 	//静态服务器
 	StaticServer = "static_server"
@@ -39,6 +45,28 @@ const (
 	SmtpFrom    = "smtp_from"
 	//是否关闭系统发送邮件队列
 	SystemMailQueueOff = "sys_mail_queue_off"
+	// SMTP连接池保持的连接数
+	SmtpPoolSize = "smtp_pool_size"
+	// 发送邮件的协程数
+	MailQueueWorkers = "mail_queue_workers"
+
+	// 苹果推送的.p8密钥文件
+	PushApnsKeyFile = "push_apns_key_file"
+	// 苹果推送的密钥编号
+	PushApnsKeyId = "push_apns_key_id"
+	// 苹果开发者团队编号
+	PushApnsTeamId = "push_apns_team_id"
+	// 应用的Bundle ID
+	PushApnsTopic = "push_apns_topic"
+	// 是否使用苹果推送的开发环境
+	PushApnsSandbox = "push_apns_sandbox"
+	// Firebase服务账号文件
+	PushFcmCredentials = "push_fcm_credentials"
+
+	// 是否启用Socket服务(TCP/WebSocket)推送,未启用时不写入推送队列
+	SocketServeOn = "socket_serve_on"
+	// WebSocket网关监听的地址,如::14198,为空时不启动
+	WebSocketServeAddr = "ws_serve_addr"
 )
 
 var (
@@ -14,12 +14,17 @@ const (
 	KvMemberUpdateTime            = "go2o:mm:uptime_"
 	KvAccountUpdateTime           = "go2o:acc:uptime_"
 	KvMemberUpdateTcpNotifyQueue  = "go2o:mm:queue:t_up_notify"
-	KvAccountUpdateTcpNotifyQueue = "go2o:q:acc_tcp_notify"  //账户TCP更新对列
-	KvMemberUpdateQueue           = "go2o:q:mm_update"       //新加入会员队列
-	KvPaymentOrderFinishQueue     = "go2o:q:pay_order"       //支付单完成通知队列
-	KvOrderBusinessQueue          = "go2o:q:sa_order_busi"   //订单业务队列(如已创建,已完成等只执行一次)
-	KvOrderExpiresTime            = "go2o:order:timeout"     //订单过期时间
-	KvOrderAutoReceive            = "go2o:order:autoreceive" //订单自动收货
+	KvAccountUpdateTcpNotifyQueue = "go2o:q:acc_tcp_notify"    //账户TCP更新对列
+	KvOrderStateTcpNotifyQueue    = "go2o:q:order_tcp_notify"  //订单状态TCP通知队列
+	KvMessageTcpNotifyQueue       = "go2o:q:msg_tcp_notify"    //站内信TCP通知队列
+	KvChatTcpNotifyQueue          = "go2o:q:chat_tcp_notify"   //客服会话TCP通知队列
+	KvMemberUpdateQueue           = "go2o:q:mm_update"         //新加入会员队列
+	KvPaymentOrderFinishQueue     = "go2o:q:pay_order"         //支付单完成通知队列
+	KvOrderBusinessQueue          = "go2o:q:sa_order_busi"     //订单业务队列(如已创建,已完成等只执行一次)
+	KvOrderExpiresTime            = "go2o:order:timeout"       //订单过期时间
+	KvOrderAutoReceive            = "go2o:order:autoreceive"   //订单自动收货
+	KvMerchantApiInfo             = "cache:partner:api:info-"  //商户接口信息缓存
+	KvMerchantApiLimit            = "cache:partner:api:limit-" //商户接口限流配置缓存
 )
 
 const (
@@ -815,4 +815,486 @@ ALTER TABLE `mch_shop`
 ALTER TABLE `pro_category`
   ADD COLUMN `icon_xy` VARCHAR(45) NOT NULL AFTER `icon`;
 
-update pro_category set icon_xy='0,0' WHERE id> 0 && icon_xy IS NULL;
\ No newline at end of file
+update pro_category set icon_xy='0,0' WHERE id> 0 && icon_xy IS NULL;
+/* 2026-10-19 */
+
+/*
+ 升级说明:订单超时取消、自动确认及自动收货改由延迟任务队列执行。
+ 自动确认原来在付款后立即执行,现在在订单更新时间(update_time)
+ 加上商户设置的确认分钟数(oa_confirm_minute,默认10分钟)后执行。
+ 确认任务在订单事件(如付款)发布时安排,升级前已等待确认(state=2)的订单
+ 没有确认任务,须在升级后由商户手动确认。
+ 任务保存在Redis中,可通过 delay_max_attempts、delay_retry_base 及
+ delay_visibility 设置最大投递次数、重试间隔及可见超时。
+*/
+
+CREATE TABLE `dlv_staff` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `mch_id` INT(11) NOT NULL COMMENT '商户编号',
+  `person_id` INT(11) NOT NULL COMMENT '人员编号',
+  `state` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '状态,1:在线',
+  `lng` DOUBLE NOT NULL DEFAULT 0 COMMENT '最后位置经度',
+  `lat` DOUBLE NOT NULL DEFAULT 0 COMMENT '最后位置纬度',
+  `locate_time` INT(11) NOT NULL DEFAULT 0 COMMENT '最后定位时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `mch_person` (`mch_id` ASC, `person_id` ASC))
+  COMMENT = '配送员';
+
+CREATE TABLE `dlv_dispatch` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `mch_id` INT(11) NOT NULL COMMENT '商户编号',
+  `sub_order_id` BIGINT(20) NOT NULL COMMENT '子订单编号',
+  `staff_id` INT(11) NOT NULL COMMENT '配送员(人员编号)',
+  `assign_mode` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '派单方式,0:手动,1:轮流,2:就近',
+  `dest_lng` DOUBLE NOT NULL DEFAULT 0 COMMENT '收货地址经度',
+  `dest_lat` DOUBLE NOT NULL DEFAULT 0 COMMENT '收货地址纬度',
+  `deliver_code` VARCHAR(10) NOT NULL COMMENT '签收码',
+  `proof_image` VARCHAR(150) NOT NULL DEFAULT '' COMMENT '签收照片',
+  `state` TINYINT(1) NOT NULL COMMENT '状态',
+  `remark` VARCHAR(120) NOT NULL DEFAULT '' COMMENT '备注',
+  `assign_time` INT(11) NOT NULL COMMENT '派单时间',
+  `accept_time` INT(11) NOT NULL DEFAULT 0 COMMENT '接单时间',
+  `pickup_time` INT(11) NOT NULL DEFAULT 0 COMMENT '取货时间',
+  `deliver_time` INT(11) NOT NULL DEFAULT 0 COMMENT '送达时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `sub_order_id` (`sub_order_id` ASC),
+  INDEX `mch_assign` (`mch_id` ASC, `assign_time` ASC))
+  COMMENT = '同城配送单';
+
+CREATE TABLE `dlv_dispatch_checkin` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `dispatch_id` INT(11) NOT NULL COMMENT '配送单编号',
+  `staff_id` INT(11) NOT NULL COMMENT '配送员',
+  `state` TINYINT(1) NOT NULL COMMENT '签到时配送单的状态',
+  `lng` DOUBLE NOT NULL COMMENT '经度',
+  `lat` DOUBLE NOT NULL COMMENT '纬度',
+  `create_time` INT(11) NOT NULL COMMENT '签到时间',
+  PRIMARY KEY (`id`),
+  INDEX `dispatch_id` (`dispatch_id` ASC))
+  COMMENT = '配送签到记录';
+
+ALTER TABLE `mch_api_info`
+  ADD COLUMN `old_secret` VARCHAR(45) NOT NULL DEFAULT '' COMMENT '轮换前的密钥' AFTER `api_secret`,
+  ADD COLUMN `old_secret_expires` INT(11) NOT NULL DEFAULT 0 COMMENT '旧密钥过期时间' AFTER `old_secret`;
+
+CREATE TABLE `mch_api_limit` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `mch_id` INT(11) NOT NULL COMMENT '商户编号',
+  `api_group` VARCHAR(20) NOT NULL COMMENT '接口分组',
+  `rate` INT(11) NOT NULL DEFAULT 0 COMMENT '每秒请求数,0为不限制',
+  `burst` INT(11) NOT NULL COMMENT '允许突发的请求数',
+  `daily_quota` INT(11) NOT NULL DEFAULT 0 COMMENT '每日配额,0为不限制',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `mch_group` (`mch_id` ASC, `api_group` ASC))
+  COMMENT = '商户接口限流配置';
+
+ALTER TABLE `mm_member`
+  CHANGE COLUMN `pwd` `pwd` VARCHAR(120) NOT NULL COMMENT '密码' ,
+  CHANGE COLUMN `trade_pwd` `trade_pwd` VARCHAR(120) NOT NULL COMMENT '交易密码';
+
+ALTER TABLE `mch_merchant`
+  CHANGE COLUMN `pwd` `pwd` VARCHAR(120) NOT NULL COMMENT '密码';
+
+CREATE TABLE `sec_two_factor` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `user_type` TINYINT(1) NOT NULL COMMENT '用户类型,1:会员 2:商户员工',
+  `user_id` BIGINT(20) NOT NULL COMMENT '用户编号',
+  `secret` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '密钥',
+  `state` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '状态',
+  `last_step` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '最后使用的验证码步数',
+  `recovery_codes` VARCHAR(1000) NOT NULL DEFAULT '' COMMENT '恢复码(散列)',
+  `verify_time` INT(11) NOT NULL DEFAULT 0 COMMENT '最后验证时间',
+  `fail_times` INT(11) NOT NULL DEFAULT 0 COMMENT '连续验证失败次数',
+  `lock_time` INT(11) NOT NULL DEFAULT 0 COMMENT '锁定到期时间',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `user` (`user_type` ASC, `user_id` ASC))
+  COMMENT = '两步验证';
+
+ALTER TABLE `usr_role`
+  ADD COLUMN `require_2fa` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否强制启用两步验证' AFTER `enabled`;
+
+ALTER TABLE `usr_person`
+  ADD COLUMN `role_flag` INT(11) NOT NULL DEFAULT 0 COMMENT '拥有的角色位值' AFTER `enabled`;
+
+ALTER TABLE `usr_credential`
+  CHANGE COLUMN `pwd` `pwd` VARCHAR(120) NOT NULL COMMENT '密码';
+
+CREATE TABLE `oa_client` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `client_id` VARCHAR(32) NOT NULL COMMENT '应用编号',
+  `client_secret` VARCHAR(120) NOT NULL DEFAULT '' COMMENT '应用密钥(散列)',
+  `name` VARCHAR(45) NOT NULL COMMENT '应用名称',
+  `logo` VARCHAR(120) NOT NULL DEFAULT '' COMMENT '图标',
+  `mch_id` INT(11) NOT NULL DEFAULT 0 COMMENT '商户编号,平台应用为0',
+  `redirect_uris` VARCHAR(1000) NOT NULL COMMENT '回调地址,以换行分隔',
+  `scopes` VARCHAR(120) NOT NULL DEFAULT '' COMMENT '允许的授权范围',
+  `public` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否为公开应用',
+  `trusted` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否受信任,无需会员确认',
+  `state` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '状态',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `client_id` (`client_id` ASC),
+  INDEX `mch_id` (`mch_id` ASC))
+  COMMENT = 'OAuth2应用';
+
+CREATE TABLE `oa_consent` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `client_id` VARCHAR(32) NOT NULL COMMENT '应用编号',
+  `scope` VARCHAR(120) NOT NULL COMMENT '已授权的范围',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `member_client` (`member_id` ASC, `client_id` ASC))
+  COMMENT = 'OAuth2会员授权';
+
+CREATE TABLE `mm_social_identity` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `provider` VARCHAR(20) NOT NULL COMMENT '平台,如:wechat',
+  `open_id` VARCHAR(64) NOT NULL COMMENT '用户在应用下的唯一标识',
+  `union_id` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '用户在平台下的唯一标识',
+  `nickname` VARCHAR(45) NOT NULL DEFAULT '' COMMENT '昵称',
+  `avatar` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '头像',
+  `bind_time` INT(11) NOT NULL COMMENT '绑定时间',
+  `login_time` INT(11) NOT NULL DEFAULT 0 COMMENT '最后登录时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `provider_open_id` (`provider` ASC, `open_id` ASC),
+  INDEX `provider_union_id` (`provider` ASC, `union_id` ASC),
+  INDEX `member_id` (`member_id` ASC))
+  COMMENT = '会员绑定的第三方账号';
+
+ALTER TABLE `usr_role`
+  ADD COLUMN `mch_id` INT(11) NOT NULL DEFAULT 0 COMMENT '商户编号,为0时表示系统角色' AFTER `id`,
+  ADD COLUMN `permissions` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '包含的权限,以逗号分隔' AFTER `require_2fa`,
+  ADD INDEX `mch_id` (`mch_id` ASC);
+
+ALTER TABLE `usr_person`
+  ADD COLUMN `mch_id` INT(11) NOT NULL DEFAULT 0 COMMENT '商户编号' AFTER `id`,
+  ADD INDEX `mch_id` (`mch_id` ASC);
+
+CREATE TABLE `usr_role_grant` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `person_id` INT(11) NOT NULL COMMENT '人员编号',
+  `role_id` INT(11) NOT NULL COMMENT '角色编号',
+  `shop_id` INT(11) NOT NULL DEFAULT 0 COMMENT '店铺编号,为0表示所有店铺',
+  `create_time` INT(11) NOT NULL COMMENT '授予时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `person_role_shop` (`person_id` ASC, `role_id` ASC, `shop_id` ASC),
+  INDEX `role_id` (`role_id` ASC))
+  COMMENT = '商户员工的角色授予';
+
+CREATE TABLE `sys_audit_log` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `actor_type` VARCHAR(20) NOT NULL COMMENT '操作人类型',
+  `actor_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '操作人编号',
+  `actor_role` VARCHAR(45) NOT NULL DEFAULT '' COMMENT '操作人角色',
+  `action` VARCHAR(45) NOT NULL COMMENT '操作',
+  `target_type` VARCHAR(20) NOT NULL COMMENT '目标聚合类型',
+  `target_id` VARCHAR(40) NOT NULL COMMENT '目标聚合编号',
+  `diff` TEXT NOT NULL COMMENT '变更内容',
+  `remark` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '备注',
+  `ip` VARCHAR(45) NOT NULL DEFAULT '' COMMENT 'IP地址',
+  `create_time` INT(11) NOT NULL COMMENT '操作时间',
+  `prev_hash` VARCHAR(64) NOT NULL COMMENT '上一条日志的散列值',
+  `hash` VARCHAR(64) NOT NULL COMMENT '散列值',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `prev_hash` (`prev_hash` ASC),
+  UNIQUE INDEX `hash` (`hash` ASC),
+  INDEX `target` (`target_type` ASC, `target_id` ASC),
+  INDEX `actor` (`actor_type` ASC, `actor_id` ASC))
+  COMMENT = '审计日志,以散列串联';
+
+ALTER TABLE `mm_trusted_info`
+  ADD COLUMN `risk_score` INT NOT NULL DEFAULT 0 COMMENT '风险分数' AFTER `remark`,
+  ADD COLUMN `risk_factors` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '风险因素' AFTER `risk_score`,
+  ADD INDEX `reviewed` (`reviewed` ASC, `risk_score` DESC);
+
+CREATE TABLE `risk_record` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `operation` VARCHAR(20) NOT NULL COMMENT '操作',
+  `member_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '会员编号',
+  `amount` DECIMAL(10,2) NOT NULL DEFAULT 0 COMMENT '金额',
+  `ip` VARCHAR(45) NOT NULL DEFAULT '' COMMENT 'IP地址',
+  `device_id` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '设备编号',
+  `decision` TINYINT(1) NOT NULL COMMENT '决策,1:通过 2:人工审核 3:拒绝',
+  `rules` VARCHAR(120) NOT NULL DEFAULT '' COMMENT '命中的规则',
+  `message` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '说明',
+  `ref_id` VARCHAR(40) NOT NULL DEFAULT '' COMMENT '关联的业务编号',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  INDEX `member` (`operation` ASC, `member_id` ASC, `create_time` ASC),
+  INDEX `ip` (`operation` ASC, `ip` ASC, `create_time` ASC),
+  INDEX `device` (`operation` ASC, `device_id` ASC, `create_time` ASC),
+  INDEX `decision` (`decision` ASC))
+  COMMENT = '风控记录';
+
+CREATE TABLE `risk_review` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `record_id` BIGINT(20) NOT NULL COMMENT '风控记录编号',
+  `operation` VARCHAR(20) NOT NULL COMMENT '操作',
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `amount` DECIMAL(10,2) NOT NULL DEFAULT 0 COMMENT '金额',
+  `data` TEXT NOT NULL COMMENT '审核通过后执行操作的参数',
+  `state` TINYINT(1) NOT NULL COMMENT '审核状态,1:待审核 2:不通过 3:通过',
+  `remark` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '审核备注',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  `review_time` INT(11) NOT NULL DEFAULT 0 COMMENT '审核时间',
+  PRIMARY KEY (`id`),
+  INDEX `state` (`state` ASC, `id` ASC))
+  COMMENT = '风控人工审核';
+
+CREATE TABLE `risk_blacklist` (
+  `id` INT NOT NULL AUTO_INCREMENT,
+  `kind` VARCHAR(20) NOT NULL COMMENT '类型',
+  `value` VARCHAR(64) NOT NULL COMMENT '内容',
+  `remark` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '备注',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `kind_value` (`kind` ASC, `value` ASC))
+  COMMENT = '风控黑名单';
+
+CREATE TABLE `sys_event_outbox` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `type` VARCHAR(40) NOT NULL COMMENT '事件类型',
+  `aggregate_type` VARCHAR(20) NOT NULL COMMENT '聚合类型',
+  `aggregate_id` VARCHAR(40) NOT NULL COMMENT '聚合编号',
+  `data` TEXT NOT NULL COMMENT '事件数据',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  INDEX `aggregate` (`aggregate_type` ASC, `aggregate_id` ASC))
+  COMMENT = '领域事件发件箱';
+
+CREATE TABLE `sys_event_offset` (
+  `consumer` VARCHAR(40) NOT NULL COMMENT '订阅者',
+  `last_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '已处理的事件编号',
+  `fail_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '处理失败的事件编号',
+  `fail_times` INT(11) NOT NULL DEFAULT 0 COMMENT '失败次数',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`consumer`))
+  COMMENT = '事件订阅者的位置';
+
+CREATE TABLE `sys_event_dead` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `consumer` VARCHAR(40) NOT NULL COMMENT '订阅者',
+  `event_id` BIGINT(20) NOT NULL COMMENT '事件编号',
+  `type` VARCHAR(40) NOT NULL COMMENT '事件类型',
+  `attempts` INT(11) NOT NULL COMMENT '处理次数',
+  `error` VARCHAR(255) NOT NULL COMMENT '最后的错误',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  INDEX `consumer` (`consumer` ASC))
+  COMMENT = '处理失败的事件';
+
+CREATE TABLE `mch_webhook` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `mch_id` INT(11) NOT NULL COMMENT '商户编号',
+  `url` VARCHAR(255) NOT NULL COMMENT '推送地址',
+  `secret` VARCHAR(64) NOT NULL COMMENT '签名密钥',
+  `events` VARCHAR(255) NOT NULL COMMENT '订阅的事件,以逗号分隔',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  INDEX `mch_id` (`mch_id` ASC))
+  COMMENT = '商户事件推送地址';
+
+CREATE TABLE `mch_webhook_delivery` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `webhook_id` INT(11) NOT NULL COMMENT '推送地址编号',
+  `mch_id` INT(11) NOT NULL COMMENT '商户编号',
+  `event_id` BIGINT(20) NOT NULL COMMENT '事件编号',
+  `event_type` VARCHAR(40) NOT NULL COMMENT '事件类型',
+  `payload` TEXT NOT NULL COMMENT '推送内容',
+  `state` TINYINT(1) NOT NULL COMMENT '状态,1:等待推送 2:成功 3:等待重试 4:失败',
+  `attempts` INT(11) NOT NULL DEFAULT 0 COMMENT '推送次数',
+  `response_code` INT(11) NOT NULL DEFAULT 0 COMMENT '响应状态码',
+  `response_body` VARCHAR(1024) NOT NULL DEFAULT '' COMMENT '响应内容',
+  `error` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '错误信息',
+  `duration` INT(11) NOT NULL DEFAULT 0 COMMENT '耗时(毫秒)',
+  `next_time` INT(11) NOT NULL DEFAULT 0 COMMENT '下次推送时间',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  INDEX `mch_webhook` (`mch_id` ASC, `webhook_id` ASC, `state` ASC))
+  COMMENT = '商户事件推送记录';
+
+CREATE TABLE `notify_template` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `notify_key` VARCHAR(40) NOT NULL COMMENT '通知项',
+  `channel` TINYINT(2) NOT NULL DEFAULT 0 COMMENT '渠道,0为所有渠道',
+  `locale` VARCHAR(10) NOT NULL DEFAULT '' COMMENT '语言,空为默认语言',
+  `subject` VARCHAR(120) NOT NULL DEFAULT '' COMMENT '主题',
+  `body` TEXT NOT NULL COMMENT '内容',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  INDEX `notify_key` (`notify_key` ASC))
+  COMMENT = '通知模板';
+
+CREATE TABLE `mm_notify_pref` (
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `locale` VARCHAR(10) NOT NULL DEFAULT '' COMMENT '语言',
+  `quiet_start` INT(4) NOT NULL DEFAULT 0 COMMENT '免打扰开始时间(当天分钟数)',
+  `quiet_end` INT(4) NOT NULL DEFAULT 0 COMMENT '免打扰结束时间(当天分钟数)',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`member_id`))
+  COMMENT = '会员通知偏好';
+
+CREATE TABLE `mm_notify_event_pref` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `notify_key` VARCHAR(40) NOT NULL COMMENT '通知项',
+  `channels` VARCHAR(20) NOT NULL DEFAULT '' COMMENT '按顺序使用的渠道,空为不接收',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `member_key` (`member_id` ASC, `notify_key` ASC))
+  COMMENT = '会员接收通知的渠道';
+
+CREATE TABLE `notify_delivery` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `notify_key` VARCHAR(40) NOT NULL COMMENT '通知项',
+  `member_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '会员编号',
+  `channel` TINYINT(2) NOT NULL DEFAULT 0 COMMENT '最终发送的渠道',
+  `locale` VARCHAR(10) NOT NULL DEFAULT '' COMMENT '语言',
+  `subject` VARCHAR(120) NOT NULL DEFAULT '' COMMENT '主题',
+  `body` TEXT NOT NULL COMMENT '内容',
+  `state` TINYINT(1) NOT NULL COMMENT '状态,1:成功 2:失败 3:会员已关闭',
+  `trace` TEXT NOT NULL COMMENT '各渠道的发送结果',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  INDEX `member_id` (`member_id` ASC))
+  COMMENT = '通知发送记录';
+
+ALTER TABLE `pt_mail_queue`
+  CHANGE COLUMN `body` `body` TEXT NULL COMMENT 'HTML内容',
+  ADD COLUMN `text_body` TEXT NOT NULL COMMENT '纯文本内容' AFTER `body`,
+  ADD COLUMN `attachments` TEXT NOT NULL COMMENT '附件,JSON格式' AFTER `text_body`,
+  ADD COLUMN `attempts` INT(11) NOT NULL DEFAULT 0 COMMENT '发送次数' AFTER `is_failed`,
+  ADD COLUMN `next_time` INT(11) NOT NULL DEFAULT 0 COMMENT '下次发送时间' AFTER `attempts`,
+  ADD COLUMN `error` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '错误信息' AFTER `next_time`;
+
+CREATE TABLE `sms_message` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `notify_key` VARCHAR(40) NOT NULL DEFAULT '' COMMENT '通知项',
+  `phone` VARCHAR(20) NOT NULL COMMENT '手机号码',
+  `provider` INT(11) NOT NULL DEFAULT 0 COMMENT '最终发送的服务商',
+  `msg_id` VARCHAR(64) NOT NULL DEFAULT '' COMMENT '服务商的短信编号',
+  `content` TEXT NOT NULL COMMENT '内容',
+  `state` TINYINT(1) NOT NULL COMMENT '状态,1:已提交 2:已送达 3:发送失败 4:未送达',
+  `report_status` VARCHAR(32) NOT NULL DEFAULT '' COMMENT '服务商回执的状态',
+  `trace` TEXT NOT NULL COMMENT '各服务商的发送结果',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  INDEX `provider_msg_id` (`provider` ASC, `msg_id` ASC),
+  INDEX `phone` (`phone` ASC))
+  COMMENT = '短信发送记录';
+
+CREATE TABLE `mss_chat_session` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `mch_id` INT(11) NOT NULL COMMENT '商户编号',
+  `agent_id` INT(11) NOT NULL DEFAULT 0 COMMENT '接待的客服(人员编号)',
+  `state` TINYINT(1) NOT NULL COMMENT '状态,1:等待接入 2:接待中 3:已结束',
+  `last_msg_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '最后一条消息编号',
+  `last_msg_time` INT(11) NOT NULL DEFAULT 0 COMMENT '最后一条消息的时间',
+  `member_unread` INT(11) NOT NULL DEFAULT 0 COMMENT '会员未读消息数量',
+  `agent_unread` INT(11) NOT NULL DEFAULT 0 COMMENT '客服未读消息数量',
+  `member_read_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '会员已读到的消息编号',
+  `agent_read_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '客服已读到的消息编号',
+  `create_time` INT(11) NOT NULL COMMENT '创建时间',
+  `assign_time` INT(11) NOT NULL DEFAULT 0 COMMENT '接入时间',
+  `close_time` INT(11) NOT NULL DEFAULT 0 COMMENT '结束时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  INDEX `member_mch` (`member_id` ASC, `mch_id` ASC),
+  INDEX `mch_agent_state` (`mch_id` ASC, `agent_id` ASC, `state` ASC))
+  COMMENT = '客服会话';
+
+CREATE TABLE `mss_chat_msg` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `session_id` INT(11) NOT NULL COMMENT '会话编号',
+  `sender_role` TINYINT(2) NOT NULL COMMENT '发送者角色,0:系统 1:会员 2:商户',
+  `sender_id` BIGINT(20) NOT NULL DEFAULT 0 COMMENT '发送者编号',
+  `msg_type` TINYINT(2) NOT NULL COMMENT '消息类型,1:文本 2:图片 3:商品 4:订单 5:系统提示',
+  `content` TEXT NOT NULL COMMENT '内容',
+  `create_time` INT(11) NOT NULL COMMENT '发送时间',
+  PRIMARY KEY (`id`),
+  INDEX `session_id` (`session_id` ASC, `id` ASC))
+  COMMENT = '客服会话消息';
+
+CREATE TABLE `mss_chat_agent` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `mch_id` INT(11) NOT NULL COMMENT '商户编号',
+  `person_id` INT(11) NOT NULL COMMENT '人员编号',
+  `state` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '状态,0:离线 1:在线 2:忙碌',
+  `max_sessions` INT(11) NOT NULL DEFAULT 5 COMMENT '同时接待的会话数量',
+  `assign_time` INT(11) NOT NULL DEFAULT 0 COMMENT '最后接入会话的时间',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `mch_person` (`mch_id` ASC, `person_id` ASC))
+  COMMENT = '商户客服';
+
+CREATE TABLE `mss_broadcast` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `subject` VARCHAR(120) NOT NULL COMMENT '标题',
+  `content` TEXT NOT NULL COMMENT '内容',
+  `segment` VARCHAR(2000) NOT NULL DEFAULT '' COMMENT '目标分群(JSON),为空时发送给所有会员',
+  `state` TINYINT(1) NOT NULL COMMENT '状态,1:广播中 2:已撤回',
+  `create_time` INT(11) NOT NULL COMMENT '发送时间',
+  `expires_time` INT(11) NOT NULL DEFAULT 0 COMMENT '过期时间,0为不过期',
+  PRIMARY KEY (`id`))
+  COMMENT = '广播站内信,会员读取收件箱时同步';
+
+CREATE TABLE `mss_inbox` (
+  `id` BIGINT(20) NOT NULL AUTO_INCREMENT,
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `broadcast_id` INT(11) NOT NULL DEFAULT 0 COMMENT '广播消息编号,0为单独发送的消息',
+  `subject` VARCHAR(120) NOT NULL COMMENT '标题',
+  `content` TEXT NOT NULL COMMENT '内容',
+  `has_read` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否已读',
+  `read_time` INT(11) NOT NULL DEFAULT 0 COMMENT '阅读时间',
+  `deleted` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否删除',
+  `create_time` INT(11) NOT NULL COMMENT '发送时间',
+  `expires_time` INT(11) NOT NULL DEFAULT 0 COMMENT '过期时间,0为不过期',
+  `broadcast_key` INT(11) AS (NULLIF(`broadcast_id`,0)) STORED COMMENT '用于广播消息去重,单独发送的消息为NULL',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `member_broadcast` (`member_id` ASC, `broadcast_key` ASC),
+  INDEX `member_read` (`member_id` ASC, `has_read` ASC),
+  INDEX `broadcast_id` (`broadcast_id` ASC),
+  INDEX `expires_time` (`expires_time` ASC))
+  COMMENT = '会员收件箱';
+
+CREATE TABLE `mss_inbox_cursor` (
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `last_id` INT(11) NOT NULL COMMENT '已同步的广播消息编号',
+  `update_time` INT(11) NOT NULL COMMENT '更新时间',
+  PRIMARY KEY (`member_id`))
+  COMMENT = '会员收件箱广播同步位置';
+
+CREATE TABLE `mm_member_group` (
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `group_id` INT(11) NOT NULL COMMENT '买家分组编号',
+  PRIMARY KEY (`member_id`))
+  COMMENT = '会员的买家分组';
+
+CREATE TABLE `mm_member_tag` (
+  `id` INT(11) NOT NULL AUTO_INCREMENT,
+  `member_id` BIGINT(20) NOT NULL COMMENT '会员编号',
+  `tag` VARCHAR(20) NOT NULL COMMENT '标签',
+  PRIMARY KEY (`id`),
+  INDEX `member_id` (`member_id` ASC),
+  INDEX `tag` (`tag` ASC))
+  COMMENT = '会员标签';
+
+CREATE TABLE `sys_leader_fence` (
+  `name` VARCHAR(20) NOT NULL COMMENT '选举名称',
+  `token` BIGINT(20) NOT NULL COMMENT '主节点令牌,只增不减',
+  PRIMARY KEY (`name`))
+  COMMENT = '主节点令牌,写入结算数据前校验';